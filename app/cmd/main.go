@@ -1,18 +1,29 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
 	"github.com/alibekabdrakhman1/orynal/config"
 	"github.com/alibekabdrakhman1/orynal/internal/app"
 	"go.uber.org/zap"
+	"os"
+	// Business.TimeZone loads on images without a zone database.
+	_ "time/tzdata"
 )
 
-//	@title			Reviews
-//	@version		1.0.0
-//	@description	Reviews Service
+const usage = `usage: orynal [command]
 
-//	@host		localhost:5000
-//	@BasePath	/api
+commands:
+  serve [-migrate] [-db URL]
+                     run the HTTP server (default)
+  migrate            manage the database schema, see "orynal migrate"
+  admin              manage user accounts, see "orynal admin"
+  seed               fill the database with demo data, see "orynal seed -h"`
 
+// The HTTP API is documented by controller.BuildOpenAPI and served at
+// /api/openapi.json, with Swagger UI at /api/docs.
 func main() {
 	logger, _ := zap.NewProduction()
 	defer logger.Sync()
@@ -26,6 +37,41 @@ func main() {
 		l.Fatalf("failed to load configs err: %v", err)
 	}
 
-	app := app.New(l, &cfg)
-	app.Run()
+	command, args := "serve", os.Args[1:]
+	if len(args) > 0 && args[0] != "" && args[0][0] != '-' {
+		command, args = args[0], args[1:]
+	}
+
+	a := app.New(l, &cfg)
+
+	switch command {
+	case "serve":
+		flags := flag.NewFlagSet("serve", flag.ExitOnError)
+		flags.BoolVar(&cfg.Database.AutoMigrate, "migrate", cfg.Database.AutoMigrate, "apply pending migrations before serving")
+		flags.StringVar(&cfg.Database.URL, "db", cfg.Database.URL, "database URL, e.g. sqlite://orynal.db (default: the Postgres settings)")
+		flags.Parse(args)
+
+		a.Run()
+	case "migrate":
+		exit(l, a.Migrate(context.Background(), args))
+	case "admin":
+		exit(l, a.Admin(context.Background(), args))
+	case "seed":
+		exit(l, a.Seed(context.Background(), args))
+	default:
+		exit(l, app.UsageError(usage))
+	}
+}
+
+func exit(l *zap.SugaredLogger, err error) {
+	var usageErr app.UsageError
+	switch {
+	case err == nil:
+		return
+	case errors.As(err, &usageErr):
+		fmt.Fprintln(os.Stderr, usageErr)
+		os.Exit(2)
+	default:
+		l.Fatal(err)
+	}
 }
@@ -10,20 +10,31 @@ type Config struct {
 	HttpServer `yaml:"HttpServer"`
 	Database   `yaml:"Database"`
 	Auth       `yaml:"User"`
+	Retention  `yaml:"Retention"`
+	Export     `yaml:"Export"`
+	Tracing    `yaml:"Tracing"`
+	Cache      `yaml:"Cache"`
+	Geocoder   `yaml:"Geocoder"`
+	Business   `yaml:"Business"`
 }
 
 type HttpServer struct {
 	Port            string        `yaml:"Port" env:"SERVER_PORT"`
 	ShutdownTimeout time.Duration `yaml:"ShutdownTimeout" env:"SHUTDOWN_TIMEOUT"`
+	DrainDelay      time.Duration `yaml:"DrainDelay" env:"DRAIN_DELAY"`
+	HealthTimeout   time.Duration `yaml:"HealthTimeout" env:"HEALTH_TIMEOUT"`
 }
 
 type Database struct {
-	Host     string `yaml:"Host" env:"DB_HOST"`
-	Port     int    `yaml:"Port" env:"DB_PORT"`
-	Database string `yaml:"Database" env:"DB_NAME"`
-	Username string `yaml:"Username" env:"DB_USER"`
-	Password string `yaml:"Password" env:"DB_PASSWORD"`
-	SslMode  string `yaml:"SslMode"`
+	// URL overrides the Postgres fields below, e.g. sqlite://orynal.db.
+	URL         string `yaml:"URL" env:"DB_URL"`
+	Host        string `yaml:"Host" env:"DB_HOST"`
+	Port        int    `yaml:"Port" env:"DB_PORT"`
+	Database    string `yaml:"Database" env:"DB_NAME"`
+	Username    string `yaml:"Username" env:"DB_USER"`
+	Password    string `yaml:"Password" env:"DB_PASSWORD"`
+	SslMode     string `yaml:"SslMode"`
+	AutoMigrate bool   `yaml:"AutoMigrate" env:"DB_AUTO_MIGRATE"`
 }
 
 type Auth struct {
@@ -31,6 +42,56 @@ type Auth struct {
 	JwtSecretKey      string `yaml:"JwtSecretKey"`
 }
 
+type Retention struct {
+	Period     time.Duration `yaml:"Period" env:"RETENTION_PERIOD"`
+	Interval   time.Duration `yaml:"Interval" env:"RETENTION_INTERVAL"`
+	CoolingOff time.Duration `yaml:"CoolingOff" env:"RETENTION_COOLING_OFF"`
+}
+
+type Export struct {
+	Dir     string        `yaml:"Dir" env:"EXPORT_DIR"`
+	LinkTTL time.Duration `yaml:"LinkTTL" env:"EXPORT_LINK_TTL"`
+	// SigningKey signs download links; it must differ from the JWT secret.
+	SigningKey string `yaml:"SigningKey" env:"EXPORT_SIGNING_KEY"`
+	// Workers build archives concurrently from a queue of Queue exports.
+	Workers int `yaml:"Workers" env:"EXPORT_WORKERS"`
+	Queue   int `yaml:"Queue" env:"EXPORT_QUEUE"`
+}
+
+type Tracing struct {
+	Exporter    string  `yaml:"Exporter" env:"TRACING_EXPORTER"`
+	Endpoint    string  `yaml:"Endpoint" env:"OTEL_EXPORTER_OTLP_ENDPOINT"`
+	Insecure    bool    `yaml:"Insecure"`
+	ServiceName string  `yaml:"ServiceName"`
+	SampleRatio float64 `yaml:"SampleRatio" env:"TRACING_SAMPLE_RATIO"`
+}
+
+type Cache struct {
+	Backend       string        `yaml:"Backend" env:"CACHE_BACKEND"`
+	Size          int           `yaml:"Size" env:"CACHE_SIZE"`
+	RedisAddr     string        `yaml:"RedisAddr" env:"REDIS_ADDR"`
+	RedisPassword string        `yaml:"RedisPassword" env:"REDIS_PASSWORD"`
+	RedisDB       int           `yaml:"RedisDB" env:"REDIS_DB"`
+	RestaurantTTL time.Duration `yaml:"RestaurantTTL"`
+	PopularTTL    time.Duration `yaml:"PopularTTL"`
+	StatisticsTTL time.Duration `yaml:"StatisticsTTL"`
+	FoodTTL       time.Duration `yaml:"FoodTTL"`
+	ServicesTTL   time.Duration `yaml:"ServicesTTL"`
+}
+
+type Geocoder struct {
+	Backend      string        `yaml:"Backend" env:"GEOCODER_BACKEND"`
+	URL          string        `yaml:"URL" env:"GEOCODER_URL"`
+	UserAgent    string        `yaml:"UserAgent"`
+	CountryCodes string        `yaml:"CountryCodes"`
+	Timeout      time.Duration `yaml:"Timeout"`
+}
+
+type Business struct {
+	// TimeZone the restaurants' opening hours are given in, UTC when empty.
+	TimeZone string `yaml:"TimeZone" env:"BUSINESS_TZ"`
+}
+
 func LoadConfig(path string) (config Config, err error) {
 	viper.AddConfigPath(path)
 	viper.SetConfigName("config")
@@ -50,6 +111,15 @@ func LoadConfig(path string) (config Config, err error) {
 	return config, nil
 }
 
+// DatabaseURL is the URL handed to gorm.Dial: Database.URL when set, the
+// Postgres DSN otherwise.
+func (config *Config) DatabaseURL() string {
+	if config.Database.URL != "" {
+		return config.Database.URL
+	}
+	return config.DSN()
+}
+
 func (config *Config) DSN() string {
 	return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
 		config.Database.Host,
@@ -60,3 +130,8 @@ func (config *Config) DSN() string {
 		config.Database.SslMode,
 	)
 }
+
+// Location is the business time zone opening hours are compared in.
+func (config *Config) Location() (*time.Location, error) {
+	return time.LoadLocation(config.Business.TimeZone)
+}
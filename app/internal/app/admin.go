@@ -0,0 +1,289 @@
+package app
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/alibekabdrakhman1/orynal/internal/model"
+	"github.com/alibekabdrakhman1/orynal/internal/repository"
+	"github.com/alibekabdrakhman1/orynal/pkg/enums"
+	"github.com/alibekabdrakhman1/orynal/pkg/utils"
+	"github.com/alibekabdrakhman1/orynal/pkg/validator"
+	"golang.org/x/term"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+const adminUsage = `usage: orynal admin <command>
+
+USER is a user id or the email of an active user.
+
+commands:
+  create -role admin|owner -email E -name N -surname S -phone P
+                          create an account, the password is prompted
+  reset-password USER     set a new prompted password
+  set-role USER ROLE      change the role to admin, owner or user
+  list [-role ROLE]       list users, including disabled ones
+  disable USER            block sign-in, keeping the account out of retention
+  enable ID               restore a disabled or deleted account
+
+Passwords are read from the terminal, or from the first line of stdin when it
+is not a terminal.`
+
+var roles = []string{enums.Admin, enums.Owner, enums.User}
+
+// Admin runs the "orynal admin" subcommand with the arguments after it.
+func (a *App) Admin(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return UsageError(adminUsage)
+	}
+
+	command, args := args[0], args[1:]
+	switch command {
+	case "create", "reset-password", "set-role", "list", "disable", "enable":
+	default:
+		return UsageError(adminUsage)
+	}
+
+	db, err := a.dial(ctx)
+	if err != nil {
+		return err
+	}
+	repo := repository.NewManager(db)
+
+	switch command {
+	case "create":
+		return a.createUser(ctx, repo, args)
+	case "reset-password":
+		return a.resetPassword(ctx, repo, args)
+	case "set-role":
+		return a.setRole(ctx, repo, args)
+	case "list":
+		return listUsers(ctx, repo, args)
+	case "disable":
+		return a.disableUser(ctx, repo, args)
+	default:
+		return a.enableUser(ctx, repo, args)
+	}
+}
+
+func (a *App) createUser(ctx context.Context, repo *repository.Manager, args []string) error {
+	flags := flag.NewFlagSet("admin create", flag.ContinueOnError)
+	user := &model.User{}
+	flags.StringVar(&user.Role, "role", enums.Admin, "admin or owner")
+	flags.StringVar(&user.Email, "email", "", "email, used to sign in")
+	flags.StringVar(&user.Name, "name", "", "first name")
+	flags.StringVar(&user.Surname, "surname", "", "last name")
+	flags.StringVar(&user.Phone, "phone", "", "phone number")
+	if err := flags.Parse(args); err != nil {
+		return UsageError(adminUsage)
+	}
+	if user.Role != enums.Admin && user.Role != enums.Owner {
+		return fmt.Errorf("cannot create a user with role %q, want admin or owner", user.Role)
+	}
+
+	password, err := readPassword()
+	if err != nil {
+		return err
+	}
+	user.Password = password
+
+	if err := validator.New().Validate(user); err != nil {
+		return err
+	}
+
+	if user.Password, err = utils.HashPassword(user.Password); err != nil {
+		return err
+	}
+
+	created, err := repo.User.Create(ctx, user)
+	if err != nil {
+		return err
+	}
+	a.logger.Infof("created %s %s with id %d", created.Role, created.Email, created.ID)
+
+	return nil
+}
+
+func (a *App) resetPassword(ctx context.Context, repo *repository.Manager, args []string) error {
+	if len(args) != 1 {
+		return UsageError(adminUsage)
+	}
+
+	user, err := findUser(ctx, repo, args[0])
+	if err != nil {
+		return err
+	}
+
+	password, err := readPassword()
+	if err != nil {
+		return err
+	}
+	if err := validator.New().Validate(&model.ChangePasswordRequest{OldPassword: "-", NewPassword: password}); err != nil {
+		return err
+	}
+
+	hash, err := utils.HashPassword(password)
+	if err != nil {
+		return err
+	}
+	if err := repo.User.SetPassword(ctx, user.ID, hash); err != nil {
+		return err
+	}
+	a.logger.Infof("reset password of %s", user.Email)
+
+	return nil
+}
+
+func (a *App) setRole(ctx context.Context, repo *repository.Manager, args []string) error {
+	if len(args) != 2 {
+		return UsageError(adminUsage)
+	}
+
+	role := args[1]
+	if !slices.Contains(roles, role) {
+		return fmt.Errorf("unknown role %q, want one of %s", role, strings.Join(roles, ", "))
+	}
+
+	user, err := findUser(ctx, repo, args[0])
+	if err != nil {
+		return err
+	}
+	if err := repo.User.SetRole(ctx, user.ID, role); err != nil {
+		return err
+	}
+	a.logger.Infof("changed role of %s from %s to %s", user.Email, user.Role, role)
+
+	return nil
+}
+
+func listUsers(ctx context.Context, repo *repository.Manager, args []string) error {
+	flags := flag.NewFlagSet("admin list", flag.ContinueOnError)
+	role := flags.String("role", "", "only list users with the role")
+	if err := flags.Parse(args); err != nil {
+		return UsageError(adminUsage)
+	}
+	if *role != "" && !slices.Contains(roles, *role) {
+		return fmt.Errorf("unknown role %q, want one of %s", *role, strings.Join(roles, ", "))
+	}
+
+	users, err := repo.User.List(ctx, *role)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tEMAIL\tNAME\tROLE\tSTATUS")
+	for _, user := range users {
+		status := "active"
+		switch {
+		case user.AnonymizedAt != nil:
+			status = "anonymized"
+		case user.DisabledAt != nil:
+			status = "disabled"
+		case user.DeletedAt.Valid:
+			status = "deleted"
+		case user.DeletionRequestedAt != nil:
+			status = "deletion requested"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s %s\t%s\t%s\n", user.ID, user.Email, user.Name, user.Surname, user.Role, status)
+	}
+
+	return w.Flush()
+}
+
+func (a *App) disableUser(ctx context.Context, repo *repository.Manager, args []string) error {
+	if len(args) != 1 {
+		return UsageError(adminUsage)
+	}
+
+	user, err := findUser(ctx, repo, args[0])
+	if err != nil {
+		return err
+	}
+	// Dropping the sessions stops refreshes; access tokens of a disabled user
+	// are rejected by the auth middleware. Unlike a deleted account, a
+	// disabled one is never anonymized by retention.
+	err = repo.WithTx(ctx, func(txRepos *repository.Manager) error {
+		if err := txRepos.User.Disable(ctx, user.ID); err != nil {
+			return err
+		}
+		return txRepos.UserToken.DeleteByUserID(ctx, user.ID)
+	})
+	if err != nil {
+		return err
+	}
+	a.logger.Infof("disabled %s", user.Email)
+
+	return nil
+}
+
+func (a *App) enableUser(ctx context.Context, repo *repository.Manager, args []string) error {
+	if len(args) != 1 {
+		return UsageError(adminUsage)
+	}
+
+	// Disabled users are hidden from lookups by email, so only ids work here.
+	id, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid user id %q, see orynal admin list", args[0])
+	}
+	if err := repo.User.Restore(ctx, uint(id), ""); err != nil {
+		return err
+	}
+	a.logger.Infof("enabled user %d", id)
+
+	return nil
+}
+
+func findUser(ctx context.Context, repo *repository.Manager, ref string) (*model.UserResponse, error) {
+	if id, err := strconv.ParseUint(ref, 10, 64); err == nil {
+		return repo.User.GetByID(ctx, uint(id))
+	}
+
+	user, err := repo.User.GetByEmail(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.UserResponse{ID: user.ID, Email: user.Email, Role: user.Role}, nil
+}
+
+// readPassword prompts twice on a terminal and reads a single line otherwise,
+// so provisioning scripts can pipe the password in.
+func readPassword() (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return "", err
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+
+	fmt.Fprint(os.Stderr, "Password: ")
+	password, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+
+	fmt.Fprint(os.Stderr, "Repeat password: ")
+	repeated, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+
+	if string(password) != string(repeated) {
+		return "", errors.New("passwords do not match")
+	}
+
+	return string(password), nil
+}
@@ -2,22 +2,34 @@ package app
 
 import (
 	"context"
+	"fmt"
 	"github.com/alibekabdrakhman1/orynal/config"
 	"github.com/alibekabdrakhman1/orynal/internal/controller"
 	http "github.com/alibekabdrakhman1/orynal/internal/controller/http/handler"
 	"github.com/alibekabdrakhman1/orynal/internal/controller/http/middleware"
 	"github.com/alibekabdrakhman1/orynal/internal/repository"
+	"github.com/alibekabdrakhman1/orynal/internal/repository/cached"
 	"github.com/alibekabdrakhman1/orynal/internal/service"
-	"github.com/alibekabdrakhman1/orynal/pkg/gorm"
+	"github.com/alibekabdrakhman1/orynal/pkg/cache"
+	"github.com/alibekabdrakhman1/orynal/pkg/geocode"
+	"github.com/alibekabdrakhman1/orynal/pkg/health"
+	"github.com/alibekabdrakhman1/orynal/pkg/migrate"
+	"github.com/alibekabdrakhman1/orynal/pkg/tracing"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 	"log"
 	"os"
 	"os/signal"
+	"sync"
+	"time"
 )
 
 type App struct {
 	logger *zap.SugaredLogger
 	config *config.Config
+	// jobs tracks the background jobs, which finish their work after ctx is
+	// done.
+	jobs sync.WaitGroup
 }
 
 func New(logger *zap.SugaredLogger, cfg *config.Config) *App {
@@ -32,21 +44,94 @@ func (a *App) Run() error {
 	defer cancel()
 	gracefullyShutdown(cancel)
 
-	db, err := gorm.Dial(ctx, a.config.DSN())
+	shutdownTracing, err := tracing.Init(ctx, a.config.Tracing)
 	if err != nil {
-		log.Fatalf("cannot сonnect to DB '%s:%d': %v", a.config.Database.Host, a.config.Database.Port, err)
+		log.Fatalf("cannot initialize tracing: %v", err)
 	}
+	defer func() {
+		ctxShutDown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctxShutDown); err != nil {
+			a.logger.Errorf("tracing shutdown err: %v", err)
+		}
+	}()
+
+	db, err := a.dial(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	server, err := a.newServer(ctx, db)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = server.StartHTTPServer(ctx)
+	a.jobs.Wait()
+
+	return err
+}
+
+// newServer wires the repositories, services and handlers on top of db and
+// starts the background jobs, which stop with ctx. SQLite databases are
+// local to the process, so their migrations are always applied.
+func (a *App) newServer(ctx context.Context, db *gorm.DB) (*controller.Server, error) {
+	migrator, err := a.newMigrator(db)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load migrations: %w", err)
+	}
+	if a.config.Database.AutoMigrate || db.Dialector.Name() == migrate.SQLite {
+		if _, err := migrator.Up(ctx); err != nil {
+			return nil, fmt.Errorf("cannot apply migrations: %w", err)
+		}
+	}
+
+	readiness := health.New(a.config.HttpServer.HealthTimeout)
+	readiness.Register(db.Dialector.Name(), health.Database(db))
+	readiness.Register("migrations", health.Migrations(db, migrator.Latest()))
 
 	repo := repository.NewManager(db)
 
-	srv := service.NewManager(repo, a.config, a.logger)
+	c, err := cache.New(a.config.Cache)
+	if err != nil {
+		return nil, fmt.Errorf("cannot configure cache: %w", err)
+	}
+	if c != nil {
+		cached.Wrap(repo, c, a.config.Cache, a.logger)
+	}
+	if r, ok := c.(*cache.Redis); ok {
+		readiness.Register("redis", r.Ping)
+	}
+
+	if key := a.config.Export.SigningKey; key == "" || key == a.config.Auth.JwtSecretKey {
+		return nil, fmt.Errorf("export signing key must be set and differ from the JWT secret")
+	}
+
+	if _, err := a.config.Location(); err != nil {
+		return nil, fmt.Errorf("cannot load business time zone: %w", err)
+	}
+
+	geocoder, err := geocode.New(a.config.Geocoder)
+	if err != nil {
+		return nil, fmt.Errorf("cannot configure geocoder: %w", err)
+	}
+
+	srv := service.NewManager(repo, geocoder, a.config, a.logger)
+	a.jobs.Add(2)
+	go func() {
+		defer a.jobs.Done()
+		srv.Retention.Run(ctx)
+	}()
+	go func() {
+		defer a.jobs.Done()
+		srv.Export.Run(ctx)
+	}()
 
 	endPointHandler := http.NewManager(srv, a.logger)
 
 	jwt := middleware.NewJWTAuth([]byte(a.config.Auth.JwtSecretKey), srv.Auth, a.logger)
 
-	HTTPServer := controller.NewServer(a.config, endPointHandler, jwt)
-	return HTTPServer.StartHTTPServer(ctx)
+	return controller.NewServer(a.config, endPointHandler, jwt, readiness, a.logger), nil
 }
 
 func gracefullyShutdown(c context.CancelFunc) {
@@ -0,0 +1,28 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	pkggorm "github.com/alibekabdrakhman1/orynal/pkg/gorm"
+	"gorm.io/gorm"
+)
+
+// UsageError is returned for malformed command lines; its text is the usage
+// to print.
+type UsageError string
+
+func (e UsageError) Error() string {
+	return string(e)
+}
+
+func (a *App) dial(ctx context.Context) (*gorm.DB, error) {
+	db, err := pkggorm.Dial(ctx, a.config.DatabaseURL())
+	if err != nil {
+		if a.config.Database.URL != "" {
+			return nil, fmt.Errorf("cannot connect to DB: %w", err)
+		}
+		return nil, fmt.Errorf("cannot connect to DB '%s:%d': %w", a.config.Database.Host, a.config.Database.Port, err)
+	}
+
+	return db, nil
+}
@@ -0,0 +1,534 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/alibekabdrakhman1/orynal/config"
+	"github.com/alibekabdrakhman1/orynal/internal/model"
+	"github.com/alibekabdrakhman1/orynal/internal/repository"
+	"github.com/alibekabdrakhman1/orynal/pkg/enums"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// client calls the API of a test server and decodes the data field of the
+// response.
+type client struct {
+	t      *testing.T
+	url    string
+	token  string
+	header http.Header
+}
+
+func (c *client) as(token string) *client {
+	return &client{t: c.t, url: c.url, token: token}
+}
+
+// on returns a client that reports failures to t, so a failed request stops
+// only the subtest it belongs to.
+func (c *client) on(t *testing.T) *client {
+	return &client{t: t, url: c.url, token: c.token, header: c.header}
+}
+
+// with returns a client that also sends the header key: value.
+func (c *client) with(key, value string) *client {
+	header := c.header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	header.Set(key, value)
+
+	return &client{t: c.t, url: c.url, token: c.token, header: header}
+}
+
+func (c *client) do(method, path string, body, data any) (int, string, http.Header) {
+	c.t.Helper()
+
+	var payload bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&payload).Encode(body); err != nil {
+			c.t.Fatal(err)
+		}
+	}
+
+	req, err := http.NewRequest(method, c.url+path, &payload)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	for key := range c.header {
+		req.Header.Set(key, c.header.Get(key))
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var envelope struct {
+		Message string          `json:"message"`
+		Data    json.RawMessage `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		c.t.Fatalf("%s %s: %v", method, path, err)
+	}
+	if data != nil && resp.StatusCode < 300 {
+		if err := json.Unmarshal(envelope.Data, data); err != nil {
+			c.t.Fatalf("%s %s: %v", method, path, err)
+		}
+	}
+
+	return resp.StatusCode, envelope.Message, resp.Header
+}
+
+func (c *client) must(status int, method, path string, body, data any) http.Header {
+	c.t.Helper()
+
+	got, message, header := c.do(method, path, body, data)
+	if got != status {
+		c.t.Fatalf("%s %s: status %d (%s), want %d", method, path, got, message, status)
+	}
+
+	return header
+}
+
+func (c *client) login(email, password string) model.JwtTokens {
+	c.t.Helper()
+
+	var tokens model.JwtTokens
+	c.must(http.StatusCreated, http.MethodPost, "/api/auth/login", model.Login{Email: email, Password: password}, &tokens)
+
+	return tokens
+}
+
+// TestServeSQLite runs the HTTP API on a SQLite file, the same way
+// orynal serve --db sqlite://file.db does. The subtests run in order on one
+// database: a restaurant with a table, a dish and an order, plus the data
+// each earlier subtest leaves behind.
+func TestServeSQLite(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cfg := &config.Config{
+		Database:   config.Database{URL: "sqlite://" + filepath.Join(t.TempDir(), "orynal.db")},
+		Auth:       config.Auth{JwtSecretKey: "secret"},
+		Export:     config.Export{SigningKey: "export-secret"},
+		HttpServer: config.HttpServer{HealthTimeout: time.Second},
+	}
+	a := New(zap.NewNop().Sugar(), cfg)
+
+	// Dialing twice, as migrate then serve does, replaces the pool metrics.
+	if _, err := a.dial(ctx); err != nil {
+		t.Fatal(err)
+	}
+	db, err := a.dial(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, err := a.newServer(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.App = server.BuildEngine()
+	server.SetupRoutes()
+
+	ts := httptest.NewServer(server.App)
+	defer ts.Close()
+
+	anonymous := &client{t: t, url: ts.URL}
+	anonymous.must(http.StatusOK, http.MethodGet, "/readyz", nil, nil)
+
+	register := func(t *testing.T, name, phone string) string {
+		email := name + "@orynal.kz"
+		anonymous.on(t).must(http.StatusCreated, http.MethodPost, "/api/auth/register", model.Register{
+			Name: name, Surname: name, Email: email, Phone: phone, Password: "password",
+		}, nil)
+		return email
+	}
+
+	adminEmail := register(t, "admin", "+77010000001")
+	if err := a.setRole(ctx, repository.NewManager(db), []string{adminEmail, enums.Admin}); err != nil {
+		t.Fatal(err)
+	}
+	admin := anonymous.as(anonymous.login(adminEmail, "password").AccessToken)
+
+	var service model.Service
+	admin.must(http.StatusCreated, http.MethodPost, "/api/admin/services", model.Service{Name: "Wi-Fi"}, nil)
+	var services []model.Service
+	admin.must(http.StatusOK, http.MethodGet, "/api/admin/services", nil, &services)
+	if len(services) != 1 {
+		t.Fatalf("services = %v, want one", services)
+	}
+	service = services[0]
+
+	var owner model.UserResponse
+	admin.must(http.StatusCreated, http.MethodPost, "/api/admin/owners", model.User{
+		Name: "owner", Surname: "owner", Email: "owner@orynal.kz", Phone: "+77010000002", Role: enums.Owner, Password: "password",
+	}, &owner)
+
+	almaty := model.Point{Lat: 43.2380, Lng: 76.9450}
+	var restaurant model.Restaurant
+	admin.must(http.StatusCreated, http.MethodPost, "/api/admin/restaurants", model.Restaurant{
+		Name:      "Orynal",
+		Address:   "Abay 1",
+		City:      "Almaty",
+		Latitude:  &almaty.Lat,
+		Longitude: &almaty.Lng,
+		Status:    true,
+		Phone:     "+77010000003",
+		OwnerID:   owner.ID,
+		ModeFrom:  time.Date(0, 1, 1, 10, 0, 0, 0, time.UTC),
+		ModeTo:    time.Date(0, 1, 1, 22, 0, 0, 0, time.UTC),
+		Icon:      model.Photo{Route: "icon.png"},
+		Services:  []model.Service{service},
+		Photos:    []model.Photo{{Route: "hall.png"}},
+	}, &restaurant)
+	restaurantPath := fmt.Sprintf("/api/restaurants/%d", restaurant.ID)
+
+	ownerClient := anonymous.as(anonymous.login("owner@orynal.kz", "password").AccessToken)
+
+	var table model.Table
+	ownerClient.must(http.StatusCreated, http.MethodPost, restaurantPath+"/tables", model.Table{
+		Name: "Window", Type: "hall", Capacity: 4, Photo: model.Photo{Route: "window.png"},
+	}, &table)
+	ownerClient.must(http.StatusCreated, http.MethodPost, restaurantPath+"/tables", model.Table{
+		Name: "Terrace", Type: "terrace", Capacity: 2, Photo: model.Photo{Route: "terrace.png"},
+	}, nil)
+	tablePath := fmt.Sprintf("%s/tables/%d", restaurantPath, table.ID)
+
+	var food model.Food
+	ownerClient.must(http.StatusCreated, http.MethodPost, restaurantPath+"/menu", model.Food{
+		Name: "Beshbarmak", Type: "main", Price: 4500, Available: true, Halal: true, Photo: model.Photo{Route: "beshbarmak.png"},
+	}, &food)
+	foodPath := fmt.Sprintf("%s/menu/%d", restaurantPath, food.ID)
+
+	clientEmail := register(t, "client", "+77010000004")
+	tokens := anonymous.login(clientEmail, "password")
+	user := anonymous.as(tokens.AccessToken)
+
+	date := time.Date(2030, 5, 17, 19, 30, 0, 0, time.UTC)
+	var order model.OrderResponse
+	user.must(http.StatusCreated, http.MethodPost, "/api/orders/create", model.OrderRequest{
+		RestaurantID: restaurant.ID,
+		TableID:      table.ID,
+		TotalSum:     4500,
+		Date:         date,
+		OrderFoods:   []uint{food.ID},
+	}, &order)
+	orderPath := fmt.Sprintf("/api/orders/%d", order.ID)
+
+	t.Run("etags", func(t *testing.T) {
+		anonymous, ownerClient := anonymous.on(t), ownerClient.on(t)
+
+		// Updates are conditional on the ETag of the version they were based on.
+		etag := anonymous.must(http.StatusOK, http.MethodGet, tablePath, nil, nil).Get("ETag")
+		if etag != `"1"` {
+			t.Fatalf("table ETag = %s, want \"1\"", etag)
+		}
+		rename := model.Table{Name: "Window seat", Type: "hall", Capacity: 4}
+		ownerClient.must(http.StatusPreconditionRequired, http.MethodPut, tablePath, rename, nil)
+		ownerClient.with("If-Match", "1").must(http.StatusBadRequest, http.MethodPut, tablePath, rename, nil)
+		var renamed model.Table
+		header := ownerClient.with("If-Match", etag).must(http.StatusOK, http.MethodPut, tablePath, rename, &renamed)
+		if renamed.Version != 2 || header.Get("ETag") != `"2"` {
+			t.Errorf("updated table version %d, ETag %s, want 2", renamed.Version, header.Get("ETag"))
+		}
+		ownerClient.with("If-Match", etag).must(http.StatusPreconditionFailed, http.MethodPut, tablePath, rename, nil)
+		ownerClient.with("If-Match", "*").must(http.StatusOK, http.MethodPut, tablePath, rename, nil)
+	})
+
+	t.Run("merge patch", func(t *testing.T) {
+		anonymous, ownerClient := anonymous.on(t), ownerClient.on(t)
+
+		// Merge patches write false and null, which PUT skips.
+		unavailable := map[string]any{"available": false, "description": nil}
+		ownerClient.with("If-Match", `"1"`).must(http.StatusOK, http.MethodPatch, foodPath, unavailable, nil)
+		ownerClient.with("If-Match", `"2"`).must(http.StatusForbidden, http.MethodPatch, foodPath, map[string]any{"restaurantId": 1}, nil)
+		var patched model.Food
+		anonymous.must(http.StatusOK, http.MethodGet, foodPath, nil, &patched)
+		if patched.Available || patched.Version != 2 || patched.Name != food.Name {
+			t.Errorf("patched food = %+v, want unavailable at version 2", patched)
+		}
+		ownerClient.with("If-Match", "*").must(http.StatusOK, http.MethodPatch, foodPath, map[string]any{"available": true}, nil)
+	})
+
+	t.Run("profile", func(t *testing.T) {
+		anonymous, user := anonymous.on(t), user.on(t)
+
+		// PUT /api/profile replaces the contact fields only.
+		var profile model.UserResponse
+		user.must(http.StatusOK, http.MethodPut, "/api/profile", map[string]string{
+			"name": "Client", "surname": "Client", "email": clientEmail, "phone": "+77010000004", "password": "plaintext", "role": enums.Admin,
+		}, &profile)
+		if profile.Name != "Client" || profile.Role != enums.User {
+			t.Errorf("profile = %+v, want the new name and the user role", profile)
+		}
+		anonymous.login(clientEmail, "password")
+		user.must(http.StatusUnprocessableEntity, http.MethodPut, "/api/profile", map[string]string{"name": "Client"}, nil)
+	})
+
+	t.Run("orders", func(t *testing.T) {
+		anonymous, user := anonymous.on(t), user.on(t)
+
+		var got model.OrderResponse
+		user.must(http.StatusOK, http.MethodGet, orderPath, nil, &got)
+		if got.Restaurant.ID != restaurant.ID || got.Table.ID != table.ID || len(got.Foods) != 1 || got.Foods[0].ID != food.ID {
+			t.Errorf("order = %+v, want the restaurant, the table and the dish", got)
+		}
+
+		available := func(day time.Time) int {
+			var list struct {
+				TotalItems int `json:"totalItems"`
+			}
+			path := restaurantPath + "/tables?date=" + day.Format("2006-01-02T15:04:05")
+			anonymous.must(http.StatusOK, http.MethodGet, path, nil, &list)
+			return list.TotalItems
+		}
+		if n := available(date.Add(-19 * time.Hour)); n != 1 {
+			t.Errorf("tables on the order day = %d, want 1", n)
+		}
+		if n := available(date.AddDate(0, 0, 1)); n != 2 {
+			t.Errorf("tables on the next day = %d, want 2", n)
+		}
+	})
+
+	t.Run("restaurant", func(t *testing.T) {
+		anonymous, ownerClient, user := anonymous.on(t), ownerClient.on(t), user.on(t)
+
+		user.must(http.StatusCreated, http.MethodPost, restaurantPath+"/reviews", model.RestaurantReview{
+			Stars: 5, Description: "Great",
+		}, nil)
+
+		ownerClient.with("If-Match", "*").must(http.StatusOK, http.MethodPatch, restaurantPath, map[string]any{"status": false}, nil)
+
+		var detail model.Restaurant
+		anonymous.must(http.StatusOK, http.MethodGet, restaurantPath, nil, &detail)
+		if detail.Icon.Route != "icon.png" || len(detail.Services) != 1 || len(detail.Photos) != 1 {
+			t.Errorf("restaurant = %+v, want the icon, the service and the photo", detail)
+		}
+		if detail.Status || detail.Version != 2 {
+			t.Errorf("restaurant status %t at version %d, want closed at version 2", detail.Status, detail.Version)
+		}
+	})
+
+	register(t, "second", "+77010000005")
+	register(t, "third", "+77010000006")
+
+	t.Run("cursor pagination", func(t *testing.T) {
+		admin := admin.on(t)
+
+		// Cursors page through clients in id order; offset pages past the end
+		// are empty.
+		type clientPage struct {
+			Items        []model.UserResponse `json:"items"`
+			ItemsPerPage int                  `json:"itemsPerPage"`
+			NextCursor   string               `json:"next_cursor"`
+			PrevCursor   string               `json:"prev_cursor"`
+		}
+		var first, second, back, past, capped clientPage
+		admin.must(http.StatusOK, http.MethodGet, "/api/admin/clients?limit=2", nil, &first)
+		if len(first.Items) != 2 || first.NextCursor == "" || first.PrevCursor != "" {
+			t.Fatalf("first page = %+v, want two clients and a next cursor", first)
+		}
+		admin.must(http.StatusOK, http.MethodGet, "/api/admin/clients?limit=2&after="+first.NextCursor, nil, &second)
+		if len(second.Items) != 1 || second.Items[0].Name != "third" || second.NextCursor != "" || second.PrevCursor == "" {
+			t.Errorf("second page = %+v, want the last client and a prev cursor", second)
+		}
+		admin.must(http.StatusOK, http.MethodGet, "/api/admin/clients?limit=2&before="+second.PrevCursor, nil, &back)
+		if len(back.Items) != 2 || back.Items[0].ID != first.Items[0].ID || back.Items[1].ID != first.Items[1].ID {
+			t.Errorf("page before = %+v, want the first page", back)
+		}
+		admin.must(http.StatusOK, http.MethodGet, "/api/admin/clients?page=5", nil, &past)
+		if len(past.Items) != 0 {
+			t.Errorf("page past the end = %+v, want no clients", past)
+		}
+		admin.must(http.StatusOK, http.MethodGet, "/api/admin/clients?limit=100000", nil, &capped)
+		if capped.ItemsPerPage != model.MaxLimit {
+			t.Errorf("itemsPerPage = %d, want %d", capped.ItemsPerPage, model.MaxLimit)
+		}
+		admin.must(http.StatusBadRequest, http.MethodGet, "/api/admin/clients?after=bogus", nil, nil)
+		admin.must(http.StatusBadRequest, http.MethodGet, "/api/admin/clients?page=2&after="+first.NextCursor, nil, nil)
+	})
+
+	t.Run("filters", func(t *testing.T) {
+		anonymous, admin, user := anonymous.on(t), admin.on(t), user.on(t)
+
+		// Filters and sorts are declared per resource and bound as parameters.
+		list := func(c *client, path, query string) []map[string]any {
+			var page struct {
+				Items []map[string]any `json:"items"`
+			}
+			c.must(http.StatusOK, http.MethodGet, path+"?"+query, nil, &page)
+			return page.Items
+		}
+		filterQuery := func(filter string) string { return "filter=" + url.QueryEscape(filter) }
+		if items := list(admin, "/api/admin/clients", filterQuery(`{"name":{"like":"IR"}}`)); len(items) != 1 || items[0]["name"] != "third" {
+			t.Errorf("clients like ir = %v, want third", items)
+		}
+		if items := list(admin, "/api/admin/clients", "order="+url.QueryEscape(`["-name"]`)); len(items) != 3 || items[0]["name"] != "third" {
+			t.Errorf("clients by name descending = %v, want third first", items)
+		}
+		if items := list(anonymous, restaurantPath+"/tables", filterQuery(`{"capacity":{"gte":3}}`)); len(items) != 1 || items[0]["id"] != float64(table.ID) {
+			t.Errorf("tables for 3 = %v, want the window table", items)
+		}
+		if items := list(user, "/api/orders", filterQuery(fmt.Sprintf(`{"tableId":{"in":[%d]},"totalSum":{"lte":5000}}`, table.ID))); len(items) != 1 {
+			t.Errorf("orders at the table = %v, want one", items)
+		}
+		if items := list(user, "/api/orders", filterQuery(`{"status":"canceled"}`)); len(items) != 0 {
+			t.Errorf("canceled orders = %v, want none", items)
+		}
+		for _, query := range []string{
+			filterQuery(`{"password":"x"}`),
+			filterQuery(`{"status":{"like":"res"}}`),
+			filterQuery(`{"date":{"gte":"yesterday"}}`),
+			"order=" + url.QueryEscape(`["phone"]`),
+			"order=" + url.QueryEscape(`["id; DROP TABLE orders"]`),
+		} {
+			user.must(http.StatusBadRequest, http.MethodGet, "/api/orders?"+query, nil, nil)
+		}
+	})
+
+	t.Run("search", func(t *testing.T) {
+		anonymous := anonymous.on(t)
+
+		// Search matches dishes as well as restaurants and marks the match.
+		var hits struct {
+			Items []model.SearchHit `json:"items"`
+		}
+		anonymous.must(http.StatusOK, http.MethodGet, "/api/search?q=beshbarmak", nil, &hits)
+		if len(hits.Items) != 1 || hits.Items[0].Restaurant.ID != restaurant.ID || !strings.Contains(hits.Items[0].Snippet, "<mark>Beshbarmak</mark>") {
+			t.Errorf("search beshbarmak = %+v, want the restaurant with a marked snippet", hits.Items)
+		}
+		anonymous.must(http.StatusBadRequest, http.MethodGet, "/api/search", nil, nil)
+	})
+
+	t.Run("dish search", func(t *testing.T) {
+		anonymous := anonymous.on(t)
+
+		// Dish search groups dishes by restaurant and measures the distance
+		// from the given point. The restaurant subtest closed the restaurant.
+		var groups struct {
+			Items []model.DishGroup `json:"items"`
+		}
+		anonymous.must(http.StatusOK, http.MethodGet, "/api/foods/search?q=BESH&dietary=halal&city=almaty&max_price=5000&lat=43.25&lng=76.95", nil, &groups)
+		if len(groups.Items) != 1 || len(groups.Items[0].Dishes) != 1 || groups.Items[0].Dishes[0].ID != food.ID {
+			t.Fatalf("dish search = %+v, want the beshbarmak", groups.Items)
+		}
+		if summary := groups.Items[0].Restaurant; summary.ID != restaurant.ID || summary.OpenNow || summary.DistanceM == nil || *summary.DistanceM < 1000 || *summary.DistanceM > 2000 {
+			t.Errorf("restaurant = %+v, want the closed restaurant about 1.4 km away", summary)
+		}
+		anonymous.must(http.StatusOK, http.MethodGet, "/api/foods/search?dietary=vegan", nil, &groups)
+		if len(groups.Items) != 0 {
+			t.Errorf("vegan dishes = %+v, want none", groups.Items)
+		}
+		for _, query := range []string{"dietary=kosher", "lat=43.25", "min_price=10&max_price=5", "lat=91&lng=0"} {
+			anonymous.must(http.StatusBadRequest, http.MethodGet, "/api/foods/search?"+query, nil, nil)
+		}
+	})
+
+	t.Run("nearby", func(t *testing.T) {
+		admin, user := admin.on(t), user.on(t)
+
+		// Nearby restaurants come nearest first with their distance.
+		var nearby struct {
+			Items []model.Restaurant `json:"items"`
+		}
+		user.must(http.StatusOK, http.MethodGet, "/api/restaurants?near=43.25,76.95&radius=2000", nil, &nearby)
+		if len(nearby.Items) != 1 || nearby.Items[0].ID != restaurant.ID || nearby.Items[0].DistanceM == nil || *nearby.Items[0].DistanceM < 1000 || *nearby.Items[0].DistanceM > 2000 {
+			t.Errorf("restaurants within 2 km = %+v, want the restaurant about 1.4 km away", nearby.Items)
+		}
+		user.must(http.StatusOK, http.MethodGet, "/api/restaurants?near=43.25,76.95&radius=1000", nil, &nearby)
+		if len(nearby.Items) != 0 {
+			t.Errorf("restaurants within 1 km = %+v, want none", nearby.Items)
+		}
+
+		var page struct {
+			NextCursor string `json:"next_cursor"`
+		}
+		admin.must(http.StatusOK, http.MethodGet, "/api/admin/clients?limit=1", nil, &page)
+		for _, query := range []string{
+			"near=43.25,76.95&order=" + url.QueryEscape(`["name"]`),
+			"near=43.25,76.95&after=" + page.NextCursor,
+			"radius=1000",
+			"near=bogus",
+			"near=43.25,76.95&radius=-1",
+		} {
+			user.must(http.StatusBadRequest, http.MethodGet, "/api/restaurants?"+query, nil, nil)
+		}
+	})
+
+	t.Run("refresh tokens", func(t *testing.T) {
+		anonymous := anonymous.on(t)
+
+		tokens := tokens
+		for i := 0; i < 2; i++ {
+			var refreshed model.JwtTokens
+			anonymous.must(http.StatusCreated, http.MethodPost, "/api/auth/refresh-token", map[string]string{
+				"refresh_token": tokens.RefreshToken,
+			}, &refreshed)
+			tokens = refreshed
+		}
+		anonymous.as(tokens.AccessToken).must(http.StatusOK, http.MethodGet, "/api/profile", nil, nil)
+	})
+
+	t.Run("disabled user", func(t *testing.T) {
+		anonymous := anonymous.on(t)
+
+		// Disabling a user revokes their access and refresh tokens.
+		disabledEmail := register(t, "disabled", "+77010000007")
+		disabled := anonymous.login(disabledEmail, "password")
+		if err := a.disableUser(ctx, repository.NewManager(db), []string{disabledEmail}); err != nil {
+			t.Fatal(err)
+		}
+		anonymous.as(disabled.AccessToken).must(http.StatusUnauthorized, http.MethodGet, "/api/profile", nil, nil)
+		anonymous.as(disabled.AccessToken).must(http.StatusUnauthorized, http.MethodGet, "/api/restaurants", nil, nil)
+		anonymous.must(http.StatusUnauthorized, http.MethodPost, "/api/auth/refresh-token", map[string]string{
+			"refresh_token": disabled.RefreshToken,
+		}, nil)
+
+		// Enabling brings the account back as it was.
+		var id uint
+		if err := db.Raw("SELECT id FROM users WHERE email = ? AND disabled_at IS NOT NULL", disabledEmail).Scan(&id).Error; err != nil || id == 0 {
+			t.Fatalf("disabled user id %d (%v), want the row marked disabled", id, err)
+		}
+		if err := a.enableUser(ctx, repository.NewManager(db), []string{fmt.Sprint(id)}); err != nil {
+			t.Fatal(err)
+		}
+		anonymous.login(disabledEmail, "password")
+	})
+
+	t.Run("purge keeps orders", func(t *testing.T) {
+		admin, ownerClient, user := admin.on(t), ownerClient.on(t), user.on(t)
+
+		// Purging deleted restaurants and dishes keeps those with orders.
+		ownerClient.must(http.StatusOK, http.MethodDelete, foodPath, nil, nil)
+		admin.must(http.StatusOK, http.MethodDelete, fmt.Sprintf("/api/admin/restaurants/%d", restaurant.ID), nil, nil)
+		repos := repository.NewManager(db)
+		if purged, err := repos.Food.PurgeDeletedFoods(ctx, time.Now().Add(time.Hour)); err != nil || purged != 0 {
+			t.Errorf("purged %d foods (%v), want none", purged, err)
+		}
+		if purged, err := repos.Restaurant.PurgeDeletedRestaurants(ctx, time.Now().Add(time.Hour)); err != nil || purged != 0 {
+			t.Errorf("purged %d restaurants (%v), want none", purged, err)
+		}
+		if err := db.Exec("DELETE FROM restaurants WHERE id = ?", restaurant.ID).Error; err == nil {
+			t.Error("deleted a restaurant with orders")
+		}
+		var got model.OrderResponse
+		user.must(http.StatusOK, http.MethodGet, orderPath, nil, &got)
+		if got.Restaurant.ID != restaurant.ID || len(got.Foods) != 1 || got.Foods[0].ID != food.ID {
+			t.Errorf("order after purge = %+v, want the restaurant and the dish", got)
+		}
+	})
+}
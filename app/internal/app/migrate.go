@@ -0,0 +1,156 @@
+package app
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"github.com/alibekabdrakhman1/orynal/pkg/migrate"
+	"github.com/alibekabdrakhman1/orynal/schema"
+	"gorm.io/gorm"
+	"os"
+	"path/filepath"
+	"strconv"
+	"text/tabwriter"
+)
+
+const migrateUsage = `usage: orynal migrate <command>
+
+commands:
+  up              apply all pending migrations
+  down [N|-all]   revert the last N migrations (default 1)
+  status          print the database version and pending migrations
+  create NAME     add an empty up/down pair to the schema directory (-dir)`
+
+// Migrate runs the "orynal migrate" subcommand with the arguments after it.
+func (a *App) Migrate(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return UsageError(migrateUsage)
+	}
+
+	command, args := args[0], args[1:]
+	steps := 0
+	switch command {
+	case "create":
+		return createMigration(args)
+	case "down":
+		var err error
+		if steps, err = downSteps(args); err != nil {
+			return err
+		}
+	case "up", "status":
+	default:
+		return UsageError(migrateUsage)
+	}
+
+	db, err := a.dial(ctx)
+	if err != nil {
+		return err
+	}
+	migrator, err := a.newMigrator(db)
+	if err != nil {
+		return err
+	}
+
+	switch command {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			return err
+		}
+		a.logger.Infof("%d migrations applied", applied)
+	case "down":
+		reverted, err := migrator.Down(ctx, steps)
+		if err != nil {
+			return err
+		}
+		a.logger.Infof("%d migrations reverted", reverted)
+	case "status":
+		return printStatus(ctx, migrator)
+	}
+
+	return nil
+}
+
+func (a *App) newMigrator(db *gorm.DB) (*migrate.Migrator, error) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	source, err := schema.For(db.Dialector.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	return migrate.New(sqlDB, db.Dialector.Name(), source, a.logger)
+}
+
+func createMigration(args []string) error {
+	flags := flag.NewFlagSet("migrate create", flag.ContinueOnError)
+	dir := flags.String("dir", "schema", "directory with the migration files")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return UsageError("usage: orynal migrate create [-dir schema] NAME")
+	}
+
+	paths, err := migrate.Create(*dir, flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	// Every migration needs a SQLite counterpart with the same version.
+	sqliteDir := filepath.Join(*dir, migrate.SQLite)
+	if _, err := os.Stat(sqliteDir); err == nil {
+		sqlitePaths, err := migrate.Create(sqliteDir, flags.Arg(0))
+		if err != nil {
+			return err
+		}
+		paths = append(paths, sqlitePaths...)
+	}
+
+	for _, path := range paths {
+		fmt.Println(path)
+	}
+
+	return nil
+}
+
+func downSteps(args []string) (int, error) {
+	switch {
+	case len(args) == 0:
+		return 1, nil
+	case len(args) == 1 && args[0] == "-all":
+		return int(^uint(0) >> 1), nil
+	case len(args) == 1:
+		steps, err := strconv.Atoi(args[0])
+		if err != nil || steps < 1 {
+			return 0, UsageError("usage: orynal migrate down [N|-all]")
+		}
+		return steps, nil
+	default:
+		return 0, UsageError("usage: orynal migrate down [N|-all]")
+	}
+}
+
+func printStatus(ctx context.Context, migrator *migrate.Migrator) error {
+	version, dirty, statuses, err := migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("version: %d (dirty: %t, latest: %d)\n\n", version, dirty, migrator.Latest())
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATUS")
+	for _, status := range statuses {
+		state := "pending"
+		if status.Applied {
+			state = "applied"
+		}
+		fmt.Fprintf(w, "%06d\t%s\t%s\n", status.Version, status.Name, state)
+	}
+
+	return w.Flush()
+}
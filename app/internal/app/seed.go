@@ -0,0 +1,70 @@
+package app
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"github.com/alibekabdrakhman1/orynal/internal/seed"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Seed runs the "orynal seed" command with the arguments after it.
+func (a *App) Seed(ctx context.Context, args []string) error {
+	presets := make([]string, 0, len(seed.Presets))
+	for name := range seed.Presets {
+		presets = append(presets, name)
+	}
+	sort.Strings(presets)
+
+	flags := flag.NewFlagSet("seed", flag.ContinueOnError)
+	size := flags.String("size", "small", "data set size: "+strings.Join(presets, ", "))
+	seedValue := flags.Uint64("seed", 1, "random seed, the same seed generates the same data")
+	password := flags.String("password", "password", "password of every seeded account")
+	date := flags.String("date", time.Now().UTC().Format(time.DateOnly), "reference date, orders are booked around it")
+	reset := flags.Bool("reset", false, "delete previously seeded data first")
+	if err := flags.Parse(args); err != nil {
+		return UsageError("usage: orynal seed [-size " + strings.Join(presets, "|") + "] [-seed N] [-date YYYY-MM-DD] [-password P] [-reset]")
+	}
+
+	preset, ok := seed.Presets[*size]
+	if !ok {
+		return fmt.Errorf("unknown size %q, want one of %s", *size, strings.Join(presets, ", "))
+	}
+	now, err := time.Parse(time.DateOnly, *date)
+	if err != nil {
+		return fmt.Errorf("invalid date %q: %w", *date, err)
+	}
+
+	db, err := a.dial(ctx)
+	if err != nil {
+		return err
+	}
+
+	if *reset {
+		if err := seed.Reset(ctx, db); err != nil {
+			return err
+		}
+	} else if exists, err := seed.Exists(ctx, db); err != nil {
+		return err
+	} else if exists {
+		return fmt.Errorf("database already has seeded accounts (@%s), rerun with -reset", seed.EmailDomain)
+	}
+
+	started := time.Now()
+	summary, err := seed.New(db, seed.Options{
+		Seed:     *seedValue,
+		Size:     preset,
+		Password: *password,
+		Now:      now,
+	}, a.logger).Run(ctx)
+	if err != nil {
+		return err
+	}
+
+	a.logger.Infof("seeded %+v in %s, sign in as admin1@%s, owner1@%s or user1@%s",
+		*summary, time.Since(started).Round(time.Millisecond), seed.EmailDomain, seed.EmailDomain, seed.EmailDomain)
+
+	return nil
+}
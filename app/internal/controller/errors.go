@@ -0,0 +1,77 @@
+package controller
+
+import (
+	"errors"
+	"github.com/alibekabdrakhman1/orynal/pkg/errs"
+	"github.com/alibekabdrakhman1/orynal/pkg/logging"
+	"github.com/alibekabdrakhman1/orynal/pkg/response"
+	"github.com/alibekabdrakhman1/orynal/pkg/validator"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+type errorData struct {
+	Code    string      `json:"code"`
+	Details interface{} `json:"details,omitempty"`
+}
+
+// HTTPErrorHandler renders every error returned by handlers and middleware.
+// Domain errors are mapped by kind, anything unknown is logged and hidden
+// behind a generic 500.
+func (s *Server) HTTPErrorHandler(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+
+	logger := logging.FromContext(c.Request().Context(), s.logger)
+
+	status, body := s.errorResponse(err)
+	if status >= http.StatusInternalServerError {
+		logger.Errorf("%s %s: %v", c.Request().Method, c.Path(), err)
+	}
+
+	if c.Request().Method == http.MethodHead {
+		err = c.NoContent(status)
+	} else {
+		err = c.JSON(status, body)
+	}
+	if err != nil {
+		logger.Error(err)
+	}
+}
+
+func (s *Server) errorResponse(err error) (int, response.CustomResponse) {
+	var validationErr *validator.ValidationError
+	if errors.As(err, &validationErr) {
+		return http.StatusUnprocessableEntity, response.CustomResponse{
+			Status:  http.StatusUnprocessableEntity,
+			Message: "Validation failed",
+			Data:    errorData{Code: errs.ErrValidation.Code, Details: validationErr.Fields},
+		}
+	}
+
+	if domainErr, ok := errs.As(err); ok && domainErr.Kind != errs.KindInternal {
+		return domainErr.HTTPStatus(), response.CustomResponse{
+			Status:  domainErr.HTTPStatus(),
+			Message: domainErr.Message,
+			Data:    errorData{Code: domainErr.Code, Details: domainErr.Details},
+		}
+	}
+
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) && httpErr.Code < http.StatusInternalServerError {
+		message, ok := httpErr.Message.(string)
+		if !ok {
+			message = http.StatusText(httpErr.Code)
+		}
+		return httpErr.Code, response.CustomResponse{
+			Status:  httpErr.Code,
+			Message: message,
+		}
+	}
+
+	return http.StatusInternalServerError, response.CustomResponse{
+		Status:  http.StatusInternalServerError,
+		Message: "Internal server error",
+	}
+}
@@ -0,0 +1,27 @@
+package controller
+
+import (
+	"github.com/alibekabdrakhman1/orynal/pkg/health"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+func (s *Server) setupHealthRoutes() {
+	s.App.GET("/healthz", s.liveness)
+	s.App.GET("/readyz", s.readiness)
+}
+
+// liveness only reports that the process serves HTTP; dependencies are left
+// to readiness so that an outage does not get the pod restarted.
+func (s *Server) liveness(c echo.Context) error {
+	return c.JSON(http.StatusOK, health.Report{Status: health.StatusOK})
+}
+
+func (s *Server) readiness(c echo.Context) error {
+	report := s.health.Ready(c.Request().Context())
+	if report.Status != health.StatusOK {
+		return c.JSON(http.StatusServiceUnavailable, report)
+	}
+
+	return c.JSON(http.StatusOK, report)
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"github.com/alibekabdrakhman1/orynal/internal/model"
 	"github.com/alibekabdrakhman1/orynal/internal/service"
+	"github.com/alibekabdrakhman1/orynal/pkg/enums"
+	"github.com/alibekabdrakhman1/orynal/pkg/errs"
 	"github.com/alibekabdrakhman1/orynal/pkg/response"
 	"github.com/alibekabdrakhman1/orynal/pkg/utils"
 	"github.com/labstack/echo/v4"
@@ -26,21 +28,16 @@ type AdminHandler struct {
 func (h *AdminHandler) CreateService(c echo.Context) error {
 	var createService model.Service
 	if err := c.Bind(&createService); err != nil {
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  http.StatusBadRequest,
-			Message: "Invalid request body",
-			Data:    err.Error(),
-		})
+		return errs.ErrInvalidBody.Wrap(err)
+	}
+
+	if err := c.Validate(&createService); err != nil {
+		return err
 	}
 
 	services, err := h.service.Restaurant.CreateService(c.Request().Context(), &createService)
 	if err != nil {
-		h.logger.Error("Failed to create owner:", err)
-		return c.JSON(http.StatusInternalServerError, response.CustomResponse{
-			Status:  http.StatusInternalServerError,
-			Message: "Failed to create owner",
-			Data:    err.Error(),
-		})
+		return err
 	}
 
 	return c.JSON(http.StatusCreated, response.CustomResponse{
@@ -53,21 +50,12 @@ func (h *AdminHandler) CreateService(c echo.Context) error {
 func (h *AdminHandler) DeleteService(c echo.Context) error {
 	serviceID, err := utils.ConvertIdToUint(c.Param("id"))
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  http.StatusBadRequest,
-			Message: "Invalid service ID",
-			Data:    err.Error(),
-		})
+		return errs.ErrInvalidID.Wrap(err)
 	}
 
 	err = h.service.Restaurant.DeleteService(c.Request().Context(), serviceID)
 	if err != nil {
-		h.logger.Error("Failed to delete service:", err)
-		return c.JSON(http.StatusInternalServerError, response.CustomResponse{
-			Status:  http.StatusInternalServerError,
-			Message: "Failed to delete service",
-			Data:    err.Error(),
-		})
+		return err
 	}
 
 	return c.JSON(http.StatusOK, response.CustomResponse{
@@ -80,21 +68,16 @@ func (h *AdminHandler) DeleteService(c echo.Context) error {
 func (h *AdminHandler) UpdateService(c echo.Context) error {
 	var updateService model.Service
 	if err := c.Bind(&updateService); err != nil {
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  http.StatusBadRequest,
-			Message: "Invalid request body",
-			Data:    err.Error(),
-		})
+		return errs.ErrInvalidBody.Wrap(err)
+	}
+
+	if err := c.Validate(&updateService); err != nil {
+		return err
 	}
 
 	services, err := h.service.Restaurant.UpdateService(c.Request().Context(), &updateService)
 	if err != nil {
-		h.logger.Error("Failed to create owner:", err)
-		return c.JSON(http.StatusInternalServerError, response.CustomResponse{
-			Status:  http.StatusInternalServerError,
-			Message: "Failed to create owner",
-			Data:    err.Error(),
-		})
+		return err
 	}
 
 	return c.JSON(http.StatusOK, response.CustomResponse{
@@ -107,21 +90,12 @@ func (h *AdminHandler) UpdateService(c echo.Context) error {
 func (h *AdminHandler) GetClients(c echo.Context) error {
 	searchParams, err := h.service.User.UserSearchFormatting(model.NewParams(), c)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, response.CustomResponse{
-			Status:  http.StatusInternalServerError,
-			Message: "Failed reading params",
-			Data:    err.Error(),
-		})
+		return err
 	}
 
 	clients, err := h.service.User.GetAllClients(c.Request().Context(), searchParams)
 	if err != nil {
-		h.logger.Error("Failed to get clients:", err)
-		return c.JSON(http.StatusInternalServerError, response.CustomResponse{
-			Status:  http.StatusInternalServerError,
-			Message: "Failed to get clients",
-			Data:    err.Error(),
-		})
+		return err
 	}
 	return c.JSON(http.StatusOK, response.CustomResponse{
 		Status:  http.StatusOK,
@@ -133,21 +107,12 @@ func (h *AdminHandler) GetClients(c echo.Context) error {
 func (h *AdminHandler) GetClient(c echo.Context) error {
 	clientID, err := utils.ConvertIdToUint(c.Param("id"))
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  http.StatusBadRequest,
-			Message: "Invalid client id",
-			Data:    err.Error(),
-		})
+		return errs.ErrInvalidID.Wrap(err)
 	}
 
 	client, err := h.service.User.GetByID(c.Request().Context(), clientID)
 	if err != nil {
-		h.logger.Error("Failed to get client:", err)
-		return c.JSON(http.StatusInternalServerError, response.CustomResponse{
-			Status:  http.StatusInternalServerError,
-			Message: "Failed to get client",
-			Data:    err.Error(),
-		})
+		return err
 	}
 
 	return c.JSON(http.StatusOK, response.CustomResponse{
@@ -160,21 +125,12 @@ func (h *AdminHandler) GetClient(c echo.Context) error {
 func (h *AdminHandler) DeleteClient(c echo.Context) error {
 	clientID, err := utils.ConvertIdToUint(c.Param("id"))
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  http.StatusBadRequest,
-			Message: "Invalid client id",
-			Data:    err.Error(),
-		})
+		return errs.ErrInvalidID.Wrap(err)
 	}
 
 	err = h.service.User.Delete(c.Request().Context(), clientID)
 	if err != nil {
-		h.logger.Error("Failed to delete client:", err)
-		return c.JSON(http.StatusInternalServerError, response.CustomResponse{
-			Status:  http.StatusInternalServerError,
-			Message: "Failed to delete client",
-			Data:    err.Error(),
-		})
+		return err
 	}
 
 	return c.JSON(http.StatusOK, response.CustomResponse{
@@ -183,24 +139,32 @@ func (h *AdminHandler) DeleteClient(c echo.Context) error {
 	})
 }
 
+func (h *AdminHandler) RestoreClient(c echo.Context) error {
+	clientID, err := utils.ConvertIdToUint(c.Param("id"))
+	if err != nil {
+		return errs.ErrInvalidID.Wrap(err)
+	}
+
+	err = h.service.User.Restore(c.Request().Context(), clientID, enums.User)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, response.CustomResponse{
+		Status:  http.StatusOK,
+		Message: "Restored",
+	})
+}
+
 func (h *AdminHandler) GetOwners(c echo.Context) error {
 	searchParams, err := h.service.User.UserSearchFormatting(model.NewParams(), c)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, response.CustomResponse{
-			Status:  http.StatusInternalServerError,
-			Message: "Failed reading params",
-			Data:    err.Error(),
-		})
+		return err
 	}
 
 	owners, err := h.service.User.GetAllOwners(c.Request().Context(), searchParams)
 	if err != nil {
-		h.logger.Error("Failed to get owners:", err)
-		return c.JSON(http.StatusInternalServerError, response.CustomResponse{
-			Status:  http.StatusInternalServerError,
-			Message: "Failed to get owners",
-			Data:    err.Error(),
-		})
+		return err
 	}
 
 	return c.JSON(http.StatusOK, response.CustomResponse{
@@ -213,21 +177,16 @@ func (h *AdminHandler) GetOwners(c echo.Context) error {
 func (h *AdminHandler) CreateOwner(c echo.Context) error {
 	var owner model.User
 	if err := c.Bind(&owner); err != nil {
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  http.StatusBadRequest,
-			Message: "Invalid request body",
-			Data:    err.Error(),
-		})
+		return errs.ErrInvalidBody.Wrap(err)
+	}
+
+	if err := c.Validate(&owner); err != nil {
+		return err
 	}
 
 	createdOwner, err := h.service.User.CreateOwner(c.Request().Context(), &owner)
 	if err != nil {
-		h.logger.Error("Failed to create owner:", err)
-		return c.JSON(http.StatusInternalServerError, response.CustomResponse{
-			Status:  http.StatusInternalServerError,
-			Message: "Failed to create owner",
-			Data:    err.Error(),
-		})
+		return err
 	}
 
 	return c.JSON(http.StatusCreated, response.CustomResponse{
@@ -240,44 +199,43 @@ func (h *AdminHandler) CreateOwner(c echo.Context) error {
 func (h *AdminHandler) DeleteOwner(c echo.Context) error {
 	ownerID, err := utils.ConvertIdToUint(c.Param("id"))
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  http.StatusBadRequest,
-			Message: "Invalid owner ID",
-			Data:    err.Error(),
-		})
+		return errs.ErrInvalidID.Wrap(err)
 	}
 
 	err = h.service.User.Delete(c.Request().Context(), ownerID)
 	if err != nil {
-		h.logger.Error("Failed to delete owner:", err)
-		return c.JSON(http.StatusInternalServerError, response.CustomResponse{
-			Status:  http.StatusInternalServerError,
-			Message: "Failed to delete owner",
-			Data:    err.Error(),
-		})
+		return err
 	}
 
 	return c.NoContent(http.StatusNoContent)
 }
 
+func (h *AdminHandler) RestoreOwner(c echo.Context) error {
+	ownerID, err := utils.ConvertIdToUint(c.Param("id"))
+	if err != nil {
+		return errs.ErrInvalidID.Wrap(err)
+	}
+
+	err = h.service.User.Restore(c.Request().Context(), ownerID, enums.Owner)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, response.CustomResponse{
+		Status:  http.StatusOK,
+		Message: "Restored",
+	})
+}
+
 func (h *AdminHandler) GetRestaurants(c echo.Context) error {
 	searchParams, err := h.service.Restaurant.RestaurantsSearchFormatting(model.NewParams(), c)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, response.CustomResponse{
-			Status:  http.StatusInternalServerError,
-			Message: "Failed reading params",
-			Data:    err.Error(),
-		})
+		return err
 	}
 
 	restaurants, err := h.service.Restaurant.GetRestaurants(c.Request().Context(), searchParams)
 	if err != nil {
-		h.logger.Error("Failed to get restaurants:", err)
-		return c.JSON(http.StatusInternalServerError, response.CustomResponse{
-			Status:  http.StatusInternalServerError,
-			Message: "Failed to get restaurants",
-			Data:    err.Error(),
-		})
+		return err
 	}
 
 	return c.JSON(http.StatusOK, response.CustomResponse{
@@ -290,22 +248,14 @@ func (h *AdminHandler) GetRestaurants(c echo.Context) error {
 func (h *AdminHandler) GetRestaurant(c echo.Context) error {
 	restaurantID, err := utils.ConvertIdToUint(c.Param("id"))
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  http.StatusBadRequest,
-			Message: "Invalid restaurant ID",
-			Data:    err.Error(),
-		})
+		return errs.ErrInvalidID.Wrap(err)
 	}
 
 	restaurant, err := h.service.Restaurant.GetRestaurantByID(c.Request().Context(), restaurantID)
 	if err != nil {
-		h.logger.Error("Failed to get restaurant:", err)
-		return c.JSON(http.StatusInternalServerError, response.CustomResponse{
-			Status:  http.StatusInternalServerError,
-			Message: "Failed to get restaurant",
-			Data:    err.Error(),
-		})
+		return err
 	}
+	setETag(c, restaurant.Version)
 
 	return c.JSON(http.StatusOK, response.CustomResponse{
 		Status:  http.StatusOK,
@@ -317,23 +267,18 @@ func (h *AdminHandler) GetRestaurant(c echo.Context) error {
 func (h *AdminHandler) CreateRestaurant(c echo.Context) error {
 	var restaurant model.Restaurant
 	if err := c.Bind(&restaurant); err != nil {
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  http.StatusBadRequest,
-			Message: "Invalid request body",
-			Data:    err.Error(),
-		})
+		return errs.ErrInvalidBody.Wrap(err)
+	}
+
+	if err := c.Validate(&restaurant); err != nil {
+		return err
 	}
 
 	fmt.Println(restaurant)
 
 	createdRestaurant, err := h.service.Restaurant.CreateRestaurant(c.Request().Context(), &restaurant)
 	if err != nil {
-		h.logger.Error("Failed to create restaurant:", err)
-		return c.JSON(http.StatusInternalServerError, response.CustomResponse{
-			Status:  http.StatusInternalServerError,
-			Message: "Failed to create restaurant",
-			Data:    err.Error(),
-		})
+		return err
 	}
 
 	return c.JSON(http.StatusCreated, response.CustomResponse{
@@ -346,21 +291,53 @@ func (h *AdminHandler) CreateRestaurant(c echo.Context) error {
 func (h *AdminHandler) DeleteRestaurant(c echo.Context) error {
 	restaurantID, err := utils.ConvertIdToUint(c.Param("id"))
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  http.StatusBadRequest,
-			Message: "Invalid restaurant ID",
-			Data:    err.Error(),
-		})
+		return errs.ErrInvalidID.Wrap(err)
 	}
 
 	err = h.service.Restaurant.DeleteRestaurant(c.Request().Context(), restaurantID)
 	if err != nil {
-		h.logger.Error("Failed to delete restaurant:", err)
-		return c.JSON(http.StatusInternalServerError, response.CustomResponse{
-			Status:  http.StatusInternalServerError,
-			Message: "Failed to delete restaurant",
-			Data:    err.Error(),
-		})
+		return err
+	}
+
+	return c.JSON(http.StatusOK, response.CustomResponse{
+		Status:  http.StatusOK,
+		Message: "Success",
+		Data:    nil,
+	})
+}
+
+func (h *AdminHandler) RestoreRestaurant(c echo.Context) error {
+	restaurantID, err := utils.ConvertIdToUint(c.Param("id"))
+	if err != nil {
+		return errs.ErrInvalidID.Wrap(err)
+	}
+
+	err = h.service.Restaurant.RestoreRestaurant(c.Request().Context(), restaurantID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, response.CustomResponse{
+		Status:  http.StatusOK,
+		Message: "Success",
+		Data:    nil,
+	})
+}
+
+func (h *AdminHandler) RestoreRestaurantFood(c echo.Context) error {
+	restaurantID, err := utils.ConvertIdToUint(c.Param("id"))
+	if err != nil {
+		return errs.ErrInvalidID.Wrap(err)
+	}
+
+	foodID, err := utils.ConvertIdToUint(c.Param("food_id"))
+	if err != nil {
+		return errs.ErrInvalidID.Wrap(err)
+	}
+
+	err = h.service.Menu.RestoreRestaurantFood(c.Request().Context(), restaurantID, foodID)
+	if err != nil {
+		return err
 	}
 
 	return c.JSON(http.StatusOK, response.CustomResponse{
@@ -373,32 +350,28 @@ func (h *AdminHandler) DeleteRestaurant(c echo.Context) error {
 func (h *AdminHandler) UpdateRestaurant(c echo.Context) error {
 	restaurantID, err := utils.ConvertIdToUint(c.Param("id"))
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  http.StatusBadRequest,
-			Message: "Invalid restaurant ID",
-			Data:    err.Error(),
-		})
+		return errs.ErrInvalidID.Wrap(err)
 	}
 
 	var updatedRestaurant model.Restaurant
 	if err := c.Bind(&updatedRestaurant); err != nil {
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  http.StatusBadRequest,
-			Message: "Invalid request body",
-			Data:    err.Error(),
-		})
+		return errs.ErrInvalidBody.Wrap(err)
+	}
+
+	if err := c.Validate(&updatedRestaurant); err != nil {
+		return err
 	}
 
 	updatedRestaurant.ID = restaurantID
+	if updatedRestaurant.Version, err = ifMatch(c); err != nil {
+		return err
+	}
+
 	restaurant, err := h.service.Restaurant.UpdateRestaurant(c.Request().Context(), &updatedRestaurant, restaurantID)
 	if err != nil {
-		h.logger.Error("Failed to update restaurant:", err)
-		return c.JSON(http.StatusInternalServerError, response.CustomResponse{
-			Status:  http.StatusInternalServerError,
-			Message: "Failed to update restaurant",
-			Data:    err.Error(),
-		})
+		return err
 	}
+	setETag(c, restaurant.Version)
 
 	return c.JSON(http.StatusOK, response.CustomResponse{
 		Status:  http.StatusOK,
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"github.com/alibekabdrakhman1/orynal/pkg/errs"
+	"github.com/labstack/echo/v4"
+	"strconv"
+	"strings"
+)
+
+// setETag sends the version of the returned entity as its ETag, e.g. "3".
+func setETag(c echo.Context, version uint) {
+	c.Response().Header().Set("ETag", `"`+strconv.FormatUint(uint64(version), 10)+`"`)
+}
+
+// ifMatch returns the version an update was based on, taken from the ETag
+// the client echoes in If-Match. "*" matches any version and yields 0, which
+// repositories treat as unconditional.
+func ifMatch(c echo.Context) (uint, error) {
+	header := strings.TrimSpace(c.Request().Header.Get("If-Match"))
+	switch header {
+	case "":
+		return 0, errs.ErrIfMatchRequired
+	case "*":
+		return 0, nil
+	}
+
+	tag, ok := strings.CutPrefix(header, `"`)
+	if ok {
+		tag, ok = strings.CutSuffix(tag, `"`)
+	}
+	if !ok {
+		return 0, errs.ErrInvalidETag
+	}
+
+	version, err := strconv.ParseUint(tag, 10, 32)
+	if err != nil || version == 0 {
+		return 0, errs.ErrInvalidETag
+	}
+
+	return uint(version), nil
+}
@@ -3,6 +3,8 @@ package handlers
 import (
 	"github.com/alibekabdrakhman1/orynal/internal/model"
 	"github.com/alibekabdrakhman1/orynal/internal/service"
+	"github.com/alibekabdrakhman1/orynal/pkg/errs"
+	"github.com/alibekabdrakhman1/orynal/pkg/patch"
 	"github.com/alibekabdrakhman1/orynal/pkg/response"
 	"github.com/alibekabdrakhman1/orynal/pkg/utils"
 	"github.com/labstack/echo/v4"
@@ -26,29 +28,17 @@ type MenuHandler struct {
 func (h *MenuHandler) GetMenuCategories(c echo.Context) error {
 	restaurantID := c.Param("id")
 	if restaurantID == "" {
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  http.StatusBadRequest,
-			Message: "Restaurant ID is required",
-		})
+		return errs.ErrInvalidID
 	}
 
 	id, err := strconv.ParseUint(restaurantID, 10, 64)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  http.StatusBadRequest,
-			Message: "Invalid restaurant ID",
-			Data:    err.Error(),
-		})
+		return errs.ErrInvalidID.Wrap(err)
 	}
 
 	types, err := h.service.Menu.GetMenuCategories(c.Request().Context(), uint(id))
 	if err != nil {
-		h.logger.Error("Failed to get tables for restaurant:", err)
-		return c.JSON(http.StatusInternalServerError, response.CustomResponse{
-			Status:  http.StatusInternalServerError,
-			Message: "Failed to get tables for restaurant",
-			Data:    err.Error(),
-		})
+		return err
 	}
 
 	return c.JSON(http.StatusOK, response.CustomResponse{
@@ -61,38 +51,22 @@ func (h *MenuHandler) GetMenuCategories(c echo.Context) error {
 func (h *MenuHandler) GetRestaurantMenu(c echo.Context) error {
 	restaurantID := c.Param("id")
 	if restaurantID == "" {
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  http.StatusBadRequest,
-			Message: "Restaurant ID is required",
-		})
+		return errs.ErrInvalidID
 	}
 
 	id, err := strconv.ParseUint(restaurantID, 10, 64)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  http.StatusBadRequest,
-			Message: "Invalid restaurant ID",
-			Data:    err.Error(),
-		})
+		return errs.ErrInvalidID.Wrap(err)
 	}
 
 	searchParams, err := h.service.Menu.MenuSearchFormatting(model.NewParams(), c)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, response.CustomResponse{
-			Status:  http.StatusInternalServerError,
-			Message: "Failed reading params",
-			Data:    err.Error(),
-		})
+		return err
 	}
 
 	menu, err := h.service.Menu.GetRestaurantMenu(c.Request().Context(), uint(id), searchParams)
 	if err != nil {
-		h.logger.Error("Failed to get restaurant menu:", err)
-		return c.JSON(http.StatusInternalServerError, response.CustomResponse{
-			Status:  http.StatusInternalServerError,
-			Message: "Failed to get restaurant menu",
-			Data:    err.Error(),
-		})
+		return err
 	}
 
 	return c.JSON(http.StatusOK, response.CustomResponse{
@@ -105,39 +79,24 @@ func (h *MenuHandler) GetRestaurantMenu(c echo.Context) error {
 func (h *MenuHandler) GetRestaurantFood(c echo.Context) error {
 	restaurantID := c.Param("id")
 	if restaurantID == "" {
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  http.StatusBadRequest,
-			Message: "Restaurant ID is required",
-		})
+		return errs.ErrInvalidID
 	}
 
 	id, err := strconv.ParseUint(restaurantID, 10, 64)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  http.StatusBadRequest,
-			Message: "Invalid restaurant ID",
-			Data:    err.Error(),
-		})
+		return errs.ErrInvalidID.Wrap(err)
 	}
 
 	foodID, err := strconv.ParseUint(c.Param("food_id"), 10, 64)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  http.StatusBadRequest,
-			Message: "Invalid food ID",
-			Data:    err.Error(),
-		})
+		return errs.ErrInvalidID.Wrap(err)
 	}
 
 	food, err := h.service.Menu.GetRestaurantFood(c.Request().Context(), uint(id), uint(foodID))
 	if err != nil {
-		h.logger.Error("Failed to get restaurant food:", err)
-		return c.JSON(http.StatusInternalServerError, response.CustomResponse{
-			Status:  http.StatusInternalServerError,
-			Message: "Failed to get restaurant food",
-			Data:    err.Error(),
-		})
+		return err
 	}
+	setETag(c, food.Version)
 
 	return c.JSON(http.StatusOK, response.CustomResponse{
 		Status:  http.StatusOK,
@@ -149,30 +108,21 @@ func (h *MenuHandler) GetRestaurantFood(c echo.Context) error {
 func (h *MenuHandler) CreateRestaurantFood(c echo.Context) error {
 	restaurantID, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  http.StatusBadRequest,
-			Message: "Invalid restaurant ID",
-			Data:    err.Error(),
-		})
+		return errs.ErrInvalidID.Wrap(err)
 	}
 
 	var food model.Food
 	if err := c.Bind(&food); err != nil {
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  http.StatusBadRequest,
-			Message: "Invalid request body",
-			Data:    err.Error(),
-		})
+		return errs.ErrInvalidBody.Wrap(err)
+	}
+
+	if err := c.Validate(&food); err != nil {
+		return err
 	}
 
 	createdFood, err := h.service.Menu.CreateRestaurantFood(c.Request().Context(), uint(restaurantID), &food)
 	if err != nil {
-		h.logger.Error("Failed to create restaurant food:", err)
-		return c.JSON(http.StatusInternalServerError, response.CustomResponse{
-			Status:  http.StatusInternalServerError,
-			Message: "Failed to create restaurant food",
-			Data:    err.Error(),
-		})
+		return err
 	}
 
 	return c.JSON(http.StatusCreated, response.CustomResponse{
@@ -185,41 +135,67 @@ func (h *MenuHandler) CreateRestaurantFood(c echo.Context) error {
 func (h *MenuHandler) UpdateRestaurantFood(c echo.Context) error {
 	restaurantID, err := utils.ConvertIdToUint(c.Param("id"))
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  http.StatusBadRequest,
-			Message: "Invalid restaurant ID",
-			Data:    err.Error(),
-		})
+		return errs.ErrInvalidID.Wrap(err)
 	}
 
 	foodID, err := utils.ConvertIdToUint(c.Param("food_id"))
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  http.StatusBadRequest,
-			Message: "Invalid food ID",
-			Data:    err.Error(),
-		})
+		return errs.ErrInvalidID.Wrap(err)
 	}
 
 	var updatedFood model.Food
 	if err := c.Bind(&updatedFood); err != nil {
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  http.StatusBadRequest,
-			Message: "Invalid request body",
-			Data:    err.Error(),
-		})
+		return errs.ErrInvalidBody.Wrap(err)
+	}
+
+	if err := c.Validate(&updatedFood); err != nil {
+		return err
 	}
 
 	updatedFood.ID = foodID
+	if updatedFood.Version, err = ifMatch(c); err != nil {
+		return err
+	}
+
 	food, err := h.service.Menu.UpdateRestaurantFood(c.Request().Context(), uint(restaurantID), &updatedFood)
 	if err != nil {
-		h.logger.Error("Failed to update restaurant food:", err)
-		return c.JSON(http.StatusInternalServerError, response.CustomResponse{
-			Status:  http.StatusInternalServerError,
-			Message: "Failed to update restaurant food",
-			Data:    err.Error(),
-		})
+		return err
+	}
+	setETag(c, food.Version)
+
+	return c.JSON(http.StatusOK, response.CustomResponse{
+		Status:  http.StatusOK,
+		Message: "Success",
+		Data:    food,
+	})
+}
+
+func (h *MenuHandler) PatchRestaurantFood(c echo.Context) error {
+	restaurantID, err := utils.ConvertIdToUint(c.Param("id"))
+	if err != nil {
+		return errs.ErrInvalidID.Wrap(err)
+	}
+
+	foodID, err := utils.ConvertIdToUint(c.Param("food_id"))
+	if err != nil {
+		return errs.ErrInvalidID.Wrap(err)
+	}
+
+	version, err := ifMatch(c)
+	if err != nil {
+		return err
+	}
+
+	p, err := patch.Decode(c.Request().Body)
+	if err != nil {
+		return err
+	}
+
+	food, err := h.service.Menu.PatchRestaurantFood(c.Request().Context(), restaurantID, foodID, version, p)
+	if err != nil {
+		return err
 	}
+	setETag(c, food.Version)
 
 	return c.JSON(http.StatusOK, response.CustomResponse{
 		Status:  http.StatusOK,
@@ -231,30 +207,17 @@ func (h *MenuHandler) UpdateRestaurantFood(c echo.Context) error {
 func (h *MenuHandler) DeleteRestaurantFood(c echo.Context) error {
 	restaurantID, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  http.StatusBadRequest,
-			Message: "Invalid restaurant ID",
-			Data:    err.Error(),
-		})
+		return errs.ErrInvalidID.Wrap(err)
 	}
 
 	foodID, err := strconv.ParseUint(c.Param("food_id"), 10, 64)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  http.StatusBadRequest,
-			Message: "Invalid food ID",
-			Data:    err.Error(),
-		})
+		return errs.ErrInvalidID.Wrap(err)
 	}
 
 	err = h.service.Menu.DeleteRestaurantFood(c.Request().Context(), uint(restaurantID), uint(foodID))
 	if err != nil {
-		h.logger.Error("Failed to delete restaurant food:", err)
-		return c.JSON(http.StatusInternalServerError, response.CustomResponse{
-			Status:  http.StatusInternalServerError,
-			Message: "Failed to delete restaurant food",
-			Data:    err.Error(),
-		})
+		return err
 	}
 
 	return c.JSON(http.StatusOK, response.CustomResponse{
@@ -3,7 +3,10 @@ package handlers
 import (
 	"github.com/alibekabdrakhman1/orynal/internal/model"
 	"github.com/alibekabdrakhman1/orynal/internal/service"
+	"github.com/alibekabdrakhman1/orynal/pkg/errs"
+	"github.com/alibekabdrakhman1/orynal/pkg/patch"
 	"github.com/alibekabdrakhman1/orynal/pkg/response"
+	"github.com/alibekabdrakhman1/orynal/pkg/utils"
 	"github.com/labstack/echo/v4"
 	"go.uber.org/zap"
 	"net/http"
@@ -25,21 +28,16 @@ type OrderHandler struct {
 func (h *OrderHandler) CreateOrder(c echo.Context) error {
 	var order model.OrderRequest
 	if err := c.Bind(&order); err != nil {
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  http.StatusBadRequest,
-			Message: "Invalid request body",
-			Data:    err.Error(),
-		})
+		return errs.ErrInvalidBody.Wrap(err)
+	}
+
+	if err := c.Validate(&order); err != nil {
+		return err
 	}
 
 	createdOrder, err := h.service.Order.Create(c.Request().Context(), &order)
 	if err != nil {
-		h.logger.Error("Failed to create order:", err)
-		return c.JSON(http.StatusInternalServerError, response.CustomResponse{
-			Status:  http.StatusInternalServerError,
-			Message: "Failed to create order",
-			Data:    err.Error(),
-		})
+		return err
 	}
 
 	return c.JSON(http.StatusCreated, response.CustomResponse{
@@ -52,29 +50,17 @@ func (h *OrderHandler) CreateOrder(c echo.Context) error {
 func (h *OrderHandler) DeleteOrder(c echo.Context) error {
 	orderID := c.Param("id")
 	if orderID == "" {
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  http.StatusBadRequest,
-			Message: "Order ID is required",
-		})
+		return errs.ErrInvalidID
 	}
 
 	id, err := strconv.ParseUint(orderID, 10, 64)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  http.StatusBadRequest,
-			Message: "Invalid order ID",
-			Data:    err.Error(),
-		})
+		return errs.ErrInvalidID.Wrap(err)
 	}
 
 	err = h.service.Order.Delete(c.Request().Context(), uint(id))
 	if err != nil {
-		h.logger.Error("Failed to delete order:", err)
-		return c.JSON(http.StatusInternalServerError, response.CustomResponse{
-			Status:  http.StatusInternalServerError,
-			Message: "Failed to delete order",
-			Data:    err.Error(),
-		})
+		return err
 	}
 
 	return c.JSON(http.StatusOK, response.CustomResponse{
@@ -86,41 +72,33 @@ func (h *OrderHandler) DeleteOrder(c echo.Context) error {
 func (h *OrderHandler) UpdateOrder(c echo.Context) error {
 	var order model.Order
 	if err := c.Bind(&order); err != nil {
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  http.StatusBadRequest,
-			Message: "Invalid request body",
-			Data:    err.Error(),
-		})
+		return errs.ErrInvalidBody.Wrap(err)
+	}
+
+	if err := c.Validate(&order); err != nil {
+		return err
 	}
 
 	orderID := c.Param("id")
 	if orderID == "" {
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  http.StatusBadRequest,
-			Message: "Order ID is required",
-		})
+		return errs.ErrInvalidID
 	}
 
 	id, err := strconv.ParseUint(orderID, 10, 64)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  http.StatusBadRequest,
-			Message: "Invalid order ID",
-			Data:    err.Error(),
-		})
+		return errs.ErrInvalidID.Wrap(err)
 	}
 
 	order.ID = uint(id)
+	if order.Version, err = ifMatch(c); err != nil {
+		return err
+	}
 
 	updatedOrder, err := h.service.Order.Update(c.Request().Context(), uint(id), &order)
 	if err != nil {
-		h.logger.Error("Failed to update order:", err)
-		return c.JSON(http.StatusInternalServerError, response.CustomResponse{
-			Status:  http.StatusInternalServerError,
-			Message: "Failed to update order",
-			Data:    err.Error(),
-		})
+		return err
 	}
+	setETag(c, updatedOrder.Version)
 
 	return c.JSON(http.StatusOK, response.CustomResponse{
 		Status:  http.StatusOK,
@@ -129,33 +107,51 @@ func (h *OrderHandler) UpdateOrder(c echo.Context) error {
 	})
 }
 
+func (h *OrderHandler) PatchOrder(c echo.Context) error {
+	id, err := utils.ConvertIdToUint(c.Param("id"))
+	if err != nil {
+		return errs.ErrInvalidID.Wrap(err)
+	}
+
+	version, err := ifMatch(c)
+	if err != nil {
+		return err
+	}
+
+	p, err := patch.Decode(c.Request().Body)
+	if err != nil {
+		return err
+	}
+
+	order, err := h.service.Order.Patch(c.Request().Context(), id, version, p)
+	if err != nil {
+		return err
+	}
+	setETag(c, order.Version)
+
+	return c.JSON(http.StatusOK, response.CustomResponse{
+		Status:  http.StatusOK,
+		Message: "Order updated successfully",
+		Data:    order,
+	})
+}
+
 func (h *OrderHandler) GetOrder(c echo.Context) error {
 	orderID := c.Param("id")
 	if orderID == "" {
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  http.StatusBadRequest,
-			Message: "Order ID is required",
-		})
+		return errs.ErrInvalidID
 	}
 
 	id, err := strconv.ParseUint(orderID, 10, 64)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  http.StatusBadRequest,
-			Message: "Invalid order ID",
-			Data:    err.Error(),
-		})
+		return errs.ErrInvalidID.Wrap(err)
 	}
 
 	order, err := h.service.Order.GetByID(c.Request().Context(), uint(id))
 	if err != nil {
-		h.logger.Error("Failed to get order:", err)
-		return c.JSON(http.StatusInternalServerError, response.CustomResponse{
-			Status:  http.StatusInternalServerError,
-			Message: "Failed to get order",
-			Data:    err.Error(),
-		})
+		return err
 	}
+	setETag(c, order.Version)
 
 	return c.JSON(http.StatusOK, response.CustomResponse{
 		Status:  http.StatusOK,
@@ -167,21 +163,12 @@ func (h *OrderHandler) GetOrder(c echo.Context) error {
 func (h *OrderHandler) GetAllOrders(c echo.Context) error {
 	searchParams, err := h.service.Order.OrderSearchFormatting(model.NewParams(), c)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, response.CustomResponse{
-			Status:  http.StatusInternalServerError,
-			Message: "Failed reading params",
-			Data:    err.Error(),
-		})
+		return err
 	}
 
 	orders, err := h.service.Order.GetAllOrders(c.Request().Context(), searchParams)
 	if err != nil {
-		h.logger.Error("Failed to get all orders:", err)
-		return c.JSON(http.StatusInternalServerError, response.CustomResponse{
-			Status:  http.StatusInternalServerError,
-			Message: "Failed to get all orders",
-			Data:    err.Error(),
-		})
+		return err
 	}
 
 	return c.JSON(http.StatusOK, response.CustomResponse{
@@ -4,7 +4,10 @@ import (
 	"github.com/alibekabdrakhman1/orynal/internal/model"
 	"github.com/alibekabdrakhman1/orynal/internal/service"
 	"github.com/alibekabdrakhman1/orynal/internal/service/infrastructure"
+	"github.com/alibekabdrakhman1/orynal/pkg/errs"
+	"github.com/alibekabdrakhman1/orynal/pkg/patch"
 	"github.com/alibekabdrakhman1/orynal/pkg/response"
+	"github.com/alibekabdrakhman1/orynal/pkg/utils"
 	"github.com/labstack/echo/v4"
 	"go.uber.org/zap"
 	"net/http"
@@ -43,12 +46,7 @@ func (h *RestaurantHandler) UnsaveRestaurant(c echo.Context) error {
 func (h *RestaurantHandler) PopularRestaurants(c echo.Context) error {
 	restaurants, err := h.service.Restaurant.PopularRestaurants(c.Request().Context())
 	if err != nil {
-		h.logger.Error("Failed to get restaurants:", err)
-		return c.JSON(http.StatusInternalServerError, response.CustomResponse{
-			Status:  http.StatusInternalServerError,
-			Message: "Failed to get restaurants",
-			Data:    err.Error(),
-		})
+		return err
 	}
 
 	return c.JSON(http.StatusOK, response.CustomResponse{
@@ -61,12 +59,7 @@ func (h *RestaurantHandler) PopularRestaurants(c echo.Context) error {
 func (h *RestaurantHandler) GetServices(c echo.Context) error {
 	services, err := h.service.Restaurant.GetServices(c.Request().Context())
 	if err != nil {
-		h.logger.Error("Failed to get services:", err)
-		return c.JSON(http.StatusInternalServerError, response.CustomResponse{
-			Status:  http.StatusInternalServerError,
-			Message: "Failed to get services",
-			Data:    err.Error(),
-		})
+		return err
 	}
 
 	return c.JSON(http.StatusOK, response.CustomResponse{
@@ -79,29 +72,17 @@ func (h *RestaurantHandler) GetServices(c echo.Context) error {
 func (h *RestaurantHandler) DeleteRestaurant(c echo.Context) error {
 	restaurantID := c.Param("id")
 	if restaurantID == "" {
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  http.StatusBadRequest,
-			Message: "Restaurant ID is required",
-		})
+		return errs.ErrInvalidID
 	}
 
 	id, err := strconv.ParseUint(restaurantID, 10, 64)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  http.StatusBadRequest,
-			Message: "Invalid restaurant ID",
-			Data:    err.Error(),
-		})
+		return errs.ErrInvalidID.Wrap(err)
 	}
 
 	err = h.service.Restaurant.DeleteRestaurant(c.Request().Context(), uint(id))
 	if err != nil {
-		h.logger.Error("Failed to delete restaurant:", err)
-		return c.JSON(http.StatusInternalServerError, response.CustomResponse{
-			Status:  http.StatusInternalServerError,
-			Message: "Failed to delete restaurant",
-			Data:    err.Error(),
-		})
+		return err
 	}
 
 	return c.JSON(http.StatusOK, response.CustomResponse{
@@ -113,41 +94,33 @@ func (h *RestaurantHandler) DeleteRestaurant(c echo.Context) error {
 func (h *RestaurantHandler) UpdateRestaurant(c echo.Context) error {
 	var restaurant model.Restaurant
 	if err := c.Bind(&restaurant); err != nil {
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  http.StatusBadRequest,
-			Message: "Invalid request body",
-			Data:    err.Error(),
-		})
+		return errs.ErrInvalidBody.Wrap(err)
+	}
+
+	if err := c.Validate(&restaurant); err != nil {
+		return err
 	}
 
 	restaurantID := c.Param("id")
 	if restaurantID == "" {
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  http.StatusBadRequest,
-			Message: "Restaurant ID is required",
-		})
+		return errs.ErrInvalidID
 	}
 
 	id, err := strconv.ParseUint(restaurantID, 10, 64)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  http.StatusBadRequest,
-			Message: "Invalid restaurant ID",
-			Data:    err.Error(),
-		})
+		return errs.ErrInvalidID.Wrap(err)
 	}
 
 	restaurant.ID = uint(id)
+	if restaurant.Version, err = ifMatch(c); err != nil {
+		return err
+	}
 
 	updatedRestaurant, err := h.service.Restaurant.UpdateRestaurant(c.Request().Context(), &restaurant, uint(id))
 	if err != nil {
-		h.logger.Error("Failed to update restaurant:", err)
-		return c.JSON(http.StatusInternalServerError, response.CustomResponse{
-			Status:  http.StatusInternalServerError,
-			Message: "Failed to update restaurant",
-			Data:    err.Error(),
-		})
+		return err
 	}
+	setETag(c, updatedRestaurant.Version)
 
 	return c.JSON(http.StatusOK, response.CustomResponse{
 		Status:  http.StatusOK,
@@ -156,24 +129,44 @@ func (h *RestaurantHandler) UpdateRestaurant(c echo.Context) error {
 	})
 }
 
+func (h *RestaurantHandler) PatchRestaurant(c echo.Context) error {
+	id, err := utils.ConvertIdToUint(c.Param("id"))
+	if err != nil {
+		return errs.ErrInvalidID.Wrap(err)
+	}
+
+	version, err := ifMatch(c)
+	if err != nil {
+		return err
+	}
+
+	p, err := patch.Decode(c.Request().Body)
+	if err != nil {
+		return err
+	}
+
+	restaurant, err := h.service.Restaurant.PatchRestaurant(c.Request().Context(), id, version, p)
+	if err != nil {
+		return err
+	}
+	setETag(c, restaurant.Version)
+
+	return c.JSON(http.StatusOK, response.CustomResponse{
+		Status:  http.StatusOK,
+		Message: "Restaurant updated successfully",
+		Data:    restaurant,
+	})
+}
+
 func (h *RestaurantHandler) GetRestaurants(c echo.Context) error {
 	searchParams, err := h.service.Restaurant.RestaurantsSearchFormatting(model.NewParams(), c)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, response.CustomResponse{
-			Status:  http.StatusInternalServerError,
-			Message: "Failed reading params",
-			Data:    err.Error(),
-		})
+		return err
 	}
 
 	restaurants, err := h.service.Restaurant.GetRestaurants(c.Request().Context(), searchParams)
 	if err != nil {
-		h.logger.Error("Failed to get restaurants:", err)
-		return c.JSON(http.StatusInternalServerError, response.CustomResponse{
-			Status:  http.StatusInternalServerError,
-			Message: "Failed to get restaurants",
-			Data:    err.Error(),
-		})
+		return err
 	}
 
 	return c.JSON(http.StatusOK, response.CustomResponse{
@@ -186,12 +179,7 @@ func (h *RestaurantHandler) GetRestaurants(c echo.Context) error {
 func (h *RestaurantHandler) GetStatistics(c echo.Context) error {
 	statistics, err := h.service.Restaurant.GetStatistics(c.Request().Context())
 	if err != nil {
-		h.logger.Error("Failed to get restaurants:", err)
-		return c.JSON(http.StatusInternalServerError, response.CustomResponse{
-			Status:  http.StatusInternalServerError,
-			Message: "Failed to get restaurants",
-			Data:    err.Error(),
-		})
+		return err
 	}
 
 	return c.JSON(http.StatusOK, response.CustomResponse{
@@ -204,30 +192,19 @@ func (h *RestaurantHandler) GetStatistics(c echo.Context) error {
 func (h *RestaurantHandler) GetRestaurantByID(c echo.Context) error {
 	restaurantID := c.Param("id")
 	if restaurantID == "" {
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  http.StatusBadRequest,
-			Message: "Restaurant ID is required",
-		})
+		return errs.ErrInvalidID
 	}
 
 	id, err := strconv.ParseUint(restaurantID, 10, 64)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  http.StatusBadRequest,
-			Message: "Invalid restaurant ID",
-			Data:    err.Error(),
-		})
+		return errs.ErrInvalidID.Wrap(err)
 	}
 
 	restaurant, err := h.service.Restaurant.GetRestaurantByID(c.Request().Context(), uint(id))
 	if err != nil {
-		h.logger.Error("Failed to get restaurant:", err)
-		return c.JSON(http.StatusInternalServerError, response.CustomResponse{
-			Status:  http.StatusInternalServerError,
-			Message: "Failed to get restaurant",
-			Data:    err.Error(),
-		})
+		return err
 	}
+	setETag(c, restaurant.Version)
 
 	return c.JSON(http.StatusOK, response.CustomResponse{
 		Status:  http.StatusOK,
@@ -239,38 +216,22 @@ func (h *RestaurantHandler) GetRestaurantByID(c echo.Context) error {
 func (h *RestaurantHandler) GetRestaurantOrders(c echo.Context) error {
 	restaurantID := c.Param("id")
 	if restaurantID == "" {
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  http.StatusBadRequest,
-			Message: "Restaurant ID is required",
-		})
+		return errs.ErrInvalidID
 	}
 
 	id, err := strconv.ParseUint(restaurantID, 10, 64)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  http.StatusBadRequest,
-			Message: "Invalid restaurant ID",
-			Data:    err.Error(),
-		})
+		return errs.ErrInvalidID.Wrap(err)
 	}
 
 	searchParams, err := h.service.Order.OrderSearchFormatting(model.NewParams(), c)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, response.CustomResponse{
-			Status:  http.StatusInternalServerError,
-			Message: "Failed reading params",
-			Data:    err.Error(),
-		})
+		return err
 	}
 
 	orders, err := h.service.Restaurant.GetRestaurantOrders(c.Request().Context(), uint(id), searchParams)
 	if err != nil {
-		h.logger.Error("Failed to get orders for restaurant:", err)
-		return c.JSON(http.StatusInternalServerError, response.CustomResponse{
-			Status:  http.StatusInternalServerError,
-			Message: "Failed to get orders for restaurant",
-			Data:    err.Error(),
-		})
+		return err
 	}
 
 	return c.JSON(http.StatusOK, response.CustomResponse{
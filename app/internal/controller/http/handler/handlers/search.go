@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"github.com/alibekabdrakhman1/orynal/internal/model"
+	"github.com/alibekabdrakhman1/orynal/internal/service"
+	"github.com/alibekabdrakhman1/orynal/pkg/response"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+type SearchHandler struct {
+	service *service.Manager
+	logger  *zap.SugaredLogger
+}
+
+func NewSearchHandler(service *service.Manager, logger *zap.SugaredLogger) *SearchHandler {
+	return &SearchHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (h *SearchHandler) SearchRestaurants(c echo.Context) error {
+	searchParams, err := h.service.Search.TextSearchFormatting(model.NewParams(), c)
+	if err != nil {
+		return err
+	}
+
+	hits, err := h.service.Search.SearchRestaurants(c.Request().Context(), searchParams)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, response.CustomResponse{
+		Status:  http.StatusOK,
+		Message: "Success",
+		Data:    hits,
+	})
+}
+
+func (h *SearchHandler) SearchDishes(c echo.Context) error {
+	search, err := h.service.Search.DishSearchFormatting(model.NewDishSearch(), c)
+	if err != nil {
+		return err
+	}
+
+	groups, err := h.service.Search.SearchDishes(c.Request().Context(), search)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, response.CustomResponse{
+		Status:  http.StatusOK,
+		Message: "Success",
+		Data:    groups,
+	})
+}
@@ -3,7 +3,10 @@ package handlers
 import (
 	"github.com/alibekabdrakhman1/orynal/internal/model"
 	"github.com/alibekabdrakhman1/orynal/internal/service"
+	"github.com/alibekabdrakhman1/orynal/pkg/errs"
+	"github.com/alibekabdrakhman1/orynal/pkg/patch"
 	"github.com/alibekabdrakhman1/orynal/pkg/response"
+	"github.com/alibekabdrakhman1/orynal/pkg/utils"
 	"github.com/labstack/echo/v4"
 	"go.uber.org/zap"
 	"net/http"
@@ -25,29 +28,17 @@ type TableHandler struct {
 func (h *TableHandler) GetTableCategories(c echo.Context) error {
 	restaurantID := c.Param("id")
 	if restaurantID == "" {
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  http.StatusBadRequest,
-			Message: "Restaurant ID is required",
-		})
+		return errs.ErrInvalidID
 	}
 
 	id, err := strconv.ParseUint(restaurantID, 10, 64)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  http.StatusBadRequest,
-			Message: "Invalid restaurant ID",
-			Data:    err.Error(),
-		})
+		return errs.ErrInvalidID.Wrap(err)
 	}
 
 	types, err := h.service.Table.GetTableCategories(c.Request().Context(), uint(id))
 	if err != nil {
-		h.logger.Error("Failed to get tables for restaurant:", err)
-		return c.JSON(http.StatusInternalServerError, response.CustomResponse{
-			Status:  http.StatusInternalServerError,
-			Message: "Failed to get tables for restaurant",
-			Data:    err.Error(),
-		})
+		return err
 	}
 
 	return c.JSON(http.StatusOK, response.CustomResponse{
@@ -60,38 +51,22 @@ func (h *TableHandler) GetTableCategories(c echo.Context) error {
 func (h *TableHandler) GetRestaurantTables(c echo.Context) error {
 	restaurantID := c.Param("id")
 	if restaurantID == "" {
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  http.StatusBadRequest,
-			Message: "Restaurant ID is required",
-		})
+		return errs.ErrInvalidID
 	}
 
 	id, err := strconv.ParseUint(restaurantID, 10, 64)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  http.StatusBadRequest,
-			Message: "Invalid restaurant ID",
-			Data:    err.Error(),
-		})
+		return errs.ErrInvalidID.Wrap(err)
 	}
 
 	searchParams, err := h.service.Table.TablesSearchFormatting(model.NewParams(), c)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, response.CustomResponse{
-			Status:  http.StatusInternalServerError,
-			Message: "Failed reading params",
-			Data:    err.Error(),
-		})
+		return err
 	}
 
 	tables, err := h.service.Table.GetRestaurantTables(c.Request().Context(), uint(id), searchParams)
 	if err != nil {
-		h.logger.Error("Failed to get tables for restaurant:", err)
-		return c.JSON(http.StatusInternalServerError, response.CustomResponse{
-			Status:  http.StatusInternalServerError,
-			Message: "Failed to get tables for restaurant",
-			Data:    err.Error(),
-		})
+		return err
 	}
 
 	return c.JSON(http.StatusOK, response.CustomResponse{
@@ -104,47 +79,29 @@ func (h *TableHandler) GetRestaurantTables(c echo.Context) error {
 func (h *TableHandler) GetRestaurantTable(c echo.Context) error {
 	restaurantID := c.Param("id")
 	if restaurantID == "" {
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  http.StatusBadRequest,
-			Message: "Restaurant ID is required",
-		})
+		return errs.ErrInvalidID
 	}
 
 	restaurantId, err := strconv.ParseUint(restaurantID, 10, 64)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  http.StatusBadRequest,
-			Message: "Invalid restaurant ID",
-			Data:    err.Error(),
-		})
+		return errs.ErrInvalidID.Wrap(err)
 	}
 
 	tableID := c.Param("table_id")
 	if tableID == "" {
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  http.StatusBadRequest,
-			Message: "Table ID is required",
-		})
+		return errs.ErrInvalidID
 	}
 
 	tableId, err := strconv.ParseUint(tableID, 10, 64)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  http.StatusBadRequest,
-			Message: "Invalid table ID",
-			Data:    err.Error(),
-		})
+		return errs.ErrInvalidID.Wrap(err)
 	}
 
 	table, err := h.service.Table.GetRestaurantTable(c.Request().Context(), uint(restaurantId), uint(tableId))
 	if err != nil {
-		h.logger.Error("Failed to get table:", err)
-		return c.JSON(http.StatusInternalServerError, response.CustomResponse{
-			Status:  http.StatusInternalServerError,
-			Message: "Failed to get table",
-			Data:    err.Error(),
-		})
+		return err
 	}
+	setETag(c, table.Version)
 
 	return c.JSON(http.StatusOK, response.CustomResponse{
 		Status:  http.StatusOK,
@@ -156,37 +113,25 @@ func (h *TableHandler) GetRestaurantTable(c echo.Context) error {
 func (h *TableHandler) CreateRestaurantTable(c echo.Context) error {
 	restaurantID := c.Param("id")
 	if restaurantID == "" {
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  http.StatusBadRequest,
-			Message: "Restaurant ID is required",
-		})
+		return errs.ErrInvalidID
 	}
 
 	restaurantId, err := strconv.ParseUint(restaurantID, 10, 64)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  http.StatusBadRequest,
-			Message: "Invalid restaurant ID",
-			Data:    err.Error(),
-		})
+		return errs.ErrInvalidID.Wrap(err)
 	}
 	var table model.Table
 	if err := c.Bind(&table); err != nil {
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  http.StatusBadRequest,
-			Message: "Invalid request body",
-			Data:    err.Error(),
-		})
+		return errs.ErrInvalidBody.Wrap(err)
+	}
+
+	if err := c.Validate(&table); err != nil {
+		return err
 	}
 
 	createdTable, err := h.service.Table.CreateRestaurantTable(c.Request().Context(), uint(restaurantId), &table)
 	if err != nil {
-		h.logger.Error("Failed to create table:", err)
-		return c.JSON(http.StatusInternalServerError, response.CustomResponse{
-			Status:  http.StatusInternalServerError,
-			Message: "Failed to create table",
-			Data:    err.Error(),
-		})
+		return err
 	}
 
 	return c.JSON(http.StatusCreated, response.CustomResponse{
@@ -199,57 +144,42 @@ func (h *TableHandler) CreateRestaurantTable(c echo.Context) error {
 func (h *TableHandler) UpdateRestaurantTable(c echo.Context) error {
 	var table model.Table
 	if err := c.Bind(&table); err != nil {
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  http.StatusBadRequest,
-			Message: "Invalid request body",
-			Data:    err.Error(),
-		})
+		return errs.ErrInvalidBody.Wrap(err)
+	}
+
+	if err := c.Validate(&table); err != nil {
+		return err
 	}
 
 	restaurantID := c.Param("id")
 	if restaurantID == "" {
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  http.StatusBadRequest,
-			Message: "Restaurant ID is required",
-		})
+		return errs.ErrInvalidID
 	}
 
 	restaurantId, err := strconv.ParseUint(restaurantID, 10, 64)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  http.StatusBadRequest,
-			Message: "Invalid restaurant ID",
-			Data:    err.Error(),
-		})
+		return errs.ErrInvalidID.Wrap(err)
 	}
 
 	tableID := c.Param("table_id")
 	if tableID == "" {
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  http.StatusBadRequest,
-			Message: "Table ID is required",
-		})
+		return errs.ErrInvalidID
 	}
 
 	tableId, err := strconv.ParseUint(tableID, 10, 64)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  http.StatusBadRequest,
-			Message: "Invalid table ID",
-			Data:    err.Error(),
-		})
+		return errs.ErrInvalidID.Wrap(err)
 	}
 	table.ID = uint(tableId)
+	if table.Version, err = ifMatch(c); err != nil {
+		return err
+	}
 
 	updatedTable, err := h.service.Table.UpdateRestaurantTable(c.Request().Context(), uint(restaurantId), &table)
 	if err != nil {
-		h.logger.Error("Failed to update table:", err)
-		return c.JSON(http.StatusInternalServerError, response.CustomResponse{
-			Status:  http.StatusInternalServerError,
-			Message: "Failed to update table",
-			Data:    err.Error(),
-		})
+		return err
 	}
+	setETag(c, updatedTable.Version)
 
 	return c.JSON(http.StatusOK, response.CustomResponse{
 		Status:  http.StatusOK,
@@ -258,49 +188,64 @@ func (h *TableHandler) UpdateRestaurantTable(c echo.Context) error {
 	})
 }
 
+func (h *TableHandler) PatchRestaurantTable(c echo.Context) error {
+	restaurantID, err := utils.ConvertIdToUint(c.Param("id"))
+	if err != nil {
+		return errs.ErrInvalidID.Wrap(err)
+	}
+
+	tableID, err := utils.ConvertIdToUint(c.Param("table_id"))
+	if err != nil {
+		return errs.ErrInvalidID.Wrap(err)
+	}
+
+	version, err := ifMatch(c)
+	if err != nil {
+		return err
+	}
+
+	p, err := patch.Decode(c.Request().Body)
+	if err != nil {
+		return err
+	}
+
+	table, err := h.service.Table.PatchRestaurantTable(c.Request().Context(), restaurantID, tableID, version, p)
+	if err != nil {
+		return err
+	}
+	setETag(c, table.Version)
+
+	return c.JSON(http.StatusOK, response.CustomResponse{
+		Status:  http.StatusOK,
+		Message: "Table updated successfully",
+		Data:    table,
+	})
+}
+
 func (h *TableHandler) DeleteRestaurantTable(c echo.Context) error {
 	restaurantID := c.Param("id")
 	if restaurantID == "" {
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  http.StatusBadRequest,
-			Message: "Restaurant ID is required",
-		})
+		return errs.ErrInvalidID
 	}
 
 	restaurantId, err := strconv.ParseUint(restaurantID, 10, 64)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  http.StatusBadRequest,
-			Message: "Invalid restaurant ID",
-			Data:    err.Error(),
-		})
+		return errs.ErrInvalidID.Wrap(err)
 	}
 
 	tableID := c.Param("table_id")
 	if tableID == "" {
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  http.StatusBadRequest,
-			Message: "Table ID is required",
-		})
+		return errs.ErrInvalidID
 	}
 
 	tableId, err := strconv.ParseUint(tableID, 10, 64)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  http.StatusBadRequest,
-			Message: "Invalid table ID",
-			Data:    err.Error(),
-		})
+		return errs.ErrInvalidID.Wrap(err)
 	}
 
 	err = h.service.Table.DeleteRestaurantTable(c.Request().Context(), uint(restaurantId), uint(tableId))
 	if err != nil {
-		h.logger.Error("Failed to delete table:", err)
-		return c.JSON(http.StatusInternalServerError, response.CustomResponse{
-			Status:  http.StatusInternalServerError,
-			Message: "Failed to delete table",
-			Data:    err.Error(),
-		})
+		return err
 	}
 
 	return c.JSON(http.StatusOK, response.CustomResponse{
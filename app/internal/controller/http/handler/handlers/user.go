@@ -4,11 +4,15 @@ import (
 	"encoding/json"
 	"github.com/alibekabdrakhman1/orynal/internal/model"
 	"github.com/alibekabdrakhman1/orynal/internal/service"
+	"github.com/alibekabdrakhman1/orynal/pkg/errs"
+	"github.com/alibekabdrakhman1/orynal/pkg/patch"
 	"github.com/alibekabdrakhman1/orynal/pkg/response"
+	"github.com/alibekabdrakhman1/orynal/pkg/utils"
 	"github.com/labstack/echo/v4"
 	"go.uber.org/zap"
 	"io/ioutil"
 	"net/http"
+	"strconv"
 )
 
 func NewUserHandler(service *service.Manager, logger *zap.SugaredLogger) *UserHandler {
@@ -26,11 +30,7 @@ type UserHandler struct {
 func (h *UserHandler) Profile(c echo.Context) error {
 	user, err := h.service.User.Profile(c.Request().Context())
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  http.StatusBadRequest,
-			Message: err.Error(),
-			Data:    err,
-		})
+		return err
 	}
 	return c.JSON(http.StatusOK, response.CustomResponse{
 		Status:  http.StatusOK,
@@ -40,23 +40,41 @@ func (h *UserHandler) Profile(c echo.Context) error {
 }
 
 func (h *UserHandler) UpdateProfile(c echo.Context) error {
-	var user model.User
-	if err := c.Bind(&user); err != nil {
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  http.StatusBadRequest,
-			Message: "Invalid request body",
-			Data:    err.Error(),
-		})
+	var profile model.ProfileRequest
+	if err := c.Bind(&profile); err != nil {
+		return errs.ErrInvalidBody.Wrap(err)
 	}
 
-	updatedUser, err := h.service.User.Update(c.Request().Context(), &user)
+	if err := c.Validate(&profile); err != nil {
+		return err
+	}
+
+	updatedUser, err := h.service.User.Update(c.Request().Context(), &model.User{
+		Name:    profile.Name,
+		Surname: profile.Surname,
+		Email:   profile.Email,
+		Phone:   profile.Phone,
+	})
 	if err != nil {
-		h.logger.Error("Failed to update user profile:", err)
-		return c.JSON(http.StatusInternalServerError, response.CustomResponse{
-			Status:  http.StatusInternalServerError,
-			Message: "Failed to update user profile",
-			Data:    err.Error(),
-		})
+		return err
+	}
+
+	return c.JSON(http.StatusOK, response.CustomResponse{
+		Status:  http.StatusOK,
+		Message: "User profile updated successfully",
+		Data:    updatedUser,
+	})
+}
+
+func (h *UserHandler) PatchProfile(c echo.Context) error {
+	p, err := patch.Decode(c.Request().Body)
+	if err != nil {
+		return err
+	}
+
+	updatedUser, err := h.service.User.Patch(c.Request().Context(), p)
+	if err != nil {
+		return err
 	}
 
 	return c.JSON(http.StatusOK, response.CustomResponse{
@@ -69,21 +87,16 @@ func (h *UserHandler) UpdateProfile(c echo.Context) error {
 func (h *UserHandler) ChangePassword(c echo.Context) error {
 	var pass model.ChangePasswordRequest
 	if err := c.Bind(&pass); err != nil {
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  http.StatusBadRequest,
-			Message: "Invalid request body",
-			Data:    err.Error(),
-		})
+		return errs.ErrInvalidBody.Wrap(err)
+	}
+
+	if err := c.Validate(&pass); err != nil {
+		return err
 	}
 
 	err := h.service.User.ChangePassword(c.Request().Context(), &pass)
 	if err != nil {
-		h.logger.Error("Failed to update user password:", err)
-		return c.JSON(http.StatusInternalServerError, response.CustomResponse{
-			Status:  http.StatusInternalServerError,
-			Message: "Failed to update user password",
-			Data:    err.Error(),
-		})
+		return err
 	}
 
 	return c.JSON(http.StatusOK, response.CustomResponse{
@@ -93,42 +106,82 @@ func (h *UserHandler) ChangePassword(c echo.Context) error {
 }
 
 func (h *UserHandler) DeleteProfile(c echo.Context) error {
-	currentUser := c.Get("user").(*model.UserResponse)
-	err := h.service.User.Delete(c.Request().Context(), currentUser.ID)
+	deletion, err := h.service.User.RequestDeletion(c.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusAccepted, response.CustomResponse{
+		Status:  http.StatusAccepted,
+		Message: "User profile deletion scheduled, log in before anonymize_at to cancel it",
+		Data:    deletion,
+	})
+}
+
+func (h *UserHandler) RequestExport(c echo.Context) error {
+	export, err := h.service.Export.RequestExport(c.Request().Context())
 	if err != nil {
-		h.logger.Error("Failed to delete user profile:", err)
-		return c.JSON(http.StatusInternalServerError, response.CustomResponse{
-			Status:  http.StatusInternalServerError,
-			Message: "Failed to delete user profile",
-			Data:    err.Error(),
-		})
+		return err
+	}
+
+	return c.JSON(http.StatusAccepted, response.CustomResponse{
+		Status:  http.StatusAccepted,
+		Message: "Data export requested",
+		Data:    export,
+	})
+}
+
+func (h *UserHandler) GetExport(c echo.Context) error {
+	exportID, err := utils.ConvertIdToUint(c.Param("id"))
+	if err != nil {
+		return errs.ErrInvalidID.Wrap(err)
+	}
+
+	export, err := h.service.Export.GetExport(c.Request().Context(), exportID)
+	if err != nil {
+		return err
 	}
 
 	return c.JSON(http.StatusOK, response.CustomResponse{
 		Status:  http.StatusOK,
-		Message: "User profile deleted successfully",
+		Message: "Success",
+		Data:    export,
 	})
 }
+
+func (h *UserHandler) DownloadExport(c echo.Context) error {
+	exportID, err := utils.ConvertIdToUint(c.Param("id"))
+	if err != nil {
+		return errs.ErrInvalidID.Wrap(err)
+	}
+
+	expires, err := strconv.ParseInt(c.QueryParam("expires"), 10, 64)
+	if err != nil {
+		return errs.ErrInvalidParams.Wrap(err)
+	}
+
+	path, err := h.service.Export.GetExportFile(c.Request().Context(), exportID, expires, c.QueryParam("signature"))
+	if err != nil {
+		return err
+	}
+
+	return c.Attachment(path, "orynal-data-export.zip")
+}
+
 func (h *UserHandler) SignIn(c echo.Context) error {
 	body, err := ioutil.ReadAll(c.Request().Body)
 	if err != nil {
-		h.logger.Error(err)
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  -1,
-			Message: "request body reading error",
-			Data:    err,
-		})
+		return errs.ErrInvalidBody.Wrap(err)
 	}
 	var request model.Login
 
 	err = json.Unmarshal(body, &request)
 	if err != nil {
-		h.logger.Error(err)
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  -1,
-			Message: "register model unmarshalling error",
-			Data:    err,
-		})
+		return errs.ErrInvalidBody.Wrap(err)
+	}
+
+	if err := c.Validate(&request); err != nil {
+		return err
 	}
 
 	//return c.JSON(http.StatusCreated, response.CustomResponse{
@@ -139,12 +192,7 @@ func (h *UserHandler) SignIn(c echo.Context) error {
 
 	userToken, err := h.service.Auth.Login(c.Request().Context(), request)
 	if err != nil {
-		h.logger.Error(err)
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  -1,
-			Message: "login error",
-			Data:    err.Error(),
-		})
+		return err
 	}
 
 	res := model.JwtTokens{
@@ -152,7 +200,6 @@ func (h *UserHandler) SignIn(c echo.Context) error {
 		RefreshToken: userToken.RefreshToken,
 	}
 
-	h.logger.Info(res)
 	return c.JSON(http.StatusCreated, response.CustomResponse{
 		Status:  0,
 		Message: "OK",
@@ -163,34 +210,23 @@ func (h *UserHandler) SignIn(c echo.Context) error {
 func (h *UserHandler) SignUp(c echo.Context) error {
 	body, err := ioutil.ReadAll(c.Request().Body)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  -1,
-			Message: "Request Body reading error",
-			Data:    err,
-		})
+		return errs.ErrInvalidBody.Wrap(err)
 	}
 
 	var request model.Register
 	err = json.Unmarshal(body, &request)
 	if err != nil {
-		h.logger.Error(err)
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  -1,
-			Message: "Register model unmarshalling error",
-			Data:    err,
-		})
+		return errs.ErrInvalidBody.Wrap(err)
+	}
+
+	if err := c.Validate(&request); err != nil {
+		return err
 	}
 
 	userId, err := h.service.Auth.Register(c.Request().Context(), request)
 	if err != nil {
-		h.logger.Error(err)
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  -1,
-			Message: "Register error",
-			Data:    err,
-		})
+		return err
 	}
-	h.logger.Info(userId)
 	return c.JSON(http.StatusCreated, response.CustomResponse{
 		Status:  0,
 		Message: "OK",
@@ -208,25 +244,14 @@ func (h *UserHandler) RefreshToken(c echo.Context) error {
 	var r refreshRequest
 	err := c.Bind(&r)
 	if err != nil {
-		h.logger.Error(err)
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  -1,
-			Message: "Request Body reading error",
-			Data:    err,
-		})
+		return errs.ErrInvalidBody.Wrap(err)
 	}
 
 	tokens, err := h.service.Auth.RefreshToken(c.Request().Context(), r.RefreshToken)
 	if err != nil {
-		h.logger.Error(err)
-		return c.JSON(http.StatusBadRequest, response.CustomResponse{
-			Status:  -1,
-			Message: "Refresh Token error",
-			Data:    err,
-		})
+		return err
 	}
 
-	h.logger.Info(tokens)
 	return c.JSON(http.StatusCreated, response.CustomResponse{
 		Status:  0,
 		Message: "OK",
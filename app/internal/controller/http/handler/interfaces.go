@@ -8,21 +8,29 @@ type IUserHandler interface {
 	RefreshToken(c echo.Context) error
 	Profile(c echo.Context) error
 	UpdateProfile(c echo.Context) error
+	PatchProfile(c echo.Context) error
 	ChangePassword(c echo.Context) error
 	DeleteProfile(c echo.Context) error
+	RequestExport(c echo.Context) error
+	GetExport(c echo.Context) error
+	DownloadExport(c echo.Context) error
 }
 
 type IAdminHandler interface {
 	GetClients(c echo.Context) error
 	GetClient(c echo.Context) error
 	DeleteClient(c echo.Context) error
+	RestoreClient(c echo.Context) error
 	GetOwners(c echo.Context) error
 	CreateOwner(c echo.Context) error
 	DeleteOwner(c echo.Context) error
+	RestoreOwner(c echo.Context) error
 	GetRestaurants(c echo.Context) error
 	GetRestaurant(c echo.Context) error
 	CreateRestaurant(c echo.Context) error
 	DeleteRestaurant(c echo.Context) error
+	RestoreRestaurant(c echo.Context) error
+	RestoreRestaurantFood(c echo.Context) error
 	UpdateRestaurant(c echo.Context) error
 	CreateService(c echo.Context) error
 	DeleteService(c echo.Context) error
@@ -40,6 +48,7 @@ type IRestaurantHandler interface {
 	GetRestaurantOrders(c echo.Context) error
 	DeleteRestaurant(c echo.Context) error
 	UpdateRestaurant(c echo.Context) error
+	PatchRestaurant(c echo.Context) error
 	GetServices(c echo.Context) error
 }
 
@@ -49,12 +58,18 @@ type IReviewsHandler interface {
 	DeleteReview(c echo.Context) error
 }
 
+type ISearchHandler interface {
+	SearchRestaurants(c echo.Context) error
+	SearchDishes(c echo.Context) error
+}
+
 type ITableHandler interface {
 	GetTableCategories(c echo.Context) error
 	GetRestaurantTables(c echo.Context) error
 	GetRestaurantTable(c echo.Context) error
 	CreateRestaurantTable(c echo.Context) error
 	UpdateRestaurantTable(c echo.Context) error
+	PatchRestaurantTable(c echo.Context) error
 	DeleteRestaurantTable(c echo.Context) error
 	GetAvailableTime(c echo.Context) error
 }
@@ -65,6 +80,7 @@ type IMenuHandler interface {
 	GetRestaurantFood(c echo.Context) error
 	CreateRestaurantFood(c echo.Context) error
 	UpdateRestaurantFood(c echo.Context) error
+	PatchRestaurantFood(c echo.Context) error
 	DeleteRestaurantFood(c echo.Context) error
 }
 
@@ -72,6 +88,7 @@ type IOrderHandler interface {
 	CreateOrder(c echo.Context) error
 	DeleteOrder(c echo.Context) error
 	UpdateOrder(c echo.Context) error
+	PatchOrder(c echo.Context) error
 	GetOrder(c echo.Context) error
 	GetAllOrders(c echo.Context) error
 }
@@ -14,6 +14,7 @@ type Manager struct {
 	Table      ITableHandler
 	Menu       IMenuHandler
 	Reviews    IReviewsHandler
+	Search     ISearchHandler
 }
 
 func NewManager(srv *service.Manager, logger *zap.SugaredLogger) *Manager {
@@ -25,5 +26,6 @@ func NewManager(srv *service.Manager, logger *zap.SugaredLogger) *Manager {
 		Table:      handlers.NewTableHandler(srv, logger),
 		Menu:       handlers.NewMenuHandler(srv, logger),
 		Reviews:    handlers.NewReviewsHandler(srv, logger),
+		Search:     handlers.NewSearchHandler(srv, logger),
 	}
 }
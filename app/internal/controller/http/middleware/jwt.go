@@ -7,6 +7,8 @@ import (
 	"github.com/alibekabdrakhman1/orynal/internal/model"
 	"github.com/alibekabdrakhman1/orynal/internal/service/services"
 	"github.com/alibekabdrakhman1/orynal/pkg/enums"
+	"github.com/alibekabdrakhman1/orynal/pkg/errs"
+	"github.com/alibekabdrakhman1/orynal/pkg/logging"
 	"github.com/alibekabdrakhman1/orynal/pkg/utils"
 	"github.com/labstack/echo/v4"
 	"go.uber.org/zap"
@@ -36,11 +38,13 @@ func (m *JWTAuth) RoleToCtx(next echo.HandlerFunc) echo.HandlerFunc {
 		}
 
 		contextUserId, _ := m.AuthService.GetJwtUserID(jwtToken)
+		if contextUserId != nil {
+			if err := m.AuthService.CheckUser(c.Request().Context(), contextUserId); err != nil {
+				return err
+			}
+		}
 		contextUserRole, _ := m.AuthService.GetJwtUserRole(jwtToken)
-		ctx := context.WithValue(c.Request().Context(), model.ContextUserIDKey, contextUserId)
-		ctx = context.WithValue(ctx, model.ContextUserRoleKey, contextUserRole)
-
-		c.SetRequest(c.Request().WithContext(ctx))
+		c.SetRequest(c.Request().WithContext(m.withUser(c.Request().Context(), contextUserId, contextUserRole)))
 
 		return next(c)
 	}
@@ -55,26 +59,41 @@ func (m *JWTAuth) ValidateAuth(next echo.HandlerFunc) echo.HandlerFunc {
 
 		contextUserId, err := m.AuthService.GetJwtUserID(jwtToken)
 		if err != nil {
-			if !errors.Is(err, services.ErrExpiredToken) {
-				m.logger.Errorf("failed to GetJwtUser err: %v", err)
+			if !errors.Is(err, errs.ErrTokenExpired) {
+				logging.FromContext(c.Request().Context(), m.logger).Errorf("failed to GetJwtUser err: %v", err)
 			}
 
-			return echo.NewHTTPError(http.StatusUnauthorized, fmt.Sprintf("Unauthorized"))
+			return err
+		} else if err := m.AuthService.CheckUser(c.Request().Context(), contextUserId); err != nil {
+			return err
 		} else {
 			contextUserRole, err := m.AuthService.GetJwtUserRole(jwtToken)
 			if err != nil {
-				m.logger.Errorf("failed to GetJwtUser err: %v", err)
+				logging.FromContext(c.Request().Context(), m.logger).Errorf("failed to GetJwtUser err: %v", err)
 			}
 
-			ctx := context.WithValue(c.Request().Context(), model.ContextUserIDKey, contextUserId)
-			ctx = context.WithValue(ctx, model.ContextUserRoleKey, contextUserRole)
-
-			c.SetRequest(c.Request().WithContext(ctx))
+			c.SetRequest(c.Request().WithContext(m.withUser(c.Request().Context(), contextUserId, contextUserRole)))
 		}
 		return next(c)
 	}
 }
 
+// withUser stores the user in ctx and adds it to the request-scoped logger.
+func (m *JWTAuth) withUser(ctx context.Context, id *model.ContextUserID, role *model.ContextUserRole) context.Context {
+	ctx = context.WithValue(ctx, model.ContextUserIDKey, id)
+	ctx = context.WithValue(ctx, model.ContextUserRoleKey, role)
+
+	logger := logging.FromContext(ctx, m.logger)
+	if id != nil {
+		logger = logger.With("user_id", id.ID)
+	}
+	if role != nil {
+		logger = logger.With("role", role.Role)
+	}
+
+	return logging.WithContext(ctx, logger)
+}
+
 func (m *JWTAuth) ValidateAdmin(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
 		if err := m.validateRole(c, enums.Admin); err != nil {
@@ -105,31 +124,29 @@ func (m *JWTAuth) ValidateUser(next echo.HandlerFunc) echo.HandlerFunc {
 func (m *JWTAuth) validateRole(c echo.Context, expectedRole string) error {
 	role, err := utils.GetRoleFromContext(c.Request().Context())
 	if err != nil {
-		m.logger.Error(err)
+		logging.FromContext(c.Request().Context(), m.logger).Error(err)
 		return err
 	}
 	if role != expectedRole {
-		m.logger.Warn(fmt.Sprintf("you are not %v", expectedRole))
-		return echo.NewHTTPError(http.StatusForbidden, fmt.Sprintf("not permitted"))
+		logging.FromContext(c.Request().Context(), m.logger).Warn(fmt.Sprintf("you are not %v", expectedRole))
+		return errs.ErrPermissionDenied
 	}
 	return nil
 }
 
 func (m *JWTAuth) getTokenFromHeader(r *http.Request) (string, error) {
 	if _, ok := r.Header[AuthorizationHeaderKey]; !ok {
-		m.logger.Warn("'Authorization' key missing from headers")
-		return "", echo.NewHTTPError(http.StatusForbidden, errors.New("authorization' key missing from headers"))
+		logging.FromContext(r.Context(), m.logger).Warn("'Authorization' key missing from headers")
+		return "", errs.ErrUnauthorized.WithMessage("authorization header is missing")
 	}
 
 	jwtToken := r.Header.Get(AuthorizationHeaderKey)
 
 	if !(len(jwtToken) > 7 && strings.ToUpper(jwtToken[0:6]) == "BEARER") {
-		m.logger.Warn(fmt.Sprintf(
-			"failed to getTokenFromHeader invalidToken: %s",
-			r.Header.Get(AuthorizationHeaderKey),
-		))
+		// The header may carry other credentials, so only its scheme is wrong.
+		logging.FromContext(r.Context(), m.logger).Warn("'Authorization' header is not a Bearer token")
 
-		return "", echo.NewHTTPError(http.StatusForbidden, fmt.Errorf("failed to getTokenFromHeader invalidToken: %s", r.Header.Get(AuthorizationHeaderKey)))
+		return "", errs.ErrInvalidToken
 	}
 
 	return jwtToken[7:], nil
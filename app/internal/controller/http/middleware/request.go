@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/alibekabdrakhman1/orynal/pkg/logging"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+	"net/http"
+	"regexp"
+	"time"
+)
+
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,128}$`)
+
+type RequestLogger struct {
+	logger *zap.SugaredLogger
+}
+
+func NewRequestLogger(logger *zap.SugaredLogger) *RequestLogger {
+	return &RequestLogger{logger: logger}
+}
+
+// Handle assigns the request id, stores a request-scoped logger in the context
+// and writes one access-log line per request. It must run before every other
+// middleware so that their logs carry the request id.
+func (m *RequestLogger) Handle(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		start := time.Now()
+
+		requestID := c.Request().Header.Get(echo.HeaderXRequestID)
+		if !requestIDPattern.MatchString(requestID) {
+			requestID = newRequestID()
+		}
+		c.Response().Header().Set(echo.HeaderXRequestID, requestID)
+
+		logger := m.logger.With("request_id", requestID)
+		c.SetRequest(c.Request().WithContext(logging.WithContext(c.Request().Context(), logger)))
+
+		if err := next(c); err != nil {
+			c.Error(err)
+		}
+
+		// The auth middleware enriches the context logger with the user.
+		logger = logging.FromContext(c.Request().Context(), logger)
+
+		status := c.Response().Status
+		fields := []interface{}{
+			"method", c.Request().Method,
+			"route", c.Path(),
+			"path", c.Request().URL.Path,
+			"status", status,
+			"latency_ms", time.Since(start).Milliseconds(),
+			"bytes_out", c.Response().Size,
+			"remote_ip", c.RealIP(),
+			"user_agent", c.Request().UserAgent(),
+		}
+
+		switch {
+		case status >= http.StatusInternalServerError:
+			logger.Errorw("http request", fields...)
+		case status >= http.StatusBadRequest:
+			logger.Warnw("http request", fields...)
+		default:
+			logger.Infow("http request", fields...)
+		}
+
+		return nil
+	}
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return time.Now().Format("20060102150405.000000000")
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,201 @@
+package controller
+
+import (
+	"encoding/json"
+	"github.com/alibekabdrakhman1/orynal/internal/model"
+	"github.com/alibekabdrakhman1/orynal/pkg/health"
+	"github.com/alibekabdrakhman1/orynal/pkg/openapi"
+	"github.com/alibekabdrakhman1/orynal/pkg/response"
+	"github.com/alibekabdrakhman1/orynal/pkg/validator"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+var (
+	userQuery   = []string{"q", "filter", "order", "order_vector", "limit", "page", "after", "before"}
+	orderQuery  = []string{"filter", "order", "order_vector", "limit", "page", "after", "before"}
+	searchQuery = []string{"q", "filter", "order", "order_vector", "limit", "page", "after", "before", "near", "radius"}
+	menuQuery   = []string{"q", "filter", "order", "order_vector", "limit", "page"}
+	tableQuery  = []string{"q", "filter", "order", "order_vector", "limit", "page", "date"}
+	pageQuery   = []string{"filter", "order", "order_vector", "limit", "page", "after", "before"}
+	textQuery   = []string{"search_q", "limit", "page"}
+	dishQuery   = []string{"q", "min_price", "max_price", "category", "dietary", "city", "lat", "lng", "limit", "page"}
+
+	ifMatch = []string{"if_match"}
+)
+
+// mergePatch documents RFC 7396 request bodies: any subset of the fields a
+// role may patch, where null resets a field.
+var mergePatch = map[string]interface{}{}
+
+const mergePatchType = "application/merge-patch+json"
+
+type refreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+// apiRoutes documents every route registered in router.go. TestOpenAPICoversRoutes
+// fails when the two drift apart.
+var apiRoutes = []openapi.Route{
+	{Method: http.MethodGet, Path: "/api/openapi.json", Tag: "Docs", Summary: "OpenAPI document", ContentType: "application/json", Response: map[string]interface{}{}},
+	{Method: http.MethodGet, Path: "/api/docs", Tag: "Docs", Summary: "Swagger UI", ContentType: "text/html"},
+	{Method: http.MethodGet, Path: "/metrics", Tag: "Ops", Summary: "Prometheus metrics", ContentType: "text/plain"},
+	{Method: http.MethodGet, Path: "/healthz", Tag: "Ops", Summary: "Liveness probe", ContentType: "application/json", Response: health.Report{}},
+	{Method: http.MethodGet, Path: "/readyz", Tag: "Ops", Summary: "Readiness probe", Description: "Responds 503 with the failing checks while a dependency is down or the server is draining.", ContentType: "application/json", Response: health.Report{}},
+
+	{Method: http.MethodPost, Path: "/api/auth/login", Tag: "Auth", Summary: "Sign in", Body: model.Login{}, Status: http.StatusCreated, Response: model.JwtTokens{}},
+	{Method: http.MethodPost, Path: "/api/auth/register", Tag: "Auth", Summary: "Register a client", Body: model.Register{}, Status: http.StatusCreated, Response: response.IDResponse{}},
+	{Method: http.MethodPost, Path: "/api/auth/refresh-token", Tag: "Auth", Summary: "Exchange a refresh token for new tokens", Body: refreshTokenRequest{}, Status: http.StatusCreated, Response: model.JwtTokens{}},
+
+	{Method: http.MethodGet, Path: "/api/profile", Tag: "Profile", Summary: "Current user profile", Auth: true, Response: model.UserResponse{}},
+	{Method: http.MethodPut, Path: "/api/profile", Tag: "Profile", Summary: "Update the current user", Auth: true, Body: model.ProfileRequest{}, Response: model.UserResponse{}},
+	{Method: http.MethodPatch, Path: "/api/profile", Tag: "Profile", Summary: "Patch the current user", Description: "JSON merge patch of name, surname, email and phone.", Auth: true, Body: mergePatch, BodyType: mergePatchType, Response: model.UserResponse{}},
+	{Method: http.MethodDelete, Path: "/api/profile", Tag: "Profile", Summary: "Request account deletion", Description: "The account is anonymized after the cooling-off period unless the user signs in again.", Auth: true, Status: http.StatusAccepted, Response: model.DeletionResponse{}},
+	{Method: http.MethodPut, Path: "/api/profile/change-password", Tag: "Profile", Summary: "Change password", Auth: true, Body: model.ChangePasswordRequest{}},
+	{Method: http.MethodPost, Path: "/api/profile/export", Tag: "Profile", Summary: "Request a personal data export", Auth: true, Status: http.StatusAccepted, Response: model.DataExport{}},
+	{Method: http.MethodGet, Path: "/api/profile/export/:id", Tag: "Profile", Summary: "Data export status", Auth: true, Response: model.DataExport{}},
+	{Method: http.MethodGet, Path: "/api/profile/export/:id/download", Tag: "Profile", Summary: "Download a data export", Description: "Authorized by the signed link returned in download_url.", Query: []string{"expires", "signature"}, ContentType: "application/zip"},
+
+	{Method: http.MethodGet, Path: "/api/admin/owners", Tag: "Admin", Summary: "List restaurant owners", Auth: true, Query: userQuery, List: true, Response: model.UserResponse{}},
+	{Method: http.MethodPost, Path: "/api/admin/owners", Tag: "Admin", Summary: "Create a restaurant owner", Auth: true, Body: model.User{}, Status: http.StatusCreated, Response: model.UserResponse{}},
+	{Method: http.MethodDelete, Path: "/api/admin/owners/:id", Tag: "Admin", Summary: "Delete a restaurant owner", Auth: true},
+	{Method: http.MethodPost, Path: "/api/admin/owners/:id/restore", Tag: "Admin", Summary: "Restore a deleted owner", Auth: true},
+	{Method: http.MethodGet, Path: "/api/admin/clients", Tag: "Admin", Summary: "List clients", Auth: true, Query: userQuery, List: true, Response: model.UserResponse{}},
+	{Method: http.MethodDelete, Path: "/api/admin/clients/:id", Tag: "Admin", Summary: "Delete a client", Auth: true},
+	{Method: http.MethodPost, Path: "/api/admin/clients/:id/restore", Tag: "Admin", Summary: "Restore a deleted client", Auth: true},
+	{Method: http.MethodGet, Path: "/api/admin/services", Tag: "Admin", Summary: "List restaurant services", Auth: true, Response: []model.Service{}},
+	{Method: http.MethodPost, Path: "/api/admin/services", Tag: "Admin", Summary: "Create a restaurant service", Auth: true, Body: model.Service{}, Status: http.StatusCreated, Response: []model.Service{}},
+	{Method: http.MethodPut, Path: "/api/admin/services/:id", Tag: "Admin", Summary: "Update a restaurant service", Auth: true, Body: model.Service{}, Response: []model.Service{}},
+	{Method: http.MethodDelete, Path: "/api/admin/services/:id", Tag: "Admin", Summary: "Delete a restaurant service", Auth: true},
+	{Method: http.MethodGet, Path: "/api/admin/restaurants", Tag: "Admin", Summary: "List restaurants", Auth: true, Query: searchQuery, List: true, Response: model.Restaurant{}},
+	{Method: http.MethodPost, Path: "/api/admin/restaurants", Tag: "Admin", Summary: "Create a restaurant", Auth: true, Body: model.Restaurant{}, Status: http.StatusCreated, Response: model.Restaurant{}},
+	{Method: http.MethodGet, Path: "/api/admin/restaurants/services", Tag: "Admin", Summary: "List restaurant services", Auth: true, Response: []model.Service{}},
+	{Method: http.MethodPost, Path: "/api/admin/restaurants/services", Tag: "Admin", Summary: "Create a restaurant service", Auth: true, Body: model.Service{}, Status: http.StatusCreated, Response: []model.Service{}},
+	{Method: http.MethodPut, Path: "/api/admin/restaurants/services/:id", Tag: "Admin", Summary: "Update a restaurant service", Auth: true, Body: model.Service{}, Response: []model.Service{}},
+	{Method: http.MethodDelete, Path: "/api/admin/restaurants/services/:id", Tag: "Admin", Summary: "Delete a restaurant service", Auth: true},
+	{Method: http.MethodGet, Path: "/api/admin/restaurants/:id", Tag: "Admin", Summary: "Get a restaurant", Auth: true, Response: model.Restaurant{}, ETag: true},
+	{Method: http.MethodPut, Path: "/api/admin/restaurants/:id", Tag: "Admin", Summary: "Update a restaurant", Auth: true, Header: ifMatch, Body: model.Restaurant{}, Response: model.Restaurant{}, ETag: true},
+	{Method: http.MethodDelete, Path: "/api/admin/restaurants/:id", Tag: "Admin", Summary: "Delete a restaurant", Auth: true},
+	{Method: http.MethodPost, Path: "/api/admin/restaurants/:id/restore", Tag: "Admin", Summary: "Restore a deleted restaurant", Auth: true},
+	{Method: http.MethodPost, Path: "/api/admin/restaurants/:id/menu/:food_id/restore", Tag: "Admin", Summary: "Restore a deleted menu item", Auth: true},
+
+	{Method: http.MethodGet, Path: "/api/orders", Tag: "Orders", Summary: "List orders", Auth: true, Query: orderQuery, List: true, Response: model.OrderResponse{}},
+	{Method: http.MethodPost, Path: "/api/orders/create", Tag: "Orders", Summary: "Book a table", Auth: true, Body: model.OrderRequest{}, Status: http.StatusCreated, Response: model.OrderResponse{}},
+	{Method: http.MethodGet, Path: "/api/orders/:id", Tag: "Orders", Summary: "Get an order", Auth: true, Response: model.OrderResponse{}, ETag: true},
+	{Method: http.MethodPut, Path: "/api/orders/:id", Tag: "Orders", Summary: "Update an order", Auth: true, Header: ifMatch, Body: model.Order{}, Response: model.OrderResponse{}, ETag: true},
+	{Method: http.MethodPatch, Path: "/api/orders/:id", Tag: "Orders", Summary: "Patch an order", Description: "JSON merge patch. Users may set tableId, date and status, owners status, admins also totalSum.", Auth: true, Header: ifMatch, Body: mergePatch, BodyType: mergePatchType, Response: model.OrderResponse{}, ETag: true},
+	{Method: http.MethodDelete, Path: "/api/orders/:id", Tag: "Orders", Summary: "Delete an order", Auth: true},
+
+	{Method: http.MethodGet, Path: "/api/search", Tag: "Search", Summary: "Search restaurants and dishes", Description: "Ranked full text search over restaurant names, descriptions, cities and dishes, falling back to trigram similarity when nothing matches. Snippets mark matches with <mark>.", Query: textQuery, List: true, Response: model.SearchHit{}},
+	{Method: http.MethodGet, Path: "/api/foods/search", Tag: "Search", Summary: "Search dishes across restaurants", Description: "Available dishes grouped by restaurant, at most 10 per restaurant; the page counts restaurants. Restaurants are ordered by distance when lat and lng are given, then by matching dishes.", Query: dishQuery, List: true, Response: model.DishGroup{}},
+
+	{Method: http.MethodGet, Path: "/api/restaurants", Tag: "Restaurants", Summary: "List restaurants", Query: searchQuery, List: true, Response: model.Restaurant{}},
+	{Method: http.MethodGet, Path: "/api/restaurants/statistics", Tag: "Restaurants", Summary: "Platform statistics", Response: model.Statistics{}},
+	{Method: http.MethodGet, Path: "/api/restaurants/popular", Tag: "Restaurants", Summary: "Most booked restaurants", List: true, Response: model.Restaurant{}},
+	{Method: http.MethodGet, Path: "/api/restaurants/services", Tag: "Restaurants", Summary: "List restaurant services", Response: []model.Service{}},
+	{Method: http.MethodGet, Path: "/api/restaurants/:id", Tag: "Restaurants", Summary: "Get a restaurant", Response: model.Restaurant{}, ETag: true},
+	{Method: http.MethodPatch, Path: "/api/restaurants/:id", Tag: "Restaurants", Summary: "Patch a restaurant", Description: "JSON merge patch of name, address, description, city, latitude, longitude, status, phone, modeFrom and modeTo; admins may also set ownerId.", Auth: true, Header: ifMatch, Body: mergePatch, BodyType: mergePatchType, Response: model.Restaurant{}, ETag: true},
+	{Method: http.MethodGet, Path: "/api/restaurants/:id/orders", Tag: "Restaurants", Summary: "Orders of an owned restaurant", Auth: true, Query: orderQuery, List: true, Response: model.OrderResponse{}},
+
+	{Method: http.MethodGet, Path: "/api/restaurants/:id/reviews", Tag: "Reviews", Summary: "List reviews", Query: pageQuery, List: true, Response: model.RestaurantReview{}},
+	{Method: http.MethodPost, Path: "/api/restaurants/:id/reviews", Tag: "Reviews", Summary: "Review a restaurant", Auth: true, Body: model.RestaurantReview{}, Status: http.StatusCreated, Response: model.RestaurantReview{}},
+	{Method: http.MethodDelete, Path: "/api/restaurants/:id/reviews/:review_id", Tag: "Reviews", Summary: "Delete own review", Auth: true},
+
+	{Method: http.MethodGet, Path: "/api/restaurants/:id/tables", Tag: "Tables", Summary: "List tables", Query: tableQuery, List: true, Response: model.Table{}},
+	{Method: http.MethodGet, Path: "/api/restaurants/:id/tables/categories", Tag: "Tables", Summary: "Table categories", Response: []string{}},
+	{Method: http.MethodGet, Path: "/api/restaurants/:id/tables/:table_id", Tag: "Tables", Summary: "Get a table", Response: model.Table{}, ETag: true},
+	{Method: http.MethodPost, Path: "/api/restaurants/:id/tables", Tag: "Tables", Summary: "Create a table", Auth: true, Body: model.Table{}, Status: http.StatusCreated, Response: model.Table{}},
+	{Method: http.MethodPut, Path: "/api/restaurants/:id/tables/:table_id", Tag: "Tables", Summary: "Update a table", Auth: true, Header: ifMatch, Body: model.Table{}, Response: model.Table{}, ETag: true},
+	{Method: http.MethodPatch, Path: "/api/restaurants/:id/tables/:table_id", Tag: "Tables", Summary: "Patch a table", Description: "JSON merge patch of name, type, description and capacity.", Auth: true, Header: ifMatch, Body: mergePatch, BodyType: mergePatchType, Response: model.Table{}, ETag: true},
+	{Method: http.MethodDelete, Path: "/api/restaurants/:id/tables/:table_id", Tag: "Tables", Summary: "Delete a table", Auth: true},
+
+	{Method: http.MethodGet, Path: "/api/restaurants/:id/menu", Tag: "Menu", Summary: "List menu items", Query: menuQuery, List: true, Response: model.Food{}},
+	{Method: http.MethodGet, Path: "/api/restaurants/:id/menu/categories", Tag: "Menu", Summary: "Menu categories", Response: []string{}},
+	{Method: http.MethodGet, Path: "/api/restaurants/:id/menu/:food_id", Tag: "Menu", Summary: "Get a menu item", Response: model.Food{}, ETag: true},
+	{Method: http.MethodPost, Path: "/api/restaurants/:id/menu", Tag: "Menu", Summary: "Create a menu item", Auth: true, Body: model.Food{}, Status: http.StatusCreated, Response: model.Food{}},
+	{Method: http.MethodPut, Path: "/api/restaurants/:id/menu/:food_id", Tag: "Menu", Summary: "Update a menu item", Auth: true, Header: ifMatch, Body: model.Food{}, Response: model.Food{}, ETag: true},
+	{Method: http.MethodPatch, Path: "/api/restaurants/:id/menu/:food_id", Tag: "Menu", Summary: "Patch a menu item", Description: "JSON merge patch of name, type, description, price, available and the dietary flags vegetarian, vegan, halal and glutenFree.", Auth: true, Header: ifMatch, Body: mergePatch, BodyType: mergePatchType, Response: model.Food{}, ETag: true},
+	{Method: http.MethodDelete, Path: "/api/restaurants/:id/menu/:food_id", Tag: "Menu", Summary: "Delete a menu item", Auth: true},
+}
+
+func BuildOpenAPI() *openapi.Document {
+	b := openapi.NewBuilder(openapi.Info{
+		Title:       "Orynal",
+		Description: "Restaurant table booking API",
+		Version:     "1.0.0",
+	}, response.CustomResponse{}, model.ListResponse{})
+	b.AddServer("/")
+
+	b.AddParameter("q", &openapi.Parameter{Name: "q", In: "query", Description: "Full text search", Schema: &openapi.Schema{Type: "string"}})
+	b.AddParameter("search_q", &openapi.Parameter{Name: "q", In: "query", Required: true, Description: "Search terms in Russian, Kazakh or English", Schema: &openapi.Schema{Type: "string"}})
+	b.AddParameter("min_price", &openapi.Parameter{Name: "min_price", In: "query", Schema: &openapi.Schema{Type: "number"}})
+	b.AddParameter("max_price", &openapi.Parameter{Name: "max_price", In: "query", Schema: &openapi.Schema{Type: "number"}})
+	b.AddParameter("category", &openapi.Parameter{Name: "category", In: "query", Description: "Menu category, the type of a dish", Schema: &openapi.Schema{Type: "string"}})
+	b.AddParameter("dietary", &openapi.Parameter{Name: "dietary", In: "query", Description: "Comma separated flags a dish must have: vegetarian, vegan, halal, glutenFree", Schema: &openapi.Schema{Type: "string"}})
+	b.AddParameter("city", &openapi.Parameter{Name: "city", In: "query", Schema: &openapi.Schema{Type: "string"}})
+	b.AddParameter("lat", &openapi.Parameter{Name: "lat", In: "query", Description: "Latitude to measure distance_m from, with lng", Schema: &openapi.Schema{Type: "number"}})
+	b.AddParameter("lng", &openapi.Parameter{Name: "lng", In: "query", Description: "Longitude to measure distance_m from, with lat", Schema: &openapi.Schema{Type: "number"}})
+	b.AddParameter("filter", &openapi.Parameter{Name: "filter", In: "query", Description: `JSON object of fields to a value or to operators among eq, in, gte, lte and like, e.g. {"status":"reserved","totalSum":{"gte":1000}}`, Schema: &openapi.Schema{Type: "string"}})
+	b.AddParameter("order", &openapi.Parameter{Name: "order", In: "query", Description: `JSON array of fields to sort by, descending with a "-" prefix, e.g. ["-date","name"]`, Schema: &openapi.Schema{Type: "string"}})
+	b.AddParameter("order_vector", &openapi.Parameter{Name: "order_vector", In: "query", Description: "Direction of the last order field", Schema: &openapi.Schema{Type: "string", Enum: []string{"asc", "desc"}}})
+	b.AddParameter("limit", &openapi.Parameter{Name: "limit", In: "query", Description: "Items per page, 20 by default and at most 100", Schema: &openapi.Schema{Type: "integer"}})
+	b.AddParameter("page", &openapi.Parameter{Name: "page", In: "query", Description: "Page index", Schema: &openapi.Schema{Type: "integer"}})
+	b.AddParameter("after", &openapi.Parameter{Name: "after", In: "query", Description: "next_cursor of the previous page; excludes page and order", Schema: &openapi.Schema{Type: "string"}})
+	b.AddParameter("before", &openapi.Parameter{Name: "before", In: "query", Description: "prev_cursor of the next page; excludes page and order", Schema: &openapi.Schema{Type: "string"}})
+	b.AddParameter("near", &openapi.Parameter{Name: "near", In: "query", Description: "lat,lng to list restaurants within radius of, nearest first with distance_m; excludes order and cursors", Schema: &openapi.Schema{Type: "string"}})
+	b.AddParameter("radius", &openapi.Parameter{Name: "radius", In: "query", Description: "Meters around near, 5000 by default and at most 50000", Schema: &openapi.Schema{Type: "number"}})
+	b.AddParameter("date", &openapi.Parameter{Name: "date", In: "query", Description: "Layout 2006-01-02T15:04:05", Schema: &openapi.Schema{Type: "string"}})
+	b.AddParameter("expires", &openapi.Parameter{Name: "expires", In: "query", Required: true, Description: "Unix time the link expires at", Schema: &openapi.Schema{Type: "integer", Format: "int64"}})
+	b.AddParameter("signature", &openapi.Parameter{Name: "signature", In: "query", Required: true, Schema: &openapi.Schema{Type: "string"}})
+	b.AddParameter("if_match", &openapi.Parameter{Name: "If-Match", In: "header", Required: true, Description: `ETag of the version being updated, e.g. "3", or * to overwrite any version`, Schema: &openapi.Schema{Type: "string"}})
+
+	b.AddResponse("Error", &openapi.Response{
+		Description: "Error",
+		Content: map[string]*openapi.MediaType{"application/json": {Schema: &openapi.Schema{AllOf: []*openapi.Schema{
+			b.Schema(response.CustomResponse{}),
+			{Type: "object", Properties: map[string]*openapi.Schema{"data": b.Schema(errorData{})}},
+		}}}},
+	})
+	b.Schema(validator.FieldError{})
+
+	for _, tag := range []string{"Auth", "Profile", "Admin", "Orders", "Restaurants", "Reviews", "Tables", "Menu", "Search", "Docs", "Ops"} {
+		b.AddTag(tag, "")
+	}
+	for _, route := range apiRoutes {
+		b.AddRoute(route)
+	}
+
+	return b.Document()
+}
+
+func (s *Server) setupDocsRoutes(g *echo.Group) {
+	spec, err := json.Marshal(BuildOpenAPI())
+	if err != nil {
+		s.logger.Fatalf("failed to marshal openapi document: %v", err)
+	}
+
+	g.GET("/openapi.json", func(c echo.Context) error {
+		return c.JSONBlob(http.StatusOK, spec)
+	})
+	g.GET("/docs", func(c echo.Context) error {
+		return c.HTML(http.StatusOK, swaggerUI)
+	})
+}
+
+const swaggerUI = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>Orynal API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>
+    window.onload = () => {
+      window.ui = SwaggerUIBundle({url: "/api/openapi.json", dom_id: "#swagger-ui"});
+    };
+  </script>
+</body>
+</html>`
@@ -0,0 +1,89 @@
+package controller
+
+import (
+	"encoding/json"
+	"github.com/alibekabdrakhman1/orynal/config"
+	handler "github.com/alibekabdrakhman1/orynal/internal/controller/http/handler"
+	"github.com/alibekabdrakhman1/orynal/pkg/health"
+	"github.com/alibekabdrakhman1/orynal/pkg/openapi"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newTestServer(t *testing.T) *Server {
+	t.Helper()
+
+	s := NewServer(&config.Config{}, handler.NewManager(nil, zap.NewNop().Sugar()), nil, health.New(time.Second), zap.NewNop().Sugar())
+	s.App = s.BuildEngine()
+	s.SetupRoutes()
+
+	return s
+}
+
+func TestOpenAPICoversRoutes(t *testing.T) {
+	s := newTestServer(t)
+	doc := BuildOpenAPI()
+
+	registered := map[string]bool{}
+	for _, route := range s.App.Routes() {
+		// Groups with middleware register catch-all 404 routes.
+		if route.Method == echo.RouteNotFound {
+			continue
+		}
+
+		key := route.Method + " " + openapi.PathOf(route.Path)
+		registered[key] = true
+
+		if !doc.HasOperation(route.Method, route.Path) {
+			t.Errorf("route %s %s is not documented in apiRoutes", route.Method, route.Path)
+		}
+	}
+
+	for _, route := range apiRoutes {
+		if !registered[route.Method+" "+openapi.PathOf(route.Path)] {
+			t.Errorf("documented route %s %s is not registered in router.go", route.Method, route.Path)
+		}
+	}
+}
+
+func TestOpenAPIDeclaresTags(t *testing.T) {
+	doc := BuildOpenAPI()
+
+	declared := map[string]bool{}
+	for _, tag := range doc.Tags {
+		declared[tag.Name] = true
+	}
+	for _, route := range apiRoutes {
+		if route.Tag != "" && !declared[route.Tag] {
+			t.Errorf("route %s %s uses undeclared tag %s", route.Method, route.Path, route.Tag)
+		}
+	}
+}
+
+func TestOpenAPIServed(t *testing.T) {
+	s := newTestServer(t)
+
+	rec := httptest.NewRecorder()
+	s.App.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/openapi.json", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /api/openapi.json: status %d", rec.Code)
+	}
+
+	var doc openapi.Document
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("decode openapi document: %v", err)
+	}
+	if doc.OpenAPI != openapi.Version {
+		t.Errorf("openapi version %q, want %q", doc.OpenAPI, openapi.Version)
+	}
+
+	for _, name := range []string{"Restaurant", "CustomResponse", "ListResponse", "FieldError"} {
+		if _, ok := doc.Components.Schemas[name]; !ok {
+			t.Errorf("schema %s is missing", name)
+		}
+	}
+}
@@ -1,16 +1,22 @@
 package controller
 
 import (
+	"github.com/alibekabdrakhman1/orynal/pkg/metrics"
 	"github.com/labstack/echo/v4"
 )
 
 func (s *Server) SetupRoutes() {
+	s.App.GET("/metrics", echo.WrapHandler(metrics.Handler()))
+	s.setupHealthRoutes()
+
 	v1 := s.App.Group("/api")
+	s.setupDocsRoutes(v1)
 	s.setupAuthRoutes(v1)
 	s.setupAdminRoutes(v1)
 	s.setupOrderRoutes(v1)
 	s.setupRestaurantRoutes(v1)
 	s.setupProfileRoutes(v1)
+	s.setupSearchRoutes(v1)
 }
 
 func (s *Server) setupAuthRoutes(g *echo.Group) {
@@ -24,8 +30,12 @@ func (s *Server) setupProfileRoutes(g *echo.Group) {
 	profile := g.Group("/profile", s.jwt.ValidateAuth)
 	profile.GET("", s.handler.User.Profile)
 	profile.PUT("", s.handler.User.UpdateProfile)
+	profile.PATCH("", s.handler.User.PatchProfile)
 	profile.DELETE("", s.handler.User.DeleteProfile)
 	profile.PUT("/change-password", s.handler.User.ChangePassword, s.jwt.ValidateAuth)
+	profile.POST("/export", s.handler.User.RequestExport)
+	profile.GET("/export/:id", s.handler.User.GetExport)
+	g.GET("/profile/export/:id/download", s.handler.User.DownloadExport)
 }
 
 func (s *Server) setupAdminRoutes(g *echo.Group) {
@@ -34,10 +44,12 @@ func (s *Server) setupAdminRoutes(g *echo.Group) {
 	admin.Use(s.jwt.ValidateAdmin)
 	admin.GET("/owners", s.handler.Admin.GetOwners)
 	admin.DELETE("/owners/:id", s.handler.Admin.DeleteOwner)
+	admin.POST("/owners/:id/restore", s.handler.Admin.RestoreOwner)
 	admin.POST("/owners", s.handler.Admin.CreateOwner)
 	s.setupAdminRestaurantRoutes(admin)
 	admin.GET("/clients", s.handler.Admin.GetClients)
 	admin.DELETE("/clients/:id", s.handler.Admin.DeleteClient)
+	admin.POST("/clients/:id/restore", s.handler.Admin.RestoreClient)
 	admin.POST("/services", s.handler.Admin.CreateService)
 	admin.PUT("/services/:id", s.handler.Admin.UpdateService)
 	admin.DELETE("/services/:id", s.handler.Admin.DeleteService)
@@ -54,6 +66,8 @@ func (s *Server) setupAdminRestaurantRoutes(g *echo.Group) {
 	restaurants.GET("/services", s.handler.Restaurant.GetServices)
 	restaurants.PUT("/:id", s.handler.Admin.UpdateRestaurant)
 	restaurants.DELETE("/:id", s.handler.Admin.DeleteRestaurant)
+	restaurants.POST("/:id/restore", s.handler.Admin.RestoreRestaurant)
+	restaurants.POST("/:id/menu/:food_id/restore", s.handler.Admin.RestoreRestaurantFood)
 	restaurants.GET("/:id", s.handler.Admin.GetRestaurant)
 }
 
@@ -63,6 +77,7 @@ func (s *Server) setupOrderRoutes(g *echo.Group) {
 	order.POST("/create", s.handler.Order.CreateOrder)
 	order.DELETE("/:id", s.handler.Order.DeleteOrder)
 	order.PUT("/:id", s.handler.Order.UpdateOrder)
+	order.PATCH("/:id", s.handler.Order.PatchOrder)
 	order.GET("/:id", s.handler.Order.GetOrder)
 	order.GET("", s.handler.Order.GetAllOrders)
 }
@@ -74,6 +89,7 @@ func (s *Server) setupRestaurantRoutes(g *echo.Group) {
 	restaurant.GET("/popular", s.handler.Restaurant.PopularRestaurants)
 	restaurant.GET("/services", s.handler.Restaurant.GetServices)
 	restaurant.GET("/:id", s.handler.Restaurant.GetRestaurantByID)
+	restaurant.PATCH("/:id", s.handler.Restaurant.PatchRestaurant, s.jwt.ValidateAuth)
 	restaurant.GET("/:id/reviews", s.handler.Reviews.GetReviews)
 	s.setupTableRoutes(restaurant)
 	s.setupMenuRoutes(restaurant)
@@ -83,6 +99,11 @@ func (s *Server) setupRestaurantRoutes(g *echo.Group) {
 
 }
 
+func (s *Server) setupSearchRoutes(g *echo.Group) {
+	g.GET("/search", s.handler.Search.SearchRestaurants)
+	g.GET("/foods/search", s.handler.Search.SearchDishes)
+}
+
 func (s *Server) setupMenuRoutes(g *echo.Group) {
 	menu := g.Group("/:id/menu")
 	menu.GET("/categories", s.handler.Menu.GetMenuCategories)
@@ -90,6 +111,7 @@ func (s *Server) setupMenuRoutes(g *echo.Group) {
 	menu.GET("/:food_id", s.handler.Menu.GetRestaurantFood)
 	menu.POST("", s.handler.Menu.CreateRestaurantFood, s.jwt.ValidateAuth, s.jwt.ValidateOwner)
 	menu.PUT("/:food_id", s.handler.Menu.UpdateRestaurantFood, s.jwt.ValidateAuth, s.jwt.ValidateOwner)
+	menu.PATCH("/:food_id", s.handler.Menu.PatchRestaurantFood, s.jwt.ValidateAuth, s.jwt.ValidateOwner)
 	menu.DELETE("/:food_id", s.handler.Menu.DeleteRestaurantFood, s.jwt.ValidateAuth, s.jwt.ValidateOwner)
 }
 
@@ -100,5 +122,6 @@ func (s *Server) setupTableRoutes(g *echo.Group) {
 	tables.GET("/:table_id", s.handler.Table.GetRestaurantTable)
 	tables.POST("", s.handler.Table.CreateRestaurantTable, s.jwt.ValidateAuth, s.jwt.ValidateOwner)
 	tables.PUT("/:table_id", s.handler.Table.UpdateRestaurantTable, s.jwt.ValidateAuth, s.jwt.ValidateOwner)
+	tables.PATCH("/:table_id", s.handler.Table.PatchRestaurantTable, s.jwt.ValidateAuth, s.jwt.ValidateOwner)
 	tables.DELETE("/:table_id", s.handler.Table.DeleteRestaurantTable, s.jwt.ValidateAuth, s.jwt.ValidateOwner)
 }
@@ -7,8 +7,13 @@ import (
 	"github.com/alibekabdrakhman1/orynal/config"
 	http "github.com/alibekabdrakhman1/orynal/internal/controller/http/handler"
 	"github.com/alibekabdrakhman1/orynal/internal/controller/http/middleware"
+	"github.com/alibekabdrakhman1/orynal/pkg/health"
+	"github.com/alibekabdrakhman1/orynal/pkg/metrics"
+	"github.com/alibekabdrakhman1/orynal/pkg/tracing"
+	"github.com/alibekabdrakhman1/orynal/pkg/validator"
 	"github.com/labstack/echo/v4"
 	middleware2 "github.com/labstack/echo/v4/middleware"
+	"go.uber.org/zap"
 	"log"
 	http2 "net/http"
 
@@ -20,13 +25,17 @@ type Server struct {
 	handler *http.Manager
 	App     *echo.Echo
 	jwt     *middleware.JWTAuth
+	health  *health.Health
+	logger  *zap.SugaredLogger
 }
 
-func NewServer(cfg *config.Config, handler *http.Manager, jwt *middleware.JWTAuth) *Server {
+func NewServer(cfg *config.Config, handler *http.Manager, jwt *middleware.JWTAuth, health *health.Health, logger *zap.SugaredLogger) *Server {
 	return &Server{
 		cfg:     cfg,
 		handler: handler,
 		jwt:     jwt,
+		health:  health,
+		logger:  logger,
 	}
 }
 
@@ -40,6 +49,11 @@ func (s *Server) StartHTTPServer(ctx context.Context) error {
 	}()
 	<-ctx.Done()
 
+	// Fail readiness first so the orchestrator stops routing new traffic here
+	// before the listener closes.
+	s.health.Drain()
+	time.Sleep(s.cfg.HttpServer.DrainDelay)
+
 	ctxShutDown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer func() {
 		cancel()
@@ -53,9 +67,15 @@ func (s *Server) StartHTTPServer(ctx context.Context) error {
 
 func (s *Server) BuildEngine() *echo.Echo {
 	e := echo.New()
+	e.Validator = validator.New()
+	e.HTTPErrorHandler = s.HTTPErrorHandler
+	e.Use(middleware.NewRequestLogger(s.logger).Handle)
+	e.Use(tracing.Middleware())
+	e.Use(metrics.Middleware())
 	e.Use(middleware2.CORSWithConfig(middleware2.CORSConfig{
-		AllowOrigins: []string{"*"},
-		AllowHeaders: []string{"*"},
+		AllowOrigins:  []string{"*"},
+		AllowHeaders:  []string{"*"},
+		ExposeHeaders: []string{"ETag"},
 	}))
 
 	return e
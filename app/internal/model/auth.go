@@ -6,16 +6,16 @@ import (
 )
 
 type Login struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required"`
 }
 
 type Register struct {
-	Name     string `json:"name"`
-	Surname  string `json:"surname"`
-	Email    string `json:"email"`
-	Phone    string `json:"phone"`
-	Password string `json:"password"`
+	Name     string `json:"name" validate:"required,max=255"`
+	Surname  string `json:"surname" validate:"required,max=255"`
+	Email    string `json:"email" validate:"required,email,max=255"`
+	Phone    string `json:"phone" validate:"required,phone"`
+	Password string `json:"password" validate:"required,min=8,max=72"`
 }
 
 type JwtTokens struct {
@@ -0,0 +1,83 @@
+package model
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"slices"
+)
+
+// Cursor positions a keyset page next to the row with ID: the rows after it
+// or, with Before, the rows before it, in id order.
+type Cursor struct {
+	ID     uint
+	Before bool
+}
+
+type cursorToken struct {
+	ID uint `json:"id"`
+}
+
+// EncodeCursor returns the opaque cursor clients pass back as after= or
+// before= to page from the row with id.
+func EncodeCursor(id uint) string {
+	token, _ := json.Marshal(cursorToken{ID: id})
+	return base64.RawURLEncoding.EncodeToString(token)
+}
+
+func DecodeCursor(cursor string) (uint, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, err
+	}
+
+	var token cursorToken
+	if err := json.Unmarshal(raw, &token); err != nil {
+		return 0, err
+	}
+	if token.ID == 0 {
+		return 0, errors.New("cursor has no id")
+	}
+
+	return token.ID, nil
+}
+
+// KeysetPage trims rows, read with one row past params.Limit in id order,
+// descending for a before cursor, to the page and returns it in ascending
+// order with the cursors of the pages around it. Pages sorted by other
+// columns have no cursors.
+func KeysetPage[T any](rows []T, params *Params, id func(T) uint) (page []T, next, prev string) {
+	more := len(rows) > params.Limit
+	if more {
+		rows = rows[:params.Limit]
+	}
+	if params.Cursor != nil && params.Cursor.Before {
+		slices.Reverse(rows)
+	}
+	if len(rows) == 0 || params.Sorted() {
+		return rows, "", ""
+	}
+
+	first, last := EncodeCursor(id(rows[0])), EncodeCursor(id(rows[len(rows)-1]))
+	switch {
+	case params.Cursor == nil:
+		if more {
+			next = last
+		}
+		if params.Offset > 0 {
+			prev = first
+		}
+	case params.Cursor.Before:
+		next = last
+		if more {
+			prev = first
+		}
+	default:
+		if more {
+			next = last
+		}
+		prev = first
+	}
+
+	return rows, next, prev
+}
@@ -0,0 +1,212 @@
+package model
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"strings"
+	"time"
+)
+
+// DishSearch holds the criteria of a dish search across restaurants. Its
+// Params page the restaurants the dishes are grouped by; their Near only
+// measures distance, with no radius.
+type DishSearch struct {
+	Params
+	MinPrice *float64
+	MaxPrice *float64
+	Category string
+	// Diets are foods columns from DietColumns a dish must have set.
+	Diets []string
+	City  string
+}
+
+func NewDishSearch() *DishSearch {
+	return &DishSearch{}
+}
+
+// DietColumns maps the dietary flags accepted by a dish search to their
+// foods columns.
+var DietColumns = map[string]string{
+	"vegetarian": "vegetarian",
+	"vegan":      "vegan",
+	"halal":      "halal",
+	"glutenFree": "gluten_free",
+}
+
+// DishesPerGroup caps the dishes returned for one restaurant; TotalDishes
+// counts them all.
+const DishesPerGroup = 10
+
+type Point struct {
+	Lat float64
+	Lng float64
+}
+
+// RestaurantSummary is the part of a restaurant shown next to its dishes.
+type RestaurantSummary struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	Address   string    `json:"address"`
+	City      string    `json:"city"`
+	Phone     string    `json:"phone"`
+	Status    bool      `json:"status"`
+	ModeFrom  time.Time `json:"modeFrom"`
+	ModeTo    time.Time `json:"modeTo"`
+	Icon      Photo     `json:"icon,omitempty"`
+	OpenNow   bool      `json:"openNow"`
+	DistanceM *float64  `json:"distance_m,omitempty"`
+}
+
+type DishGroup struct {
+	Restaurant  RestaurantSummary `json:"restaurant"`
+	Dishes      []Food            `json:"dishes"`
+	TotalDishes int               `json:"totalDishes"`
+}
+
+func (r Restaurant) Summary() RestaurantSummary {
+	return RestaurantSummary{
+		ID:       r.ID,
+		Name:     r.Name,
+		Address:  r.Address,
+		City:     r.City,
+		Phone:    r.Phone,
+		Status:   r.Status,
+		ModeFrom: r.ModeFrom,
+		ModeTo:   r.ModeTo,
+		Icon:     r.Icon,
+	}
+}
+
+// Location is the point of a restaurant, nil until it has coordinates.
+func (r Restaurant) Location() *Point {
+	if r.Latitude == nil || r.Longitude == nil {
+		return nil
+	}
+	return &Point{Lat: *r.Latitude, Lng: *r.Longitude}
+}
+
+// OpenAt reports whether the restaurant takes guests at t. Opening hours
+// are wall clock times, compared with the clock of t; hours ending before
+// they start run past midnight.
+func (s RestaurantSummary) OpenAt(t time.Time) bool {
+	if !s.Status {
+		return false
+	}
+
+	minutes := func(t time.Time) int { return t.Hour()*60 + t.Minute() }
+	now, from, to := minutes(t), minutes(s.ModeFrom), minutes(s.ModeTo)
+
+	switch {
+	case from == to:
+		return true
+	case from < to:
+		return from <= now && now < to
+	default:
+		return now >= from || now < to
+	}
+}
+
+// EarthRadiusM is the mean radius of the Earth in meters.
+const EarthRadiusM = 6371008.8
+
+// Distance is the great-circle distance between a and b in meters.
+func Distance(a, b Point) float64 {
+	radians := func(deg float64) float64 { return deg * math.Pi / 180 }
+	dLat, dLng := radians(b.Lat-a.Lat), radians(b.Lng-a.Lng)
+	h := math.Pow(math.Sin(dLat/2), 2) + math.Cos(radians(a.Lat))*math.Cos(radians(b.Lat))*math.Pow(math.Sin(dLng/2), 2)
+	return 2 * EarthRadiusM * math.Asin(math.Sqrt(h))
+}
+
+// MatchesText reports whether the query is a case-insensitive substring of
+// the name or description of food, for backends without full-text search.
+func (s *DishSearch) MatchesText(food Food) bool {
+	q := strings.ToLower(strings.TrimSpace(s.Query))
+	return q == "" || strings.Contains(strings.ToLower(food.Name), q) || strings.Contains(strings.ToLower(food.Description), q)
+}
+
+// Matches reports whether food, served by restaurant, meets every criterion
+// of the search. Unavailable dishes never match.
+func (s *DishSearch) Matches(food Food, restaurant Restaurant) bool {
+	switch {
+	case !food.Available,
+		s.MinPrice != nil && food.Price < *s.MinPrice,
+		s.MaxPrice != nil && food.Price > *s.MaxPrice,
+		s.Category != "" && !strings.EqualFold(food.Type, s.Category),
+		s.City != "" && !strings.EqualFold(restaurant.City, s.City):
+		return false
+	}
+
+	diets := map[string]bool{"vegetarian": food.Vegetarian, "vegan": food.Vegan, "halal": food.Halal, "gluten_free": food.GlutenFree}
+	for _, column := range s.Diets {
+		if !diets[column] {
+			return false
+		}
+	}
+
+	return s.MatchesText(food)
+}
+
+// GroupDishes groups the dishes matching s by restaurant and returns the
+// page of groups: nearest first when s.Near is set, then those with the
+// most matching dishes. dishes must be ordered by id.
+func GroupDishes(dishes []Food, restaurants map[uint]Restaurant, s *DishSearch) *ListResponse {
+	index := map[uint]int{}
+	groups := []DishGroup{}
+	for _, food := range dishes {
+		restaurant, ok := restaurants[food.RestaurantID]
+		if !ok || !s.Matches(food, restaurant) {
+			continue
+		}
+
+		i, ok := index[restaurant.ID]
+		if !ok {
+			i = len(groups)
+			index[restaurant.ID] = i
+			summary := restaurant.Summary()
+			if location := restaurant.Location(); s.Near != nil && location != nil {
+				distance := Distance(*s.Near, *location)
+				summary.DistanceM = &distance
+			}
+			groups = append(groups, DishGroup{Restaurant: summary})
+		}
+		groups[i].Dishes = append(groups[i].Dishes, food)
+		groups[i].TotalDishes++
+	}
+
+	slices.SortFunc(groups, func(a, b DishGroup) int {
+		return cmp.Or(
+			compareDistance(a.Restaurant.DistanceM, b.Restaurant.DistanceM),
+			cmp.Compare(b.TotalDishes, a.TotalDishes),
+			cmp.Compare(a.Restaurant.ID, b.Restaurant.ID),
+		)
+	})
+
+	total := len(groups)
+	groups = groups[min(s.Offset, total):]
+	groups = groups[:min(s.Limit, len(groups))]
+	for i := range groups {
+		groups[i].Dishes = groups[i].Dishes[:min(DishesPerGroup, len(groups[i].Dishes))]
+	}
+
+	return &ListResponse{
+		Items:        groups,
+		ItemsPerPage: s.Limit,
+		PageIndex:    s.PageIndex,
+		TotalItems:   total,
+	}
+}
+
+// compareDistance orders restaurants without coordinates last.
+func compareDistance(a, b *float64) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	default:
+		return cmp.Compare(*a, *b)
+	}
+}
@@ -0,0 +1,31 @@
+package model
+
+import "time"
+
+type DataExport struct {
+	ID          uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID      uint       `gorm:"not null" json:"user_id"`
+	Status      string     `gorm:"not null" json:"status"`
+	FilePath    string     `json:"-"`
+	Error       string     `json:"error,omitempty"`
+	CreatedAt   time.Time  `gorm:"default:current_timestamp" json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	DownloadURL string     `gorm:"-" json:"download_url,omitempty"`
+}
+
+type Session struct {
+	Role      string    `json:"role"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type UserDataExport struct {
+	GeneratedAt time.Time          `json:"generated_at"`
+	Profile     *UserResponse      `json:"profile"`
+	Orders      []OrderResponse    `json:"orders"`
+	Reviews     []RestaurantReview `json:"reviews"`
+	Favorites   []Restaurant       `json:"favorites"`
+	Sessions    []Session          `json:"sessions"`
+}
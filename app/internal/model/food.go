@@ -1,13 +1,21 @@
 package model
 
+import "gorm.io/gorm"
+
 type Food struct {
-	ID           uint    `gorm:"primaryKey;autoIncrement" json:"id"`
-	Name         string  `gorm:"not null" json:"name"`
-	Type         string  `gorm:"not null" json:"type"`
-	Description  string  `json:"description"`
-	Price        float64 `gorm:"not null" json:"price"`
-	Available    bool    `gorm:"not null" json:"available"`
-	PhotoID      uint    `json:"photo_id,omitempty"`
-	Photo        Photo   `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;" json:"photo,omitempty"`
-	RestaurantID uint    `gorm:"not null" json:"restaurantId"`
+	ID           uint           `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name         string         `gorm:"not null" json:"name" validate:"required,max=255"`
+	Type         string         `gorm:"not null" json:"type" validate:"required,max=100"`
+	Description  string         `json:"description" validate:"max=2000"`
+	Price        float64        `gorm:"not null" json:"price" validate:"gt=0"`
+	Available    bool           `gorm:"not null" json:"available"`
+	Vegetarian   bool           `gorm:"not null;default:false" json:"vegetarian"`
+	Vegan        bool           `gorm:"not null;default:false" json:"vegan"`
+	Halal        bool           `gorm:"not null;default:false" json:"halal"`
+	GlutenFree   bool           `gorm:"not null;default:false" json:"glutenFree"`
+	PhotoID      uint           `json:"photo_id,omitempty"`
+	Photo        Photo          `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;" json:"photo,omitempty"`
+	RestaurantID uint           `gorm:"not null" json:"restaurantId"`
+	Version      uint           `gorm:"not null;default:1" json:"version"`
+	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
 }
@@ -0,0 +1,106 @@
+package model
+
+// FilterOp compares a field with the value of a filter.
+type FilterOp string
+
+const (
+	OpEq   FilterOp = "eq"
+	OpIn   FilterOp = "in"
+	OpGte  FilterOp = "gte"
+	OpLte  FilterOp = "lte"
+	OpLike FilterOp = "like"
+)
+
+// FieldType is the type filter values of a field are parsed as.
+type FieldType int
+
+const (
+	StringField FieldType = iota
+	IntField
+	NumberField
+	BoolField
+	TimeField
+)
+
+// Field describes a column clients may filter or sort a list by.
+type Field struct {
+	Column   string
+	Type     FieldType
+	Ops      []FilterOp
+	Sortable bool
+}
+
+// ListSpec maps the json names of the fields of a resource to their columns.
+type ListSpec map[string]Field
+
+// Filter keeps the rows whose Column compares to Value by Op. Value has the
+// Go type of the field: string, int64, float64, bool or time.Time, and is a
+// slice of those for OpIn.
+type Filter struct {
+	Column string
+	Op     FilterOp
+	Value  any
+}
+
+// Sort orders rows by Column.
+type Sort struct {
+	Column string
+	Desc   bool
+}
+
+var (
+	textOps   = []FilterOp{OpEq, OpIn, OpLike}
+	numberOps = []FilterOp{OpEq, OpIn, OpGte, OpLte}
+	boolOps   = []FilterOp{OpEq}
+	timeOps   = []FilterOp{OpGte, OpLte}
+)
+
+var RestaurantsListSpec = ListSpec{
+	"id":      {Column: "id", Type: IntField, Ops: numberOps, Sortable: true},
+	"name":    {Column: "name", Type: StringField, Ops: textOps, Sortable: true},
+	"city":    {Column: "city", Type: StringField, Ops: textOps, Sortable: true},
+	"status":  {Column: "status", Type: BoolField, Ops: boolOps},
+	"ownerId": {Column: "owner_id", Type: IntField, Ops: numberOps},
+}
+
+var ReviewsListSpec = ListSpec{
+	"id":    {Column: "id", Type: IntField, Ops: numberOps, Sortable: true},
+	"stars": {Column: "stars", Type: IntField, Ops: numberOps, Sortable: true},
+	"date":  {Column: "date", Type: TimeField, Ops: timeOps, Sortable: true},
+}
+
+var TablesListSpec = ListSpec{
+	"id":       {Column: "id", Type: IntField, Ops: numberOps, Sortable: true},
+	"name":     {Column: "name", Type: StringField, Ops: textOps, Sortable: true},
+	"type":     {Column: "type", Type: StringField, Ops: textOps, Sortable: true},
+	"capacity": {Column: "capacity", Type: IntField, Ops: numberOps, Sortable: true},
+}
+
+var UsersListSpec = ListSpec{
+	"id":      {Column: "id", Type: IntField, Ops: numberOps, Sortable: true},
+	"name":    {Column: "name", Type: StringField, Ops: textOps, Sortable: true},
+	"surname": {Column: "surname", Type: StringField, Ops: textOps, Sortable: true},
+	"email":   {Column: "email", Type: StringField, Ops: textOps, Sortable: true},
+	"phone":   {Column: "phone", Type: StringField, Ops: textOps},
+}
+
+var OrdersListSpec = ListSpec{
+	"id":           {Column: "id", Type: IntField, Ops: numberOps, Sortable: true},
+	"date":         {Column: "date", Type: TimeField, Ops: timeOps, Sortable: true},
+	"status":       {Column: "status", Type: StringField, Ops: []FilterOp{OpEq, OpIn}, Sortable: true},
+	"totalSum":     {Column: "total_sum", Type: NumberField, Ops: numberOps, Sortable: true},
+	"restaurantId": {Column: "restaurant_id", Type: IntField, Ops: numberOps},
+	"tableId":      {Column: "table_id", Type: IntField, Ops: numberOps},
+}
+
+var MenuListSpec = ListSpec{
+	"id":         {Column: "id", Type: IntField, Ops: numberOps, Sortable: true},
+	"name":       {Column: "name", Type: StringField, Ops: textOps, Sortable: true},
+	"type":       {Column: "type", Type: StringField, Ops: textOps, Sortable: true},
+	"price":      {Column: "price", Type: NumberField, Ops: numberOps, Sortable: true},
+	"available":  {Column: "available", Type: BoolField, Ops: boolOps},
+	"vegetarian": {Column: "vegetarian", Type: BoolField, Ops: boolOps},
+	"vegan":      {Column: "vegan", Type: BoolField, Ops: boolOps},
+	"halal":      {Column: "halal", Type: BoolField, Ops: boolOps},
+	"glutenFree": {Column: "gluten_free", Type: BoolField, Ops: boolOps},
+}
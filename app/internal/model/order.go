@@ -5,12 +5,13 @@ import "time"
 type Order struct {
 	ID           uint      `gorm:"primaryKey;autoIncrement" json:"id"`
 	RestaurantID uint      `gorm:"not null" json:"restaurantId"`
-	TotalSum     float64   `json:"totalSum"`
+	TotalSum     float64   `json:"totalSum" validate:"gte=0"`
 	UserID       uint      `json:"userId"`
 	TableID      uint      `json:"tableId"`
 	Date         time.Time `gorm:"not null" json:"date"`
-	Status       string    `gorm:"not null" json:"status"`
-	OrderFoods   []uint    `json:"foods"`
+	Status       string    `gorm:"not null" json:"status" validate:"omitempty,oneof=reserved canceled completed"`
+	OrderFoods   []uint    `gorm:"-" json:"foods" validate:"dive,gt=0"`
+	Version      uint      `gorm:"not null;default:1" json:"version"`
 }
 
 func (Order) TableName() string {
@@ -18,12 +19,12 @@ func (Order) TableName() string {
 }
 
 type OrderRequest struct {
-	RestaurantID uint      `gorm:"not null" json:"restaurantId"`
-	TotalSum     float64   `json:"totalSum"`
-	TableID      uint      `json:"tableId"`
-	Date         time.Time `gorm:"not null" json:"date"`
-	Status       string    `gorm:"not null" json:"status"`
-	OrderFoods   []uint    `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"foods"`
+	RestaurantID uint      `gorm:"not null" json:"restaurantId" validate:"required"`
+	TotalSum     float64   `json:"totalSum" validate:"gte=0"`
+	TableID      uint      `json:"tableId" validate:"required"`
+	Date         time.Time `gorm:"not null" json:"date" validate:"required"`
+	Status       string    `gorm:"not null" json:"status" validate:"omitempty,oneof=reserved canceled completed"`
+	OrderFoods   []uint    `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"foods" validate:"dive,gt=0"`
 }
 
 type OrderFood struct {
@@ -48,6 +49,7 @@ type OrderResponse struct {
 	Table        Table               `gorm:"foreignKey:TableID" json:"table"`
 	UserID       uint                `json:"userId"`
 	User         UserResponse        `gorm:"foreignKey:UserID" json:"user"`
-	OrderFoods   []OrderFoodResponse `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"order_foods"`
-	Foods        []Food              `json:"foods"`
+	OrderFoods   []OrderFoodResponse `gorm:"-" json:"order_foods"`
+	Foods        []Food              `gorm:"-" json:"foods"`
+	Version      uint                `json:"version"`
 }
@@ -3,32 +3,37 @@ package model
 import "time"
 
 type Params struct {
-	Filter     map[string]interface{}
-	Query      string
-	Order      interface{}
-	SortVector interface{}
-	Date       *time.Time
-	Offset     int
-	Limit      int
-	PageIndex  int
+	Filters   []Filter
+	Query     string
+	Sort      []Sort
+	Date      *time.Time
+	Offset    int
+	Limit     int
+	PageIndex int
+	// Cursor selects keyset pagination instead of Offset.
+	Cursor *Cursor
+	// Near orders rows by their distance from it, keeping those within
+	// Radius meters.
+	Near   *Point
+	Radius float64
 }
 
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+
+	DefaultRadius = 5000
+	MaxRadius     = 50000
+)
+
 func NewParams() *Params {
 	return &Params{}
 }
 
-var TablesOrderKeyList = []string{
-	"id",
-	"name",
-	"type",
-	"capacity",
-}
-
-var UsersOrderKeyList = []string{
-	"id",
-	"name",
-	"surname",
-	"email",
+// Sorted reports whether rows are ordered by the Sort columns or by distance
+// rather than by id.
+func (p *Params) Sorted() bool {
+	return len(p.Sort) > 0 || p.Near != nil
 }
 
 type ListResponse struct {
@@ -37,4 +42,6 @@ type ListResponse struct {
 	PageIndex    int         `json:"pageIndex"`
 	TotalPages   int         `json:"totalPages"`
 	TotalItems   int         `json:"totalItems"`
+	NextCursor   string      `json:"next_cursor,omitempty"`
+	PrevCursor   string      `json:"prev_cursor,omitempty"`
 }
@@ -1,30 +1,38 @@
 package model
 
-import "time"
+import (
+	"gorm.io/gorm"
+	"time"
+)
 
 type Restaurant struct {
-	ID          uint         `gorm:"primaryKey;autoIncrement" json:"id"`
-	Name        string       `gorm:"size:255;not null" json:"name"`
-	Address     string       `gorm:"size:255;not null" json:"address"`
-	Description string       `json:"description"`
-	City        string       `json:"city"`
-	Status      bool         `json:"status"`
-	Phone       string       `gorm:"not null" json:"phone"`
-	OwnerID     uint         `gorm:"not null" json:"ownerId"`
-	Owner       UserResponse `gorm:"foreignKey:OwnerID;references:ID" json:"owner"`
-	ModeFrom    time.Time    `gorm:"not null" json:"modeFrom"`
-	ModeTo      time.Time    `gorm:"not null" json:"modeTo"`
-	IconID      uint         `gorm:"not null" json:"icon_id,omitempty"`
-	Icon        Photo        `gorm:"foreignKey:IconID;references:ID" json:"icon,omitempty"`
-	Services    []Service    `gorm:"many2many:restaurant_services;" json:"services"`
-	Photos      []Photo      `gorm:"many2many:restaurant_photos;" json:"photos,omitempty"`
-	Orders      []Order      `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
-	Tables      []Table      `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+	ID          uint           `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name        string         `gorm:"size:255;not null" json:"name" validate:"required,max=255"`
+	Address     string         `gorm:"size:255;not null" json:"address" validate:"required,max=255"`
+	Description string         `json:"description" validate:"max=2000"`
+	City        string         `json:"city" validate:"max=100"`
+	Latitude    *float64       `json:"latitude,omitempty" validate:"omitempty,gte=-90,lte=90"`
+	Longitude   *float64       `json:"longitude,omitempty" validate:"omitempty,gte=-180,lte=180"`
+	DistanceM   *float64       `gorm:"->;-:migration" json:"distance_m,omitempty"`
+	Status      bool           `json:"status"`
+	Phone       string         `gorm:"not null" json:"phone" validate:"required,phone"`
+	OwnerID     uint           `gorm:"not null" json:"ownerId"`
+	Owner       UserResponse   `gorm:"foreignKey:OwnerID;references:ID" json:"owner"`
+	ModeFrom    time.Time      `gorm:"not null" json:"modeFrom"`
+	ModeTo      time.Time      `gorm:"not null" json:"modeTo"`
+	IconID      uint           `gorm:"not null" json:"icon_id,omitempty"`
+	Icon        Photo          `gorm:"foreignKey:IconID;references:ID" json:"icon,omitempty"`
+	Services    []Service      `gorm:"many2many:restaurant_services;" json:"services"`
+	Photos      []Photo        `gorm:"many2many:restaurant_photos;" json:"photos,omitempty"`
+	Orders      []Order        `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+	Tables      []Table        `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+	Version     uint           `gorm:"not null;default:1" json:"version"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
 type Service struct {
 	ID   uint   `gorm:"primaryKey;autoIncrement" json:"id"`
-	Name string `gorm:"size:255;not null" json:"name"`
+	Name string `gorm:"size:255;not null" json:"name" validate:"required,max=255"`
 }
 
 type RestaurantService struct {
@@ -4,8 +4,8 @@ import "time"
 
 type RestaurantReview struct {
 	ID           uint         `gorm:"primaryKey;autoIncrement" json:"id"`
-	Stars        int          `gorm:"not null" json:"stars"`
-	Description  string       `json:"description"`
+	Stars        int          `gorm:"not null" json:"stars" validate:"gte=1,lte=5"`
+	Description  string       `json:"description" validate:"max=2000"`
 	UserID       uint         `gorm:"not null" json:"user_id"`
 	RestaurantID uint         `gorm:"not null" json:"restaurant_id"`
 	Date         time.Time    `gorm:"default:CURRENT_TIMESTAMP" json:"date"`
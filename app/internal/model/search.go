@@ -0,0 +1,120 @@
+package model
+
+import (
+	"cmp"
+	"html"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// SearchHit is a restaurant matching a search with the rank it is ordered
+// by and an HTML snippet of the match, with matched words in <mark>. Fuzzy
+// hits matched by trigram similarity after the full-text search found none.
+type SearchHit struct {
+	Restaurant Restaurant `json:"restaurant"`
+	Rank       float64    `json:"rank"`
+	Snippet    string     `json:"snippet"`
+	Fuzzy      bool       `json:"fuzzy,omitempty"`
+}
+
+const snippetContext = 40
+
+type searchField struct {
+	text   string
+	weight float64
+}
+
+// MatchRestaurant ranks a case-insensitive substring match of q in a
+// restaurant and its dishes, for backends without full-text search. Fields
+// are weighted like the Postgres document and the snippet comes from the
+// heaviest one; a rank of 0 means no match.
+func MatchRestaurant(q string, restaurant Restaurant, dishes []Food) (rank float64, snippet string) {
+	fields := []searchField{{restaurant.Name, 1}, {restaurant.City, 0.4}}
+	for _, dish := range dishes {
+		fields = append(fields, searchField{dish.Name, 0.4})
+	}
+	fields = append(fields, searchField{restaurant.Description, 0.2})
+	for _, dish := range dishes {
+		fields = append(fields, searchField{dish.Description, 0.1})
+	}
+
+	for _, field := range fields {
+		if marked, ok := Highlight(field.text, q); ok {
+			if snippet == "" {
+				snippet = marked
+			}
+			rank += field.weight
+		}
+	}
+
+	return rank, snippet
+}
+
+// Highlight escapes text for HTML and marks the first case-insensitive
+// occurrence of q, keeping some context around it.
+func Highlight(text, q string) (string, bool) {
+	runes, query := []rune(text), []rune(strings.TrimSpace(q))
+	if len(query) == 0 {
+		return "", false
+	}
+
+	at := -1
+	for i := 0; i+len(query) <= len(runes); i++ {
+		if equalFold(runes[i:i+len(query)], query) {
+			at = i
+			break
+		}
+	}
+	if at < 0 {
+		return "", false
+	}
+
+	start, end := max(at-snippetContext, 0), min(at+len(query)+snippetContext, len(runes))
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	b.WriteString(html.EscapeString(string(runes[start:at])))
+	b.WriteString("<mark>" + html.EscapeString(string(runes[at:at+len(query)])) + "</mark>")
+	b.WriteString(html.EscapeString(string(runes[at+len(query) : end])))
+	if end < len(runes) {
+		b.WriteString("…")
+	}
+
+	return b.String(), true
+}
+
+func equalFold(a, b []rune) bool {
+	for i := range a {
+		if unicode.ToLower(a[i]) != unicode.ToLower(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// SubstringSearch ranks restaurants with MatchRestaurant against
+// params.Query and returns the page of params, best match first.
+func SubstringSearch(restaurants []Restaurant, dishes map[uint][]Food, params *Params) *ListResponse {
+	hits := []SearchHit{}
+	for _, restaurant := range restaurants {
+		if rank, snippet := MatchRestaurant(params.Query, restaurant, dishes[restaurant.ID]); rank > 0 {
+			hits = append(hits, SearchHit{Restaurant: restaurant, Rank: rank, Snippet: snippet})
+		}
+	}
+	slices.SortStableFunc(hits, func(a, b SearchHit) int {
+		return cmp.Or(cmp.Compare(b.Rank, a.Rank), cmp.Compare(a.Restaurant.ID, b.Restaurant.ID))
+	})
+
+	total := len(hits)
+	hits = hits[min(params.Offset, total):]
+	hits = hits[:min(params.Limit, len(hits))]
+
+	return &ListResponse{
+		Items:        hits,
+		ItemsPerPage: params.Limit,
+		PageIndex:    params.PageIndex,
+		TotalItems:   total,
+	}
+}
@@ -2,11 +2,12 @@ package model
 
 type Table struct {
 	ID           uint   `gorm:"primaryKey;autoIncrement" json:"id"`
-	Name         string `gorm:"not null" json:"name"`
-	Type         string `gorm:"not null" json:"type"`
-	Description  string `json:"description"`
-	Capacity     int    `gorm:"not null" json:"capacity"`
+	Name         string `gorm:"not null" json:"name" validate:"required,max=255"`
+	Type         string `gorm:"not null" json:"type" validate:"required,max=100"`
+	Description  string `json:"description" validate:"max=2000"`
+	Capacity     int    `gorm:"not null" json:"capacity" validate:"gte=1,lte=100"`
 	PhotoID      uint   `json:"photo_id,omitempty"`
 	Photo        Photo  `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;" json:"photo,omitempty"`
 	RestaurantID uint   `gorm:"not null" json:"restaurant_id"`
+	Version      uint   `gorm:"not null;default:1" json:"version"`
 }
@@ -1,27 +1,51 @@
 package model
 
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
 type User struct {
-	ID       uint   `gorm:"primary_key;auto_increment" json:"id"`
-	Name     string `gorm:"not null" json:"name"`
-	Surname  string `gorm:"not null" json:"surname"`
-	Email    string `gorm:"unique;not null" json:"email"`
-	Phone    string `gorm:"unique;not null" json:"phone"`
-	Role     string `gorm:"not null" json:"role"`
-	Password string `gorm:"not null" json:"password"`
+	ID                  uint           `gorm:"primary_key;auto_increment" json:"id"`
+	Name                string         `gorm:"not null" json:"name" validate:"required,max=255"`
+	Surname             string         `gorm:"not null" json:"surname" validate:"required,max=255"`
+	Email               string         `gorm:"unique;not null" json:"email" validate:"required,email,max=255"`
+	Phone               string         `gorm:"unique;not null" json:"phone" validate:"required,phone"`
+	Role                string         `gorm:"not null" json:"role"`
+	Password            string         `gorm:"not null" json:"password" validate:"required,min=8,max=72"`
+	DeletionRequestedAt *time.Time     `json:"-"`
+	AnonymizedAt        *time.Time     `json:"-"`
+	DisabledAt          *time.Time     `json:"-"`
+	DeletedAt           gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
 type UserResponse struct {
-	ID      uint   `gorm:"primary_key;auto_increment" json:"id"`
-	Name    string `gorm:"not null" json:"name"`
-	Surname string `gorm:"not null" json:"surname"`
-	Email   string `gorm:"unique;not null" json:"email"`
-	Phone   string `gorm:"unique;not null" json:"phone"`
-	Role    string `gorm:"not null" json:"role"`
+	ID                  uint       `gorm:"primary_key;auto_increment" json:"id"`
+	Name                string     `gorm:"not null" json:"name"`
+	Surname             string     `gorm:"not null" json:"surname"`
+	Email               string     `gorm:"unique;not null" json:"email"`
+	Phone               string     `gorm:"unique;not null" json:"phone"`
+	Role                string     `gorm:"not null" json:"role"`
+	DeletionRequestedAt *time.Time `json:"deletion_requested_at,omitempty"`
+}
+
+// ProfileRequest is the body of PUT /api/profile: the fields a user may
+// replace on their own account.
+type ProfileRequest struct {
+	Name    string `json:"name" validate:"required,max=255"`
+	Surname string `json:"surname" validate:"required,max=255"`
+	Email   string `json:"email" validate:"required,email,max=255"`
+	Phone   string `json:"phone" validate:"required,phone"`
 }
 
 type ChangePasswordRequest struct {
-	OldPassword string `json:"old_password"`
-	NewPassword string `json:"new_password"`
+	OldPassword string `json:"old_password" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required,min=8,max=72"`
+}
+
+type DeletionResponse struct {
+	DeletionRequestedAt time.Time `json:"deletion_requested_at"`
+	AnonymizeAt         time.Time `json:"anonymize_at"`
 }
 
 func (UserResponse) TableName() string {
@@ -0,0 +1,125 @@
+// Package cached decorates repositories with a read-through cache. Reads of
+// hot entities are served from the cache until their TTL passes, and the
+// write methods of each decorator drop the entries they make stale.
+package cached
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/alibekabdrakhman1/orynal/config"
+	"github.com/alibekabdrakhman1/orynal/internal/model"
+	"github.com/alibekabdrakhman1/orynal/internal/repository"
+	"github.com/alibekabdrakhman1/orynal/pkg/cache"
+	"github.com/alibekabdrakhman1/orynal/pkg/logging"
+	"github.com/alibekabdrakhman1/orynal/pkg/metrics"
+	"go.uber.org/zap"
+	"time"
+)
+
+// Wrap replaces the cacheable repositories of m, and of the transactions it
+// starts, with cached decorators. Inside a transaction reads go to the
+// database and invalidations wait for the commit, so the cache never holds
+// uncommitted rows.
+func Wrap(m *repository.Manager, c cache.Cache, cfg config.Cache, logger *zap.SugaredLogger) {
+	m.Decorate(func(m *repository.Manager) {
+		b := &base{cache: c, logger: logger, repos: m}
+
+		m.Restaurant = newRestaurantRepository(m.Restaurant, b, cfg)
+		m.Food = newFoodRepository(m.Food, b, cfg)
+		m.Services = newServicesRepository(m.Services, b, cfg)
+	})
+}
+
+type base struct {
+	cache  cache.Cache
+	logger *zap.SugaredLogger
+	repos  *repository.Manager
+}
+
+func (b *base) lookup(ctx context.Context, entity, key string) ([]byte, bool) {
+	if b.repos.InTx() {
+		return nil, false
+	}
+
+	raw, ok, err := b.cache.Get(ctx, key)
+	switch {
+	case err != nil:
+		metrics.CacheRequests.WithLabelValues(entity, "error").Inc()
+		logging.FromContext(ctx, b.logger).Warnf("cache get %s: %v", key, err)
+		return nil, false
+	case ok:
+		metrics.CacheRequests.WithLabelValues(entity, "hit").Inc()
+	default:
+		metrics.CacheRequests.WithLabelValues(entity, "miss").Inc()
+	}
+
+	return raw, ok
+}
+
+func (b *base) store(ctx context.Context, key string, value interface{}, ttl time.Duration) {
+	if b.repos.InTx() {
+		return
+	}
+
+	raw, err := json.Marshal(value)
+	if err == nil {
+		err = b.cache.Set(ctx, key, raw, ttl)
+	}
+	if err != nil {
+		logging.FromContext(ctx, b.logger).Warnf("cache set %s: %v", key, err)
+	}
+}
+
+// invalidate drops keys and every key under prefixes once the write commits.
+// Failures are only logged: the database write already succeeded and the TTL
+// bounds staleness.
+func (b *base) invalidate(ctx context.Context, keys []string, prefixes ...string) {
+	b.repos.AfterCommit(ctx, func(ctx context.Context) {
+		if err := b.cache.Delete(ctx, keys...); err != nil {
+			logging.FromContext(ctx, b.logger).Warnf("cache delete %v: %v", keys, err)
+		}
+		for _, prefix := range prefixes {
+			if err := b.cache.DeletePrefix(ctx, prefix); err != nil {
+				logging.FromContext(ctx, b.logger).Warnf("cache delete prefix %s: %v", prefix, err)
+			}
+		}
+	})
+}
+
+func fetch[T any](ctx context.Context, b *base, entity, key string, ttl time.Duration, load func(context.Context) (T, error)) (T, error) {
+	if raw, ok := b.lookup(ctx, entity, key); ok {
+		var value T
+		if err := json.Unmarshal(raw, &value); err == nil {
+			return value, nil
+		}
+	}
+
+	value, err := load(ctx)
+	if err != nil {
+		return value, err
+	}
+	b.store(ctx, key, value, ttl)
+
+	return value, nil
+}
+
+// fetchList caches a page whose items are a []T, and decodes them back into
+// that type because callers type-assert ListResponse.Items.
+func fetchList[T any](ctx context.Context, b *base, entity, key string, ttl time.Duration, load func(context.Context) (*model.ListResponse, error)) (*model.ListResponse, error) {
+	if raw, ok := b.lookup(ctx, entity, key); ok {
+		var items []T
+		list := &model.ListResponse{Items: &items}
+		if err := json.Unmarshal(raw, list); err == nil {
+			list.Items = items
+			return list, nil
+		}
+	}
+
+	list, err := load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	b.store(ctx, key, list, ttl)
+
+	return list, nil
+}
@@ -0,0 +1,238 @@
+package cached
+
+import (
+	"context"
+	"errors"
+	"github.com/alibekabdrakhman1/orynal/config"
+	"github.com/alibekabdrakhman1/orynal/internal/model"
+	"github.com/alibekabdrakhman1/orynal/internal/repository"
+	"github.com/alibekabdrakhman1/orynal/internal/repository/memory"
+	"github.com/alibekabdrakhman1/orynal/pkg/cache"
+	"github.com/alibekabdrakhman1/orynal/pkg/enums"
+	"github.com/alibekabdrakhman1/orynal/pkg/errs"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+)
+
+// fixture holds a cached manager and an uncached one over the same store, so
+// a test can change rows behind the cache's back.
+type fixture struct {
+	repos      *repository.Manager
+	store      *repository.Manager
+	cache      *cache.Memory
+	now        time.Time
+	restaurant uint
+	rival      uint
+	food       uint
+}
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+	ctx := context.Background()
+
+	store := memory.NewStore()
+	f := &fixture{
+		repos: repository.NewMemoryManager(store),
+		store: repository.NewMemoryManager(store),
+		cache: cache.NewMemory(100),
+		now:   time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	f.cache.SetClock(func() time.Time { return f.now })
+	Wrap(f.repos, f.cache, config.Cache{RestaurantTTL: time.Minute, PopularTTL: time.Minute, FoodTTL: time.Minute}, zap.NewNop().Sugar())
+
+	owner, err := f.store.User.Create(ctx, &model.User{Name: "owner", Email: "owner@orynal.kz", Phone: "owner", Role: enums.Owner})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := f.store.User.Create(ctx, &model.User{Name: "client", Email: "client@orynal.kz", Phone: "client", Role: enums.User})
+	if err != nil {
+		t.Fatal(err)
+	}
+	restaurant := func(name string) uint {
+		created, err := f.store.Restaurant.CreateRestaurant(ctx, &model.Restaurant{Name: name, OwnerID: owner.ID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return created.ID
+	}
+	f.restaurant, f.rival = restaurant("Orynal"), restaurant("Rival")
+
+	food, err := f.store.Food.CreateRestaurantFood(ctx, &model.Food{Name: "Beshbarmak", Type: "main", Price: 4500, RestaurantID: f.restaurant})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.food = food.ID
+	table, err := f.store.Table.CreateTable(ctx, &model.Table{Name: "Window", Type: "hall", Capacity: 4, RestaurantID: f.restaurant})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.store.Order.CreateOrder(ctx, &model.Order{RestaurantID: f.restaurant, UserID: client.ID, TableID: table.ID, Date: f.now, Status: enums.Reserved}); err != nil {
+		t.Fatal(err)
+	}
+
+	return f
+}
+
+// name reads the restaurant through the cache.
+func (f *fixture) name(t *testing.T, repos *repository.Manager) string {
+	t.Helper()
+	restaurant, err := repos.Restaurant.GetRestaurantByID(context.Background(), f.restaurant)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return restaurant.Name
+}
+
+// rename changes the restaurant in repos, behind the cache when repos is
+// f.store.
+func (f *fixture) rename(t *testing.T, repos *repository.Manager, name string) {
+	t.Helper()
+	if _, err := repos.Restaurant.UpdateRestaurant(context.Background(), f.restaurant, &model.Restaurant{Name: name}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReadThrough(t *testing.T) {
+	f := newFixture(t)
+
+	f.name(t, f.repos)
+	f.rename(t, f.store, "Behind")
+	if name := f.name(t, f.repos); name != "Orynal" {
+		t.Errorf("cached name = %s, want Orynal until the entry expires", name)
+	}
+
+	f.now = f.now.Add(time.Minute + time.Second)
+	if name := f.name(t, f.repos); name != "Behind" {
+		t.Errorf("name after the ttl = %s, want Behind", name)
+	}
+
+	f.rename(t, f.repos, "Renamed")
+	if name := f.name(t, f.repos); name != "Renamed" {
+		t.Errorf("name after a write = %s, want Renamed", name)
+	}
+}
+
+func TestTransactions(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("invalidates on commit", func(t *testing.T) {
+		f := newFixture(t)
+		f.name(t, f.repos)
+
+		err := f.repos.WithTx(ctx, func(txRepos *repository.Manager) error {
+			f.rename(t, txRepos, "Renamed")
+			if _, ok, _ := f.cache.Get(ctx, restaurantKey(f.restaurant)); !ok {
+				t.Error("entry dropped before the commit")
+			}
+			if name := f.name(t, txRepos); name != "Renamed" {
+				t.Errorf("name inside the transaction = %s, want the uncommitted Renamed", name)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, ok, _ := f.cache.Get(ctx, restaurantKey(f.restaurant)); ok {
+			t.Error("entry kept after the commit")
+		}
+		if name := f.name(t, f.repos); name != "Renamed" {
+			t.Errorf("name after the commit = %s, want Renamed", name)
+		}
+	})
+
+	t.Run("keeps entries on rollback", func(t *testing.T) {
+		f := newFixture(t)
+		f.name(t, f.repos)
+
+		errAbort := errors.New("abort")
+		err := f.repos.WithTx(ctx, func(txRepos *repository.Manager) error {
+			f.rename(t, txRepos, "Renamed")
+			return errAbort
+		})
+		if !errors.Is(err, errAbort) {
+			t.Fatalf("got %v, want %v", err, errAbort)
+		}
+
+		if _, ok, _ := f.cache.Get(ctx, restaurantKey(f.restaurant)); !ok {
+			t.Error("entry dropped by a rolled back write")
+		}
+		if name := f.name(t, f.repos); name != "Orynal" {
+			t.Errorf("name after the rollback = %s, want Orynal", name)
+		}
+	})
+
+	t.Run("does not cache reads", func(t *testing.T) {
+		f := newFixture(t)
+
+		err := f.repos.WithTx(ctx, func(txRepos *repository.Manager) error {
+			f.name(t, txRepos)
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if f.cache.Len() != 0 {
+			t.Errorf("%d entries cached by a transaction, want none", f.cache.Len())
+		}
+	})
+}
+
+func TestPopularKeepsItemType(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		list, err := f.repos.Restaurant.GetPopularRestaurants(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		restaurants, ok := list.Items.([]model.Restaurant)
+		if !ok || len(restaurants) != 1 || restaurants[0].ID != f.restaurant {
+			t.Errorf("read %d: items %#v, want the ordered restaurant as []model.Restaurant", i, list.Items)
+		}
+	}
+}
+
+func TestFoodOfAnotherRestaurant(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+
+	if _, err := f.repos.Food.GetRestaurantFood(ctx, f.restaurant, f.food); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, _ := f.cache.Get(ctx, foodKey(f.food)); !ok {
+		t.Fatal("food was not cached")
+	}
+	if _, err := f.repos.Food.GetRestaurantFood(ctx, f.rival, f.food); !errors.Is(err, errs.ErrFoodNotFound) {
+		t.Errorf("cached food through another restaurant: %v, want %v", err, errs.ErrFoodNotFound)
+	}
+}
+
+func TestDeleteFoodDropsMenus(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+
+	for _, id := range []uint{f.restaurant, f.rival} {
+		if _, err := f.repos.Food.GetMenuCategories(ctx, id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := f.repos.Food.DeleteRestaurantFood(ctx, f.food); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{foodKey(f.food), menuCategoriesKey(f.restaurant), menuCategoriesKey(f.rival)} {
+		if _, ok, _ := f.cache.Get(ctx, key); ok {
+			t.Errorf("%s kept after deleting the food", key)
+		}
+	}
+	categories, err := f.repos.Food.GetMenuCategories(ctx, f.restaurant)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(categories) != 0 {
+		t.Errorf("categories after deleting the only dish = %v, want none", categories)
+	}
+}
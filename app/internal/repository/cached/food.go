@@ -0,0 +1,105 @@
+package cached
+
+import (
+	"context"
+	"fmt"
+	"github.com/alibekabdrakhman1/orynal/config"
+	"github.com/alibekabdrakhman1/orynal/internal/model"
+	"github.com/alibekabdrakhman1/orynal/internal/repository"
+)
+
+const menuCategoriesPrefix = "menu-categories:"
+
+func foodKey(id uint) string {
+	return fmt.Sprintf("food:%d", id)
+}
+
+func menuCategoriesKey(restaurantID uint) string {
+	return fmt.Sprintf("%s%d", menuCategoriesPrefix, restaurantID)
+}
+
+type FoodRepository struct {
+	repository.IFoodRepository
+	*base
+	cfg config.Cache
+}
+
+func newFoodRepository(inner repository.IFoodRepository, b *base, cfg config.Cache) *FoodRepository {
+	return &FoodRepository{
+		IFoodRepository: inner,
+		base:            b,
+		cfg:             cfg,
+	}
+}
+
+func (r *FoodRepository) GetRestaurantFood(ctx context.Context, restaurantID uint, foodID uint) (*model.Food, error) {
+	food, err := fetch(ctx, r.base, "food", foodKey(foodID), r.cfg.FoodTTL, func(ctx context.Context) (*model.Food, error) {
+		return r.IFoodRepository.GetRestaurantFood(ctx, restaurantID, foodID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Food is keyed by id alone so deletes can invalidate it; a cached dish
+	// of another restaurant must still produce the repository's not found.
+	if food.RestaurantID != restaurantID {
+		return r.IFoodRepository.GetRestaurantFood(ctx, restaurantID, foodID)
+	}
+
+	return food, nil
+}
+
+func (r *FoodRepository) GetMenuCategories(ctx context.Context, restaurantID uint) ([]string, error) {
+	return fetch(ctx, r.base, "menu-categories", menuCategoriesKey(restaurantID), r.cfg.FoodTTL, func(ctx context.Context) ([]string, error) {
+		return r.IFoodRepository.GetMenuCategories(ctx, restaurantID)
+	})
+}
+
+func (r *FoodRepository) CreateRestaurantFood(ctx context.Context, food *model.Food) (*model.Food, error) {
+	created, err := r.IFoodRepository.CreateRestaurantFood(ctx, food)
+	if err != nil {
+		return nil, err
+	}
+	r.invalidate(ctx, []string{menuCategoriesKey(food.RestaurantID)})
+
+	return created, nil
+}
+
+func (r *FoodRepository) UpdateRestaurantFood(ctx context.Context, food *model.Food) (*model.Food, error) {
+	updated, err := r.IFoodRepository.UpdateRestaurantFood(ctx, food)
+	if err != nil {
+		return nil, err
+	}
+	r.invalidate(ctx, []string{foodKey(food.ID), menuCategoriesKey(food.RestaurantID)})
+
+	return updated, nil
+}
+
+func (r *FoodRepository) PatchRestaurantFood(ctx context.Context, food *model.Food, fields []string) (*model.Food, error) {
+	patched, err := r.IFoodRepository.PatchRestaurantFood(ctx, food, fields)
+	if err != nil {
+		return nil, err
+	}
+	r.invalidate(ctx, []string{foodKey(food.ID), menuCategoriesKey(food.RestaurantID)})
+
+	return patched, nil
+}
+
+// DeleteRestaurantFood does not know the restaurant, so it drops the menu
+// categories of all of them.
+func (r *FoodRepository) DeleteRestaurantFood(ctx context.Context, foodID uint) error {
+	if err := r.IFoodRepository.DeleteRestaurantFood(ctx, foodID); err != nil {
+		return err
+	}
+	r.invalidate(ctx, []string{foodKey(foodID)}, menuCategoriesPrefix)
+
+	return nil
+}
+
+func (r *FoodRepository) RestoreRestaurantFood(ctx context.Context, restaurantID uint, foodID uint) error {
+	if err := r.IFoodRepository.RestoreRestaurantFood(ctx, restaurantID, foodID); err != nil {
+		return err
+	}
+	r.invalidate(ctx, []string{foodKey(foodID), menuCategoriesKey(restaurantID)})
+
+	return nil
+}
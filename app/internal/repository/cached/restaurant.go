@@ -0,0 +1,125 @@
+package cached
+
+import (
+	"context"
+	"fmt"
+	"github.com/alibekabdrakhman1/orynal/config"
+	"github.com/alibekabdrakhman1/orynal/internal/model"
+	"github.com/alibekabdrakhman1/orynal/internal/repository"
+	"time"
+)
+
+const (
+	popularKey    = "restaurants:popular"
+	statisticsKey = "restaurants:statistics"
+)
+
+func restaurantKey(id uint) string {
+	return fmt.Sprintf("restaurant:%d", id)
+}
+
+type RestaurantRepository struct {
+	repository.IRestaurantRepository
+	*base
+	cfg config.Cache
+}
+
+func newRestaurantRepository(inner repository.IRestaurantRepository, b *base, cfg config.Cache) *RestaurantRepository {
+	return &RestaurantRepository{
+		IRestaurantRepository: inner,
+		base:                  b,
+		cfg:                   cfg,
+	}
+}
+
+func (r *RestaurantRepository) GetRestaurantByID(ctx context.Context, id uint) (*model.Restaurant, error) {
+	return fetch(ctx, r.base, "restaurant", restaurantKey(id), r.cfg.RestaurantTTL, func(ctx context.Context) (*model.Restaurant, error) {
+		return r.IRestaurantRepository.GetRestaurantByID(ctx, id)
+	})
+}
+
+func (r *RestaurantRepository) GetPopularRestaurants(ctx context.Context) (*model.ListResponse, error) {
+	return fetchList[model.Restaurant](ctx, r.base, "popular", popularKey, r.cfg.PopularTTL, r.IRestaurantRepository.GetPopularRestaurants)
+}
+
+func (r *RestaurantRepository) GetStatistics(ctx context.Context) (*model.Statistics, error) {
+	return fetch(ctx, r.base, "statistics", statisticsKey, r.cfg.StatisticsTTL, r.IRestaurantRepository.GetStatistics)
+}
+
+func (r *RestaurantRepository) CreateRestaurant(ctx context.Context, restaurant *model.Restaurant) (*model.Restaurant, error) {
+	created, err := r.IRestaurantRepository.CreateRestaurant(ctx, restaurant)
+	if err != nil {
+		return nil, err
+	}
+	r.invalidate(ctx, []string{statisticsKey})
+
+	return created, nil
+}
+
+func (r *RestaurantRepository) DeleteRestaurant(ctx context.Context, restaurantID uint) error {
+	if err := r.IRestaurantRepository.DeleteRestaurant(ctx, restaurantID); err != nil {
+		return err
+	}
+	r.invalidate(ctx, []string{restaurantKey(restaurantID), popularKey, statisticsKey})
+
+	return nil
+}
+
+func (r *RestaurantRepository) RestoreRestaurant(ctx context.Context, restaurantID uint) error {
+	if err := r.IRestaurantRepository.RestoreRestaurant(ctx, restaurantID); err != nil {
+		return err
+	}
+	r.invalidate(ctx, []string{restaurantKey(restaurantID), popularKey, statisticsKey})
+
+	return nil
+}
+
+func (r *RestaurantRepository) PurgeDeletedRestaurants(ctx context.Context, before time.Time) (int64, error) {
+	purged, err := r.IRestaurantRepository.PurgeDeletedRestaurants(ctx, before)
+	if err != nil {
+		return purged, err
+	}
+	if purged > 0 {
+		r.invalidate(ctx, []string{statisticsKey})
+	}
+
+	return purged, nil
+}
+
+func (r *RestaurantRepository) UpdateRestaurant(ctx context.Context, restaurantID uint, restaurant *model.Restaurant) (*model.Restaurant, error) {
+	updated, err := r.IRestaurantRepository.UpdateRestaurant(ctx, restaurantID, restaurant)
+	if err != nil {
+		return nil, err
+	}
+	r.invalidate(ctx, []string{restaurantKey(restaurantID), popularKey})
+
+	return updated, nil
+}
+
+func (r *RestaurantRepository) PatchRestaurant(ctx context.Context, restaurant *model.Restaurant, fields []string) (*model.Restaurant, error) {
+	patched, err := r.IRestaurantRepository.PatchRestaurant(ctx, restaurant, fields)
+	if err != nil {
+		return nil, err
+	}
+	r.invalidate(ctx, []string{restaurantKey(restaurant.ID), popularKey})
+
+	return patched, nil
+}
+
+func (r *RestaurantRepository) UpdateRestaurantPhotos(ctx context.Context, restaurantID uint, photos []model.Photo) error {
+	if err := r.IRestaurantRepository.UpdateRestaurantPhotos(ctx, restaurantID, photos); err != nil {
+		return err
+	}
+	r.invalidate(ctx, []string{restaurantKey(restaurantID), popularKey})
+
+	return nil
+}
+
+func (r *RestaurantRepository) UpdateRestaurantServices(ctx context.Context, restaurantID uint, services []model.Service) error {
+	if err := r.IRestaurantRepository.UpdateRestaurantServices(ctx, restaurantID, services); err != nil {
+		return err
+	}
+	r.invalidate(ctx, []string{restaurantKey(restaurantID), popularKey})
+
+	return nil
+}
@@ -0,0 +1,62 @@
+package cached
+
+import (
+	"context"
+	"github.com/alibekabdrakhman1/orynal/config"
+	"github.com/alibekabdrakhman1/orynal/internal/model"
+	"github.com/alibekabdrakhman1/orynal/internal/repository"
+)
+
+const (
+	servicesKey      = "services"
+	restaurantPrefix = "restaurant:"
+)
+
+type ServicesRepository struct {
+	repository.IServicesRepository
+	*base
+	cfg config.Cache
+}
+
+func newServicesRepository(inner repository.IServicesRepository, b *base, cfg config.Cache) *ServicesRepository {
+	return &ServicesRepository{
+		IServicesRepository: inner,
+		base:                b,
+		cfg:                 cfg,
+	}
+}
+
+func (r *ServicesRepository) GetServices(ctx context.Context) ([]model.Service, error) {
+	return fetch(ctx, r.base, "services", servicesKey, r.cfg.ServicesTTL, r.IServicesRepository.GetServices)
+}
+
+func (r *ServicesRepository) CreateService(ctx context.Context, service *model.Service) ([]model.Service, error) {
+	services, err := r.IServicesRepository.CreateService(ctx, service)
+	if err != nil {
+		return nil, err
+	}
+	r.invalidate(ctx, []string{servicesKey})
+
+	return services, nil
+}
+
+// Restaurants embed their services by name, so renames and deletes drop
+// every cached restaurant too.
+func (r *ServicesRepository) UpdateService(ctx context.Context, service *model.Service) ([]model.Service, error) {
+	services, err := r.IServicesRepository.UpdateService(ctx, service)
+	if err != nil {
+		return nil, err
+	}
+	r.invalidate(ctx, []string{servicesKey, popularKey}, restaurantPrefix)
+
+	return services, nil
+}
+
+func (r *ServicesRepository) DeleteService(ctx context.Context, id uint) error {
+	if err := r.IServicesRepository.DeleteService(ctx, id); err != nil {
+		return err
+	}
+	r.invalidate(ctx, []string{servicesKey, popularKey}, restaurantPrefix)
+
+	return nil
+}
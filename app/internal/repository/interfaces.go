@@ -9,17 +9,31 @@ import (
 type IUserTokenRepository interface {
 	CreateUserToken(ctx context.Context, userToken model.UserToken) error
 	UpdateUserToken(ctx context.Context, userToken model.UserToken) error
+	GetByUserID(ctx context.Context, userID uint) ([]model.UserToken, error)
+	DeleteByUserID(ctx context.Context, userID uint) error
 }
 
 type IUserRepository interface {
 	Create(ctx context.Context, user *model.User) (*model.UserResponse, error)
 	Update(ctx context.Context, user *model.User) (*model.UserResponse, error)
+	Patch(ctx context.Context, user *model.User, fields []string) (*model.UserResponse, error)
 	ChangePassword(ctx context.Context, id uint, pass *model.ChangePasswordRequest) error
+	SetPassword(ctx context.Context, id uint, password string) error
+	SetRole(ctx context.Context, id uint, role string) error
 	Delete(ctx context.Context, id uint) error
+	Disable(ctx context.Context, id uint) error
+	// Restore brings back a deleted or disabled user with role, or with any
+	// role when it is empty.
+	Restore(ctx context.Context, id uint, role string) error
+	RequestDeletion(ctx context.Context, id uint, at time.Time) error
+	CancelDeletion(ctx context.Context, id uint) error
+	GetDueForAnonymization(ctx context.Context, requestedBefore time.Time, deletedBefore time.Time) ([]uint, error)
+	Anonymize(ctx context.Context, id uint) error
 	GetByID(ctx context.Context, id uint) (*model.UserResponse, error)
 	GetByEmail(ctx context.Context, email string) (*model.User, error)
 	GetAllClients(ctx context.Context, params *model.Params) (*model.ListResponse, error)
 	GetAllOwners(ctx context.Context, params *model.Params) (*model.ListResponse, error)
+	List(ctx context.Context, role string) ([]model.User, error)
 }
 
 type IRestaurantRepository interface {
@@ -31,7 +45,10 @@ type IRestaurantRepository interface {
 	GetPopularRestaurants(ctx context.Context) (*model.ListResponse, error)
 	CreateRestaurant(ctx context.Context, restaurant *model.Restaurant) (*model.Restaurant, error)
 	DeleteRestaurant(ctx context.Context, restaurantID uint) error
+	RestoreRestaurant(ctx context.Context, restaurantID uint) error
+	PurgeDeletedRestaurants(ctx context.Context, before time.Time) (int64, error)
 	UpdateRestaurant(ctx context.Context, restaurantID uint, restaurant *model.Restaurant) (*model.Restaurant, error)
+	PatchRestaurant(ctx context.Context, restaurant *model.Restaurant, fields []string) (*model.Restaurant, error)
 	UpdateRestaurantPhotos(ctx context.Context, restaurantID uint, photos []model.Photo) error
 	UpdateRestaurantServices(ctx context.Context, restaurantID uint, services []model.Service) error
 }
@@ -41,6 +58,7 @@ type ITableRepository interface {
 	GetRestaurantTable(ctx context.Context, restaurantID uint, tableID uint) (*model.Table, error)
 	CreateTable(ctx context.Context, table *model.Table) (*model.Table, error)
 	UpdateTable(ctx context.Context, table *model.Table) (*model.Table, error)
+	PatchTable(ctx context.Context, table *model.Table, fields []string) (*model.Table, error)
 	DeleteTable(ctx context.Context, id uint) error
 	GetAvailableTime(ctx context.Context, date time.Time) ([]time.Time, error)
 	GetTableCategories(ctx context.Context, restaurantID uint) ([]string, error)
@@ -51,7 +69,10 @@ type IFoodRepository interface {
 	GetRestaurantFood(ctx context.Context, restaurantID uint, foodID uint) (*model.Food, error)
 	CreateRestaurantFood(ctx context.Context, food *model.Food) (*model.Food, error)
 	UpdateRestaurantFood(ctx context.Context, food *model.Food) (*model.Food, error)
+	PatchRestaurantFood(ctx context.Context, food *model.Food, fields []string) (*model.Food, error)
 	DeleteRestaurantFood(ctx context.Context, foodID uint) error
+	RestoreRestaurantFood(ctx context.Context, restaurantID uint, foodID uint) error
+	PurgeDeletedFoods(ctx context.Context, before time.Time) (int64, error)
 	GetMenuCategories(ctx context.Context, restaurantID uint) ([]string, error)
 }
 
@@ -59,6 +80,7 @@ type IOrderRepository interface {
 	CreateOrder(ctx context.Context, order *model.Order) (*model.OrderResponse, error)
 	DeleteOrder(ctx context.Context, id uint) error
 	UpdateOrder(ctx context.Context, order *model.Order) (*model.OrderResponse, error)
+	PatchOrder(ctx context.Context, order *model.Order, fields []string) (*model.OrderResponse, error)
 	GetOrder(ctx context.Context, id uint) (*model.OrderResponse, error)
 	GetAllOrders(ctx context.Context, userID uint, params *model.Params) (*model.ListResponse, error)
 	GetRestaurantOrders(ctx context.Context, restaurantID uint, params *model.Params) (*model.ListResponse, error)
@@ -76,4 +98,19 @@ type IReviewsRepository interface {
 	CreateReview(ctx context.Context, review *model.RestaurantReview) (*model.RestaurantReview, error)
 	DeleteReview(ctx context.Context, id uint) error
 	GetReview(ctx context.Context, id uint) (*model.RestaurantReview, error)
+	GetUserReviews(ctx context.Context, userID uint) ([]model.RestaurantReview, error)
+}
+
+type ISearchRepository interface {
+	SearchRestaurants(ctx context.Context, params *model.Params) (*model.ListResponse, error)
+	SearchDishes(ctx context.Context, search *model.DishSearch) (*model.ListResponse, error)
+}
+
+type IDataExportRepository interface {
+	Create(ctx context.Context, export *model.DataExport) (*model.DataExport, error)
+	Update(ctx context.Context, export *model.DataExport) error
+	GetByID(ctx context.Context, id uint) (*model.DataExport, error)
+	GetPending(ctx context.Context, userID uint, since time.Time) (*model.DataExport, error)
+	GetExpired(ctx context.Context, before time.Time) ([]model.DataExport, error)
+	Delete(ctx context.Context, id uint) error
 }
@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"context"
+	"github.com/alibekabdrakhman1/orynal/internal/repository/memory"
 	"github.com/alibekabdrakhman1/orynal/internal/repository/postgre"
 	"gorm.io/gorm"
 )
@@ -14,6 +16,15 @@ type Manager struct {
 	Table      ITableRepository
 	Services   IServicesRepository
 	Reviews    IReviewsRepository
+	Search     ISearchRepository
+	DataExport IDataExportRepository
+
+	// transaction runs fn with repositories bound to a new transaction.
+	transaction func(ctx context.Context, fn func(*Manager) error) error
+	decorators  []func(*Manager)
+	// afterCommit collects the hooks of the running transaction; it is nil
+	// outside one.
+	afterCommit *[]func(context.Context)
 }
 
 func NewManager(db *gorm.DB) *Manager {
@@ -26,5 +37,83 @@ func NewManager(db *gorm.DB) *Manager {
 		Table:      postgre.NewTableRepository(db),
 		Services:   postgre.NewServicesRepository(db),
 		Reviews:    postgre.NewReviewsRepository(db),
+		Search:     postgre.NewSearchRepository(db),
+		DataExport: postgre.NewDataExportRepository(db),
+		transaction: func(ctx context.Context, fn func(*Manager) error) error {
+			return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+				return fn(NewManager(tx))
+			})
+		},
+	}
+}
+
+// NewMemoryManager backs every repository with the same in-memory store, for
+// tests that exercise services without a database.
+func NewMemoryManager(store *memory.Store) *Manager {
+	return &Manager{
+		User:       memory.NewUserRepository(store),
+		UserToken:  memory.NewUserTokenRepository(store),
+		Restaurant: memory.NewRestaurantRepository(store),
+		Order:      memory.NewOrderRepository(store),
+		Food:       memory.NewFoodRepository(store),
+		Table:      memory.NewTableRepository(store),
+		Services:   memory.NewServicesRepository(store),
+		Reviews:    memory.NewReviewsRepository(store),
+		Search:     memory.NewSearchRepository(store),
+		DataExport: memory.NewDataExportRepository(store),
+		transaction: func(ctx context.Context, fn func(*Manager) error) error {
+			return store.Transaction(func() error {
+				return fn(NewMemoryManager(store))
+			})
+		},
+	}
+}
+
+// WithTx runs fn with repositories that share one transaction, committed when
+// fn returns nil and rolled back otherwise. Called on the repositories of a
+// running transaction, it joins that transaction.
+func (m *Manager) WithTx(ctx context.Context, fn func(txRepos *Manager) error) error {
+	if m.InTx() {
+		return fn(m)
+	}
+
+	var hooks []func(context.Context)
+	err := m.transaction(ctx, func(txRepos *Manager) error {
+		txRepos.afterCommit = &hooks
+		for _, decorate := range m.decorators {
+			decorate(txRepos)
+		}
+		return fn(txRepos)
+	})
+	if err != nil {
+		return err
 	}
+
+	for _, hook := range hooks {
+		hook(ctx)
+	}
+
+	return nil
+}
+
+// InTx reports whether m is bound to a running transaction.
+func (m *Manager) InTx() bool {
+	return m.afterCommit != nil
+}
+
+// AfterCommit defers fn until the transaction of m commits and drops it on
+// rollback. Outside a transaction fn runs right away.
+func (m *Manager) AfterCommit(ctx context.Context, fn func(context.Context)) {
+	if !m.InTx() {
+		fn(ctx)
+		return
+	}
+	*m.afterCommit = append(*m.afterCommit, fn)
+}
+
+// Decorate applies fn to the repositories of m and to those of every
+// transaction m starts.
+func (m *Manager) Decorate(fn func(*Manager)) {
+	fn(m)
+	m.decorators = append(m.decorators, fn)
 }
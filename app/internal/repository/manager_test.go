@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/alibekabdrakhman1/orynal/internal/model"
+	"github.com/alibekabdrakhman1/orynal/internal/repository/memory"
+	"github.com/alibekabdrakhman1/orynal/pkg/enums"
+	"github.com/alibekabdrakhman1/orynal/pkg/errs"
+	"testing"
+)
+
+func TestWithTx(t *testing.T) {
+	ctx := context.Background()
+	errAbort := errors.New("abort")
+
+	createOwner := func(repos *Manager, email string) uint {
+		t.Helper()
+		user, err := repos.User.Create(ctx, &model.User{Name: "owner", Email: email, Phone: email, Role: enums.Owner})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return user.ID
+	}
+
+	t.Run("rollback", func(t *testing.T) {
+		repos := NewMemoryManager(memory.NewStore())
+
+		var committed bool
+		err := repos.WithTx(ctx, func(txRepos *Manager) error {
+			ownerID := createOwner(txRepos, "owner@orynal.kz")
+			if _, err := txRepos.Restaurant.CreateRestaurant(ctx, &model.Restaurant{Name: "Restaurant", OwnerID: ownerID}); err != nil {
+				return err
+			}
+			txRepos.AfterCommit(ctx, func(context.Context) { committed = true })
+			return errAbort
+		})
+		if !errors.Is(err, errAbort) {
+			t.Fatalf("WithTx() = %v, want %v", err, errAbort)
+		}
+		if committed {
+			t.Error("after commit hook ran on rollback")
+		}
+		if _, err := repos.User.GetByEmail(ctx, "owner@orynal.kz"); !errors.Is(err, errs.ErrUserNotFound) {
+			t.Errorf("owner after rollback: %v, want %v", err, errs.ErrUserNotFound)
+		}
+		if _, err := repos.Restaurant.GetRestaurantByID(ctx, 1); !errors.Is(err, errs.ErrRestaurantNotFound) {
+			t.Errorf("restaurant after rollback: %v, want %v", err, errs.ErrRestaurantNotFound)
+		}
+	})
+
+	t.Run("commit", func(t *testing.T) {
+		repos := NewMemoryManager(memory.NewStore())
+
+		var hooks []string
+		err := repos.WithTx(ctx, func(txRepos *Manager) error {
+			createOwner(txRepos, "owner@orynal.kz")
+			txRepos.AfterCommit(ctx, func(context.Context) { hooks = append(hooks, "outer") })
+
+			// A nested call joins the running transaction.
+			return txRepos.WithTx(ctx, func(nested *Manager) error {
+				if nested != txRepos {
+					t.Error("nested WithTx started a new transaction")
+				}
+				nested.AfterCommit(ctx, func(context.Context) { hooks = append(hooks, "nested") })
+				if len(hooks) != 0 {
+					t.Error("after commit hook ran before the commit")
+				}
+				return nil
+			})
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(hooks) != 2 || hooks[0] != "outer" || hooks[1] != "nested" {
+			t.Errorf("hooks = %v, want [outer nested]", hooks)
+		}
+		if _, err := repos.User.GetByEmail(ctx, "owner@orynal.kz"); err != nil {
+			t.Errorf("owner after commit: %v", err)
+		}
+	})
+
+	t.Run("decorators", func(t *testing.T) {
+		repos := NewMemoryManager(memory.NewStore())
+
+		var decorated []bool
+		repos.Decorate(func(m *Manager) { decorated = append(decorated, m.InTx()) })
+		err := repos.WithTx(ctx, func(*Manager) error { return nil })
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(decorated) != 2 || decorated[0] || !decorated[1] {
+			t.Errorf("decorated in tx = %v, want [false true]", decorated)
+		}
+	})
+}
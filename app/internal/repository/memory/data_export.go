@@ -0,0 +1,97 @@
+package memory
+
+import (
+	"context"
+	"github.com/alibekabdrakhman1/orynal/internal/model"
+	"github.com/alibekabdrakhman1/orynal/pkg/enums"
+	"github.com/alibekabdrakhman1/orynal/pkg/errs"
+	"time"
+)
+
+type DataExportRepository struct {
+	store *Store
+}
+
+func NewDataExportRepository(store *Store) *DataExportRepository {
+	return &DataExportRepository{store: store}
+}
+
+func (r *DataExportRepository) Create(ctx context.Context, export *model.DataExport) (*model.DataExport, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.users[export.UserID]; !ok {
+		return nil, errs.ErrReferenceViolation
+	}
+
+	if export.CreatedAt.IsZero() {
+		export.CreatedAt = time.Now()
+	}
+	export.ID = r.store.next("data_exports")
+	r.store.exports[export.ID] = *export
+
+	return export, nil
+}
+
+// Update saves every column, inserting the export when it has no id yet.
+func (r *DataExportRepository) Update(ctx context.Context, export *model.DataExport) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if export.ID == 0 {
+		export.ID = r.store.next("data_exports")
+	}
+	r.store.exports[export.ID] = *export
+
+	return nil
+}
+
+func (r *DataExportRepository) GetByID(ctx context.Context, id uint) (*model.DataExport, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	export, ok := r.store.exports[id]
+	if !ok {
+		return nil, errs.ErrExportNotFound
+	}
+
+	return &export, nil
+}
+
+func (r *DataExportRepository) GetPending(ctx context.Context, userID uint, since time.Time) (*model.DataExport, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	exports := sortedByID(r.store.exports)
+	for i := len(exports) - 1; i >= 0; i-- {
+		export := exports[i]
+		if export.UserID == userID && export.Status == enums.ExportPending && export.CreatedAt.After(since) {
+			return &export, nil
+		}
+	}
+
+	return nil, errs.ErrExportNotFound
+}
+
+func (r *DataExportRepository) GetExpired(ctx context.Context, before time.Time) ([]model.DataExport, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var exports []model.DataExport
+	for _, export := range sortedByID(r.store.exports) {
+		if export.ExpiresAt != nil && export.ExpiresAt.Before(before) {
+			exports = append(exports, export)
+		}
+	}
+
+	return exports, nil
+}
+
+func (r *DataExportRepository) Delete(ctx context.Context, id uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	delete(r.store.exports, id)
+
+	return nil
+}
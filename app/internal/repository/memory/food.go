@@ -0,0 +1,239 @@
+package memory
+
+import (
+	"context"
+	"github.com/alibekabdrakhman1/orynal/internal/model"
+	"github.com/alibekabdrakhman1/orynal/pkg/errs"
+	"slices"
+	"strings"
+	"time"
+)
+
+type FoodRepository struct {
+	store *Store
+}
+
+func NewFoodRepository(store *Store) *FoodRepository {
+	return &FoodRepository{store: store}
+}
+
+func (r *FoodRepository) GetMenuCategories(ctx context.Context, restaurantID uint) ([]string, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var types []string
+	for _, food := range r.store.foods {
+		if food.RestaurantID == restaurantID && !food.DeletedAt.Valid && !slices.Contains(types, food.Type) {
+			types = append(types, food.Type)
+		}
+	}
+	slices.Sort(types)
+
+	return types, nil
+}
+
+func (r *FoodRepository) GetRestaurantMenu(ctx context.Context, restaurantID uint, params *model.Params) (*model.ListResponse, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var foods []model.Food
+	for _, food := range sortedByID(r.store.foods) {
+		if food.RestaurantID != restaurantID || food.DeletedAt.Valid {
+			continue
+		}
+		if params.Query != "" && !strings.EqualFold(food.Type, params.Query) {
+			continue
+		}
+		foods = append(foods, food)
+	}
+
+	foods, err := filter(foods, params)
+	if err != nil {
+		return nil, err
+	}
+
+	paged, _, _, err := page(foods, params)
+	if err != nil {
+		return nil, err
+	}
+	for i := range paged {
+		paged[i].Photo = r.store.photos[paged[i].PhotoID]
+	}
+
+	return &model.ListResponse{
+		Items:        paged,
+		ItemsPerPage: params.Limit,
+		PageIndex:    params.PageIndex,
+		TotalItems:   len(foods),
+	}, nil
+}
+
+func (r *FoodRepository) GetRestaurantFood(ctx context.Context, restaurantID uint, foodID uint) (*model.Food, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	food, ok := r.store.foods[foodID]
+	if !ok || food.DeletedAt.Valid || food.RestaurantID != restaurantID {
+		return nil, errs.ErrFoodNotFound
+	}
+	food.Photo = r.store.photos[food.PhotoID]
+
+	return &food, nil
+}
+
+func (r *FoodRepository) CreateRestaurantFood(ctx context.Context, food *model.Food) (*model.Food, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.restaurants[food.RestaurantID]; !ok {
+		return nil, errs.ErrReferenceViolation
+	}
+
+	if food.Photo.Route != "" && food.Photo.ID == 0 {
+		food.Photo = r.store.createPhoto(food.Photo.Route)
+	}
+	if food.Photo.ID != 0 {
+		food.PhotoID = food.Photo.ID
+	}
+
+	food.ID = r.store.next("foods")
+	food.Version = 1
+	row := *food
+	row.Photo = model.Photo{}
+	r.store.foods[food.ID] = row
+
+	return food, nil
+}
+
+// UpdateRestaurantFood merges the non-zero fields and, when the photo id
+// changes, drops the old photo and stores the new one.
+func (r *FoodRepository) UpdateRestaurantFood(ctx context.Context, food *model.Food) (*model.Food, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, ok := r.store.foods[food.ID]
+	if !ok || existing.DeletedAt.Valid {
+		return nil, errs.ErrFoodNotFound
+	}
+	if food.Version != 0 && food.Version != existing.Version {
+		return nil, errs.ErrVersionMismatch
+	}
+	existing.Version++
+	food.Version = existing.Version
+	oldPhotoID := existing.PhotoID
+
+	if food.Name != "" {
+		existing.Name = food.Name
+	}
+	if food.Type != "" {
+		existing.Type = food.Type
+	}
+	if food.Description != "" {
+		existing.Description = food.Description
+	}
+	if food.Price != 0 {
+		existing.Price = food.Price
+	}
+	if food.Available {
+		existing.Available = true
+	}
+	if food.Vegetarian {
+		existing.Vegetarian = true
+	}
+	if food.Vegan {
+		existing.Vegan = true
+	}
+	if food.Halal {
+		existing.Halal = true
+	}
+	if food.GlutenFree {
+		existing.GlutenFree = true
+	}
+	if food.RestaurantID != 0 {
+		existing.RestaurantID = food.RestaurantID
+	}
+
+	if oldPhotoID != food.PhotoID {
+		if oldPhotoID != 0 {
+			r.store.deletePhoto(oldPhotoID)
+		}
+
+		if food.Photo.ID == 0 {
+			food.Photo = r.store.createPhoto(food.Photo.Route)
+		} else {
+			r.store.photos[food.Photo.ID] = food.Photo
+		}
+		existing.PhotoID = food.Photo.ID
+	}
+	r.store.foods[food.ID] = existing
+
+	return food, nil
+}
+
+func (r *FoodRepository) PatchRestaurantFood(ctx context.Context, food *model.Food, fields []string) (*model.Food, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, ok := r.store.foods[food.ID]
+	if !ok || existing.DeletedAt.Valid {
+		return nil, errs.ErrFoodNotFound
+	}
+	if food.Version != existing.Version {
+		return nil, errs.ErrVersionMismatch
+	}
+	assign(&existing, food, fields)
+	existing.Version++
+	food.Version = existing.Version
+	r.store.foods[food.ID] = existing
+
+	return food, nil
+}
+
+func (r *FoodRepository) DeleteRestaurantFood(ctx context.Context, foodID uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	food, ok := r.store.foods[foodID]
+	if !ok || food.DeletedAt.Valid {
+		return errs.ErrFoodNotFound
+	}
+
+	food.DeletedAt = deletedAt(time.Now())
+	r.store.foods[foodID] = food
+
+	return nil
+}
+
+func (r *FoodRepository) RestoreRestaurantFood(ctx context.Context, restaurantID uint, foodID uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	food, ok := r.store.foods[foodID]
+	if !ok || !food.DeletedAt.Valid || food.RestaurantID != restaurantID {
+		return errs.ErrFoodNotFound
+	}
+
+	food.DeletedAt.Valid = false
+	r.store.foods[foodID] = food
+
+	return nil
+}
+
+func (r *FoodRepository) PurgeDeletedFoods(ctx context.Context, before time.Time) (int64, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var purged int64
+	for id, food := range r.store.foods {
+		if !food.DeletedAt.Valid || !food.DeletedAt.Time.Before(before) || r.store.foodOrdered(id) {
+			continue
+		}
+		r.store.deleteFood(id)
+		if food.PhotoID != 0 {
+			r.store.deletePhoto(food.PhotoID)
+		}
+		purged++
+	}
+
+	return purged, nil
+}
@@ -0,0 +1,237 @@
+package memory
+
+import (
+	"context"
+	"github.com/alibekabdrakhman1/orynal/internal/model"
+	"github.com/alibekabdrakhman1/orynal/pkg/errs"
+)
+
+type OrderRepository struct {
+	store *Store
+}
+
+func NewOrderRepository(store *Store) *OrderRepository {
+	return &OrderRepository{store: store}
+}
+
+func (r *OrderRepository) CreateOrder(ctx context.Context, order *model.Order) (*model.OrderResponse, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.restaurants[order.RestaurantID]; !ok {
+		return nil, errs.ErrReferenceViolation
+	}
+	if _, ok := r.store.tables[order.TableID]; !ok {
+		return nil, errs.ErrReferenceViolation
+	}
+	if _, ok := r.store.users[order.UserID]; !ok {
+		return nil, errs.ErrReferenceViolation
+	}
+	for _, foodID := range order.OrderFoods {
+		if _, ok := r.store.foods[foodID]; !ok {
+			return nil, errs.ErrReferenceViolation
+		}
+	}
+
+	order.ID = r.store.next("orders")
+	order.Version = 1
+	row := *order
+	row.OrderFoods = nil
+	r.store.orders[order.ID] = row
+
+	for _, foodID := range order.OrderFoods {
+		id := r.store.next("order_foods")
+		r.store.orderFoods[id] = model.OrderFood{ID: id, OrderID: order.ID, FoodID: foodID}
+	}
+
+	response := r.withRelations(toOrderResponse(row))
+	for _, foodID := range order.OrderFoods {
+		food := r.store.foods[foodID]
+		if food.DeletedAt.Valid {
+			continue
+		}
+		response.OrderFoods = append(response.OrderFoods, model.OrderFoodResponse{
+			FoodID: foodID,
+			Food:   food,
+		})
+	}
+
+	return &response, nil
+}
+
+func (r *OrderRepository) DeleteOrder(ctx context.Context, id uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.store.deleteOrder(id)
+
+	return nil
+}
+
+func (r *OrderRepository) UpdateOrder(ctx context.Context, order *model.Order) (*model.OrderResponse, error) {
+	r.store.mu.Lock()
+
+	existing, ok := r.store.orders[order.ID]
+	if !ok {
+		r.store.mu.Unlock()
+		return nil, errs.ErrOrderNotFound
+	}
+	if order.Version != 0 && order.Version != existing.Version {
+		r.store.mu.Unlock()
+		return nil, errs.ErrVersionMismatch
+	}
+	existing.Version++
+
+	// Updates with a struct only writes non-zero fields.
+	if order.RestaurantID != 0 {
+		existing.RestaurantID = order.RestaurantID
+	}
+	if order.TotalSum != 0 {
+		existing.TotalSum = order.TotalSum
+	}
+	if order.UserID != 0 {
+		existing.UserID = order.UserID
+	}
+	if order.TableID != 0 {
+		existing.TableID = order.TableID
+	}
+	if !order.Date.IsZero() {
+		existing.Date = order.Date
+	}
+	if order.Status != "" {
+		existing.Status = order.Status
+	}
+	r.store.orders[order.ID] = existing
+
+	r.store.mu.Unlock()
+
+	return r.GetOrder(ctx, order.ID)
+}
+
+func (r *OrderRepository) PatchOrder(ctx context.Context, order *model.Order, fields []string) (*model.OrderResponse, error) {
+	r.store.mu.Lock()
+
+	existing, ok := r.store.orders[order.ID]
+	if !ok {
+		r.store.mu.Unlock()
+		return nil, errs.ErrOrderNotFound
+	}
+	if _, ok := r.store.tables[order.TableID]; !ok {
+		r.store.mu.Unlock()
+		return nil, errs.ErrReferenceViolation
+	}
+	if order.Version != existing.Version {
+		r.store.mu.Unlock()
+		return nil, errs.ErrVersionMismatch
+	}
+	assign(&existing, order, fields)
+	existing.Version++
+	r.store.orders[order.ID] = existing
+
+	r.store.mu.Unlock()
+
+	return r.GetOrder(ctx, order.ID)
+}
+
+// GetOrder returns the order with the restaurant, its icon and services, the
+// table, the user and every dish ordered, including dishes removed from the
+// menu since.
+func (r *OrderRepository) GetOrder(ctx context.Context, id uint) (*model.OrderResponse, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	order, ok := r.store.orders[id]
+	if !ok {
+		return nil, errs.ErrOrderNotFound
+	}
+
+	response := r.withRelations(toOrderResponse(order))
+	response.Restaurant.Icon = r.store.photos[response.Restaurant.IconID]
+
+	user, ok := r.store.userResponse(order.UserID)
+	if !ok {
+		return nil, errs.ErrOrderNotFound
+	}
+	response.User = user
+
+	for _, link := range sortedByID(r.store.orderFoods) {
+		if link.OrderID != id {
+			continue
+		}
+		food, ok := r.store.foods[link.FoodID]
+		if !ok {
+			continue
+		}
+		food.Photo = r.store.photos[food.PhotoID]
+		response.Foods = append(response.Foods, food)
+	}
+
+	return &response, nil
+}
+
+func (r *OrderRepository) GetAllOrders(ctx context.Context, userID uint, params *model.Params) (*model.ListResponse, error) {
+	return r.list(params, func(order model.Order) bool { return order.UserID == userID })
+}
+
+func (r *OrderRepository) GetRestaurantOrders(ctx context.Context, restaurantID uint, params *model.Params) (*model.ListResponse, error) {
+	return r.list(params, func(order model.Order) bool { return order.RestaurantID == restaurantID })
+}
+
+func (r *OrderRepository) list(params *model.Params, match func(model.Order) bool) (*model.ListResponse, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var orders []model.OrderResponse
+	for _, order := range sortedByID(r.store.orders) {
+		if match(order) {
+			orders = append(orders, toOrderResponse(order))
+		}
+	}
+
+	orders, err := filter(orders, params)
+	if err != nil {
+		return nil, err
+	}
+
+	paged, next, prev, err := page(orders, params)
+	if err != nil {
+		return nil, err
+	}
+	for i := range paged {
+		paged[i] = r.withRelations(paged[i])
+	}
+
+	return &model.ListResponse{
+		Items:        paged,
+		ItemsPerPage: params.Limit,
+		PageIndex:    params.PageIndex,
+		TotalItems:   len(orders),
+		NextCursor:   next,
+		PrevCursor:   prev,
+	}, nil
+}
+
+// withRelations attaches the restaurant, deleted or not, with its services
+// and the table. The caller holds the lock.
+func (r *OrderRepository) withRelations(order model.OrderResponse) model.OrderResponse {
+	order.Restaurant = r.store.restaurants[order.RestaurantID]
+	if order.Restaurant.ID != 0 {
+		order.Restaurant.Services = r.store.restaurantServiceList(order.RestaurantID)
+	}
+	order.Table = r.store.tables[order.TableID]
+
+	return order
+}
+
+func toOrderResponse(order model.Order) model.OrderResponse {
+	return model.OrderResponse{
+		ID:           order.ID,
+		TotalSum:     order.TotalSum,
+		Date:         order.Date,
+		Status:       order.Status,
+		RestaurantID: order.RestaurantID,
+		TableID:      order.TableID,
+		UserID:       order.UserID,
+		Version:      order.Version,
+	}
+}
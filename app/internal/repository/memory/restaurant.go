@@ -0,0 +1,468 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"github.com/alibekabdrakhman1/orynal/internal/model"
+	"github.com/alibekabdrakhman1/orynal/pkg/errs"
+	"slices"
+	"time"
+)
+
+type RestaurantRepository struct {
+	store *Store
+}
+
+func NewRestaurantRepository(store *Store) *RestaurantRepository {
+	return &RestaurantRepository{store: store}
+}
+
+func (r *RestaurantRepository) GetRestaurants(ctx context.Context, params *model.Params) (*model.ListResponse, error) {
+	return r.list(params, func(restaurant model.Restaurant) bool { return true })
+}
+
+func (r *RestaurantRepository) GetRestaurantsByOwner(ctx context.Context, ownerID uint, params *model.Params) (*model.ListResponse, error) {
+	return r.list(params, func(restaurant model.Restaurant) bool { return restaurant.OwnerID == ownerID })
+}
+
+func (r *RestaurantRepository) GetStatistics(ctx context.Context) (*model.Statistics, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var countRestaurants int64
+	for _, restaurant := range r.store.restaurants {
+		if !restaurant.DeletedAt.Valid {
+			countRestaurants++
+		}
+	}
+	countOrders := int64(len(r.store.orders))
+
+	return &model.Statistics{
+		OrderCount:       countOrders,
+		PeopleCount:      countOrders * 5,
+		RestaurantsCount: countRestaurants,
+	}, nil
+}
+
+func (r *RestaurantRepository) GetRestaurantByID(ctx context.Context, id uint) (*model.Restaurant, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	restaurant, ok := r.store.restaurants[id]
+	if !ok || restaurant.DeletedAt.Valid {
+		return nil, errs.ErrRestaurantNotFound
+	}
+
+	restaurant = r.withRelations(restaurant, true)
+	return &restaurant, nil
+}
+
+func (r *RestaurantRepository) GetFavoriteRestaurants(ctx context.Context, userID uint, params *model.Params) (*model.ListResponse, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var restaurants []model.Restaurant
+	for _, f := range sortedByID(r.store.favorites) {
+		restaurant, ok := r.store.restaurants[f.RestaurantID]
+		if f.UserID != userID || !ok || restaurant.DeletedAt.Valid {
+			continue
+		}
+		restaurants = append(restaurants, restaurant)
+	}
+	slices.SortFunc(restaurants, func(a, b model.Restaurant) int { return cmp.Compare(a.ID, b.ID) })
+
+	restaurants, err := filter(restaurants, params)
+	if err != nil {
+		return nil, err
+	}
+	restaurants = near(restaurants, params)
+
+	paged, next, prev, err := page(restaurants, params)
+	if err != nil {
+		return nil, err
+	}
+	for i := range paged {
+		paged[i].Icon = r.store.photos[paged[i].IconID]
+	}
+
+	return &model.ListResponse{
+		Items:        paged,
+		ItemsPerPage: params.Limit,
+		PageIndex:    params.PageIndex,
+		TotalItems:   len(restaurants),
+		NextCursor:   next,
+		PrevCursor:   prev,
+	}, nil
+}
+
+// GetPopularRestaurants ranks restaurants by their number of orders,
+// deleted orders included; restaurants without orders are not listed.
+func (r *RestaurantRepository) GetPopularRestaurants(ctx context.Context) (*model.ListResponse, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	orderCount := map[uint]int{}
+	for _, order := range r.store.orders {
+		orderCount[order.RestaurantID]++
+	}
+
+	var restaurants []model.Restaurant
+	for id := range orderCount {
+		restaurant, ok := r.store.restaurants[id]
+		if ok && !restaurant.DeletedAt.Valid {
+			restaurants = append(restaurants, restaurant)
+		}
+	}
+	slices.SortFunc(restaurants, func(a, b model.Restaurant) int {
+		if c := cmp.Compare(orderCount[b.ID], orderCount[a.ID]); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+	if len(restaurants) > 10 {
+		restaurants = restaurants[:10]
+	}
+
+	for i := range restaurants {
+		restaurants[i] = r.withRelations(restaurants[i], true)
+	}
+
+	return &model.ListResponse{
+		Items:        restaurants,
+		ItemsPerPage: 10,
+		TotalPages:   1,
+		PageIndex:    1,
+		TotalItems:   10,
+	}, nil
+}
+
+func (r *RestaurantRepository) CreateRestaurant(ctx context.Context, restaurant *model.Restaurant) (*model.Restaurant, error) {
+	r.store.mu.Lock()
+
+	if _, ok := r.store.users[restaurant.OwnerID]; !ok {
+		r.store.mu.Unlock()
+		return nil, errs.ErrReferenceViolation
+	}
+	for _, service := range restaurant.Services {
+		if _, ok := r.store.services[service.ID]; !ok {
+			r.store.mu.Unlock()
+			return nil, errs.ErrReferenceViolation
+		}
+	}
+
+	if restaurant.Icon.Route != "" {
+		restaurant.IconID = r.store.createPhoto(restaurant.Icon.Route).ID
+	}
+
+	created := model.Restaurant{
+		ID:          r.store.next("restaurants"),
+		Name:        restaurant.Name,
+		Address:     restaurant.Address,
+		Description: restaurant.Description,
+		City:        restaurant.City,
+		Latitude:    restaurant.Latitude,
+		Longitude:   restaurant.Longitude,
+		Status:      restaurant.Status,
+		OwnerID:     restaurant.OwnerID,
+		Phone:       restaurant.Phone,
+		ModeFrom:    restaurant.ModeFrom,
+		ModeTo:      restaurant.ModeTo,
+		IconID:      restaurant.IconID,
+		Version:     1,
+	}
+	r.store.restaurants[created.ID] = created
+
+	for _, service := range restaurant.Services {
+		r.linkService(created.ID, service.ID)
+	}
+	for _, photo := range restaurant.Photos {
+		r.linkPhoto(created.ID, r.store.createPhoto(photo.Route).ID)
+	}
+
+	r.store.mu.Unlock()
+
+	return r.GetRestaurantByID(ctx, created.ID)
+}
+
+func (r *RestaurantRepository) DeleteRestaurant(ctx context.Context, restaurantID uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if restaurant, ok := r.store.restaurants[restaurantID]; ok && !restaurant.DeletedAt.Valid {
+		restaurant.DeletedAt = deletedAt(time.Now())
+		r.store.restaurants[restaurantID] = restaurant
+	}
+
+	return nil
+}
+
+func (r *RestaurantRepository) RestoreRestaurant(ctx context.Context, restaurantID uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	restaurant, ok := r.store.restaurants[restaurantID]
+	if !ok || !restaurant.DeletedAt.Valid {
+		return errs.ErrRestaurantNotFound
+	}
+
+	restaurant.DeletedAt.Valid = false
+	r.store.restaurants[restaurantID] = restaurant
+
+	return nil
+}
+
+func (r *RestaurantRepository) PurgeDeletedRestaurants(ctx context.Context, before time.Time) (int64, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var purged int64
+	for id, restaurant := range r.store.restaurants {
+		if restaurant.DeletedAt.Valid && restaurant.DeletedAt.Time.Before(before) && !r.store.restaurantOrdered(id) {
+			r.store.deleteRestaurant(id)
+			purged++
+		}
+	}
+
+	return purged, nil
+}
+
+func (r *RestaurantRepository) UpdateRestaurant(ctx context.Context, restaurantID uint, restaurant *model.Restaurant) (*model.Restaurant, error) {
+	r.store.mu.Lock()
+
+	existing, ok := r.store.restaurants[restaurantID]
+	if !ok || existing.DeletedAt.Valid {
+		r.store.mu.Unlock()
+		return nil, errs.ErrRestaurantNotFound
+	}
+	if restaurant.OwnerID != 0 {
+		if _, ok := r.store.users[restaurant.OwnerID]; !ok {
+			r.store.mu.Unlock()
+			return nil, errs.ErrReferenceViolation
+		}
+	}
+	if restaurant.Version != 0 && restaurant.Version != existing.Version {
+		r.store.mu.Unlock()
+		return nil, errs.ErrVersionMismatch
+	}
+	existing.Version++
+	restaurant.Version = existing.Version
+
+	// Updates with a struct only writes non-zero fields.
+	if restaurant.Name != "" {
+		existing.Name = restaurant.Name
+	}
+	if restaurant.Address != "" {
+		existing.Address = restaurant.Address
+	}
+	if restaurant.Description != "" {
+		existing.Description = restaurant.Description
+	}
+	if restaurant.City != "" {
+		existing.City = restaurant.City
+	}
+	// Coordinates are written as a pair even when nil.
+	existing.Latitude, existing.Longitude = restaurant.Latitude, restaurant.Longitude
+	if restaurant.Status {
+		existing.Status = true
+	}
+	if restaurant.Phone != "" {
+		existing.Phone = restaurant.Phone
+	}
+	if restaurant.OwnerID != 0 {
+		existing.OwnerID = restaurant.OwnerID
+	}
+	if !restaurant.ModeFrom.IsZero() {
+		existing.ModeFrom = restaurant.ModeFrom
+	}
+	if !restaurant.ModeTo.IsZero() {
+		existing.ModeTo = restaurant.ModeTo
+	}
+	if restaurant.IconID != 0 {
+		existing.IconID = restaurant.IconID
+	}
+	r.store.restaurants[restaurantID] = existing
+
+	r.store.mu.Unlock()
+
+	if err := r.UpdateRestaurantPhotos(ctx, restaurantID, restaurant.Photos); err != nil {
+		return nil, err
+	}
+	if err := r.UpdateRestaurantServices(ctx, restaurantID, restaurant.Services); err != nil {
+		return nil, err
+	}
+
+	return r.GetRestaurantByID(ctx, restaurantID)
+}
+
+func (r *RestaurantRepository) PatchRestaurant(ctx context.Context, restaurant *model.Restaurant, fields []string) (*model.Restaurant, error) {
+	r.store.mu.Lock()
+
+	existing, ok := r.store.restaurants[restaurant.ID]
+	if !ok || existing.DeletedAt.Valid {
+		r.store.mu.Unlock()
+		return nil, errs.ErrRestaurantNotFound
+	}
+	if _, ok := r.store.users[restaurant.OwnerID]; !ok {
+		r.store.mu.Unlock()
+		return nil, errs.ErrReferenceViolation
+	}
+	if restaurant.Version != existing.Version {
+		r.store.mu.Unlock()
+		return nil, errs.ErrVersionMismatch
+	}
+	assign(&existing, restaurant, fields)
+	existing.Version++
+	r.store.restaurants[restaurant.ID] = existing
+
+	r.store.mu.Unlock()
+
+	return r.GetRestaurantByID(ctx, restaurant.ID)
+}
+
+// UpdateRestaurantPhotos replaces the gallery with new photo rows and drops
+// the old photos that no restaurant links to anymore.
+func (r *RestaurantRepository) UpdateRestaurantPhotos(ctx context.Context, restaurantID uint, photos []model.Photo) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.restaurants[restaurantID]; !ok && len(photos) > 0 {
+		return errs.ErrReferenceViolation
+	}
+
+	var oldPhotoIDs []uint
+	for linkID, link := range r.store.restaurantPhotos {
+		if link.RestaurantID == restaurantID {
+			oldPhotoIDs = append(oldPhotoIDs, link.PhotoID)
+			delete(r.store.restaurantPhotos, linkID)
+		}
+	}
+
+	for _, photo := range photos {
+		r.linkPhoto(restaurantID, r.store.createPhoto(photo.Route).ID)
+	}
+
+	for _, photoID := range oldPhotoIDs {
+		linked := false
+		for _, link := range r.store.restaurantPhotos {
+			if link.PhotoID == photoID {
+				linked = true
+				break
+			}
+		}
+		if !linked {
+			r.store.deletePhoto(photoID)
+		}
+	}
+
+	return nil
+}
+
+func (r *RestaurantRepository) UpdateRestaurantServices(ctx context.Context, restaurantID uint, services []model.Service) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, service := range services {
+		if _, ok := r.store.services[service.ID]; !ok {
+			return errs.ErrReferenceViolation
+		}
+	}
+	if _, ok := r.store.restaurants[restaurantID]; !ok && len(services) > 0 {
+		return errs.ErrReferenceViolation
+	}
+
+	for linkID, link := range r.store.restaurantServices {
+		if link.RestaurantID == restaurantID {
+			delete(r.store.restaurantServices, linkID)
+		}
+	}
+	for _, service := range services {
+		r.linkService(restaurantID, service.ID)
+	}
+
+	return nil
+}
+
+func (r *RestaurantRepository) list(params *model.Params, match func(model.Restaurant) bool) (*model.ListResponse, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var restaurants []model.Restaurant
+	for _, restaurant := range sortedByID(r.store.restaurants) {
+		if restaurant.DeletedAt.Valid || !match(restaurant) {
+			continue
+		}
+		if params.Query != "" && !containsFold(restaurant.Name, params.Query) {
+			continue
+		}
+		restaurants = append(restaurants, restaurant)
+	}
+
+	restaurants, err := filter(restaurants, params)
+	if err != nil {
+		return nil, err
+	}
+	restaurants = near(restaurants, params)
+
+	paged, next, prev, err := page(restaurants, params)
+	if err != nil {
+		return nil, err
+	}
+	for i := range paged {
+		paged[i] = r.withRelations(paged[i], false)
+	}
+
+	return &model.ListResponse{
+		Items:        paged,
+		ItemsPerPage: params.Limit,
+		PageIndex:    params.PageIndex,
+		TotalItems:   len(restaurants),
+		NextCursor:   next,
+		PrevCursor:   prev,
+	}, nil
+}
+
+// withRelations attaches the owner, icon and services, and the gallery when
+// withPhotos is set. The caller holds the lock.
+func (r *RestaurantRepository) withRelations(restaurant model.Restaurant, withPhotos bool) model.Restaurant {
+	restaurant.Owner, _ = r.store.userResponse(restaurant.OwnerID)
+	restaurant.Icon = r.store.photos[restaurant.IconID]
+	restaurant.Services = r.store.restaurantServiceList(restaurant.ID)
+	if withPhotos {
+		restaurant.Photos = r.store.restaurantPhotoList(restaurant.ID)
+	}
+
+	return restaurant
+}
+
+func (r *RestaurantRepository) linkService(restaurantID, serviceID uint) {
+	id := r.store.next("restaurant_service")
+	r.store.restaurantServices[id] = model.RestaurantService{ID: id, ServiceID: serviceID, RestaurantID: restaurantID}
+}
+
+func (r *RestaurantRepository) linkPhoto(restaurantID, photoID uint) {
+	id := r.store.next("restaurant_photos")
+	r.store.restaurantPhotos[id] = model.RestaurantPhoto{ID: id, PhotoID: photoID, RestaurantID: restaurantID}
+}
+
+// near keeps the restaurants within params.Radius of params.Near, nearest
+// first, with their distance.
+func near(restaurants []model.Restaurant, params *model.Params) []model.Restaurant {
+	if params.Near == nil {
+		return restaurants
+	}
+
+	var kept []model.Restaurant
+	for _, restaurant := range restaurants {
+		location := restaurant.Location()
+		if location == nil {
+			continue
+		}
+		if distance := model.Distance(*params.Near, *location); distance <= params.Radius {
+			restaurant.DistanceM = &distance
+			kept = append(kept, restaurant)
+		}
+	}
+	slices.SortStableFunc(kept, func(a, b model.Restaurant) int { return cmp.Compare(*a.DistanceM, *b.DistanceM) })
+
+	return kept
+}
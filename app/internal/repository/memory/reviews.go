@@ -0,0 +1,118 @@
+package memory
+
+import (
+	"context"
+	"github.com/alibekabdrakhman1/orynal/internal/model"
+	"github.com/alibekabdrakhman1/orynal/pkg/errs"
+	"slices"
+	"time"
+)
+
+type ReviewsRepository struct {
+	store *Store
+}
+
+func NewReviewsRepository(store *Store) *ReviewsRepository {
+	return &ReviewsRepository{store: store}
+}
+
+func (r *ReviewsRepository) GetReviews(ctx context.Context, restaurantID uint, params *model.Params) (*model.ListResponse, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var reviews []model.RestaurantReview
+	for _, review := range sortedByID(r.store.reviews) {
+		if review.RestaurantID == restaurantID {
+			reviews = append(reviews, review)
+		}
+	}
+
+	reviews, err := filter(reviews, params)
+	if err != nil {
+		return nil, err
+	}
+
+	paged, next, prev, err := page(reviews, params)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*model.RestaurantReview, 0, len(paged))
+	for _, review := range paged {
+		review.User, _ = r.store.userResponse(review.UserID)
+		items = append(items, &review)
+	}
+
+	return &model.ListResponse{
+		Items:        items,
+		ItemsPerPage: params.Limit,
+		PageIndex:    params.PageIndex,
+		TotalItems:   len(reviews),
+		NextCursor:   next,
+		PrevCursor:   prev,
+	}, nil
+}
+
+func (r *ReviewsRepository) CreateReview(ctx context.Context, review *model.RestaurantReview) (*model.RestaurantReview, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	user, ok := r.store.userResponse(review.UserID)
+	if !ok {
+		return nil, errs.ErrReferenceViolation
+	}
+	if _, ok := r.store.restaurants[review.RestaurantID]; !ok {
+		return nil, errs.ErrReferenceViolation
+	}
+
+	if review.Date.IsZero() {
+		review.Date = time.Now()
+	}
+	review.ID = r.store.next("restaurant_reviews")
+	review.User = model.UserResponse{}
+	r.store.reviews[review.ID] = *review
+
+	review.User = user
+
+	return review, nil
+}
+
+func (r *ReviewsRepository) DeleteReview(ctx context.Context, id uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	delete(r.store.reviews, id)
+
+	return nil
+}
+
+func (r *ReviewsRepository) GetReview(ctx context.Context, id uint) (*model.RestaurantReview, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	review, ok := r.store.reviews[id]
+	if !ok {
+		return nil, errs.ErrReviewNotFound
+	}
+
+	if review.User, ok = r.store.userResponse(review.UserID); !ok {
+		return nil, errs.ErrReviewNotFound
+	}
+
+	return &review, nil
+}
+
+func (r *ReviewsRepository) GetUserReviews(ctx context.Context, userID uint) ([]model.RestaurantReview, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var reviews []model.RestaurantReview
+	for _, review := range sortedByID(r.store.reviews) {
+		if review.UserID == userID {
+			reviews = append(reviews, review)
+		}
+	}
+	slices.SortStableFunc(reviews, func(a, b model.RestaurantReview) int { return b.Date.Compare(a.Date) })
+
+	return reviews, nil
+}
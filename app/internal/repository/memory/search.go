@@ -0,0 +1,61 @@
+package memory
+
+import (
+	"context"
+	"github.com/alibekabdrakhman1/orynal/internal/model"
+)
+
+type SearchRepository struct {
+	store *Store
+}
+
+func NewSearchRepository(store *Store) *SearchRepository {
+	return &SearchRepository{store: store}
+}
+
+// SearchRestaurants matches substrings like the SQLite fallback of the
+// postgre package.
+func (r *SearchRepository) SearchRestaurants(ctx context.Context, params *model.Params) (*model.ListResponse, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var restaurants []model.Restaurant
+	for _, restaurant := range sortedByID(r.store.restaurants) {
+		if !restaurant.DeletedAt.Valid {
+			restaurant.Icon = r.store.photos[restaurant.IconID]
+			restaurants = append(restaurants, restaurant)
+		}
+	}
+
+	dishes := map[uint][]model.Food{}
+	for _, food := range sortedByID(r.store.foods) {
+		if !food.DeletedAt.Valid {
+			dishes[food.RestaurantID] = append(dishes[food.RestaurantID], food)
+		}
+	}
+
+	return model.SubstringSearch(restaurants, dishes, params), nil
+}
+
+func (r *SearchRepository) SearchDishes(ctx context.Context, search *model.DishSearch) (*model.ListResponse, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	restaurants := map[uint]model.Restaurant{}
+	for id, restaurant := range r.store.restaurants {
+		if !restaurant.DeletedAt.Valid {
+			restaurant.Icon = r.store.photos[restaurant.IconID]
+			restaurants[id] = restaurant
+		}
+	}
+
+	var dishes []model.Food
+	for _, food := range sortedByID(r.store.foods) {
+		if !food.DeletedAt.Valid {
+			food.Photo = r.store.photos[food.PhotoID]
+			dishes = append(dishes, food)
+		}
+	}
+
+	return model.GroupDishes(dishes, restaurants, search), nil
+}
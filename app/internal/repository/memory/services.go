@@ -0,0 +1,76 @@
+package memory
+
+import (
+	"context"
+	"github.com/alibekabdrakhman1/orynal/internal/model"
+	"github.com/alibekabdrakhman1/orynal/pkg/errs"
+)
+
+type ServicesRepository struct {
+	store *Store
+}
+
+func NewServicesRepository(store *Store) *ServicesRepository {
+	return &ServicesRepository{store: store}
+}
+
+func (r *ServicesRepository) CreateService(ctx context.Context, service *model.Service) ([]model.Service, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if r.nameTaken(service.ID, service.Name) {
+		return nil, errs.ErrAlreadyExists
+	}
+
+	service.ID = r.store.next("services")
+	r.store.services[service.ID] = *service
+
+	return sortedByID(r.store.services), nil
+}
+
+func (r *ServicesRepository) DeleteService(ctx context.Context, id uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	delete(r.store.services, id)
+	for linkID, link := range r.store.restaurantServices {
+		if link.ServiceID == id {
+			delete(r.store.restaurantServices, linkID)
+		}
+	}
+
+	return nil
+}
+
+func (r *ServicesRepository) GetServices(ctx context.Context) ([]model.Service, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return sortedByID(r.store.services), nil
+}
+
+func (r *ServicesRepository) UpdateService(ctx context.Context, service *model.Service) ([]model.Service, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if existing, ok := r.store.services[service.ID]; ok && service.Name != "" {
+		if r.nameTaken(service.ID, service.Name) {
+			return nil, errs.ErrAlreadyExists
+		}
+		existing.Name = service.Name
+		r.store.services[service.ID] = existing
+	}
+
+	return sortedByID(r.store.services), nil
+}
+
+// nameTaken enforces the unique name column. The caller holds the lock.
+func (r *ServicesRepository) nameTaken(id uint, name string) bool {
+	for _, other := range r.store.services {
+		if other.ID != id && other.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,522 @@
+// Package memory implements the repository interfaces on top of in-process
+// maps. It follows the semantics of the postgre package that services rely
+// on: soft deletes, not found and conflict errors, offset and cursor
+// pagination and the cascades declared in the schema, so service tests run
+// without a database.
+package memory
+
+import (
+	"cmp"
+	"fmt"
+	"github.com/alibekabdrakhman1/orynal/internal/model"
+	"github.com/alibekabdrakhman1/orynal/pkg/errs"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Store holds the rows shared by the repositories of one Manager. Rows are
+// kept by value without their relations, which are attached on read the way
+// the SQL joins do.
+type Store struct {
+	mu sync.RWMutex
+	// tx serializes transactions, see Transaction.
+	tx sync.Mutex
+
+	users       map[uint]model.User
+	tokens      map[uint]model.UserToken
+	photos      map[uint]model.Photo
+	restaurants map[uint]model.Restaurant
+	services    map[uint]model.Service
+	tables      map[uint]model.Table
+	foods       map[uint]model.Food
+	orders      map[uint]model.Order
+	reviews     map[uint]model.RestaurantReview
+	exports     map[uint]model.DataExport
+
+	// Join tables, keyed by their own id like the SQL ones.
+	restaurantPhotos   map[uint]model.RestaurantPhoto
+	restaurantServices map[uint]model.RestaurantService
+	orderFoods         map[uint]model.OrderFood
+	favorites          map[uint]favorite
+
+	seq map[string]uint
+}
+
+type favorite struct {
+	UserID       uint
+	RestaurantID uint
+}
+
+func NewStore() *Store {
+	return &Store{
+		users:              map[uint]model.User{},
+		tokens:             map[uint]model.UserToken{},
+		photos:             map[uint]model.Photo{},
+		restaurants:        map[uint]model.Restaurant{},
+		services:           map[uint]model.Service{},
+		tables:             map[uint]model.Table{},
+		foods:              map[uint]model.Food{},
+		orders:             map[uint]model.Order{},
+		reviews:            map[uint]model.RestaurantReview{},
+		exports:            map[uint]model.DataExport{},
+		restaurantPhotos:   map[uint]model.RestaurantPhoto{},
+		restaurantServices: map[uint]model.RestaurantService{},
+		orderFoods:         map[uint]model.OrderFood{},
+		favorites:          map[uint]favorite{},
+		seq:                map[string]uint{},
+	}
+}
+
+// AddFavorite marks a restaurant as a favorite of the user. The API has no
+// endpoint for it yet, so tests seed favorites directly.
+func (s *Store) AddFavorite(userID, restaurantID uint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[userID]; !ok {
+		return errs.ErrReferenceViolation
+	}
+	if _, ok := s.restaurants[restaurantID]; !ok {
+		return errs.ErrReferenceViolation
+	}
+
+	s.favorites[s.next("favorite_restaurants")] = favorite{UserID: userID, RestaurantID: restaurantID}
+
+	return nil
+}
+
+// Transaction runs fn and, when it fails, restores every row to what it was
+// before fn started. Transactions run one at a time, and a rollback also
+// discards writes made concurrently outside of one.
+func (s *Store) Transaction(fn func() error) error {
+	s.tx.Lock()
+	defer s.tx.Unlock()
+
+	s.mu.RLock()
+	saved := s.snapshot()
+	s.mu.RUnlock()
+
+	if err := fn(); err != nil {
+		s.mu.Lock()
+		s.restore(saved)
+		s.mu.Unlock()
+		return err
+	}
+
+	return nil
+}
+
+// snapshot copies the rows of s. The caller holds the lock.
+func (s *Store) snapshot() *Store {
+	return &Store{
+		users:              maps.Clone(s.users),
+		tokens:             maps.Clone(s.tokens),
+		photos:             maps.Clone(s.photos),
+		restaurants:        maps.Clone(s.restaurants),
+		services:           maps.Clone(s.services),
+		tables:             maps.Clone(s.tables),
+		foods:              maps.Clone(s.foods),
+		orders:             maps.Clone(s.orders),
+		reviews:            maps.Clone(s.reviews),
+		exports:            maps.Clone(s.exports),
+		restaurantPhotos:   maps.Clone(s.restaurantPhotos),
+		restaurantServices: maps.Clone(s.restaurantServices),
+		orderFoods:         maps.Clone(s.orderFoods),
+		favorites:          maps.Clone(s.favorites),
+	}
+}
+
+// restore puts back the rows of a snapshot. Like SQL sequences, ids handed
+// out since are not reused. The caller holds the lock.
+func (s *Store) restore(saved *Store) {
+	s.users = saved.users
+	s.tokens = saved.tokens
+	s.photos = saved.photos
+	s.restaurants = saved.restaurants
+	s.services = saved.services
+	s.tables = saved.tables
+	s.foods = saved.foods
+	s.orders = saved.orders
+	s.reviews = saved.reviews
+	s.exports = saved.exports
+	s.restaurantPhotos = saved.restaurantPhotos
+	s.restaurantServices = saved.restaurantServices
+	s.orderFoods = saved.orderFoods
+	s.favorites = saved.favorites
+}
+
+func (s *Store) next(table string) uint {
+	s.seq[table]++
+	return s.seq[table]
+}
+
+func (s *Store) createPhoto(route string) model.Photo {
+	photo := model.Photo{ID: s.next("photos"), Route: route}
+	s.photos[photo.ID] = photo
+	return photo
+}
+
+// deletePhoto removes a photo and applies its ON DELETE rules: gallery links
+// go away, icons and dish or table photos are set to NULL.
+func (s *Store) deletePhoto(id uint) {
+	if _, ok := s.photos[id]; !ok {
+		return
+	}
+	delete(s.photos, id)
+
+	for linkID, link := range s.restaurantPhotos {
+		if link.PhotoID == id {
+			delete(s.restaurantPhotos, linkID)
+		}
+	}
+	for restaurantID, restaurant := range s.restaurants {
+		if restaurant.IconID == id {
+			restaurant.IconID = 0
+			s.restaurants[restaurantID] = restaurant
+		}
+	}
+	for foodID, food := range s.foods {
+		if food.PhotoID == id {
+			food.PhotoID = 0
+			s.foods[foodID] = food
+		}
+	}
+	for tableID, table := range s.tables {
+		if table.PhotoID == id {
+			table.PhotoID = 0
+			s.tables[tableID] = table
+		}
+	}
+}
+
+// deleteRestaurant hard deletes a restaurant together with every row that
+// references it ON DELETE CASCADE. Callers check restaurantOrdered first:
+// orders restrict it.
+func (s *Store) deleteRestaurant(id uint) {
+	delete(s.restaurants, id)
+
+	for linkID, link := range s.restaurantPhotos {
+		if link.RestaurantID == id {
+			delete(s.restaurantPhotos, linkID)
+		}
+	}
+	for linkID, link := range s.restaurantServices {
+		if link.RestaurantID == id {
+			delete(s.restaurantServices, linkID)
+		}
+	}
+	for favoriteID, f := range s.favorites {
+		if f.RestaurantID == id {
+			delete(s.favorites, favoriteID)
+		}
+	}
+	for tableID, table := range s.tables {
+		if table.RestaurantID == id {
+			s.deleteTable(tableID)
+		}
+	}
+	for foodID, food := range s.foods {
+		if food.RestaurantID == id {
+			s.deleteFood(foodID)
+		}
+	}
+	for reviewID, review := range s.reviews {
+		if review.RestaurantID == id {
+			delete(s.reviews, reviewID)
+		}
+	}
+}
+
+func (s *Store) deleteTable(id uint) {
+	delete(s.tables, id)
+
+	for orderID, order := range s.orders {
+		if order.TableID == id {
+			s.deleteOrder(orderID)
+		}
+	}
+}
+
+func (s *Store) deleteOrder(id uint) {
+	delete(s.orders, id)
+
+	for linkID, link := range s.orderFoods {
+		if link.OrderID == id {
+			delete(s.orderFoods, linkID)
+		}
+	}
+}
+
+// deleteFood hard deletes a dish. Callers check foodOrdered first: order
+// foods restrict it.
+func (s *Store) deleteFood(id uint) {
+	delete(s.foods, id)
+}
+
+// restaurantOrdered reports whether an order references the restaurant or
+// one of its dishes.
+func (s *Store) restaurantOrdered(id uint) bool {
+	for _, order := range s.orders {
+		if order.RestaurantID == id {
+			return true
+		}
+	}
+	for _, link := range s.orderFoods {
+		if food, ok := s.foods[link.FoodID]; ok && food.RestaurantID == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *Store) foodOrdered(id uint) bool {
+	for _, link := range s.orderFoods {
+		if link.FoodID == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// userResponse reads a user the way the users table is joined: soft deleted
+// and anonymized rows included.
+func (s *Store) userResponse(id uint) (model.UserResponse, bool) {
+	user, ok := s.users[id]
+	if !ok {
+		return model.UserResponse{}, false
+	}
+
+	return toUserResponse(user), true
+}
+
+func (s *Store) restaurantServiceList(restaurantID uint) []model.Service {
+	var services []model.Service
+	for _, link := range sortedByID(s.restaurantServices) {
+		if link.RestaurantID != restaurantID {
+			continue
+		}
+		if service, ok := s.services[link.ServiceID]; ok {
+			services = append(services, service)
+		}
+	}
+	slices.SortFunc(services, func(a, b model.Service) int { return cmp.Compare(a.ID, b.ID) })
+
+	return services
+}
+
+func (s *Store) restaurantPhotoList(restaurantID uint) []model.Photo {
+	var photos []model.Photo
+	for _, link := range sortedByID(s.restaurantPhotos) {
+		if link.RestaurantID != restaurantID {
+			continue
+		}
+		if photo, ok := s.photos[link.PhotoID]; ok {
+			photos = append(photos, photo)
+		}
+	}
+	slices.SortFunc(photos, func(a, b model.Photo) int { return cmp.Compare(a.ID, b.ID) })
+
+	return photos
+}
+
+func toUserResponse(user model.User) model.UserResponse {
+	return model.UserResponse{
+		ID:                  user.ID,
+		Name:                user.Name,
+		Surname:             user.Surname,
+		Email:               user.Email,
+		Phone:               user.Phone,
+		Role:                user.Role,
+		DeletionRequestedAt: user.DeletionRequestedAt,
+	}
+}
+
+func deletedAt(now time.Time) gorm.DeletedAt {
+	return gorm.DeletedAt{Time: now, Valid: true}
+}
+
+// sortedByID returns the values of rows ordered by key, the order a table
+// without ORDER BY is read in practice.
+func sortedByID[T any](rows map[uint]T) []T {
+	ids := make([]uint, 0, len(rows))
+	for id := range rows {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	values := make([]T, 0, len(ids))
+	for _, id := range ids {
+		values = append(values, rows[id])
+	}
+
+	return values
+}
+
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+// page applies the params' ORDER BY, then their cursor or OFFSET, and LIMIT
+// to rows already in id order, and returns the cursors around the page.
+func page[T any](rows []T, params *model.Params) (paged []T, next, prev string, err error) {
+	if params.Sorted() {
+		if err := orderBy(rows, params.Sort); err != nil {
+			return nil, "", "", err
+		}
+	}
+
+	switch {
+	case params.Cursor == nil:
+		rows = rows[min(max(params.Offset, 0), len(rows)):]
+	case params.Cursor.Before:
+		end, _ := slices.BinarySearchFunc(rows, params.Cursor.ID, func(row T, id uint) int { return cmp.Compare(rowID(row), id) })
+		rows = slices.Clone(rows[:end])
+		slices.Reverse(rows)
+	default:
+		start, found := slices.BinarySearchFunc(rows, params.Cursor.ID, func(row T, id uint) int { return cmp.Compare(rowID(row), id) })
+		if found {
+			start++
+		}
+		rows = rows[start:]
+	}
+
+	paged, next, prev = model.KeysetPage(rows[:min(params.Limit+1, len(rows))], params, rowID[T])
+	return paged, next, prev, nil
+}
+
+// rowID returns the ID field of a model.
+func rowID[T any](row T) uint {
+	return uint(reflect.Indirect(reflect.ValueOf(row)).FieldByName("ID").Uint())
+}
+
+// filter keeps the rows that match every filter of params, the way the
+// postgre filter helper's WHERE clauses do.
+func filter[T any](rows []T, params *model.Params) ([]T, error) {
+	if len(params.Filters) == 0 {
+		return rows, nil
+	}
+
+	typ := reflect.TypeOf(rows).Elem()
+	fields := make([]int, len(params.Filters))
+	for i, f := range params.Filters {
+		field, err := fieldByColumn(typ, f.Column)
+		if err != nil {
+			return nil, err
+		}
+		fields[i] = field
+	}
+
+	return slices.DeleteFunc(slices.Clone(rows), func(row T) bool {
+		v := reflect.ValueOf(row)
+		for i, f := range params.Filters {
+			if !matches(v.Field(fields[i]), f) {
+				return true
+			}
+		}
+		return false
+	}), nil
+}
+
+func matches(field reflect.Value, f model.Filter) bool {
+	compare := func(value any) int {
+		return compareValues(field, reflect.ValueOf(value).Convert(field.Type()))
+	}
+
+	switch f.Op {
+	case model.OpIn:
+		return slices.ContainsFunc(f.Value.([]any), func(value any) bool { return compare(value) == 0 })
+	case model.OpGte:
+		return compare(f.Value) >= 0
+	case model.OpLte:
+		return compare(f.Value) <= 0
+	case model.OpLike:
+		return containsFold(field.String(), f.Value.(string))
+	default:
+		return compare(f.Value) == 0
+	}
+}
+
+func fieldByColumn(typ reflect.Type, column string) (int, error) {
+	var naming schema.NamingStrategy
+	for i := 0; i < typ.NumField(); i++ {
+		if naming.ColumnName("", typ.Field(i).Name) == column {
+			return i, nil
+		}
+	}
+
+	return -1, fmt.Errorf("column %q does not exist", column)
+}
+
+// orderBy sorts rows stably by the sort columns, leaving ties in id order.
+func orderBy[T any](rows []T, sorts []model.Sort) error {
+	typ := reflect.TypeOf(rows).Elem()
+	fields := make([]int, len(sorts))
+	for i, sort := range sorts {
+		field, err := fieldByColumn(typ, sort.Column)
+		if err != nil {
+			return err
+		}
+		fields[i] = field
+	}
+
+	slices.SortStableFunc(rows, func(a, b T) int {
+		va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+		for i, field := range fields {
+			c := compareValues(va.Field(field), vb.Field(field))
+			if sorts[i].Desc {
+				c = -c
+			}
+			if c != 0 {
+				return c
+			}
+		}
+		return 0
+	})
+
+	return nil
+}
+
+func compareValues(a, b reflect.Value) int {
+	switch a.Kind() {
+	case reflect.String:
+		return cmp.Compare(a.String(), b.String())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return cmp.Compare(a.Int(), b.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return cmp.Compare(a.Uint(), b.Uint())
+	case reflect.Float32, reflect.Float64:
+		return cmp.Compare(a.Float(), b.Float())
+	case reflect.Bool:
+		return cmp.Compare(boolToInt(a.Bool()), boolToInt(b.Bool()))
+	}
+
+	if ta, ok := a.Interface().(time.Time); ok {
+		return ta.Compare(b.Interface().(time.Time))
+	}
+
+	return 0
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// assign copies the named fields of src to dst, zero values included, like
+// gorm's Select(fields).Updates(src).
+func assign[T any](dst, src *T, fields []string) {
+	d, s := reflect.ValueOf(dst).Elem(), reflect.ValueOf(src).Elem()
+	for _, field := range fields {
+		d.FieldByName(field).Set(s.FieldByName(field))
+	}
+}
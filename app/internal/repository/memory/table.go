@@ -0,0 +1,199 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"github.com/alibekabdrakhman1/orynal/internal/model"
+	"github.com/alibekabdrakhman1/orynal/pkg/errs"
+	"slices"
+	"strings"
+	"time"
+)
+
+type TableRepository struct {
+	store *Store
+}
+
+func NewTableRepository(store *Store) *TableRepository {
+	return &TableRepository{store: store}
+}
+
+func (r *TableRepository) GetTableCategories(ctx context.Context, restaurantID uint) ([]string, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var types []string
+	for _, table := range r.store.tables {
+		if table.RestaurantID == restaurantID && !slices.Contains(types, table.Type) {
+			types = append(types, table.Type)
+		}
+	}
+	slices.Sort(types)
+
+	return types, nil
+}
+
+func (r *TableRepository) GetRestaurantTables(ctx context.Context, restaurantID uint, params *model.Params) (*model.ListResponse, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var tables []model.Table
+	for _, table := range sortedByID(r.store.tables) {
+		if table.RestaurantID != restaurantID {
+			continue
+		}
+		if params.Query != "" && !strings.EqualFold(table.Type, params.Query) {
+			continue
+		}
+		if params.Date != nil && r.reserved(table.ID, *params.Date) {
+			continue
+		}
+		tables = append(tables, table)
+	}
+
+	tables, err := filter(tables, params)
+	if err != nil {
+		return nil, err
+	}
+
+	paged, _, _, err := page(tables, params)
+	if err != nil {
+		return nil, err
+	}
+	for i := range paged {
+		paged[i].Photo = r.store.photos[paged[i].PhotoID]
+	}
+
+	return &model.ListResponse{
+		Items:        paged,
+		ItemsPerPage: params.Limit,
+		PageIndex:    params.PageIndex,
+		TotalItems:   len(tables),
+	}, nil
+}
+
+func (r *TableRepository) GetRestaurantTable(ctx context.Context, restaurantID uint, tableID uint) (*model.Table, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	table, ok := r.store.tables[tableID]
+	if !ok || table.RestaurantID != restaurantID {
+		return nil, errs.ErrTableNotFound
+	}
+	table.Photo = r.store.photos[table.PhotoID]
+
+	return &table, nil
+}
+
+func (r *TableRepository) CreateTable(ctx context.Context, table *model.Table) (*model.Table, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.restaurants[table.RestaurantID]; !ok {
+		return nil, errs.ErrReferenceViolation
+	}
+
+	if table.Photo.Route != "" && table.Photo.ID == 0 {
+		table.Photo = r.store.createPhoto(table.Photo.Route)
+	}
+	if table.Photo.ID != 0 {
+		table.PhotoID = table.Photo.ID
+	}
+
+	table.ID = r.store.next("tables")
+	table.Version = 1
+	row := *table
+	row.Photo = model.Photo{}
+	r.store.tables[table.ID] = row
+
+	return table, nil
+}
+
+func (r *TableRepository) UpdateTable(ctx context.Context, table *model.Table) (*model.Table, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, ok := r.store.tables[table.ID]
+	if !ok {
+		return nil, errs.ErrTableNotFound
+	}
+	if table.Version != 0 && table.Version != existing.Version {
+		return nil, errs.ErrVersionMismatch
+	}
+	existing.Version++
+	table.Version = existing.Version
+
+	// Updates with a struct only writes non-zero fields.
+	if table.Name != "" {
+		existing.Name = table.Name
+	}
+	if table.Type != "" {
+		existing.Type = table.Type
+	}
+	if table.Description != "" {
+		existing.Description = table.Description
+	}
+	if table.Capacity != 0 {
+		existing.Capacity = table.Capacity
+	}
+	if table.PhotoID != 0 {
+		existing.PhotoID = table.PhotoID
+	}
+	if table.RestaurantID != 0 {
+		existing.RestaurantID = table.RestaurantID
+	}
+	r.store.tables[table.ID] = existing
+
+	return table, nil
+}
+
+func (r *TableRepository) PatchTable(ctx context.Context, table *model.Table, fields []string) (*model.Table, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, ok := r.store.tables[table.ID]
+	if !ok {
+		return nil, errs.ErrTableNotFound
+	}
+	if table.Version != existing.Version {
+		return nil, errs.ErrVersionMismatch
+	}
+	assign(&existing, table, fields)
+	existing.Version++
+	table.Version = existing.Version
+	r.store.tables[table.ID] = existing
+
+	return table, nil
+}
+
+func (r *TableRepository) DeleteTable(ctx context.Context, id uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.tables[id]; !ok {
+		return errs.ErrTableNotFound
+	}
+	r.store.deleteTable(id)
+
+	return nil
+}
+
+func (r *TableRepository) GetAvailableTime(ctx context.Context, date time.Time) ([]time.Time, error) {
+	return nil, errors.New("available time is not implemented")
+}
+
+// reserved reports whether the table has an order on the calendar day of
+// date, like the day range join in postgre. The caller holds the lock.
+func (r *TableRepository) reserved(tableID uint, date time.Time) bool {
+	y, m, d := date.Date()
+	for _, order := range r.store.orders {
+		if order.TableID != tableID {
+			continue
+		}
+		if oy, om, od := order.Date.Date(); oy == y && om == m && od == d {
+			return true
+		}
+	}
+
+	return false
+}
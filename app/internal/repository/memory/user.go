@@ -0,0 +1,347 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"github.com/alibekabdrakhman1/orynal/internal/model"
+	"github.com/alibekabdrakhman1/orynal/pkg/enums"
+	"github.com/alibekabdrakhman1/orynal/pkg/errs"
+	"github.com/alibekabdrakhman1/orynal/pkg/utils"
+	"time"
+)
+
+type UserRepository struct {
+	store *Store
+}
+
+func NewUserRepository(store *Store) *UserRepository {
+	return &UserRepository{store: store}
+}
+
+func (r *UserRepository) Create(ctx context.Context, user *model.User) (*model.UserResponse, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if err := r.checkUnique(*user); err != nil {
+		return nil, err
+	}
+
+	user.ID = r.store.next("users")
+	r.store.users[user.ID] = *user
+
+	response := toUserResponse(*user)
+	response.DeletionRequestedAt = nil
+
+	return &response, nil
+}
+
+func (r *UserRepository) Update(ctx context.Context, user *model.User) (*model.UserResponse, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	oldUser, err := r.active(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Role != "" && oldUser.Role != user.Role {
+		return nil, errs.ErrRoleChangeForbidden
+	}
+
+	// Updates with a struct only writes non-zero fields.
+	if user.Name != "" {
+		oldUser.Name = user.Name
+	}
+	if user.Surname != "" {
+		oldUser.Surname = user.Surname
+	}
+	if user.Email != "" {
+		oldUser.Email = user.Email
+	}
+	if user.Phone != "" {
+		oldUser.Phone = user.Phone
+	}
+	if user.Password != "" {
+		oldUser.Password = user.Password
+	}
+	if err := r.checkUnique(oldUser); err != nil {
+		return nil, err
+	}
+	r.store.users[oldUser.ID] = oldUser
+
+	response := toUserResponse(oldUser)
+	response.DeletionRequestedAt = nil
+
+	return &response, nil
+}
+
+func (r *UserRepository) Patch(ctx context.Context, user *model.User, fields []string) (*model.UserResponse, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, err := r.active(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	assign(&existing, user, fields)
+	if err := r.checkUnique(existing); err != nil {
+		return nil, err
+	}
+	r.store.users[existing.ID] = existing
+
+	response := toUserResponse(existing)
+	return &response, nil
+}
+
+func (r *UserRepository) ChangePassword(ctx context.Context, id uint, pass *model.ChangePasswordRequest) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	user, err := r.active(id)
+	if err != nil {
+		return err
+	}
+
+	if utils.CheckPassword(pass.OldPassword, user.Password) != nil {
+		return errs.ErrWrongPassword
+	}
+
+	user.Password = pass.NewPassword
+	r.store.users[id] = user
+
+	return nil
+}
+
+func (r *UserRepository) SetPassword(ctx context.Context, id uint, password string) error {
+	return r.update(id, func(user *model.User) { user.Password = password })
+}
+
+func (r *UserRepository) SetRole(ctx context.Context, id uint, role string) error {
+	return r.update(id, func(user *model.User) { user.Role = role })
+}
+
+func (r *UserRepository) Delete(ctx context.Context, id uint) error {
+	return r.update(id, func(user *model.User) { user.DeletedAt = deletedAt(time.Now()) })
+}
+
+func (r *UserRepository) Disable(ctx context.Context, id uint) error {
+	now := time.Now()
+	return r.update(id, func(user *model.User) {
+		user.DeletedAt = deletedAt(now)
+		user.DisabledAt = &now
+	})
+}
+
+func (r *UserRepository) Restore(ctx context.Context, id uint, role string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	user, ok := r.store.users[id]
+	if !ok || !user.DeletedAt.Valid || user.AnonymizedAt != nil || (role != "" && user.Role != role) {
+		return errs.ErrUserNotFound
+	}
+
+	user.DeletedAt.Valid = false
+	user.DisabledAt = nil
+	r.store.users[id] = user
+
+	return nil
+}
+
+func (r *UserRepository) RequestDeletion(ctx context.Context, id uint, at time.Time) error {
+	return r.update(id, func(user *model.User) { user.DeletionRequestedAt = &at })
+}
+
+func (r *UserRepository) CancelDeletion(ctx context.Context, id uint) error {
+	err := r.update(id, func(user *model.User) { user.DeletionRequestedAt = nil })
+	if err == errs.ErrUserNotFound {
+		return nil
+	}
+
+	return err
+}
+
+func (r *UserRepository) GetDueForAnonymization(ctx context.Context, requestedBefore time.Time, deletedBefore time.Time) ([]uint, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var ids []uint
+	for _, user := range sortedByID(r.store.users) {
+		if user.AnonymizedAt != nil || user.DisabledAt != nil {
+			continue
+		}
+		requested := user.DeletionRequestedAt != nil && user.DeletionRequestedAt.Before(requestedBefore)
+		deleted := user.DeletedAt.Valid && user.DeletedAt.Time.Before(deletedBefore)
+		if requested || deleted {
+			ids = append(ids, user.ID)
+		}
+	}
+
+	return ids, nil
+}
+
+func (r *UserRepository) Anonymize(ctx context.Context, id uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	user, ok := r.store.users[id]
+	if !ok || user.AnonymizedAt != nil {
+		return errs.ErrUserNotFound
+	}
+
+	now := time.Now()
+	user.Name = enums.DeletedUserName
+	user.Surname = ""
+	user.Email = fmt.Sprintf("deleted-%d@%s", id, enums.DeletedUserDomain)
+	user.Phone = fmt.Sprintf("deleted-%d", id)
+	user.Password = ""
+	user.DeletionRequestedAt = nil
+	user.AnonymizedAt = &now
+	if !user.DeletedAt.Valid {
+		user.DeletedAt = deletedAt(now)
+	}
+	r.store.users[id] = user
+
+	for tokenID, token := range r.store.tokens {
+		if token.UserID == id {
+			delete(r.store.tokens, tokenID)
+		}
+	}
+	for favoriteID, f := range r.store.favorites {
+		if f.UserID == id {
+			delete(r.store.favorites, favoriteID)
+		}
+	}
+
+	return nil
+}
+
+func (r *UserRepository) GetByID(ctx context.Context, id uint) (*model.UserResponse, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	user, err := r.active(id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := toUserResponse(user)
+	return &response, nil
+}
+
+func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*model.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, user := range sortedByID(r.store.users) {
+		if user.Email == email && !user.DeletedAt.Valid {
+			return &user, nil
+		}
+	}
+
+	return nil, errs.ErrUserNotFound
+}
+
+func (r *UserRepository) GetAllClients(ctx context.Context, params *model.Params) (*model.ListResponse, error) {
+	return r.listByRole(enums.User, params)
+}
+
+func (r *UserRepository) GetAllOwners(ctx context.Context, params *model.Params) (*model.ListResponse, error) {
+	return r.listByRole(enums.Owner, params)
+}
+
+func (r *UserRepository) List(ctx context.Context, role string) ([]model.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var users []model.User
+	for _, user := range sortedByID(r.store.users) {
+		if role == "" || user.Role == role {
+			users = append(users, user)
+		}
+	}
+
+	return users, nil
+}
+
+func (r *UserRepository) listByRole(role string, params *model.Params) (*model.ListResponse, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var users []model.User
+	for _, user := range sortedByID(r.store.users) {
+		if user.DeletedAt.Valid || user.Role != role {
+			continue
+		}
+		if params.Query != "" && !containsFold(user.Name, params.Query) {
+			continue
+		}
+		users = append(users, user)
+	}
+
+	users, err := filter(users, params)
+	if err != nil {
+		return nil, err
+	}
+
+	paged, next, prev, err := page(users, params)
+	if err != nil {
+		return nil, err
+	}
+
+	var userResponses []model.UserResponse
+	for _, user := range paged {
+		response := toUserResponse(user)
+		response.DeletionRequestedAt = nil
+		userResponses = append(userResponses, response)
+	}
+
+	return &model.ListResponse{
+		Items:        userResponses,
+		ItemsPerPage: params.Limit,
+		PageIndex:    params.PageIndex,
+		TotalItems:   len(users),
+		NextCursor:   next,
+		PrevCursor:   prev,
+	}, nil
+}
+
+// active returns a user that is not soft deleted. The caller holds the lock.
+func (r *UserRepository) active(id uint) (model.User, error) {
+	user, ok := r.store.users[id]
+	if !ok || user.DeletedAt.Valid {
+		return model.User{}, errs.ErrUserNotFound
+	}
+
+	return user, nil
+}
+
+func (r *UserRepository) update(id uint, fn func(user *model.User)) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	user, err := r.active(id)
+	if err != nil {
+		return err
+	}
+
+	fn(&user)
+	r.store.users[id] = user
+
+	return nil
+}
+
+// checkUnique enforces the unique email and phone columns, which cover soft
+// deleted users too.
+func (r *UserRepository) checkUnique(user model.User) error {
+	for _, other := range r.store.users {
+		if other.ID == user.ID {
+			continue
+		}
+		if other.Email == user.Email || other.Phone == user.Phone {
+			return errs.ErrAlreadyExists
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package memory
+
+import (
+	"context"
+	"github.com/alibekabdrakhman1/orynal/internal/model"
+	"github.com/alibekabdrakhman1/orynal/pkg/errs"
+	"time"
+)
+
+type UserTokenRepository struct {
+	store *Store
+}
+
+func NewUserTokenRepository(store *Store) *UserTokenRepository {
+	return &UserTokenRepository{store: store}
+}
+
+func (r *UserTokenRepository) CreateUserToken(ctx context.Context, userToken model.UserToken) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.users[userToken.UserID]; !ok {
+		return errs.ErrReferenceViolation
+	}
+
+	now := time.Now()
+	for id, existing := range r.store.tokens {
+		if existing.UserID == userToken.UserID {
+			existing.AccessToken = userToken.AccessToken
+			existing.RefreshToken = userToken.RefreshToken
+			existing.UpdatedAt = now
+			r.store.tokens[id] = existing
+			return nil
+		}
+	}
+
+	userToken.ID = r.store.next("user_tokens")
+	userToken.CreatedAt = now
+	userToken.UpdatedAt = now
+	r.store.tokens[userToken.ID] = userToken
+
+	return nil
+}
+
+func (r *UserTokenRepository) UpdateUserToken(ctx context.Context, userToken model.UserToken) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if userToken.ID == 0 {
+		userToken.ID = r.store.next("user_tokens")
+	}
+	userToken.UpdatedAt = time.Now()
+	r.store.tokens[userToken.ID] = userToken
+
+	return nil
+}
+
+func (r *UserTokenRepository) GetByUserID(ctx context.Context, userID uint) ([]model.UserToken, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var tokens []model.UserToken
+	for _, token := range sortedByID(r.store.tokens) {
+		if token.UserID == userID {
+			tokens = append(tokens, token)
+		}
+	}
+
+	return tokens, nil
+}
+
+func (r *UserTokenRepository) DeleteByUserID(ctx context.Context, userID uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for id, token := range r.store.tokens {
+		if token.UserID == userID {
+			delete(r.store.tokens, id)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package postgre
+
+import (
+	"context"
+	"github.com/alibekabdrakhman1/orynal/internal/model"
+	"github.com/alibekabdrakhman1/orynal/pkg/enums"
+	"github.com/alibekabdrakhman1/orynal/pkg/errs"
+	"github.com/alibekabdrakhman1/orynal/pkg/tracing"
+	"gorm.io/gorm"
+	"time"
+)
+
+func NewDataExportRepository(db *gorm.DB) *DataExportRepository {
+	return &DataExportRepository{
+		DB: db,
+	}
+}
+
+type DataExportRepository struct {
+	DB *gorm.DB
+}
+
+func (r *DataExportRepository) Create(ctx context.Context, export *model.DataExport) (*model.DataExport, error) {
+	ctx, span := tracing.Start(ctx, "DataExportRepository.Create")
+	defer span.End()
+
+	if err := r.DB.WithContext(ctx).Create(export).Error; err != nil {
+		return nil, wrapError(err, errs.ErrExportNotFound)
+	}
+	return export, nil
+}
+
+func (r *DataExportRepository) Update(ctx context.Context, export *model.DataExport) error {
+	ctx, span := tracing.Start(ctx, "DataExportRepository.Update")
+	defer span.End()
+
+	if err := r.DB.WithContext(ctx).Save(export).Error; err != nil {
+		return wrapError(err, errs.ErrExportNotFound)
+	}
+	return nil
+}
+
+func (r *DataExportRepository) GetByID(ctx context.Context, id uint) (*model.DataExport, error) {
+	ctx, span := tracing.Start(ctx, "DataExportRepository.GetByID")
+	defer span.End()
+
+	var export model.DataExport
+	if err := r.DB.WithContext(ctx).First(&export, id).Error; err != nil {
+		return nil, wrapError(err, errs.ErrExportNotFound)
+	}
+	return &export, nil
+}
+
+// GetPending returns the latest export of the user still being prepared that
+// was requested after since.
+func (r *DataExportRepository) GetPending(ctx context.Context, userID uint, since time.Time) (*model.DataExport, error) {
+	ctx, span := tracing.Start(ctx, "DataExportRepository.GetPending")
+	defer span.End()
+
+	var export model.DataExport
+	if err := r.DB.WithContext(ctx).
+		Where("user_id = ? AND status = ? AND created_at > ?", userID, enums.ExportPending, since).
+		Order("id DESC").
+		First(&export).Error; err != nil {
+		return nil, wrapError(err, errs.ErrExportNotFound)
+	}
+	return &export, nil
+}
+
+func (r *DataExportRepository) GetExpired(ctx context.Context, before time.Time) ([]model.DataExport, error) {
+	ctx, span := tracing.Start(ctx, "DataExportRepository.GetExpired")
+	defer span.End()
+
+	var exports []model.DataExport
+	if err := r.DB.WithContext(ctx).Where("expires_at < ?", before).Find(&exports).Error; err != nil {
+		return nil, wrapError(err, errs.ErrExportNotFound)
+	}
+	return exports, nil
+}
+
+func (r *DataExportRepository) Delete(ctx context.Context, id uint) error {
+	ctx, span := tracing.Start(ctx, "DataExportRepository.Delete")
+	defer span.End()
+
+	if err := r.DB.WithContext(ctx).Delete(&model.DataExport{}, id).Error; err != nil {
+		return wrapError(err, errs.ErrExportNotFound)
+	}
+	return nil
+}
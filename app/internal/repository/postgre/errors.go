@@ -0,0 +1,30 @@
+package postgre
+
+import (
+	"errors"
+	"github.com/alibekabdrakhman1/orynal/pkg/errs"
+	"gorm.io/gorm"
+)
+
+// wrapError converts gorm errors into domain errors. Errors that are already
+// domain errors pass through untouched, anything unknown stays internal.
+func wrapError(err error, notFound *errs.Error) error {
+	if err == nil {
+		return nil
+	}
+
+	if _, ok := errs.As(err); ok {
+		return err
+	}
+
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return notFound.Wrap(err)
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return errs.ErrAlreadyExists.Wrap(err)
+	case errors.Is(err, gorm.ErrForeignKeyViolated):
+		return errs.ErrReferenceViolation.Wrap(err)
+	default:
+		return err
+	}
+}
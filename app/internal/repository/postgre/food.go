@@ -2,10 +2,11 @@ package postgre
 
 import (
 	"context"
-	"errors"
-	"fmt"
 	"github.com/alibekabdrakhman1/orynal/internal/model"
+	"github.com/alibekabdrakhman1/orynal/pkg/errs"
+	"github.com/alibekabdrakhman1/orynal/pkg/tracing"
 	"gorm.io/gorm"
+	"time"
 )
 
 func NewFoodRepository(db *gorm.DB) *FoodRepository {
@@ -19,19 +20,25 @@ type FoodRepository struct {
 }
 
 func (r *FoodRepository) GetMenuCategories(ctx context.Context, restaurantID uint) ([]string, error) {
+	ctx, span := tracing.Start(ctx, "FoodRepository.GetMenuCategories")
+	defer span.End()
+
 	var types []string
 
 	if err := r.DB.WithContext(ctx).Table("foods").
 		Select("DISTINCT type").
-		Where("restaurant_id = ?", restaurantID).
+		Where("restaurant_id = ? AND deleted_at IS NULL", restaurantID).
 		Pluck("type", &types).Error; err != nil {
-		return nil, err
+		return nil, wrapError(err, errs.ErrFoodNotFound)
 	}
 
 	return types, nil
 }
 
 func (r *FoodRepository) GetRestaurantMenu(ctx context.Context, restaurantID uint, params *model.Params) (*model.ListResponse, error) {
+	ctx, span := tracing.Start(ctx, "FoodRepository.GetRestaurantMenu")
+	defer span.End()
+
 	var foods []model.Food
 	var totalItems int64
 
@@ -44,14 +51,9 @@ func (r *FoodRepository) GetRestaurantMenu(ctx context.Context, restaurantID uin
 		countQuery = countQuery.Where("LOWER(type) = LOWER(?)", params.Query)
 	}
 
+	countQuery = filter(countQuery, params, "foods")
 	if err := countQuery.Count(&totalItems).Error; err != nil {
-		return nil, err
-	}
-
-	fmt.Println(totalItems)
-
-	if int64(params.Offset) >= totalItems {
-		return nil, errors.New("offset exceeds total items")
+		return nil, wrapError(err, errs.ErrFoodNotFound)
 	}
 
 	query := r.DB.WithContext(ctx).
@@ -63,27 +65,15 @@ func (r *FoodRepository) GetRestaurantMenu(ctx context.Context, restaurantID uin
 		query = query.Where("LOWER(type) = LOWER(?)", params.Query)
 	}
 
-	query = query.Limit(params.Limit).
-		Offset(params.Offset)
-
-	if params.Order != nil && params.SortVector != nil {
-		query = query.Order(params.Order.(string) + " " + params.SortVector.(string))
-	}
+	query = paginate(filter(query, params, "foods"), params, "foods")
 
 	if err := query.Find(&foods).Error; err != nil {
-		return nil, err
+		return nil, wrapError(err, errs.ErrFoodNotFound)
 	}
+	foods, _, _ = model.KeysetPage(foods, params, func(food model.Food) uint { return food.ID })
 
-	for i := 0; i < len(foods); i++ {
-		if foods[i].PhotoID != 0 {
-			var photo model.Photo
-			if err := r.DB.Table("photos").Where("id = ?", foods[i].PhotoID).First(&photo).Error; err != nil {
-				return nil, err
-			}
-
-			foods[i].Photo = photo
-		}
-
+	if err := loadFoodPhotos(ctx, r.DB, foods); err != nil {
+		return nil, wrapError(err, errs.ErrFoodNotFound)
 	}
 
 	return &model.ListResponse{
@@ -95,52 +85,70 @@ func (r *FoodRepository) GetRestaurantMenu(ctx context.Context, restaurantID uin
 }
 
 func (r *FoodRepository) GetRestaurantFood(ctx context.Context, restaurantID uint, foodID uint) (*model.Food, error) {
+	ctx, span := tracing.Start(ctx, "FoodRepository.GetRestaurantFood")
+	defer span.End()
+
 	var food model.Food
 	if err := r.DB.WithContext(ctx).Where("restaurant_id = ? AND id = ?", restaurantID, foodID).First(&food).Error; err != nil {
-		return nil, err
+		return nil, wrapError(err, errs.ErrFoodNotFound)
 	}
 
-	var photo model.Photo
-	if err := r.DB.Table("photos").Where("id = ?", food.PhotoID).First(&photo).Error; err != nil {
-		return nil, err
+	foods := []model.Food{food}
+	if err := loadFoodPhotos(ctx, r.DB, foods); err != nil {
+		return nil, wrapError(err, errs.ErrFoodNotFound)
 	}
 
-	food.Photo = photo
-
-	return &food, nil
+	return &foods[0], nil
 }
 
 func (r *FoodRepository) CreateRestaurantFood(ctx context.Context, food *model.Food) (*model.Food, error) {
+	ctx, span := tracing.Start(ctx, "FoodRepository.CreateRestaurantFood")
+	defer span.End()
+
+	food.Version = 1
 	if err := r.DB.WithContext(ctx).Create(food).Error; err != nil {
-		return nil, err
+		return nil, wrapError(err, errs.ErrFoodNotFound)
 	}
 	return food, nil
 }
 
 func (r *FoodRepository) UpdateRestaurantFood(ctx context.Context, food *model.Food) (*model.Food, error) {
+	ctx, span := tracing.Start(ctx, "FoodRepository.UpdateRestaurantFood")
+	defer span.End()
+
 	var existingFood model.Food
 	if err := r.DB.WithContext(ctx).First(&existingFood, food.ID).Error; err != nil {
-		return nil, err
+		return nil, wrapError(err, errs.ErrFoodNotFound)
+	}
+
+	if food.Version != 0 && food.Version != existingFood.Version {
+		return nil, errs.ErrVersionMismatch
 	}
+	currentVersion := existingFood.Version
+	food.Version = currentVersion + 1
 
-	if err := r.DB.WithContext(ctx).Model(&existingFood).Updates(food).Error; err != nil {
-		return nil, err
+	result := r.DB.WithContext(ctx).Model(&existingFood).Where("version = ?", currentVersion).Updates(food)
+	if result.Error != nil {
+		return nil, wrapError(result.Error, errs.ErrFoodNotFound)
+	}
+	if result.RowsAffected == 0 {
+		return nil, errs.ErrVersionMismatch
 	}
 
 	if existingFood.PhotoID != food.PhotoID {
 		if existingFood.PhotoID != 0 {
 			if err := r.DeleteFoodPhoto(ctx, existingFood.PhotoID); err != nil {
-				return nil, err
+				return nil, wrapError(err, errs.ErrFoodNotFound)
 			}
 		}
 
 		if food.Photo.ID == 0 {
 			if err := r.DB.WithContext(ctx).Create(&food.Photo).Error; err != nil {
-				return nil, err
+				return nil, wrapError(err, errs.ErrFoodNotFound)
 			}
 		} else {
 			if err := r.DB.WithContext(ctx).Model(&food.Photo).Updates(food.Photo).Error; err != nil {
-				return nil, err
+				return nil, wrapError(err, errs.ErrFoodNotFound)
 			}
 		}
 
@@ -150,28 +158,97 @@ func (r *FoodRepository) UpdateRestaurantFood(ctx context.Context, food *model.F
 	return food, nil
 }
 
+func (r *FoodRepository) PatchRestaurantFood(ctx context.Context, food *model.Food, fields []string) (*model.Food, error) {
+	ctx, span := tracing.Start(ctx, "FoodRepository.PatchRestaurantFood")
+	defer span.End()
+
+	if err := patchVersioned(r.DB.WithContext(ctx), food, &food.Version, fields); err != nil {
+		return nil, wrapError(err, errs.ErrFoodNotFound)
+	}
+
+	return food, nil
+}
+
 func (r *FoodRepository) DeleteRestaurantFood(ctx context.Context, foodID uint) error {
+	ctx, span := tracing.Start(ctx, "FoodRepository.DeleteRestaurantFood")
+	defer span.End()
+
 	var food model.Food
 	if err := r.DB.WithContext(ctx).First(&food, foodID).Error; err != nil {
-		return err
+		return wrapError(err, errs.ErrFoodNotFound)
 	}
 
-	if food.PhotoID != 0 {
-		if err := r.DeleteFoodPhoto(ctx, food.PhotoID); err != nil {
-			return err
-		}
+	if err := r.DB.WithContext(ctx).Delete(&food).Error; err != nil {
+		return wrapError(err, errs.ErrFoodNotFound)
 	}
 
-	if err := r.DB.WithContext(ctx).Delete(&model.Food{}, foodID).Error; err != nil {
-		return err
+	return nil
+}
+
+func (r *FoodRepository) RestoreRestaurantFood(ctx context.Context, restaurantID uint, foodID uint) error {
+	ctx, span := tracing.Start(ctx, "FoodRepository.RestoreRestaurantFood")
+	defer span.End()
+
+	result := r.DB.WithContext(ctx).Unscoped().
+		Model(&model.Food{}).
+		Where("id = ? AND restaurant_id = ? AND deleted_at IS NOT NULL", foodID, restaurantID).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return wrapError(result.Error, errs.ErrFoodNotFound)
+	}
+
+	if result.RowsAffected == 0 {
+		return errs.ErrFoodNotFound
 	}
 
 	return nil
 }
 
+func (r *FoodRepository) PurgeDeletedFoods(ctx context.Context, before time.Time) (int64, error) {
+	ctx, span := tracing.Start(ctx, "FoodRepository.PurgeDeletedFoods")
+	defer span.End()
+
+	// Dishes that were ordered are kept, deleted, for the order history.
+	var foods []model.Food
+	if err := r.DB.WithContext(ctx).Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+		Where("NOT EXISTS (SELECT 1 FROM order_foods WHERE order_foods.food_id = foods.id)").
+		Find(&foods).Error; err != nil {
+		return 0, wrapError(err, errs.ErrFoodNotFound)
+	}
+
+	if len(foods) == 0 {
+		return 0, nil
+	}
+
+	var ids, photoIDs []uint
+	for _, food := range foods {
+		ids = append(ids, food.ID)
+		if food.PhotoID != 0 {
+			photoIDs = append(photoIDs, food.PhotoID)
+		}
+	}
+
+	result := r.DB.WithContext(ctx).Unscoped().Delete(&model.Food{}, ids)
+	if result.Error != nil {
+		return 0, wrapError(result.Error, errs.ErrFoodNotFound)
+	}
+
+	if len(photoIDs) > 0 {
+		if err := r.DB.WithContext(ctx).Delete(&model.Photo{}, photoIDs).Error; err != nil {
+			return 0, wrapError(err, errs.ErrFoodNotFound)
+		}
+	}
+
+	return result.RowsAffected, nil
+}
+
 func (r *FoodRepository) DeleteFoodPhoto(ctx context.Context, photoID uint) error {
+	ctx, span := tracing.Start(ctx, "FoodRepository.DeleteFoodPhoto")
+	defer span.End()
+
 	if err := r.DB.WithContext(ctx).Delete(&model.Photo{}, photoID).Error; err != nil {
-		return err
+		return wrapError(err, errs.ErrFoodNotFound)
 	}
 	return nil
 }
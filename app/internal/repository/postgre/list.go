@@ -0,0 +1,102 @@
+package postgre
+
+import (
+	"github.com/alibekabdrakhman1/orynal/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"math"
+	"strings"
+)
+
+// filter adds the params' filters on columns of table to query. Columns
+// come from a model.ListSpec and values are bound, never spliced into SQL.
+func filter(query *gorm.DB, params *model.Params, table string) *gorm.DB {
+	for _, f := range params.Filters {
+		column := clause.Column{Table: table, Name: f.Column}
+
+		switch f.Op {
+		case model.OpIn:
+			query = query.Where(clause.IN{Column: column, Values: f.Value.([]any)})
+		case model.OpGte:
+			query = query.Where(clause.Gte{Column: column, Value: f.Value})
+		case model.OpLte:
+			query = query.Where(clause.Lte{Column: column, Value: f.Value})
+		case model.OpLike:
+			pattern := "%" + likeEscaper.Replace(strings.ToLower(f.Value.(string))) + "%"
+			query = query.Where(clause.Expr{SQL: `LOWER(?) LIKE ? ESCAPE '\'`, Vars: []any{column, pattern}})
+		default:
+			query = query.Where(clause.Eq{Column: column, Value: f.Value})
+		}
+	}
+
+	return query
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// paginate orders query by the params' columns, then by the id of table so
+// pages are stable, and reads one row past the page, which model.KeysetPage
+// uses to tell whether another page follows. A cursor reads the rows next to
+// its id instead of skipping Offset rows.
+func paginate(query *gorm.DB, params *model.Params, table string) *gorm.DB {
+	id := clause.Column{Table: table, Name: "id"}
+	query = query.Limit(params.Limit + 1)
+
+	switch {
+	case params.Cursor == nil:
+		for _, sort := range params.Sort {
+			query = query.Order(clause.OrderByColumn{Column: clause.Column{Table: table, Name: sort.Column}, Desc: sort.Desc})
+		}
+		return query.Order(clause.OrderByColumn{Column: id}).Offset(params.Offset)
+	case params.Cursor.Before:
+		return query.Where(clause.Lt{Column: id, Value: params.Cursor.ID}).Order(clause.OrderByColumn{Column: id, Desc: true})
+	default:
+		return query.Where(clause.Gt{Column: id, Value: params.Cursor.ID}).Order(clause.OrderByColumn{Column: id})
+	}
+}
+
+// distanceSQL is the haversine distance in meters, as in model.Distance,
+// from the point bound by distanceVars; it is NULL for restaurants without
+// coordinates. Neither PostGIS nor an SQLite extension is needed.
+const distanceSQL = `2 * 6371008.8 * asin(sqrt(
+	power(sin(radians(restaurants.latitude - ?) / 2), 2) +
+	cos(radians(?)) * cos(radians(restaurants.latitude)) * power(sin(radians(restaurants.longitude - ?) / 2), 2)
+))`
+
+func distanceVars(p model.Point) []any {
+	return []any{p.Lat, p.Lat, p.Lng}
+}
+
+// near keeps the restaurants within params.Radius of params.Near. The
+// bounding box around the circle lets idx_restaurants_location skip most
+// rows before the distance is computed; longitude is left unbounded when the
+// circle covers a pole or the antimeridian.
+func near(query *gorm.DB, params *model.Params) *gorm.DB {
+	if params.Near == nil {
+		return query
+	}
+
+	p, r := params.Near, params.Radius/model.EarthRadiusM
+	lat := p.Lat * math.Pi / 180
+	dLat, dLng := r*180/math.Pi, 180.0
+	if math.Abs(lat)+r < math.Pi/2 {
+		dLng = math.Asin(math.Sin(r)/math.Cos(lat)) * 180 / math.Pi
+	}
+
+	query = query.Where("restaurants.latitude BETWEEN ? AND ?", p.Lat-dLat, p.Lat+dLat)
+	if west, east := p.Lng-dLng, p.Lng+dLng; west >= -180 && east <= 180 {
+		query = query.Where("restaurants.longitude BETWEEN ? AND ?", west, east)
+	}
+
+	return query.Where(distanceSQL+" <= ?", append(distanceVars(*p), params.Radius)...)
+}
+
+// byDistance selects the distance_m of the restaurants near params.Near and
+// orders by it, ahead of the id paginate orders by.
+func byDistance(query *gorm.DB, params *model.Params) *gorm.DB {
+	if params.Near == nil {
+		return query
+	}
+
+	return query.Select("restaurants.*, "+distanceSQL+" AS distance_m", distanceVars(*params.Near)...).Order("distance_m")
+}
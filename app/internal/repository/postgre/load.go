@@ -0,0 +1,206 @@
+package postgre
+
+import (
+	"context"
+	"github.com/alibekabdrakhman1/orynal/internal/model"
+	"gorm.io/gorm"
+)
+
+// The loaders below attach relations to a page of rows with one IN (...)
+// query per relation, so the number of round trips does not grow with the
+// page size. Rows whose relation is missing are left with the zero value.
+
+func loadOwners(ctx context.Context, db *gorm.DB, restaurants []model.Restaurant) error {
+	ids := collect(restaurants, func(r model.Restaurant) uint { return r.OwnerID })
+	if len(ids) == 0 {
+		return nil
+	}
+
+	var owners []model.UserResponse
+	if err := db.WithContext(ctx).Table("users").Where("id IN ?", ids).Find(&owners).Error; err != nil {
+		return err
+	}
+
+	byID := index(owners, func(u model.UserResponse) uint { return u.ID })
+	for i := range restaurants {
+		restaurants[i].Owner = byID[restaurants[i].OwnerID]
+	}
+
+	return nil
+}
+
+func loadIcons(ctx context.Context, db *gorm.DB, restaurants []model.Restaurant) error {
+	photos, err := loadPhotos(ctx, db, collect(restaurants, func(r model.Restaurant) uint { return r.IconID }))
+	if err != nil {
+		return err
+	}
+
+	for i := range restaurants {
+		restaurants[i].Icon = photos[restaurants[i].IconID]
+	}
+
+	return nil
+}
+
+type restaurantService struct {
+	RestaurantID uint
+	model.Service
+}
+
+func loadServices(ctx context.Context, db *gorm.DB, restaurants []model.Restaurant) error {
+	ids := collect(restaurants, func(r model.Restaurant) uint { return r.ID })
+	if len(ids) == 0 {
+		return nil
+	}
+
+	var rows []restaurantService
+	if err := db.WithContext(ctx).Table("services").
+		Select("restaurant_service.restaurant_id, services.*").
+		Joins("JOIN restaurant_service ON services.id = restaurant_service.service_id").
+		Where("restaurant_service.restaurant_id IN ?", ids).
+		Order("services.id").
+		Scan(&rows).Error; err != nil {
+		return err
+	}
+
+	byRestaurant := map[uint][]model.Service{}
+	for _, row := range rows {
+		byRestaurant[row.RestaurantID] = append(byRestaurant[row.RestaurantID], row.Service)
+	}
+	for i := range restaurants {
+		restaurants[i].Services = byRestaurant[restaurants[i].ID]
+	}
+
+	return nil
+}
+
+type restaurantPhoto struct {
+	RestaurantID uint
+	model.Photo
+}
+
+func loadGallery(ctx context.Context, db *gorm.DB, restaurants []model.Restaurant) error {
+	ids := collect(restaurants, func(r model.Restaurant) uint { return r.ID })
+	if len(ids) == 0 {
+		return nil
+	}
+
+	var rows []restaurantPhoto
+	if err := db.WithContext(ctx).Table("photos").
+		Select("restaurant_photos.restaurant_id, photos.*").
+		Joins("JOIN restaurant_photos ON photos.id = restaurant_photos.photo_id").
+		Where("restaurant_photos.restaurant_id IN ?", ids).
+		Order("photos.id").
+		Scan(&rows).Error; err != nil {
+		return err
+	}
+
+	byRestaurant := map[uint][]model.Photo{}
+	for _, row := range rows {
+		byRestaurant[row.RestaurantID] = append(byRestaurant[row.RestaurantID], row.Photo)
+	}
+	for i := range restaurants {
+		restaurants[i].Photos = byRestaurant[restaurants[i].ID]
+	}
+
+	return nil
+}
+
+// loadPhotos returns the photos with the given ids keyed by id.
+func loadPhotos(ctx context.Context, db *gorm.DB, ids []uint) (map[uint]model.Photo, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	var photos []model.Photo
+	if err := db.WithContext(ctx).Table("photos").Where("id IN ?", ids).Find(&photos).Error; err != nil {
+		return nil, err
+	}
+
+	return index(photos, func(p model.Photo) uint { return p.ID }), nil
+}
+
+func loadFoodPhotos(ctx context.Context, db *gorm.DB, foods []model.Food) error {
+	photos, err := loadPhotos(ctx, db, collect(foods, func(f model.Food) uint { return f.PhotoID }))
+	if err != nil {
+		return err
+	}
+
+	for i := range foods {
+		foods[i].Photo = photos[foods[i].PhotoID]
+	}
+
+	return nil
+}
+
+func loadTablePhotos(ctx context.Context, db *gorm.DB, tables []model.Table) error {
+	photos, err := loadPhotos(ctx, db, collect(tables, func(t model.Table) uint { return t.PhotoID }))
+	if err != nil {
+		return err
+	}
+
+	for i := range tables {
+		tables[i].Photo = photos[tables[i].PhotoID]
+	}
+
+	return nil
+}
+
+// loadOrderRelations attaches the restaurant with its services and the table
+// to every order. Restaurants are loaded unscoped so history survives their
+// deletion.
+func loadOrderRelations(ctx context.Context, db *gorm.DB, orders []model.OrderResponse) error {
+	restaurantIDs := collect(orders, func(o model.OrderResponse) uint { return o.RestaurantID })
+	if len(restaurantIDs) == 0 {
+		return nil
+	}
+
+	var restaurants []model.Restaurant
+	if err := db.WithContext(ctx).Table("restaurants").Unscoped().Where("id IN ?", restaurantIDs).Find(&restaurants).Error; err != nil {
+		return err
+	}
+	if err := loadServices(ctx, db, restaurants); err != nil {
+		return err
+	}
+
+	var tables []model.Table
+	if tableIDs := collect(orders, func(o model.OrderResponse) uint { return o.TableID }); len(tableIDs) > 0 {
+		if err := db.WithContext(ctx).Table("tables").Where("id IN ?", tableIDs).Find(&tables).Error; err != nil {
+			return err
+		}
+	}
+
+	restaurantsByID := index(restaurants, func(r model.Restaurant) uint { return r.ID })
+	tablesByID := index(tables, func(t model.Table) uint { return t.ID })
+	for i := range orders {
+		orders[i].Restaurant = restaurantsByID[orders[i].RestaurantID]
+		orders[i].Table = tablesByID[orders[i].TableID]
+	}
+
+	return nil
+}
+
+// collect returns the distinct non-zero keys of items in order.
+func collect[T any](items []T, key func(T) uint) []uint {
+	seen := make(map[uint]struct{}, len(items))
+	ids := make([]uint, 0, len(items))
+	for _, item := range items {
+		id := key(item)
+		if _, ok := seen[id]; ok || id == 0 {
+			continue
+		}
+		seen[id] = struct{}{}
+		ids = append(ids, id)
+	}
+
+	return ids
+}
+
+func index[T any](items []T, key func(T) uint) map[uint]T {
+	byKey := make(map[uint]T, len(items))
+	for _, item := range items {
+		byKey[key(item)] = item
+	}
+
+	return byKey
+}
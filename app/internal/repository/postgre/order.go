@@ -3,6 +3,8 @@ package postgre
 import (
 	"context"
 	"github.com/alibekabdrakhman1/orynal/internal/model"
+	"github.com/alibekabdrakhman1/orynal/pkg/errs"
+	"github.com/alibekabdrakhman1/orynal/pkg/tracing"
 	"gorm.io/gorm"
 )
 
@@ -17,198 +19,182 @@ type OrderRepository struct {
 }
 
 func (r *OrderRepository) CreateOrder(ctx context.Context, order *model.Order) (*model.OrderResponse, error) {
-	var orderResponse model.OrderResponse
+	ctx, span := tracing.Start(ctx, "OrderRepository.CreateOrder")
+	defer span.End()
 
-	tx := r.DB.WithContext(ctx).Begin()
+	var orderResponse model.OrderResponse
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Table("orders").Create(order).Error; err != nil {
+			return err
+		}
 
-	if err := tx.Table("orders").Create(order).Error; err != nil {
-		tx.Rollback()
-		return nil, err
-	}
+		if len(order.OrderFoods) > 0 {
+			orderFoods := make([]model.OrderFood, 0, len(order.OrderFoods))
+			for _, foodID := range order.OrderFoods {
+				orderFoods = append(orderFoods, model.OrderFood{OrderID: order.ID, FoodID: foodID})
+			}
+			if err := tx.Table("order_foods").Create(&orderFoods).Error; err != nil {
+				return err
+			}
+		}
 
-	if err := tx.Table("orders").Preload("Restaurant").Preload("Table").Where("id = ?", order.ID).First(&orderResponse).Error; err != nil {
-		tx.Rollback()
-		return nil, err
-	}
+		orders := []model.OrderResponse{{
+			ID:           order.ID,
+			TotalSum:     order.TotalSum,
+			Date:         order.Date,
+			Status:       order.Status,
+			RestaurantID: order.RestaurantID,
+			TableID:      order.TableID,
+			UserID:       order.UserID,
+			Version:      order.Version,
+		}}
+		if err := loadOrderRelations(ctx, tx, orders); err != nil {
+			return err
+		}
+		orderResponse = orders[0]
 
-	for _, foodID := range order.OrderFoods {
-		orderFood := model.OrderFood{
-			OrderID: order.ID,
-			FoodID:  foodID,
+		var foods []model.Food
+		if len(order.OrderFoods) > 0 {
+			if err := tx.Table("foods").Where("id IN ?", order.OrderFoods).Find(&foods).Error; err != nil {
+				return err
+			}
 		}
-		if err := tx.Table("order_foods").Create(&orderFood).Error; err != nil {
-			tx.Rollback()
-			return nil, err
+		foodsByID := index(foods, func(f model.Food) uint { return f.ID })
+
+		for _, foodID := range order.OrderFoods {
+			food, ok := foodsByID[foodID]
+			if !ok {
+				continue
+			}
+			orderResponse.OrderFoods = append(orderResponse.OrderFoods, model.OrderFoodResponse{
+				FoodID: foodID,
+				Food:   food,
+			})
 		}
-	}
-
-	if err := tx.Commit().Error; err != nil {
-		return nil, err
-	}
 
-	var rest model.Restaurant
-	if err := r.DB.WithContext(ctx).Table("restaurants").Where("id = ?", order.RestaurantID).First(&rest).Error; err != nil {
-		return nil, err
+		return nil
+	})
+	if err != nil {
+		return nil, wrapError(err, errs.ErrOrderNotFound)
 	}
-	orderResponse.Restaurant = rest
-
-	var table model.Table
-	if err := r.DB.WithContext(ctx).Table("tables").Where("id = ?", order.TableID).First(&table).Error; err != nil {
-		return nil, err
-	}
-	orderResponse.Table = table
-
-	var orderFoods []model.OrderFoodResponse
-	for _, foodID := range order.OrderFoods {
-		var food model.Food
-		if err := r.DB.WithContext(ctx).Table("foods").Where("id = ?", foodID).First(&food).Error; err != nil {
-			continue
-		}
-		orderFoods = append(orderFoods, model.OrderFoodResponse{
-			FoodID: foodID,
-			Food:   food,
-		})
-	}
-	orderResponse.OrderFoods = orderFoods
 
 	return &orderResponse, nil
 }
 
 func (r *OrderRepository) DeleteOrder(ctx context.Context, id uint) error {
+	ctx, span := tracing.Start(ctx, "OrderRepository.DeleteOrder")
+	defer span.End()
+
 	if err := r.DB.WithContext(ctx).Table("orders").Delete(&model.Order{}, id).Error; err != nil {
-		return err
+		return wrapError(err, errs.ErrOrderNotFound)
 	}
 
 	return nil
 }
 
 func (r *OrderRepository) UpdateOrder(ctx context.Context, order *model.Order) (*model.OrderResponse, error) {
+	ctx, span := tracing.Start(ctx, "OrderRepository.UpdateOrder")
+	defer span.End()
+
 	var or model.Order
 	if err := r.DB.WithContext(ctx).Table("orders").First(&or, order.ID).Error; err != nil {
-		return nil, err
+		return nil, wrapError(err, errs.ErrOrderNotFound)
 	}
 
-	if err := r.DB.WithContext(ctx).Model(&or).Table("orders").Updates(order).Error; err != nil {
-		return nil, err
+	if order.Version != 0 && order.Version != or.Version {
+		return nil, errs.ErrVersionMismatch
+	}
+	order.Version = or.Version + 1
+
+	result := r.DB.WithContext(ctx).Model(&or).Table("orders").Where("version = ?", or.Version).Updates(order)
+	if result.Error != nil {
+		return nil, wrapError(result.Error, errs.ErrOrderNotFound)
+	}
+	if result.RowsAffected == 0 {
+		return nil, errs.ErrVersionMismatch
 	}
 
 	return r.GetOrder(ctx, order.ID)
 }
 
-func (r *OrderRepository) GetOrder(ctx context.Context, id uint) (*model.OrderResponse, error) {
-	var order model.OrderResponse
-
-	if err := r.DB.WithContext(ctx).
-		Table("orders").
-		Preload("Restaurant").
-		Preload("Table").
-		Preload("User").
-		Where("id = ?", id).
-		First(&order).Error; err != nil {
-		return nil, err
-	}
+func (r *OrderRepository) PatchOrder(ctx context.Context, order *model.Order, fields []string) (*model.OrderResponse, error) {
+	ctx, span := tracing.Start(ctx, "OrderRepository.PatchOrder")
+	defer span.End()
 
-	var rest model.Restaurant
-	if err := r.DB.WithContext(ctx).Table("restaurants").Where("id = ?", order.RestaurantID).First(&rest).Error; err != nil {
-		return nil, err
+	if err := patchVersioned(r.DB.WithContext(ctx), order, &order.Version, fields); err != nil {
+		return nil, wrapError(err, errs.ErrOrderNotFound)
 	}
 
-	var icon model.Photo
-	if err := r.DB.Table("photos").Where("id = ?", rest.IconID).First(&icon).Error; err != nil {
-		return nil, err
-	}
+	return r.GetOrder(ctx, order.ID)
+}
 
-	rest.Icon = icon
+func (r *OrderRepository) GetOrder(ctx context.Context, id uint) (*model.OrderResponse, error) {
+	ctx, span := tracing.Start(ctx, "OrderRepository.GetOrder")
+	defer span.End()
 
-	var services []model.Service
-	if err := r.DB.Raw("SELECT services.* FROM services JOIN restaurant_service ON services.id = restaurant_service.service_id WHERE restaurant_service.restaurant_id = ?", rest.ID).Scan(&services).Error; err != nil {
-		return nil, err
+	var order model.OrderResponse
+	if err := r.DB.WithContext(ctx).Table("orders").Where("id = ?", id).First(&order).Error; err != nil {
+		return nil, wrapError(err, errs.ErrOrderNotFound)
 	}
-	rest.Services = services
 
-	order.Restaurant = rest
-
-	var table model.Table
-	if err := r.DB.WithContext(ctx).Table("tables").Where("id = ?", order.TableID).First(&table).Error; err != nil {
-		return nil, err
+	orders := []model.OrderResponse{order}
+	if err := loadOrderRelations(ctx, r.DB, orders); err != nil {
+		return nil, wrapError(err, errs.ErrOrderNotFound)
 	}
-	order.Table = table
+	order = orders[0]
 
-	var orderFoods []model.OrderFood
+	restaurants := []model.Restaurant{order.Restaurant}
+	if err := loadIcons(ctx, r.DB, restaurants); err != nil {
+		return nil, wrapError(err, errs.ErrOrderNotFound)
+	}
+	order.Restaurant = restaurants[0]
 
-	if err := r.DB.WithContext(ctx).
-		Table("order_foods").
-		Where("order_id = ?", order.ID).
-		Find(&orderFoods).Error; err != nil {
-		return nil, err
+	if err := r.DB.WithContext(ctx).Table("users").Where("id = ?", order.UserID).First(&order.User).Error; err != nil {
+		return nil, wrapError(err, errs.ErrOrderNotFound)
 	}
 
+	// Foods are loaded unscoped so the order keeps dishes removed from the
+	// menu since, once per order_foods row.
 	var foods []model.Food
-	for _, orderFood := range orderFoods {
-		var food model.Food
-		if err := r.DB.WithContext(ctx).Table("foods").Where("id = ?", orderFood.FoodID).First(&food).Error; err != nil {
-			return nil, err
-		}
-
-		var photo model.Photo
-		if err := r.DB.Table("photos").Where("id = ?", food.PhotoID).First(&photo).Error; err != nil {
-			return nil, err
-		}
-
-		food.Photo = photo
-
-		foods = append(foods, food)
+	if err := r.DB.WithContext(ctx).Unscoped().Table("foods").
+		Select("foods.*").
+		Joins("JOIN order_foods ON order_foods.food_id = foods.id").
+		Where("order_foods.order_id = ?", order.ID).
+		Order("order_foods.id").
+		Find(&foods).Error; err != nil {
+		return nil, wrapError(err, errs.ErrOrderNotFound)
 	}
-
-	var user model.UserResponse
-	if err := r.DB.Table("users").Where("id = ?", order.UserID).First(&user).Error; err != nil {
-		return nil, err
+	if err := loadFoodPhotos(ctx, r.DB, foods); err != nil {
+		return nil, wrapError(err, errs.ErrOrderNotFound)
 	}
-
-	order.User = user
 	order.Foods = foods
 
 	return &order, nil
 }
 
 func (r *OrderRepository) GetAllOrders(ctx context.Context, userID uint, params *model.Params) (*model.ListResponse, error) {
+	ctx, span := tracing.Start(ctx, "OrderRepository.GetAllOrders")
+	defer span.End()
+
 	var orders []model.OrderResponse
 	var totalItems int64
 
 	query := r.DB.WithContext(ctx).Table("orders").Where("user_id = ?", userID)
+	query = filter(query, params, "orders")
 
 	if err := query.Model(&model.OrderResponse{}).Count(&totalItems).Error; err != nil {
-		return nil, err
+		return nil, wrapError(err, errs.ErrOrderNotFound)
 	}
 
-	query = query.Limit(params.Limit).Offset(params.Offset)
-
-	if params.Order != nil && params.SortVector != nil {
-		query.Order(params.Order.(string) + " " + params.SortVector.(string))
-	}
+	query = paginate(query, params, "orders")
 
 	if err := query.Find(&orders).Error; err != nil {
-		return nil, err
+		return nil, wrapError(err, errs.ErrOrderNotFound)
 	}
+	orders, next, prev := model.KeysetPage(orders, params, func(order model.OrderResponse) uint { return order.ID })
 
-	for i := 0; i < len(orders); i++ {
-		var rest model.Restaurant
-		if err := r.DB.WithContext(ctx).Table("restaurants").Where("id = ?", orders[i].RestaurantID).First(&rest).Error; err != nil {
-			return nil, err
-		}
-		var services []model.Service
-		if err := r.DB.Raw("SELECT services.* FROM services JOIN restaurant_service ON services.id = restaurant_service.service_id WHERE restaurant_service.restaurant_id = ?", rest.ID).Scan(&services).Error; err != nil {
-			return nil, err
-		}
-		rest.Services = services
-
-		orders[i].Restaurant = rest
-
-		var table model.Table
-		if err := r.DB.WithContext(ctx).Table("tables").Where("id = ?", orders[i].TableID).First(&table).Error; err != nil {
-			return nil, err
-		}
-		orders[i].Table = table
+	if err := loadOrderRelations(ctx, r.DB, orders); err != nil {
+		return nil, wrapError(err, errs.ErrOrderNotFound)
 	}
 
 	return &model.ListResponse{
@@ -216,47 +202,34 @@ func (r *OrderRepository) GetAllOrders(ctx context.Context, userID uint, params
 		ItemsPerPage: params.Limit,
 		PageIndex:    params.PageIndex,
 		TotalItems:   int(totalItems),
+		NextCursor:   next,
+		PrevCursor:   prev,
 	}, nil
 }
 
 func (r *OrderRepository) GetRestaurantOrders(ctx context.Context, restaurantID uint, params *model.Params) (*model.ListResponse, error) {
+	ctx, span := tracing.Start(ctx, "OrderRepository.GetRestaurantOrders")
+	defer span.End()
+
 	var orders []model.OrderResponse
 	var totalItems int64
 
 	query := r.DB.WithContext(ctx).Table("orders").Where("restaurant_id = ?", restaurantID)
+	query = filter(query, params, "orders")
 
 	if err := query.Model(&model.OrderResponse{}).Count(&totalItems).Error; err != nil {
-		return nil, err
+		return nil, wrapError(err, errs.ErrOrderNotFound)
 	}
 
-	query = query.Limit(params.Limit).Offset(params.Offset)
-
-	if params.Order != nil && params.SortVector != nil {
-		query.Order(params.Order.(string) + " " + params.SortVector.(string))
-	}
+	query = paginate(query, params, "orders")
 
 	if err := query.Find(&orders).Error; err != nil {
-		return nil, err
+		return nil, wrapError(err, errs.ErrOrderNotFound)
 	}
+	orders, next, prev := model.KeysetPage(orders, params, func(order model.OrderResponse) uint { return order.ID })
 
-	for i := 0; i < len(orders); i++ {
-		var rest model.Restaurant
-		if err := r.DB.WithContext(ctx).Table("restaurants").Where("id = ?", orders[i].RestaurantID).First(&rest).Error; err != nil {
-			return nil, err
-		}
-		var services []model.Service
-		if err := r.DB.Raw("SELECT services.* FROM services JOIN restaurant_service ON services.id = restaurant_service.service_id WHERE restaurant_service.restaurant_id = ?", rest.ID).Scan(&services).Error; err != nil {
-			return nil, err
-		}
-		rest.Services = services
-
-		orders[i].Restaurant = rest
-
-		var table model.Table
-		if err := r.DB.WithContext(ctx).Table("tables").Where("id = ?", orders[i].TableID).First(&table).Error; err != nil {
-			return nil, err
-		}
-		orders[i].Table = table
+	if err := loadOrderRelations(ctx, r.DB, orders); err != nil {
+		return nil, wrapError(err, errs.ErrOrderNotFound)
 	}
 
 	return &model.ListResponse{
@@ -264,5 +237,7 @@ func (r *OrderRepository) GetRestaurantOrders(ctx context.Context, restaurantID
 		ItemsPerPage: params.Limit,
 		PageIndex:    params.PageIndex,
 		TotalItems:   int(totalItems),
+		NextCursor:   next,
+		PrevCursor:   prev,
 	}, nil
 }
@@ -0,0 +1,24 @@
+package postgre
+
+import (
+	"github.com/alibekabdrakhman1/orynal/pkg/errs"
+	"gorm.io/gorm"
+	"slices"
+)
+
+// patchVersioned writes fields of row, zero values included, if the row is
+// still at *version, and bumps *version.
+func patchVersioned(db *gorm.DB, row any, version *uint, fields []string) error {
+	current := *version
+	*version = current + 1
+
+	result := db.Model(row).Select(slices.Concat(fields, []string{"Version"})).Where("version = ?", current).Updates(row)
+	if result.Error == nil && result.RowsAffected == 0 {
+		result.Error = errs.ErrVersionMismatch
+	}
+	if result.Error != nil {
+		*version = current
+	}
+
+	return result.Error
+}
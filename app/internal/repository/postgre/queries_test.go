@@ -0,0 +1,342 @@
+package postgre
+
+import (
+	"context"
+	"database/sql/driver"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alibekabdrakhman1/orynal/internal/model"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"testing"
+)
+
+// pageSize is large enough that a per-row query would blow the budget of
+// any case below.
+const pageSize = 20
+
+type query struct {
+	pattern string
+	columns []string
+	rows    [][]driver.Value
+}
+
+func rows(n int, row func(i int) []driver.Value) [][]driver.Value {
+	values := make([][]driver.Value, n)
+	for i := range values {
+		values[i] = row(i)
+	}
+	return values
+}
+
+func count(table string, n int) query {
+	return query{`SELECT count\(\*\) FROM "` + table + `"`, []string{"count"}, [][]driver.Value{{n}}}
+}
+
+var (
+	restaurantRows = query{`FROM "restaurants"`, []string{"id", "owner_id", "icon_id"}, rows(pageSize, func(i int) []driver.Value {
+		return []driver.Value{i + 1, 100 + i, 200 + i}
+	})}
+	ownerRows = query{`FROM "users" WHERE id IN`, []string{"id"}, rows(pageSize, func(i int) []driver.Value {
+		return []driver.Value{100 + i}
+	})}
+	iconRows = query{`FROM "photos" WHERE id IN`, []string{"id", "route"}, rows(pageSize, func(i int) []driver.Value {
+		return []driver.Value{200 + i, "icon.png"}
+	})}
+	serviceRows = query{`FROM "services" JOIN restaurant_service .* IN`, []string{"restaurant_id", "id", "name"}, rows(pageSize, func(i int) []driver.Value {
+		return []driver.Value{i + 1, 1, "Wi-Fi"}
+	})}
+	galleryRows = query{`FROM "photos" JOIN restaurant_photos .* IN`, []string{"restaurant_id", "id", "route"}, rows(pageSize, func(i int) []driver.Value {
+		return []driver.Value{i + 1, 300 + i, "photo.png"}
+	})}
+	orderRows = query{`FROM "orders"`, []string{"id", "restaurant_id", "table_id", "user_id"}, rows(pageSize, func(i int) []driver.Value {
+		return []driver.Value{i + 1, i%3 + 1, i + 1, 7}
+	})}
+	orderRestaurantRows = query{`FROM "restaurants" WHERE id IN`, []string{"id", "icon_id"}, rows(3, func(i int) []driver.Value {
+		return []driver.Value{i + 1, 200 + i}
+	})}
+	tableRows = query{`FROM "tables"`, []string{"id", "photo_id"}, rows(pageSize, func(i int) []driver.Value {
+		return []driver.Value{i + 1, 400 + i}
+	})}
+	tableIDRows = query{`FROM "tables" WHERE id IN`, []string{"id"}, rows(pageSize, func(i int) []driver.Value {
+		return []driver.Value{i + 1}
+	})}
+	foodRows = query{`FROM "foods"`, []string{"id", "photo_id"}, rows(pageSize, func(i int) []driver.Value {
+		return []driver.Value{i + 1, 500 + i}
+	})}
+	photoRows = query{`FROM "photos" WHERE id IN`, []string{"id"}, rows(pageSize, func(i int) []driver.Value {
+		return []driver.Value{400 + i}
+	})}
+)
+
+func newMockDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	t.Helper()
+
+	conn, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: conn}), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return db, mock
+}
+
+// TestQueryCount pins the queries each read endpoint issues for a full page.
+// sqlmock fails on any statement that is not expected, so a relation loaded
+// per row shows up as an unexpected query.
+func TestQueryCount(t *testing.T) {
+	params := func() *model.Params { return &model.Params{Limit: pageSize, PageIndex: 1} }
+
+	tests := []struct {
+		name    string
+		call    func(ctx context.Context, db *gorm.DB) error
+		queries []query
+	}{
+		{
+			name: "GetRestaurants",
+			call: func(ctx context.Context, db *gorm.DB) error {
+				_, err := NewRestaurantRepository(db).GetRestaurants(ctx, params())
+				return err
+			},
+			queries: []query{count("restaurants", pageSize), restaurantRows, ownerRows, iconRows, serviceRows},
+		},
+		{
+			name: "GetRestaurantsByOwner",
+			call: func(ctx context.Context, db *gorm.DB) error {
+				_, err := NewRestaurantRepository(db).GetRestaurantsByOwner(ctx, 100, params())
+				return err
+			},
+			queries: []query{count("restaurants", pageSize), restaurantRows, ownerRows, iconRows, serviceRows},
+		},
+		{
+			name: "GetPopularRestaurants",
+			call: func(ctx context.Context, db *gorm.DB) error {
+				_, err := NewRestaurantRepository(db).GetPopularRestaurants(ctx)
+				return err
+			},
+			queries: []query{
+				{`FROM "orders" JOIN restaurants`, restaurantRows.columns, restaurantRows.rows},
+				ownerRows, iconRows, serviceRows, galleryRows,
+			},
+		},
+		{
+			name: "GetRestaurantByID",
+			call: func(ctx context.Context, db *gorm.DB) error {
+				_, err := NewRestaurantRepository(db).GetRestaurantByID(ctx, 1)
+				return err
+			},
+			queries: []query{
+				{`FROM "restaurants" WHERE "restaurants"."id" = \$1`, restaurantRows.columns, restaurantRows.rows[:1]},
+				ownerRows, iconRows, serviceRows, galleryRows,
+			},
+		},
+		{
+			name: "GetFavoriteRestaurants",
+			call: func(ctx context.Context, db *gorm.DB) error {
+				_, err := NewRestaurantRepository(db).GetFavoriteRestaurants(ctx, 7, params())
+				return err
+			},
+			queries: []query{count("restaurants", pageSize), restaurantRows, iconRows},
+		},
+		{
+			name: "GetOrder",
+			call: func(ctx context.Context, db *gorm.DB) error {
+				_, err := NewOrderRepository(db).GetOrder(ctx, 1)
+				return err
+			},
+			queries: []query{
+				{orderRows.pattern, orderRows.columns, orderRows.rows[:1]},
+				orderRestaurantRows, serviceRows, tableIDRows, iconRows,
+				{`FROM "users" WHERE id = \$1`, []string{"id"}, [][]driver.Value{{7}}},
+				{`FROM "foods" JOIN order_foods`, foodRows.columns, foodRows.rows},
+				photoRows,
+			},
+		},
+		{
+			name: "GetAllOrders",
+			call: func(ctx context.Context, db *gorm.DB) error {
+				_, err := NewOrderRepository(db).GetAllOrders(ctx, 7, params())
+				return err
+			},
+			queries: []query{count("orders", pageSize), orderRows, orderRestaurantRows, serviceRows, tableIDRows},
+		},
+		{
+			name: "GetRestaurantOrders",
+			call: func(ctx context.Context, db *gorm.DB) error {
+				_, err := NewOrderRepository(db).GetRestaurantOrders(ctx, 1, params())
+				return err
+			},
+			queries: []query{count("orders", pageSize), orderRows, orderRestaurantRows, serviceRows, tableIDRows},
+		},
+		{
+			name: "GetRestaurantTables",
+			call: func(ctx context.Context, db *gorm.DB) error {
+				_, err := NewTableRepository(db).GetRestaurantTables(ctx, 1, params())
+				return err
+			},
+			queries: []query{count("tables", pageSize), tableRows, photoRows},
+		},
+		{
+			name: "GetRestaurantMenu",
+			call: func(ctx context.Context, db *gorm.DB) error {
+				_, err := NewFoodRepository(db).GetRestaurantMenu(ctx, 1, params())
+				return err
+			},
+			queries: []query{count("foods", pageSize), foodRows, photoRows},
+		},
+		{
+			name: "SearchDishes",
+			call: func(ctx context.Context, db *gorm.DB) error {
+				_, err := NewSearchRepository(db).SearchDishes(ctx, &model.DishSearch{
+					Params: model.Params{Query: "plov", Limit: pageSize, PageIndex: 1, Near: &model.Point{Lat: 43.2, Lng: 76.9}},
+					Diets:  []string{"halal"},
+				})
+				return err
+			},
+			queries: []query{
+				{`SELECT COUNT\(DISTINCT\("foods"."restaurant_id"\)\) FROM "foods" JOIN restaurants .* search_tsquery`, []string{"count"}, [][]driver.Value{{pageSize}}},
+				{`FROM \(SELECT restaurants.id AS restaurant_id, count\(\*\) AS dishes, 2 \* 6371008.8 .* AS groups ORDER BY distance IS NULL`, []string{"restaurant_id", "dishes", "distance"}, rows(pageSize, func(i int) []driver.Value {
+					return []driver.Value{i + 1, 1, 1000.0 * float64(i)}
+				})},
+				{`FROM "restaurants" WHERE id IN`, restaurantRows.columns, restaurantRows.rows},
+				iconRows,
+				{`FROM \(SELECT foods.\*, row_number\(\) OVER .* AS foods WHERE position <=`, []string{"id", "restaurant_id", "photo_id"}, rows(pageSize, func(i int) []driver.Value {
+					return []driver.Value{i + 1, i + 1, 400 + i}
+				})},
+				photoRows,
+			},
+		},
+		{
+			name: "GetReviews",
+			call: func(ctx context.Context, db *gorm.DB) error {
+				_, err := NewReviewsRepository(db).GetReviews(ctx, 1, params())
+				return err
+			},
+			queries: []query{
+				{`FROM "restaurant_reviews"`, []string{"id", "user_id"}, rows(pageSize, func(i int) []driver.Value {
+					return []driver.Value{i + 1, 100 + i}
+				})},
+				{`FROM "users" WHERE "users"."id" IN`, ownerRows.columns, ownerRows.rows},
+				count("restaurant_reviews", pageSize),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := newMockDB(t)
+			for _, q := range tt.queries {
+				result := sqlmock.NewRows(q.columns)
+				for _, row := range q.rows {
+					result.AddRow(row...)
+				}
+				mock.ExpectQuery(q.pattern).WillReturnRows(result)
+			}
+
+			if err := tt.call(context.Background(), db); err != nil {
+				t.Fatalf("want %d queries: %v", len(tt.queries), err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestLoadRelationsAttachesByKey(t *testing.T) {
+	db, mock := newMockDB(t)
+	for _, q := range []query{count("restaurants", pageSize), restaurantRows, ownerRows, iconRows, serviceRows} {
+		result := sqlmock.NewRows(q.columns)
+		for _, row := range q.rows {
+			result.AddRow(row...)
+		}
+		mock.ExpectQuery(q.pattern).WillReturnRows(result)
+	}
+
+	list, err := NewRestaurantRepository(db).GetRestaurants(context.Background(), &model.Params{Limit: pageSize})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	restaurants := list.Items.([]model.Restaurant)
+	if len(restaurants) != pageSize {
+		t.Fatalf("got %d restaurants, want %d", len(restaurants), pageSize)
+	}
+	for _, restaurant := range restaurants {
+		if restaurant.Owner.ID != restaurant.OwnerID {
+			t.Errorf("restaurant %d: owner %d, want %d", restaurant.ID, restaurant.Owner.ID, restaurant.OwnerID)
+		}
+		if restaurant.Icon.ID != restaurant.IconID {
+			t.Errorf("restaurant %d: icon %d, want %d", restaurant.ID, restaurant.Icon.ID, restaurant.IconID)
+		}
+		if len(restaurant.Services) != 1 {
+			t.Errorf("restaurant %d: %d services, want 1", restaurant.ID, len(restaurant.Services))
+		}
+	}
+}
+
+// TestListClauses pins the SQL filter and paginate build: columns are quoted
+// and qualified, and values, LIKE patterns included, are bound.
+func TestListClauses(t *testing.T) {
+	db, _ := newMockDB(t)
+
+	params := &model.Params{
+		Filters: []model.Filter{
+			{Column: "name", Op: model.OpLike, Value: "50%_off"},
+			{Column: "status", Op: model.OpIn, Value: []any{"reserved", "completed"}},
+			{Column: "total_sum", Op: model.OpGte, Value: 1000.0},
+		},
+		Sort:   []model.Sort{{Column: "date", Desc: true}},
+		Limit:  10,
+		Offset: 20,
+	}
+	stmt := paginate(filter(db.Session(&gorm.Session{DryRun: true}).Table("orders"), params, "orders"), params, "orders").
+		Find(&[]model.OrderResponse{}).Statement
+
+	want := `SELECT * FROM "orders" WHERE LOWER("orders"."name") LIKE $1 ESCAPE '\' AND "orders"."status" IN ($2,$3) AND "orders"."total_sum" >= $4 ORDER BY "orders"."date" DESC,"orders"."id" LIMIT $5 OFFSET $6`
+	if got := stmt.SQL.String(); got != want {
+		t.Errorf("got SQL\n%s\nwant\n%s", got, want)
+	}
+	if got := stmt.Vars[0]; got != `%50\%\_off%` {
+		t.Errorf("got LIKE pattern %v, want the wildcards escaped", got)
+	}
+}
+
+// TestSearchFallsBackToTrigrams pins the fallback to trigram similarity when
+// the full-text query matches nothing, and the escaping of snippets.
+func TestSearchFallsBackToTrigrams(t *testing.T) {
+	db, mock := newMockDB(t)
+	mock.ExpectQuery(`SELECT count\(\*\) FROM restaurants\s+JOIN restaurant_search`).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery(`SELECT count\(\*\) FROM restaurants\s+WHERE .* <% restaurants.name`).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery(`word_similarity`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "icon_id", "rank", "snippet"}).
+			AddRow(1, "Tom & Jerry", 200, 0.6, "Tom & Jerry — "+markStart+"Beshbarmak"+markStop))
+	mock.ExpectQuery(iconRows.pattern).WillReturnRows(sqlmock.NewRows(iconRows.columns).AddRow(200, "icon.png"))
+
+	list, err := NewSearchRepository(db).SearchRestaurants(context.Background(), &model.Params{Query: "beshbarmk", Limit: pageSize})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+
+	hits := list.Items.([]model.SearchHit)
+	if len(hits) != 1 || list.TotalItems != 1 {
+		t.Fatalf("got %d hits of %d, want 1", len(hits), list.TotalItems)
+	}
+	hit := hits[0]
+	if !hit.Fuzzy || hit.Rank != 0.6 || hit.Restaurant.Icon.Route != "icon.png" {
+		t.Errorf("got hit %+v, want a fuzzy match with its icon", hit)
+	}
+	if want := "Tom &amp; Jerry — <mark>Beshbarmak</mark>"; hit.Snippet != want {
+		t.Errorf("got snippet %q, want %q", hit.Snippet, want)
+	}
+}
@@ -2,9 +2,11 @@ package postgre
 
 import (
 	"context"
-	"errors"
 	"github.com/alibekabdrakhman1/orynal/internal/model"
+	"github.com/alibekabdrakhman1/orynal/pkg/errs"
+	"github.com/alibekabdrakhman1/orynal/pkg/tracing"
 	"gorm.io/gorm"
+	"time"
 )
 
 func NewRestaurantRepository(db *gorm.DB) *RestaurantRepository {
@@ -18,46 +20,28 @@ type RestaurantRepository struct {
 }
 
 func (r *RestaurantRepository) GetPopularRestaurants(ctx context.Context) (*model.ListResponse, error) {
+	ctx, span := tracing.Start(ctx, "RestaurantRepository.GetPopularRestaurants")
+	defer span.End()
+
 	var restaurants []model.Restaurant
 
-	err := r.DB.Table("orders").
+	err := r.DB.WithContext(ctx).Table("orders").
 		WithContext(ctx).
-		Select("restaurants.id, restaurants.name, restaurants.address, restaurants.description, restaurants.city, restaurants.status, restaurants.phone, restaurants.owner_id, restaurants.mode_from, restaurants.mode_to, restaurants.icon_id, count(orders.id) as order_count").
+		Unscoped().
+		Select("restaurants.id, restaurants.name, restaurants.address, restaurants.description, restaurants.city, restaurants.status, restaurants.phone, restaurants.owner_id, restaurants.mode_from, restaurants.mode_to, restaurants.icon_id, restaurants.version, count(orders.id) as order_count").
 		Joins("JOIN restaurants ON restaurants.id = orders.restaurant_id").
+		Where("restaurants.deleted_at IS NULL").
 		Group("restaurants.id").
 		Order("order_count DESC").
 		Limit(10).
 		Find(&restaurants).Error
 
-	for i := 0; i < len(restaurants); i++ {
-		var owner model.UserResponse
-		if err := r.DB.Table("users").Where("id = ?", restaurants[i].OwnerID).First(&owner).Error; err != nil {
-			return nil, err
-		}
-
-		var icon model.Photo
-		if err := r.DB.Table("photos").Where("id = ?", restaurants[i].IconID).First(&icon).Error; err != nil {
-			return nil, err
-		}
-
-		var services []model.Service
-		if err := r.DB.Raw("SELECT services.* FROM services JOIN restaurant_service ON services.id = restaurant_service.service_id WHERE restaurant_service.restaurant_id = ?", restaurants[i].ID).Scan(&services).Error; err != nil {
-			return nil, err
-		}
-
-		var photos []model.Photo
-		if err := r.DB.Raw("SELECT photos.* FROM photos JOIN restaurant_photos ON photos.id = restaurant_photos.photo_id WHERE restaurant_photos.restaurant_id = ?", restaurants[i].ID).Scan(&photos).Error; err != nil {
-			return nil, err
-		}
-
-		restaurants[i].Owner = owner
-		restaurants[i].Services = services
-		restaurants[i].Icon = icon
-		restaurants[i].Photos = photos
+	if err != nil {
+		return nil, wrapError(err, errs.ErrRestaurantNotFound)
 	}
 
-	if err != nil {
-		return nil, err
+	if err := r.loadRelations(ctx, restaurants, true); err != nil {
+		return nil, wrapError(err, errs.ErrRestaurantNotFound)
 	}
 
 	return &model.ListResponse{
@@ -69,7 +53,27 @@ func (r *RestaurantRepository) GetPopularRestaurants(ctx context.Context) (*mode
 	}, nil
 }
 
+// loadRelations attaches owners, icons and services, and the photo gallery
+// when withPhotos is set, in a fixed number of queries.
+func (r *RestaurantRepository) loadRelations(ctx context.Context, restaurants []model.Restaurant, withPhotos bool) error {
+	loaders := []func(context.Context, *gorm.DB, []model.Restaurant) error{loadOwners, loadIcons, loadServices}
+	if withPhotos {
+		loaders = append(loaders, loadGallery)
+	}
+
+	for _, load := range loaders {
+		if err := load(ctx, r.DB, restaurants); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (r *RestaurantRepository) GetRestaurants(ctx context.Context, params *model.Params) (*model.ListResponse, error) {
+	ctx, span := tracing.Start(ctx, "RestaurantRepository.GetRestaurants")
+	defer span.End()
+
 	var restaurants []model.Restaurant
 	var totalItems int64
 
@@ -77,47 +81,25 @@ func (r *RestaurantRepository) GetRestaurants(ctx context.Context, params *model
 	if params.Query != "" {
 		countQuery = countQuery.Where("LOWER(name) LIKE LOWER(?)", "%"+params.Query+"%")
 	}
-	if err := countQuery.Table("restaurants").Count(&totalItems).Error; err != nil {
-		return nil, err
-	}
-
-	if int(totalItems) <= params.Offset {
-		return nil, errors.New("offset cannot be less than total items")
+	countQuery = near(filter(countQuery, params, "restaurants"), params)
+	if err := countQuery.Model(&model.Restaurant{}).Count(&totalItems).Error; err != nil {
+		return nil, wrapError(err, errs.ErrRestaurantNotFound)
 	}
 
-	query := r.DB.WithContext(ctx).Table("restaurants").
-		Limit(params.Limit).
-		Offset(params.Offset)
+	query := r.DB.WithContext(ctx).Table("restaurants")
 
 	if params.Query != "" {
 		query = query.Where("LOWER(name) LIKE LOWER(?)", "%"+params.Query+"%")
 	}
-
-	query = query.Find(&restaurants)
+	query = paginate(byDistance(near(filter(query, params, "restaurants"), params), params), params, "restaurants")
 
 	if err := query.Find(&restaurants).Error; err != nil {
-		return nil, err
+		return nil, wrapError(err, errs.ErrRestaurantNotFound)
 	}
+	restaurants, next, prev := model.KeysetPage(restaurants, params, func(restaurant model.Restaurant) uint { return restaurant.ID })
 
-	for i := 0; i < len(restaurants); i++ {
-		var owner model.UserResponse
-		if err := r.DB.Table("users").Where("id = ?", restaurants[i].OwnerID).First(&owner).Error; err != nil {
-			return nil, err
-		}
-
-		var icon model.Photo
-		if err := r.DB.Table("photos").Where("id = ?", restaurants[i].IconID).First(&icon).Error; err != nil {
-			return nil, err
-		}
-
-		var services []model.Service
-		if err := r.DB.Raw("SELECT services.* FROM services JOIN restaurant_service ON services.id = restaurant_service.service_id WHERE restaurant_service.restaurant_id = ?", restaurants[i].ID).Scan(&services).Error; err != nil {
-			return nil, err
-		}
-
-		restaurants[i].Owner = owner
-		restaurants[i].Services = services
-		restaurants[i].Icon = icon
+	if err := r.loadRelations(ctx, restaurants, false); err != nil {
+		return nil, wrapError(err, errs.ErrRestaurantNotFound)
 	}
 
 	return &model.ListResponse{
@@ -125,17 +107,22 @@ func (r *RestaurantRepository) GetRestaurants(ctx context.Context, params *model
 		ItemsPerPage: params.Limit,
 		PageIndex:    params.PageIndex,
 		TotalItems:   int(totalItems),
+		NextCursor:   next,
+		PrevCursor:   prev,
 	}, nil
 }
 
 func (r *RestaurantRepository) GetStatistics(ctx context.Context) (*model.Statistics, error) {
+	ctx, span := tracing.Start(ctx, "RestaurantRepository.GetStatistics")
+	defer span.End()
+
 	var countRestaurants int64
 	if err := r.DB.WithContext(ctx).Table("restaurants").Model(&model.Restaurant{}).Count(&countRestaurants).Error; err != nil {
-		return nil, err
+		return nil, wrapError(err, errs.ErrRestaurantNotFound)
 	}
 	var countOrders int64
 	if err := r.DB.WithContext(ctx).Table("orders").Model(&model.Order{}).Count(&countOrders).Error; err != nil {
-		return nil, err
+		return nil, wrapError(err, errs.ErrRestaurantNotFound)
 	}
 
 	return &model.Statistics{
@@ -146,44 +133,26 @@ func (r *RestaurantRepository) GetStatistics(ctx context.Context) (*model.Statis
 }
 
 func (r *RestaurantRepository) GetRestaurantByID(ctx context.Context, id uint) (*model.Restaurant, error) {
-	var restaurantResponse model.Restaurant
-
-	if err := r.DB.WithContext(ctx).
-		Table("restaurants").
-		Preload("Photo").
-		First(&restaurantResponse, id).Error; err != nil {
-		return &model.Restaurant{}, err
-	}
-
-	var owner model.UserResponse
-	if err := r.DB.Table("users").Where("id = ?", restaurantResponse.OwnerID).First(&owner).Error; err != nil {
-		return nil, err
-	}
+	ctx, span := tracing.Start(ctx, "RestaurantRepository.GetRestaurantByID")
+	defer span.End()
 
-	var icon model.Photo
-	if err := r.DB.Table("photos").Where("id = ?", restaurantResponse.IconID).First(&icon).Error; err != nil {
-		return nil, err
+	var restaurant model.Restaurant
+	if err := r.DB.WithContext(ctx).Table("restaurants").First(&restaurant, id).Error; err != nil {
+		return nil, wrapError(err, errs.ErrRestaurantNotFound)
 	}
 
-	var services []model.Service
-	if err := r.DB.Raw("SELECT services.* FROM services JOIN restaurant_service ON services.id = restaurant_service.service_id WHERE restaurant_service.restaurant_id = ?", id).Scan(&services).Error; err != nil {
-		return nil, err
+	restaurants := []model.Restaurant{restaurant}
+	if err := r.loadRelations(ctx, restaurants, true); err != nil {
+		return nil, wrapError(err, errs.ErrRestaurantNotFound)
 	}
 
-	var photos []model.Photo
-	if err := r.DB.Raw("SELECT photos.* FROM photos JOIN restaurant_photos ON photos.id = restaurant_photos.photo_id WHERE restaurant_photos.restaurant_id = ?", id).Scan(&photos).Error; err != nil {
-		return nil, err
-	}
-
-	restaurantResponse.Owner = owner
-	restaurantResponse.Services = services
-	restaurantResponse.Photos = photos
-	restaurantResponse.Icon = icon
-
-	return &restaurantResponse, nil
+	return &restaurants[0], nil
 }
 
 func (r *RestaurantRepository) GetRestaurantsByOwner(ctx context.Context, ownerID uint, params *model.Params) (*model.ListResponse, error) {
+	ctx, span := tracing.Start(ctx, "RestaurantRepository.GetRestaurantsByOwner")
+	defer span.End()
+
 	var restaurants []model.Restaurant
 	var totalItems int64
 
@@ -191,48 +160,26 @@ func (r *RestaurantRepository) GetRestaurantsByOwner(ctx context.Context, ownerI
 	if params.Query != "" {
 		countQuery = countQuery.Where("LOWER(name) LIKE LOWER(?)", "%"+params.Query+"%")
 	}
-	if err := countQuery.Table("restaurants").Where("owner_id = ?", ownerID).Count(&totalItems).Error; err != nil {
-		return nil, err
-	}
-
-	if int(totalItems) <= params.Offset {
-		return nil, errors.New("offset cannot be less than total items")
+	countQuery = near(filter(countQuery, params, "restaurants"), params)
+	if err := countQuery.Model(&model.Restaurant{}).Where("owner_id = ?", ownerID).Count(&totalItems).Error; err != nil {
+		return nil, wrapError(err, errs.ErrRestaurantNotFound)
 	}
 
 	query := r.DB.WithContext(ctx).Table("restaurants").
-		Where("owner_id = ?", ownerID).
-		Limit(params.Limit).
-		Offset(params.Offset)
+		Where("owner_id = ?", ownerID)
 
 	if params.Query != "" {
 		query = query.Where("LOWER(name) LIKE LOWER(?)", "%"+params.Query+"%")
 	}
-
-	query = query.Find(&restaurants)
+	query = paginate(byDistance(near(filter(query, params, "restaurants"), params), params), params, "restaurants")
 
 	if err := query.Find(&restaurants).Error; err != nil {
-		return nil, err
+		return nil, wrapError(err, errs.ErrRestaurantNotFound)
 	}
+	restaurants, next, prev := model.KeysetPage(restaurants, params, func(restaurant model.Restaurant) uint { return restaurant.ID })
 
-	for i := 0; i < len(restaurants); i++ {
-		var owner model.UserResponse
-		if err := r.DB.Table("users").Where("id = ?", restaurants[i].OwnerID).First(&owner).Error; err != nil {
-			return nil, err
-		}
-
-		var icon model.Photo
-		if err := r.DB.Table("photos").Where("id = ?", restaurants[i].IconID).First(&icon).Error; err != nil {
-			return nil, err
-		}
-
-		var services []model.Service
-		if err := r.DB.Raw("SELECT services.* FROM services JOIN restaurant_service ON services.id = restaurant_service.service_id WHERE restaurant_service.restaurant_id = ?", restaurants[i].ID).Scan(&services).Error; err != nil {
-			return nil, err
-		}
-
-		restaurants[i].Owner = owner
-		restaurants[i].Services = services
-		restaurants[i].Icon = icon
+	if err := r.loadRelations(ctx, restaurants, false); err != nil {
+		return nil, wrapError(err, errs.ErrRestaurantNotFound)
 	}
 
 	return &model.ListResponse{
@@ -240,128 +187,242 @@ func (r *RestaurantRepository) GetRestaurantsByOwner(ctx context.Context, ownerI
 		ItemsPerPage: params.Limit,
 		PageIndex:    params.PageIndex,
 		TotalItems:   int(totalItems),
+		NextCursor:   next,
+		PrevCursor:   prev,
 	}, nil
 }
 
 func (r *RestaurantRepository) GetFavoriteRestaurants(ctx context.Context, userID uint, params *model.Params) (*model.ListResponse, error) {
-	//TODO implement me
-	panic("implement me")
-}
+	ctx, span := tracing.Start(ctx, "RestaurantRepository.GetFavoriteRestaurants")
+	defer span.End()
 
-func (r *RestaurantRepository) CreateRestaurant(ctx context.Context, restaurant *model.Restaurant) (*model.Restaurant, error) {
-	tx := r.DB.WithContext(ctx).Begin()
+	var restaurants []model.Restaurant
+	var totalItems int64
 
-	if restaurant.Icon.Route != "" {
-		iconPhoto := model.Photo{Route: restaurant.Icon.Route}
-		if err := tx.Table("photos").Create(&iconPhoto).Error; err != nil {
-			tx.Rollback()
-			return nil, err
-		}
-		restaurant.IconID = iconPhoto.ID
+	countQuery := r.DB.WithContext(ctx).
+		Model(&model.Restaurant{}).
+		Joins("JOIN favorite_restaurants ON favorite_restaurants.restaurant_id = restaurants.id").
+		Where("favorite_restaurants.user_id = ?", userID)
+	countQuery = filter(countQuery, params, "restaurants")
+	if err := countQuery.Count(&totalItems).Error; err != nil {
+		return nil, wrapError(err, errs.ErrRestaurantNotFound)
 	}
 
-	createRestaurant := model.Restaurant{
-		Name:        restaurant.Name,
-		Address:     restaurant.Address,
-		Description: restaurant.Description,
-		City:        restaurant.City,
-		Status:      restaurant.Status,
-		OwnerID:     restaurant.OwnerID,
-		Phone:       restaurant.Phone,
-		ModeFrom:    restaurant.ModeFrom,
-		ModeTo:      restaurant.ModeTo,
-		IconID:      restaurant.IconID,
+	query := r.DB.WithContext(ctx).Table("restaurants").
+		Select("restaurants.*").
+		Joins("JOIN favorite_restaurants ON favorite_restaurants.restaurant_id = restaurants.id").
+		Where("favorite_restaurants.user_id = ?", userID)
+	query = paginate(filter(query, params, "restaurants"), params, "restaurants")
+
+	if err := query.Find(&restaurants).Error; err != nil {
+		return nil, wrapError(err, errs.ErrRestaurantNotFound)
 	}
+	restaurants, next, prev := model.KeysetPage(restaurants, params, func(restaurant model.Restaurant) uint { return restaurant.ID })
 
-	if err := tx.Table("restaurants").Create(&createRestaurant).Error; err != nil {
-		tx.Rollback()
-		return nil, err
+	if err := loadIcons(ctx, r.DB, restaurants); err != nil {
+		return nil, wrapError(err, errs.ErrRestaurantNotFound)
 	}
 
-	for _, service := range restaurant.Services {
-		restaurantService := model.RestaurantService{
-			ServiceID:    service.ID,
-			RestaurantID: createRestaurant.ID,
-		}
-		if err := tx.Table("restaurant_service").Create(&restaurantService).Error; err != nil {
-			tx.Rollback()
-			return nil, err
+	return &model.ListResponse{
+		Items:        restaurants,
+		ItemsPerPage: params.Limit,
+		PageIndex:    params.PageIndex,
+		TotalItems:   int(totalItems),
+		NextCursor:   next,
+		PrevCursor:   prev,
+	}, nil
+}
+
+func (r *RestaurantRepository) CreateRestaurant(ctx context.Context, restaurant *model.Restaurant) (*model.Restaurant, error) {
+	ctx, span := tracing.Start(ctx, "RestaurantRepository.CreateRestaurant")
+	defer span.End()
+
+	var createRestaurant model.Restaurant
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if restaurant.Icon.Route != "" {
+			iconPhoto := model.Photo{Route: restaurant.Icon.Route}
+			if err := tx.Table("photos").Create(&iconPhoto).Error; err != nil {
+				return err
+			}
+			restaurant.IconID = iconPhoto.ID
 		}
-	}
 
-	if len(restaurant.Photos) > 0 {
-		var photos []model.Photo
-		for _, photo := range restaurant.Photos {
-			photos = append(photos, model.Photo{Route: photo.Route})
+		createRestaurant = model.Restaurant{
+			Name:        restaurant.Name,
+			Address:     restaurant.Address,
+			Description: restaurant.Description,
+			City:        restaurant.City,
+			Latitude:    restaurant.Latitude,
+			Longitude:   restaurant.Longitude,
+			Status:      restaurant.Status,
+			OwnerID:     restaurant.OwnerID,
+			Phone:       restaurant.Phone,
+			ModeFrom:    restaurant.ModeFrom,
+			ModeTo:      restaurant.ModeTo,
+			IconID:      restaurant.IconID,
 		}
-		if err := tx.Table("photos").Create(&photos).Error; err != nil {
-			tx.Rollback()
-			return nil, err
+
+		if err := tx.Table("restaurants").Create(&createRestaurant).Error; err != nil {
+			return err
 		}
 
-		var restaurantPhotos []model.RestaurantPhoto
-		for _, photo := range photos {
-			restaurantPhoto := model.RestaurantPhoto{
-				PhotoID:      photo.ID,
+		for _, service := range restaurant.Services {
+			restaurantService := model.RestaurantService{
+				ServiceID:    service.ID,
 				RestaurantID: createRestaurant.ID,
 			}
-			restaurantPhotos = append(restaurantPhotos, restaurantPhoto)
+			if err := tx.Table("restaurant_service").Create(&restaurantService).Error; err != nil {
+				return err
+			}
 		}
-		if err := tx.Table("restaurant_photos").Create(&restaurantPhotos).Error; err != nil {
-			tx.Rollback()
-			return nil, err
+
+		if len(restaurant.Photos) > 0 {
+			var photos []model.Photo
+			for _, photo := range restaurant.Photos {
+				photos = append(photos, model.Photo{Route: photo.Route})
+			}
+			if err := tx.Table("photos").Create(&photos).Error; err != nil {
+				return err
+			}
+
+			var restaurantPhotos []model.RestaurantPhoto
+			for _, photo := range photos {
+				restaurantPhoto := model.RestaurantPhoto{
+					PhotoID:      photo.ID,
+					RestaurantID: createRestaurant.ID,
+				}
+				restaurantPhotos = append(restaurantPhotos, restaurantPhoto)
+			}
+			if err := tx.Table("restaurant_photos").Create(&restaurantPhotos).Error; err != nil {
+				return err
+			}
 		}
-	}
 
-	if err := tx.Commit().Error; err != nil {
-		return nil, err
+		return nil
+	})
+	if err != nil {
+		return nil, wrapError(err, errs.ErrRestaurantNotFound)
 	}
 
 	return r.GetRestaurantByID(ctx, createRestaurant.ID)
 }
 
 func (r *RestaurantRepository) DeleteRestaurant(ctx context.Context, restaurantID uint) error {
+	ctx, span := tracing.Start(ctx, "RestaurantRepository.DeleteRestaurant")
+	defer span.End()
+
 	if err := r.DB.WithContext(ctx).Table("restaurants").Delete(&model.Restaurant{}, restaurantID).Error; err != nil {
-		return err
+		return wrapError(err, errs.ErrRestaurantNotFound)
+	}
+	return nil
+}
+
+func (r *RestaurantRepository) RestoreRestaurant(ctx context.Context, restaurantID uint) error {
+	ctx, span := tracing.Start(ctx, "RestaurantRepository.RestoreRestaurant")
+	defer span.End()
+
+	result := r.DB.WithContext(ctx).Unscoped().
+		Model(&model.Restaurant{}).
+		Where("id = ? AND deleted_at IS NOT NULL", restaurantID).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return wrapError(result.Error, errs.ErrRestaurantNotFound)
 	}
+
+	if result.RowsAffected == 0 {
+		return errs.ErrRestaurantNotFound
+	}
+
 	return nil
 }
 
+func (r *RestaurantRepository) PurgeDeletedRestaurants(ctx context.Context, before time.Time) (int64, error) {
+	ctx, span := tracing.Start(ctx, "RestaurantRepository.PurgeDeletedRestaurants")
+	defer span.End()
+
+	// Restaurants with orders are kept, deleted, so the order history and
+	// revenue survive; the foreign keys restrict their removal.
+	result := r.DB.WithContext(ctx).Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+		Where("NOT EXISTS (SELECT 1 FROM orders WHERE orders.restaurant_id = restaurants.id)").
+		Where("NOT EXISTS (SELECT 1 FROM order_foods JOIN foods ON foods.id = order_foods.food_id WHERE foods.restaurant_id = restaurants.id)").
+		Delete(&model.Restaurant{})
+	if result.Error != nil {
+		return 0, wrapError(result.Error, errs.ErrRestaurantNotFound)
+	}
+
+	return result.RowsAffected, nil
+}
+
 func (r *RestaurantRepository) UpdateRestaurant(ctx context.Context, restaurantID uint, restaurant *model.Restaurant) (*model.Restaurant, error) {
+	ctx, span := tracing.Start(ctx, "RestaurantRepository.UpdateRestaurant")
+	defer span.End()
+
 	var existingRestaurant model.Restaurant
 	if err := r.DB.WithContext(ctx).Table("restaurants").First(&existingRestaurant, restaurantID).Error; err != nil {
-		return nil, err
+		return nil, wrapError(err, errs.ErrRestaurantNotFound)
 	}
 
-	if err := r.DB.WithContext(ctx).Table("restaurants").Model(&existingRestaurant).Updates(restaurant).Error; err != nil {
-		return nil, err
+	if restaurant.Version != 0 && restaurant.Version != existingRestaurant.Version {
+		return nil, errs.ErrVersionMismatch
+	}
+	restaurant.Version = existingRestaurant.Version + 1
+
+	result := r.DB.WithContext(ctx).Table("restaurants").Model(&existingRestaurant).
+		Where("version = ?", existingRestaurant.Version).
+		Updates(restaurant)
+	if result.Error != nil {
+		return nil, wrapError(result.Error, errs.ErrRestaurantNotFound)
+	}
+	if result.RowsAffected == 0 {
+		return nil, errs.ErrVersionMismatch
+	}
+	// Coordinates are written as a pair even when nil, which Updates skips,
+	// so a restaurant that lost its point does not keep the old one.
+	if err := r.DB.WithContext(ctx).Table("restaurants").Where("id = ?", restaurantID).
+		UpdateColumns(map[string]interface{}{"latitude": restaurant.Latitude, "longitude": restaurant.Longitude}).Error; err != nil {
+		return nil, wrapError(err, errs.ErrRestaurantNotFound)
 	}
 
 	if err := r.UpdateRestaurantPhotos(ctx, restaurantID, restaurant.Photos); err != nil {
-		return nil, err
+		return nil, wrapError(err, errs.ErrRestaurantNotFound)
 	}
 
 	if err := r.UpdateRestaurantServices(ctx, restaurantID, restaurant.Services); err != nil {
-		return nil, err
+		return nil, wrapError(err, errs.ErrRestaurantNotFound)
 	}
 
 	return r.GetRestaurantByID(ctx, restaurantID)
 }
 
+func (r *RestaurantRepository) PatchRestaurant(ctx context.Context, restaurant *model.Restaurant, fields []string) (*model.Restaurant, error) {
+	ctx, span := tracing.Start(ctx, "RestaurantRepository.PatchRestaurant")
+	defer span.End()
+
+	if err := patchVersioned(r.DB.WithContext(ctx), restaurant, &restaurant.Version, fields); err != nil {
+		return nil, wrapError(err, errs.ErrRestaurantNotFound)
+	}
+
+	return r.GetRestaurantByID(ctx, restaurant.ID)
+}
+
 func (r *RestaurantRepository) UpdateRestaurantPhotos(ctx context.Context, restaurantID uint, photos []model.Photo) error {
+	ctx, span := tracing.Start(ctx, "RestaurantRepository.UpdateRestaurantPhotos")
+	defer span.End()
+
 	var existingPhotos []model.RestaurantPhoto
 	if err := r.DB.WithContext(ctx).Table("restaurant_photos").Where("restaurant_id = ?", restaurantID).Find(&existingPhotos).Error; err != nil {
-		return err
+		return wrapError(err, errs.ErrRestaurantNotFound)
 	}
 
 	if err := r.DB.WithContext(ctx).Table("restaurant_photos").Where("restaurant_id = ?", restaurantID).Delete(&model.RestaurantPhoto{}).Error; err != nil {
-		return err
+		return wrapError(err, errs.ErrRestaurantNotFound)
 	}
 
 	for _, photo := range photos {
 		newPhoto := model.Photo{Route: photo.Route}
 		if err := r.DB.WithContext(ctx).Table("photos").Create(&newPhoto).Error; err != nil {
-			return err
+			return wrapError(err, errs.ErrRestaurantNotFound)
 		}
 
 		newRestaurantPhoto := model.RestaurantPhoto{
@@ -369,18 +430,18 @@ func (r *RestaurantRepository) UpdateRestaurantPhotos(ctx context.Context, resta
 			RestaurantID: restaurantID,
 		}
 		if err := r.DB.WithContext(ctx).Table("restaurant_photos").Create(&newRestaurantPhoto).Error; err != nil {
-			return err
+			return wrapError(err, errs.ErrRestaurantNotFound)
 		}
 	}
 
 	for _, oldPhoto := range existingPhotos {
 		var count int64
 		if err := r.DB.WithContext(ctx).Table("restaurant_photos").Where("photo_id = ?", oldPhoto.PhotoID).Count(&count).Error; err != nil {
-			return err
+			return wrapError(err, errs.ErrRestaurantNotFound)
 		}
 		if count == 0 {
 			if err := r.DB.WithContext(ctx).Table("photos").Delete(&model.Photo{ID: oldPhoto.PhotoID}).Error; err != nil {
-				return err
+				return wrapError(err, errs.ErrRestaurantNotFound)
 			}
 		}
 	}
@@ -389,8 +450,11 @@ func (r *RestaurantRepository) UpdateRestaurantPhotos(ctx context.Context, resta
 }
 
 func (r *RestaurantRepository) UpdateRestaurantServices(ctx context.Context, restaurantID uint, services []model.Service) error {
+	ctx, span := tracing.Start(ctx, "RestaurantRepository.UpdateRestaurantServices")
+	defer span.End()
+
 	if err := r.DB.WithContext(ctx).Table("restaurant_service").Where("restaurant_id = ?", restaurantID).Delete(&model.RestaurantService{}).Error; err != nil {
-		return err
+		return wrapError(err, errs.ErrRestaurantNotFound)
 	}
 
 	for _, service := range services {
@@ -398,7 +462,7 @@ func (r *RestaurantRepository) UpdateRestaurantServices(ctx context.Context, res
 			RestaurantID: restaurantID,
 			ServiceID:    service.ID,
 		}).Error; err != nil {
-			return err
+			return wrapError(err, errs.ErrRestaurantNotFound)
 		}
 	}
 
@@ -3,6 +3,8 @@ package postgre
 import (
 	"context"
 	"github.com/alibekabdrakhman1/orynal/internal/model"
+	"github.com/alibekabdrakhman1/orynal/pkg/errs"
+	"github.com/alibekabdrakhman1/orynal/pkg/tracing"
 	"gorm.io/gorm"
 )
 
@@ -17,29 +19,24 @@ type ReviewsRepository struct {
 }
 
 func (r *ReviewsRepository) GetReviews(ctx context.Context, restaurantID uint, params *model.Params) (*model.ListResponse, error) {
+	ctx, span := tracing.Start(ctx, "ReviewsRepository.GetReviews")
+	defer span.End()
+
 	var reviews []*model.RestaurantReview
-	query := r.DB.Table("restaurant_reviews").
+	query := r.DB.WithContext(ctx).Table("restaurant_reviews").
 		Where("restaurant_id = ?", restaurantID).
-		Preload("User").
-		Limit(params.Limit).
-		Offset(params.Offset)
+		Preload("User")
+	query = paginate(filter(query, params, "restaurant_reviews"), params, "restaurant_reviews")
 
 	if err := query.Find(&reviews).Error; err != nil {
-		return nil, err
-	}
-
-	for i := 0; i < len(reviews); i++ {
-		var user model.UserResponse
-		if err := r.DB.Table("users").Where("id = ?", reviews[i].UserID).First(&user).Error; err != nil {
-			return nil, err
-		}
-
-		reviews[i].User = user
+		return nil, wrapError(err, errs.ErrReviewNotFound)
 	}
+	reviews, next, prev := model.KeysetPage(reviews, params, func(review *model.RestaurantReview) uint { return review.ID })
 
 	var totalItems int64
-	if err := r.DB.Model(&model.RestaurantReview{}).Where("restaurant_id = ?", restaurantID).Count(&totalItems).Error; err != nil {
-		return nil, err
+	countQuery := r.DB.WithContext(ctx).Model(&model.RestaurantReview{}).Where("restaurant_id = ?", restaurantID)
+	if err := filter(countQuery, params, "restaurant_reviews").Count(&totalItems).Error; err != nil {
+		return nil, wrapError(err, errs.ErrReviewNotFound)
 	}
 
 	return &model.ListResponse{
@@ -47,17 +44,22 @@ func (r *ReviewsRepository) GetReviews(ctx context.Context, restaurantID uint, p
 		ItemsPerPage: params.Limit,
 		PageIndex:    params.PageIndex,
 		TotalItems:   int(totalItems),
+		NextCursor:   next,
+		PrevCursor:   prev,
 	}, nil
 }
 
 func (r *ReviewsRepository) CreateReview(ctx context.Context, review *model.RestaurantReview) (*model.RestaurantReview, error) {
+	ctx, span := tracing.Start(ctx, "ReviewsRepository.CreateReview")
+	defer span.End()
+
 	if err := r.DB.WithContext(ctx).Table("restaurant_reviews").Create(review).Error; err != nil {
-		return nil, err
+		return nil, wrapError(err, errs.ErrReviewNotFound)
 	}
 
 	var user model.UserResponse
-	if err := r.DB.Table("users").Where("id = ?", review.UserID).First(&user).Error; err != nil {
-		return nil, err
+	if err := r.DB.WithContext(ctx).Table("users").Where("id = ?", review.UserID).First(&user).Error; err != nil {
+		return nil, wrapError(err, errs.ErrReviewNotFound)
 	}
 
 	review.User = user
@@ -66,23 +68,44 @@ func (r *ReviewsRepository) CreateReview(ctx context.Context, review *model.Rest
 }
 
 func (r *ReviewsRepository) DeleteReview(ctx context.Context, id uint) error {
+	ctx, span := tracing.Start(ctx, "ReviewsRepository.DeleteReview")
+	defer span.End()
+
 	if err := r.DB.WithContext(ctx).Table("restaurant_reviews").Where("id = ?", id).Delete(&model.RestaurantReview{}).Error; err != nil {
-		return err
+		return wrapError(err, errs.ErrReviewNotFound)
 	}
 	return nil
 }
 
 func (r *ReviewsRepository) GetReview(ctx context.Context, id uint) (*model.RestaurantReview, error) {
+	ctx, span := tracing.Start(ctx, "ReviewsRepository.GetReview")
+	defer span.End()
+
 	var review model.RestaurantReview
 	if err := r.DB.WithContext(ctx).Table("restaurant_reviews").First(&review, id).Error; err != nil {
-		return nil, err
+		return nil, wrapError(err, errs.ErrReviewNotFound)
 	}
 
 	var user model.UserResponse
-	if err := r.DB.Table("users").Where("id = ?", review.UserID).First(&user).Error; err != nil {
-		return nil, err
+	if err := r.DB.WithContext(ctx).Table("users").Where("id = ?", review.UserID).First(&user).Error; err != nil {
+		return nil, wrapError(err, errs.ErrReviewNotFound)
 	}
 
 	review.User = user
 	return &review, nil
 }
+
+func (r *ReviewsRepository) GetUserReviews(ctx context.Context, userID uint) ([]model.RestaurantReview, error) {
+	ctx, span := tracing.Start(ctx, "ReviewsRepository.GetUserReviews")
+	defer span.End()
+
+	var reviews []model.RestaurantReview
+	if err := r.DB.WithContext(ctx).Table("restaurant_reviews").
+		Where("user_id = ?", userID).
+		Order("date DESC").
+		Find(&reviews).Error; err != nil {
+		return nil, wrapError(err, errs.ErrReviewNotFound)
+	}
+
+	return reviews, nil
+}
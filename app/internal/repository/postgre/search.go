@@ -0,0 +1,341 @@
+package postgre
+
+import (
+	"context"
+	"github.com/alibekabdrakhman1/orynal/internal/model"
+	"github.com/alibekabdrakhman1/orynal/pkg/errs"
+	"github.com/alibekabdrakhman1/orynal/pkg/tracing"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"html"
+	"strings"
+)
+
+func NewSearchRepository(db *gorm.DB) *SearchRepository {
+	return &SearchRepository{
+		DB: db,
+	}
+}
+
+type SearchRepository struct {
+	DB *gorm.DB
+}
+
+// ts_headline and the trigram snippet mark matches with private use
+// characters, which markup turns into <mark> once the snippet is escaped.
+const (
+	markStart = "\uE000"
+	markStop  = "\uE001"
+)
+
+var headlineOptions = `StartSel="` + markStart + `", StopSel="` + markStop + `", MaxFragments=2, MaxWords=20, MinWords=5, FragmentDelimiter=" … "`
+
+const fullTextFrom = `FROM restaurants
+JOIN restaurant_search ON restaurant_search.restaurant_id = restaurants.id
+CROSS JOIN search_tsquery(@q) AS query`
+
+const fullTextWhere = `WHERE restaurants.deleted_at IS NULL AND restaurant_search.document @@ query`
+
+const fullTextSearch = `SELECT restaurants.*,
+	ts_rank_cd(restaurant_search.document, query) AS rank,
+	ts_headline('russian', concat_ws(' — ', restaurants.name, restaurants.city, restaurants.description, dishes.names), query, @options) AS snippet
+` + fullTextFrom + `
+LEFT JOIN LATERAL (
+	SELECT string_agg(foods.name, ', ' ORDER BY foods.id) AS names
+	FROM foods WHERE foods.restaurant_id = restaurants.id AND foods.deleted_at IS NULL
+) AS dishes ON true
+` + fullTextWhere + `
+ORDER BY rank DESC, restaurants.id
+LIMIT @limit OFFSET @offset`
+
+// trigramWhere matches names within a typo or two of q; <% compares q with
+// the most similar run of words and can use the gin_trgm_ops indexes.
+const trigramWhere = `WHERE restaurants.deleted_at IS NULL AND (@q <% restaurants.name OR EXISTS (
+	SELECT 1 FROM foods
+	WHERE foods.restaurant_id = restaurants.id AND foods.deleted_at IS NULL AND @q <% foods.name
+))`
+
+const trigramSearch = `SELECT restaurants.*,
+	greatest(word_similarity(@q, restaurants.name), coalesce(dish.similarity, 0)) AS rank,
+	CASE WHEN word_similarity(@q, restaurants.name) >= coalesce(dish.similarity, 0)
+		THEN @start || restaurants.name || @stop
+		ELSE restaurants.name || ' — ' || @start || dish.name || @stop
+	END AS snippet
+FROM restaurants
+LEFT JOIN LATERAL (
+	SELECT foods.name, word_similarity(@q, foods.name) AS similarity
+	FROM foods WHERE foods.restaurant_id = restaurants.id AND foods.deleted_at IS NULL
+	ORDER BY similarity DESC, foods.id
+	LIMIT 1
+) AS dish ON true
+` + trigramWhere + `
+ORDER BY rank DESC, restaurants.id
+LIMIT @limit OFFSET @offset`
+
+type searchRow struct {
+	model.Restaurant
+	Rank    float64
+	Snippet string
+}
+
+// SearchRestaurants ranks restaurants by a full-text match of params.Query
+// against their document in restaurant_search, falling back to trigram
+// similarity of restaurant and dish names when nothing matches. SQLite has
+// neither and matches substrings.
+func (r *SearchRepository) SearchRestaurants(ctx context.Context, params *model.Params) (*model.ListResponse, error) {
+	ctx, span := tracing.Start(ctx, "SearchRepository.SearchRestaurants")
+	defer span.End()
+
+	if r.DB.Dialector.Name() == "sqlite" {
+		return r.searchSubstrings(ctx, params)
+	}
+
+	args := map[string]any{
+		"q":       params.Query,
+		"options": headlineOptions,
+		"start":   markStart,
+		"stop":    markStop,
+		"limit":   params.Limit,
+		"offset":  params.Offset,
+	}
+
+	fuzzy := false
+	rows, total, err := r.search(ctx, "SELECT count(*) "+fullTextFrom+"\n"+fullTextWhere, fullTextSearch, args)
+	if err == nil && total == 0 {
+		fuzzy = true
+		rows, total, err = r.search(ctx, "SELECT count(*) FROM restaurants\n"+trigramWhere, trigramSearch, args)
+	}
+	if err != nil {
+		return nil, wrapError(err, errs.ErrRestaurantNotFound)
+	}
+
+	restaurants := make([]model.Restaurant, len(rows))
+	for i := range rows {
+		restaurants[i] = rows[i].Restaurant
+	}
+	if err := loadIcons(ctx, r.DB, restaurants); err != nil {
+		return nil, wrapError(err, errs.ErrRestaurantNotFound)
+	}
+
+	hits := make([]model.SearchHit, len(rows))
+	for i, row := range rows {
+		hits[i] = model.SearchHit{Restaurant: restaurants[i], Rank: row.Rank, Snippet: markup(row.Snippet), Fuzzy: fuzzy}
+	}
+
+	return &model.ListResponse{
+		Items:        hits,
+		ItemsPerPage: params.Limit,
+		PageIndex:    params.PageIndex,
+		TotalItems:   int(total),
+	}, nil
+}
+
+func (r *SearchRepository) search(ctx context.Context, countQuery, query string, args map[string]any) ([]searchRow, int64, error) {
+	var total int64
+	if err := r.DB.WithContext(ctx).Raw(countQuery, args).Scan(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if total <= int64(args["offset"].(int)) {
+		return nil, total, nil
+	}
+
+	var rows []searchRow
+	if err := r.DB.WithContext(ctx).Raw(query, args).Scan(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return rows, total, nil
+}
+
+// searchSubstrings matches in Go rather than with LIKE, whose case folding
+// in SQLite is ASCII only; SQLite databases are local and small.
+func (r *SearchRepository) searchSubstrings(ctx context.Context, params *model.Params) (*model.ListResponse, error) {
+	var restaurants []model.Restaurant
+	if err := r.DB.WithContext(ctx).Order("id").Find(&restaurants).Error; err != nil {
+		return nil, wrapError(err, errs.ErrRestaurantNotFound)
+	}
+
+	var foods []model.Food
+	if err := r.DB.WithContext(ctx).Order("id").Find(&foods).Error; err != nil {
+		return nil, wrapError(err, errs.ErrFoodNotFound)
+	}
+	dishes := map[uint][]model.Food{}
+	for _, food := range foods {
+		dishes[food.RestaurantID] = append(dishes[food.RestaurantID], food)
+	}
+
+	list := model.SubstringSearch(restaurants, dishes, params)
+
+	hits := list.Items.([]model.SearchHit)
+	page := make([]model.Restaurant, len(hits))
+	for i := range hits {
+		page[i] = hits[i].Restaurant
+	}
+	if err := loadIcons(ctx, r.DB, page); err != nil {
+		return nil, wrapError(err, errs.ErrRestaurantNotFound)
+	}
+	for i := range hits {
+		hits[i].Restaurant = page[i]
+	}
+
+	return list, nil
+}
+
+// dishTextSQL uses the idx_foods_search and idx_foods_name_trgm indexes, so
+// typos in a dish name still match.
+const dishTextSQL = `(search_tsvector(foods.name || ' ' || coalesce(foods.description, '')) @@ search_tsquery(?) OR ? <% foods.name)`
+
+type dishGroupRow struct {
+	RestaurantID uint
+	Dishes       int
+	Distance     *float64
+}
+
+// SearchDishes pages the restaurants serving dishes that match search,
+// nearest first when search.Near is set, then those with the most matching
+// dishes, and loads up to model.DishesPerGroup of the dishes of each.
+func (r *SearchRepository) SearchDishes(ctx context.Context, search *model.DishSearch) (*model.ListResponse, error) {
+	ctx, span := tracing.Start(ctx, "SearchRepository.SearchDishes")
+	defer span.End()
+
+	text, err := r.dishText(ctx, search)
+	if err != nil {
+		return nil, wrapError(err, errs.ErrFoodNotFound)
+	}
+
+	var total int64
+	if err := r.dishes(ctx, search, text).Distinct("foods.restaurant_id").Count(&total).Error; err != nil {
+		return nil, wrapError(err, errs.ErrFoodNotFound)
+	}
+
+	groups := []model.DishGroup{}
+	if total > int64(search.Offset) {
+		if groups, err = r.dishGroups(ctx, search, text); err != nil {
+			return nil, wrapError(err, errs.ErrFoodNotFound)
+		}
+	}
+
+	return &model.ListResponse{
+		Items:        groups,
+		ItemsPerPage: search.Limit,
+		PageIndex:    search.PageIndex,
+		TotalItems:   int(total),
+	}, nil
+}
+
+func (r *SearchRepository) dishGroups(ctx context.Context, search *model.DishSearch, text clause.Expression) ([]model.DishGroup, error) {
+	grouped := r.dishes(ctx, search, text).Select("restaurants.id AS restaurant_id, count(*) AS dishes").Group("restaurants.id")
+	order := "dishes DESC, restaurant_id"
+	if search.Near != nil {
+		grouped = r.dishes(ctx, search, text).
+			Select("restaurants.id AS restaurant_id, count(*) AS dishes, "+distanceSQL+" AS distance", distanceVars(*search.Near)...).
+			Group("restaurants.id")
+		order = "distance IS NULL, distance, " + order
+	}
+
+	var rows []dishGroupRow
+	if err := r.DB.WithContext(ctx).Table("(?) AS groups", grouped).
+		Order(order).Limit(search.Limit).Offset(search.Offset).
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	ids := collect(rows, func(row dishGroupRow) uint { return row.RestaurantID })
+
+	var restaurants []model.Restaurant
+	if err := r.DB.WithContext(ctx).Where("id IN ?", ids).Find(&restaurants).Error; err != nil {
+		return nil, err
+	}
+	if err := loadIcons(ctx, r.DB, restaurants); err != nil {
+		return nil, err
+	}
+
+	ranked := r.dishes(ctx, search, text).
+		Select("foods.*, row_number() OVER (PARTITION BY foods.restaurant_id ORDER BY foods.id) AS position").
+		Where("foods.restaurant_id IN ?", ids)
+	var foods []model.Food
+	if err := r.DB.WithContext(ctx).Table("(?) AS foods", ranked).
+		Where("position <= ?", model.DishesPerGroup).Order("id").
+		Find(&foods).Error; err != nil {
+		return nil, err
+	}
+	if err := loadFoodPhotos(ctx, r.DB, foods); err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uint]model.Restaurant, len(restaurants))
+	for _, restaurant := range restaurants {
+		byID[restaurant.ID] = restaurant
+	}
+	dishes := make(map[uint][]model.Food, len(rows))
+	for _, food := range foods {
+		dishes[food.RestaurantID] = append(dishes[food.RestaurantID], food)
+	}
+
+	groups := make([]model.DishGroup, len(rows))
+	for i, row := range rows {
+		summary := byID[row.RestaurantID].Summary()
+		summary.DistanceM = row.Distance
+		groups[i] = model.DishGroup{Restaurant: summary, Dishes: dishes[row.RestaurantID], TotalDishes: row.Dishes}
+	}
+
+	return groups, nil
+}
+
+// dishes selects the available dishes of restaurants that meet search.
+func (r *SearchRepository) dishes(ctx context.Context, search *model.DishSearch, text clause.Expression) *gorm.DB {
+	query := r.DB.WithContext(ctx).Table("foods").
+		Joins("JOIN restaurants ON restaurants.id = foods.restaurant_id AND restaurants.deleted_at IS NULL").
+		Where("foods.deleted_at IS NULL").
+		Where(clause.Eq{Column: clause.Column{Table: "foods", Name: "available"}, Value: true})
+
+	if search.MinPrice != nil {
+		query = query.Where(clause.Gte{Column: clause.Column{Table: "foods", Name: "price"}, Value: *search.MinPrice})
+	}
+	if search.MaxPrice != nil {
+		query = query.Where(clause.Lte{Column: clause.Column{Table: "foods", Name: "price"}, Value: *search.MaxPrice})
+	}
+	if search.Category != "" {
+		query = query.Where("LOWER(foods.type) = LOWER(?)", search.Category)
+	}
+	if search.City != "" {
+		query = query.Where("LOWER(restaurants.city) = LOWER(?)", search.City)
+	}
+	for _, column := range search.Diets {
+		query = query.Where(clause.Eq{Column: clause.Column{Table: "foods", Name: column}, Value: true})
+	}
+	if text != nil {
+		query = query.Where(text)
+	}
+
+	return query
+}
+
+// dishText is the condition matching search.Query against dishes. SQLite
+// has no full-text search, so the ids of the dishes whose text contains it
+// are collected in Go, as searchSubstrings does.
+func (r *SearchRepository) dishText(ctx context.Context, search *model.DishSearch) (clause.Expression, error) {
+	if search.Query == "" {
+		return nil, nil
+	}
+	if r.DB.Dialector.Name() != "sqlite" {
+		return clause.Expr{SQL: dishTextSQL, Vars: []any{search.Query, search.Query}}, nil
+	}
+
+	var foods []model.Food
+	if err := r.DB.WithContext(ctx).Select("id", "name", "description").Order("id").Find(&foods).Error; err != nil {
+		return nil, err
+	}
+	ids := []any{}
+	for _, food := range foods {
+		if search.MatchesText(food) {
+			ids = append(ids, food.ID)
+		}
+	}
+
+	return clause.IN{Column: clause.Column{Table: "foods", Name: "id"}, Values: ids}, nil
+}
+
+// markup escapes a snippet for HTML and turns the match marks into <mark>.
+func markup(snippet string) string {
+	return strings.NewReplacer(markStart, "<mark>", markStop, "</mark>").Replace(html.EscapeString(snippet))
+}
@@ -3,6 +3,8 @@ package postgre
 import (
 	"context"
 	"github.com/alibekabdrakhman1/orynal/internal/model"
+	"github.com/alibekabdrakhman1/orynal/pkg/errs"
+	"github.com/alibekabdrakhman1/orynal/pkg/tracing"
 	"gorm.io/gorm"
 )
 
@@ -17,41 +19,53 @@ type ServicesRepository struct {
 }
 
 func (r *ServicesRepository) CreateService(ctx context.Context, service *model.Service) ([]model.Service, error) {
+	ctx, span := tracing.Start(ctx, "ServicesRepository.CreateService")
+	defer span.End()
+
 	if err := r.DB.WithContext(ctx).Table("services").Create(service).Error; err != nil {
-		return nil, err
+		return nil, wrapError(err, errs.ErrServiceNotFound)
 	}
 
 	services, err := r.GetServices(ctx)
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err, errs.ErrServiceNotFound)
 	}
 
 	return services, nil
 }
 
 func (r *ServicesRepository) DeleteService(ctx context.Context, id uint) error {
+	ctx, span := tracing.Start(ctx, "ServicesRepository.DeleteService")
+	defer span.End()
+
 	if err := r.DB.WithContext(ctx).Table("services").Where("id = ?", id).Delete(&model.Service{}).Error; err != nil {
-		return err
+		return wrapError(err, errs.ErrServiceNotFound)
 	}
 	return nil
 }
 
 func (r *ServicesRepository) GetServices(ctx context.Context) ([]model.Service, error) {
+	ctx, span := tracing.Start(ctx, "ServicesRepository.GetServices")
+	defer span.End()
+
 	var services []model.Service
 	if err := r.DB.WithContext(ctx).Table("services").Find(&services).Error; err != nil {
-		return nil, err
+		return nil, wrapError(err, errs.ErrServiceNotFound)
 	}
 	return services, nil
 }
 
 func (r *ServicesRepository) UpdateService(ctx context.Context, service *model.Service) ([]model.Service, error) {
+	ctx, span := tracing.Start(ctx, "ServicesRepository.UpdateService")
+	defer span.End()
+
 	if err := r.DB.WithContext(ctx).Table("services").Model(&model.Service{}).Where("id = ?", service.ID).Updates(service).Error; err != nil {
-		return nil, err
+		return nil, wrapError(err, errs.ErrServiceNotFound)
 	}
 
 	services, err := r.GetServices(ctx)
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err, errs.ErrServiceNotFound)
 	}
 
 	return services, nil
@@ -2,8 +2,9 @@ package postgre
 
 import (
 	"context"
-	"errors"
 	"github.com/alibekabdrakhman1/orynal/internal/model"
+	"github.com/alibekabdrakhman1/orynal/pkg/errs"
+	"github.com/alibekabdrakhman1/orynal/pkg/tracing"
 	"gorm.io/gorm"
 	"time"
 )
@@ -19,19 +20,25 @@ type TableRepository struct {
 }
 
 func (r *TableRepository) GetTableCategories(ctx context.Context, restaurantID uint) ([]string, error) {
+	ctx, span := tracing.Start(ctx, "TableRepository.GetTableCategories")
+	defer span.End()
+
 	var types []string
 
 	if err := r.DB.WithContext(ctx).Table("tables").
 		Select("DISTINCT type").
 		Where("restaurant_id = ?", restaurantID).
 		Pluck("type", &types).Error; err != nil {
-		return nil, err
+		return nil, wrapError(err, errs.ErrTableNotFound)
 	}
 
 	return types, nil
 }
 
 func (r *TableRepository) GetRestaurantTables(ctx context.Context, restaurantID uint, params *model.Params) (*model.ListResponse, error) {
+	ctx, span := tracing.Start(ctx, "TableRepository.GetRestaurantTables")
+	defer span.End()
+
 	var tables []model.Table
 	var totalItems int64
 
@@ -45,17 +52,15 @@ func (r *TableRepository) GetRestaurantTables(ctx context.Context, restaurantID
 	}
 
 	if params.Date != nil {
+		from, to := dayRange(*params.Date)
 		countQuery = countQuery.
-			Joins("LEFT JOIN orders o ON tables.id = o.table_id AND o.date::date = ? AND tables.restaurant_id = ?", params.Date, restaurantID).
+			Joins("LEFT JOIN orders o ON tables.id = o.table_id AND o.date >= ? AND o.date < ? AND tables.restaurant_id = ?", from, to, restaurantID).
 			Where("o.id IS NULL")
 	}
 
+	countQuery = filter(countQuery, params, "tables")
 	if err := countQuery.Count(&totalItems).Error; err != nil {
-		return nil, err
-	}
-
-	if int64(params.Offset) >= totalItems {
-		return nil, errors.New("offset exceeds total items")
+		return nil, wrapError(err, errs.ErrTableNotFound)
 	}
 
 	query := r.DB.WithContext(ctx).
@@ -67,29 +72,21 @@ func (r *TableRepository) GetRestaurantTables(ctx context.Context, restaurantID
 	}
 
 	if params.Date != nil {
+		from, to := dayRange(*params.Date)
 		query = query.
-			Joins("LEFT JOIN orders o ON tables.id = o.table_id AND o.date::date = ? AND tables.restaurant_id = ?", params.Date, restaurantID).
+			Joins("LEFT JOIN orders o ON tables.id = o.table_id AND o.date >= ? AND o.date < ? AND tables.restaurant_id = ?", from, to, restaurantID).
 			Where("o.id IS NULL")
 	}
 
-	query = query.Limit(params.Limit).
-		Offset(params.Offset)
-
-	if params.Order != nil && params.SortVector != nil {
-		query = query.Order(params.Order.(string) + " " + params.SortVector.(string))
-	}
+	query = paginate(filter(query, params, "tables"), params, "tables")
 
 	if err := query.Find(&tables).Error; err != nil {
-		return nil, err
+		return nil, wrapError(err, errs.ErrTableNotFound)
 	}
+	tables, _, _ = model.KeysetPage(tables, params, func(table model.Table) uint { return table.ID })
 
-	for i := 0; i < len(tables); i++ {
-		var photo model.Photo
-		if err := r.DB.Table("photos").Where("id = ?", tables[i].PhotoID).First(&photo).Error; err != nil {
-			continue
-		}
-
-		tables[i].Photo = photo
+	if err := loadTablePhotos(ctx, r.DB, tables); err != nil {
+		return nil, wrapError(err, errs.ErrTableNotFound)
 	}
 
 	return &model.ListResponse{
@@ -101,55 +98,96 @@ func (r *TableRepository) GetRestaurantTables(ctx context.Context, restaurantID
 }
 
 func (r *TableRepository) GetRestaurantTable(ctx context.Context, restaurantID uint, tableID uint) (*model.Table, error) {
+	ctx, span := tracing.Start(ctx, "TableRepository.GetRestaurantTable")
+	defer span.End()
+
 	var table model.Table
 	if err := r.DB.WithContext(ctx).Where("restaurant_id = ? AND id = ?", restaurantID, tableID).First(&table).Error; err != nil {
-		return nil, err
+		return nil, wrapError(err, errs.ErrTableNotFound)
 	}
 
-	var photo model.Photo
-	if err := r.DB.Table("photos").Where("id = ?", table.PhotoID).First(&photo).Error; err != nil {
-		return nil, err
+	tables := []model.Table{table}
+	if err := loadTablePhotos(ctx, r.DB, tables); err != nil {
+		return nil, wrapError(err, errs.ErrTableNotFound)
 	}
 
-	table.Photo = photo
-
-	return &table, nil
+	return &tables[0], nil
 }
 
 func (r *TableRepository) CreateTable(ctx context.Context, table *model.Table) (*model.Table, error) {
+	ctx, span := tracing.Start(ctx, "TableRepository.CreateTable")
+	defer span.End()
+
+	table.Version = 1
 	if err := r.DB.WithContext(ctx).Create(table).Error; err != nil {
-		return nil, err
+		return nil, wrapError(err, errs.ErrTableNotFound)
 	}
 	return table, nil
 }
 
 func (r *TableRepository) UpdateTable(ctx context.Context, table *model.Table) (*model.Table, error) {
+	ctx, span := tracing.Start(ctx, "TableRepository.UpdateTable")
+	defer span.End()
+
 	var ot model.Table
 	if err := r.DB.WithContext(ctx).First(&ot, table.ID).Error; err != nil {
-		return nil, err
+		return nil, wrapError(err, errs.ErrTableNotFound)
+	}
+
+	if table.Version != 0 && table.Version != ot.Version {
+		return nil, errs.ErrVersionMismatch
+	}
+	table.Version = ot.Version + 1
+
+	result := r.DB.WithContext(ctx).Model(&ot).Where("version = ?", ot.Version).Updates(table)
+	if result.Error != nil {
+		return nil, wrapError(result.Error, errs.ErrTableNotFound)
 	}
+	if result.RowsAffected == 0 {
+		return nil, errs.ErrVersionMismatch
+	}
+
+	return table, nil
+}
+
+func (r *TableRepository) PatchTable(ctx context.Context, table *model.Table, fields []string) (*model.Table, error) {
+	ctx, span := tracing.Start(ctx, "TableRepository.PatchTable")
+	defer span.End()
 
-	if err := r.DB.WithContext(ctx).Model(&ot).Updates(table).Error; err != nil {
-		return nil, err
+	if err := patchVersioned(r.DB.WithContext(ctx), table, &table.Version, fields); err != nil {
+		return nil, wrapError(err, errs.ErrTableNotFound)
 	}
 
 	return table, nil
 }
 
 func (r *TableRepository) DeleteTable(ctx context.Context, id uint) error {
+	ctx, span := tracing.Start(ctx, "TableRepository.DeleteTable")
+	defer span.End()
+
 	var table model.Table
 	if err := r.DB.WithContext(ctx).First(&table, id).Error; err != nil {
-		return err
+		return wrapError(err, errs.ErrTableNotFound)
 	}
 
 	if err := r.DB.WithContext(ctx).Delete(&table).Error; err != nil {
-		return err
+		return wrapError(err, errs.ErrTableNotFound)
 	}
 
 	return nil
 }
 
 func (r *TableRepository) GetAvailableTime(ctx context.Context, date time.Time) ([]time.Time, error) {
+	ctx, span := tracing.Start(ctx, "TableRepository.GetAvailableTime")
+	defer span.End()
+
 	//TODO implement me
 	panic("implement me")
 }
+
+// dayRange returns the bounds of the calendar day of date. Comparing against
+// them instead of casting o.date works on every dialect and can use an index.
+func dayRange(date time.Time) (time.Time, time.Time) {
+	from := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+	return from, from.AddDate(0, 0, 1)
+}
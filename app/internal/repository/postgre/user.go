@@ -2,11 +2,14 @@ package postgre
 
 import (
 	"context"
-	"errors"
+	"fmt"
 	"github.com/alibekabdrakhman1/orynal/internal/model"
 	"github.com/alibekabdrakhman1/orynal/pkg/enums"
+	"github.com/alibekabdrakhman1/orynal/pkg/errs"
+	"github.com/alibekabdrakhman1/orynal/pkg/tracing"
 	"github.com/alibekabdrakhman1/orynal/pkg/utils"
 	"gorm.io/gorm"
+	"time"
 )
 
 type UserRepository struct {
@@ -20,13 +23,16 @@ func NewUserRepository(db *gorm.DB) *UserRepository {
 }
 
 func (r *UserRepository) Create(ctx context.Context, user *model.User) (*model.UserResponse, error) {
+	ctx, span := tracing.Start(ctx, "UserRepository.Create")
+	defer span.End()
+
 	result := r.DB.WithContext(ctx).Create(&user)
 	if result.Error != nil {
-		return nil, result.Error
+		return nil, wrapError(result.Error, errs.ErrUserNotFound)
 	}
 
 	if result.RowsAffected == 0 {
-		return nil, errors.New("unable to create user")
+		return nil, errs.ErrAlreadyExists.WithMessage("unable to create user")
 	}
 
 	return &model.UserResponse{
@@ -40,17 +46,21 @@ func (r *UserRepository) Create(ctx context.Context, user *model.User) (*model.U
 }
 
 func (r *UserRepository) Update(ctx context.Context, user *model.User) (*model.UserResponse, error) {
+	ctx, span := tracing.Start(ctx, "UserRepository.Update")
+	defer span.End()
+
 	var oldUser model.User
 	if err := r.DB.WithContext(ctx).First(&oldUser, user.ID).Error; err != nil {
-		return nil, err
+		return nil, wrapError(err, errs.ErrUserNotFound)
 	}
 
-	if oldUser.Role != user.Role {
-		return nil, errors.New("you cannot change user role")
+	// An empty role keeps the current one, like every other zero field.
+	if user.Role != "" && oldUser.Role != user.Role {
+		return nil, errs.ErrRoleChangeForbidden
 	}
 
 	if err := r.DB.WithContext(ctx).Model(&oldUser).Updates(user).Error; err != nil {
-		return nil, err
+		return nil, wrapError(err, errs.ErrUserNotFound)
 	}
 
 	return &model.UserResponse{
@@ -63,64 +73,271 @@ func (r *UserRepository) Update(ctx context.Context, user *model.User) (*model.U
 	}, nil
 }
 
+func (r *UserRepository) Patch(ctx context.Context, user *model.User, fields []string) (*model.UserResponse, error) {
+	ctx, span := tracing.Start(ctx, "UserRepository.Patch")
+	defer span.End()
+
+	result := r.DB.WithContext(ctx).Model(user).Select(fields).Updates(user)
+	if result.Error == nil && result.RowsAffected == 0 {
+		result.Error = errs.ErrUserNotFound
+	}
+	if result.Error != nil {
+		return nil, wrapError(result.Error, errs.ErrUserNotFound)
+	}
+
+	return r.GetByID(ctx, user.ID)
+}
+
 func (r *UserRepository) ChangePassword(ctx context.Context, id uint, pass *model.ChangePasswordRequest) error {
+	ctx, span := tracing.Start(ctx, "UserRepository.ChangePassword")
+	defer span.End()
+
 	var oldUser model.User
 	if err := r.DB.WithContext(ctx).First(&oldUser, id).Error; err != nil {
-		return err
+		return wrapError(err, errs.ErrUserNotFound)
 	}
 
 	if utils.CheckPassword(pass.OldPassword, oldUser.Password) != nil {
-		return errors.New("wrong old password")
+		return errs.ErrWrongPassword
 	}
 
 	oldUser.Password = pass.NewPassword
 
 	if err := r.DB.WithContext(ctx).Save(&oldUser).Error; err != nil {
-		return err
+		return wrapError(err, errs.ErrUserNotFound)
+	}
+
+	return nil
+}
+
+// SetPassword stores an already hashed password without checking the old one.
+func (r *UserRepository) SetPassword(ctx context.Context, id uint, password string) error {
+	ctx, span := tracing.Start(ctx, "UserRepository.SetPassword")
+	defer span.End()
+
+	result := r.DB.WithContext(ctx).
+		Model(&model.User{}).
+		Where("id = ?", id).
+		Update("password", password)
+	if result.Error != nil {
+		return wrapError(result.Error, errs.ErrUserNotFound)
+	}
+
+	if result.RowsAffected == 0 {
+		return errs.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// SetRole changes the role that Update refuses to touch.
+func (r *UserRepository) SetRole(ctx context.Context, id uint, role string) error {
+	ctx, span := tracing.Start(ctx, "UserRepository.SetRole")
+	defer span.End()
+
+	result := r.DB.WithContext(ctx).
+		Model(&model.User{}).
+		Where("id = ?", id).
+		Update("role", role)
+	if result.Error != nil {
+		return wrapError(result.Error, errs.ErrUserNotFound)
+	}
+
+	if result.RowsAffected == 0 {
+		return errs.ErrUserNotFound
 	}
 
 	return nil
 }
 
 func (r *UserRepository) Delete(ctx context.Context, id uint) error {
+	ctx, span := tracing.Start(ctx, "UserRepository.Delete")
+	defer span.End()
+
 	var user model.User
 	if err := r.DB.WithContext(ctx).First(&user, id).Error; err != nil {
-		return err
+		return wrapError(err, errs.ErrUserNotFound)
 	}
 
 	if err := r.DB.WithContext(ctx).Delete(&user).Error; err != nil {
-		return err
+		return wrapError(err, errs.ErrUserNotFound)
+	}
+
+	return nil
+}
+
+// Disable soft-deletes the user like Delete, but marks the row so retention
+// does not anonymize it.
+func (r *UserRepository) Disable(ctx context.Context, id uint) error {
+	ctx, span := tracing.Start(ctx, "UserRepository.Disable")
+	defer span.End()
+
+	now := time.Now()
+	result := r.DB.WithContext(ctx).
+		Model(&model.User{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{"deleted_at": now, "disabled_at": now})
+	if result.Error != nil {
+		return wrapError(result.Error, errs.ErrUserNotFound)
+	}
+
+	if result.RowsAffected == 0 {
+		return errs.ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (r *UserRepository) Restore(ctx context.Context, id uint, role string) error {
+	ctx, span := tracing.Start(ctx, "UserRepository.Restore")
+	defer span.End()
+
+	query := r.DB.WithContext(ctx).Unscoped().
+		Model(&model.User{}).
+		Where("id = ? AND deleted_at IS NOT NULL AND anonymized_at IS NULL", id)
+	if role != "" {
+		query = query.Where("role = ?", role)
+	}
+	result := query.Updates(map[string]interface{}{"deleted_at": nil, "disabled_at": nil})
+	if result.Error != nil {
+		return wrapError(result.Error, errs.ErrUserNotFound)
+	}
+
+	if result.RowsAffected == 0 {
+		return errs.ErrUserNotFound
 	}
 
 	return nil
 }
 
+func (r *UserRepository) RequestDeletion(ctx context.Context, id uint, at time.Time) error {
+	ctx, span := tracing.Start(ctx, "UserRepository.RequestDeletion")
+	defer span.End()
+
+	result := r.DB.WithContext(ctx).
+		Model(&model.User{}).
+		Where("id = ?", id).
+		Update("deletion_requested_at", at)
+	if result.Error != nil {
+		return wrapError(result.Error, errs.ErrUserNotFound)
+	}
+
+	if result.RowsAffected == 0 {
+		return errs.ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (r *UserRepository) CancelDeletion(ctx context.Context, id uint) error {
+	ctx, span := tracing.Start(ctx, "UserRepository.CancelDeletion")
+	defer span.End()
+
+	if err := r.DB.WithContext(ctx).
+		Model(&model.User{}).
+		Where("id = ?", id).
+		Update("deletion_requested_at", nil).Error; err != nil {
+		return wrapError(err, errs.ErrUserNotFound)
+	}
+
+	return nil
+}
+
+func (r *UserRepository) GetDueForAnonymization(ctx context.Context, requestedBefore time.Time, deletedBefore time.Time) ([]uint, error) {
+	ctx, span := tracing.Start(ctx, "UserRepository.GetDueForAnonymization")
+	defer span.End()
+
+	var ids []uint
+	if err := r.DB.WithContext(ctx).Unscoped().
+		Model(&model.User{}).
+		Where("anonymized_at IS NULL AND disabled_at IS NULL").
+		Where("deletion_requested_at < ? OR deleted_at < ?", requestedBefore, deletedBefore).
+		Pluck("id", &ids).Error; err != nil {
+		return nil, wrapError(err, errs.ErrUserNotFound)
+	}
+
+	return ids, nil
+}
+
+// Anonymize scrubs personal data from the user row and turns it into a
+// tombstone, so orders and reviews stay linked to it instead of being
+// cascade-deleted together with the account.
+func (r *UserRepository) Anonymize(ctx context.Context, id uint) error {
+	ctx, span := tracing.Start(ctx, "UserRepository.Anonymize")
+	defer span.End()
+
+	now := time.Now()
+
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Unscoped().
+			Model(&model.User{}).
+			Where("id = ? AND anonymized_at IS NULL", id).
+			Updates(map[string]interface{}{
+				"name":                  enums.DeletedUserName,
+				"surname":               "",
+				"email":                 fmt.Sprintf("deleted-%d@%s", id, enums.DeletedUserDomain),
+				"phone":                 fmt.Sprintf("deleted-%d", id),
+				"password":              "",
+				"deletion_requested_at": nil,
+				"anonymized_at":         now,
+				"deleted_at":            gorm.Expr("COALESCE(deleted_at, ?)", now),
+			})
+		if result.Error != nil {
+			return wrapError(result.Error, errs.ErrUserNotFound)
+		}
+
+		if result.RowsAffected == 0 {
+			return errs.ErrUserNotFound
+		}
+
+		if err := tx.Where("user_id = ?", id).Delete(&model.UserToken{}).Error; err != nil {
+			return wrapError(err, errs.ErrUserNotFound)
+		}
+
+		if err := tx.Exec("DELETE FROM favorite_restaurants WHERE user_id = ?", id).Error; err != nil {
+			return wrapError(err, errs.ErrUserNotFound)
+		}
+
+		return nil
+	})
+}
+
 func (r *UserRepository) GetByID(ctx context.Context, id uint) (*model.UserResponse, error) {
+	ctx, span := tracing.Start(ctx, "UserRepository.GetByID")
+	defer span.End()
+
 	var user model.User
 	if err := r.DB.WithContext(ctx).First(&user, id).Error; err != nil {
-		return nil, err
+		return nil, wrapError(err, errs.ErrUserNotFound)
 	}
 
 	return &model.UserResponse{
-		ID:      user.ID,
-		Name:    user.Name,
-		Surname: user.Surname,
-		Email:   user.Email,
-		Phone:   user.Phone,
-		Role:    user.Role,
+		ID:                  user.ID,
+		Name:                user.Name,
+		Surname:             user.Surname,
+		Email:               user.Email,
+		Phone:               user.Phone,
+		Role:                user.Role,
+		DeletionRequestedAt: user.DeletionRequestedAt,
 	}, nil
 }
 
 func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*model.User, error) {
+	ctx, span := tracing.Start(ctx, "UserRepository.GetByEmail")
+	defer span.End()
+
 	var user *model.User
 	if err := r.DB.WithContext(ctx).Where("email = ?", email).First(&user).Error; err != nil {
-		return nil, err
+		return nil, wrapError(err, errs.ErrUserNotFound)
 	}
 
 	return user, nil
 }
 
 func (r *UserRepository) GetAllClients(ctx context.Context, params *model.Params) (*model.ListResponse, error) {
+	ctx, span := tracing.Start(ctx, "UserRepository.GetAllClients")
+	defer span.End()
+
 	var clients []model.User
 	var totalItems int64
 
@@ -128,29 +345,22 @@ func (r *UserRepository) GetAllClients(ctx context.Context, params *model.Params
 	if params.Query != "" {
 		countQuery = countQuery.Where("LOWER(name) LIKE LOWER(?)", "%"+params.Query+"%")
 	}
+	countQuery = filter(countQuery, params, "users")
 	if err := countQuery.Model(&model.User{}).Count(&totalItems).Error; err != nil {
-		return nil, err
-	}
-
-	if int64(params.Offset) >= totalItems {
-		return nil, errors.New("offset exceeds total items")
+		return nil, wrapError(err, errs.ErrUserNotFound)
 	}
 
-	query := r.DB.WithContext(ctx).Where("role = ?", enums.User).
-		Limit(params.Limit).
-		Offset(params.Offset)
+	query := r.DB.WithContext(ctx).Where("role = ?", enums.User)
 
 	if params.Query != "" {
 		query = query.Where("LOWER(name) LIKE LOWER(?)", "%"+params.Query+"%")
 	}
-
-	if params.Order != nil && params.SortVector != nil {
-		query.Order(params.Order.(string) + params.SortVector.(string))
-	}
+	query = paginate(filter(query, params, "users"), params, "users")
 
 	if err := query.Find(&clients).Error; err != nil {
-		return nil, err
+		return nil, wrapError(err, errs.ErrUserNotFound)
 	}
+	clients, next, prev := model.KeysetPage(clients, params, func(user model.User) uint { return user.ID })
 
 	var userResponses []model.UserResponse
 	for _, user := range clients {
@@ -169,10 +379,15 @@ func (r *UserRepository) GetAllClients(ctx context.Context, params *model.Params
 		ItemsPerPage: params.Limit,
 		PageIndex:    params.PageIndex,
 		TotalItems:   int(totalItems),
+		NextCursor:   next,
+		PrevCursor:   prev,
 	}, nil
 }
 
 func (r *UserRepository) GetAllOwners(ctx context.Context, params *model.Params) (*model.ListResponse, error) {
+	ctx, span := tracing.Start(ctx, "UserRepository.GetAllOwners")
+	defer span.End()
+
 	var owners []model.User
 	var totalItems int64
 
@@ -180,29 +395,22 @@ func (r *UserRepository) GetAllOwners(ctx context.Context, params *model.Params)
 	if params.Query != "" {
 		countQuery = countQuery.Where("LOWER(name) LIKE LOWER(?)", "%"+params.Query+"%")
 	}
+	countQuery = filter(countQuery, params, "users")
 	if err := countQuery.Model(&model.User{}).Count(&totalItems).Error; err != nil {
-		return nil, err
-	}
-
-	if int64(params.Offset) >= totalItems {
-		return nil, errors.New("offset exceeds total items")
+		return nil, wrapError(err, errs.ErrUserNotFound)
 	}
 
-	query := r.DB.WithContext(ctx).Where("role = ?", enums.Owner).
-		Limit(params.Limit).
-		Offset(params.Offset)
+	query := r.DB.WithContext(ctx).Where("role = ?", enums.Owner)
 
 	if params.Query != "" {
 		query = query.Where("LOWER(name) LIKE LOWER(?)", "%"+params.Query+"%")
 	}
-
-	if params.Order != nil && params.SortVector != nil {
-		query.Order(params.Order.(string) + params.SortVector.(string))
-	}
+	query = paginate(filter(query, params, "users"), params, "users")
 
 	if err := query.Find(&owners).Error; err != nil {
-		return nil, err
+		return nil, wrapError(err, errs.ErrUserNotFound)
 	}
+	owners, next, prev := model.KeysetPage(owners, params, func(user model.User) uint { return user.ID })
 
 	var userResponses []model.UserResponse
 	for _, user := range owners {
@@ -221,5 +429,26 @@ func (r *UserRepository) GetAllOwners(ctx context.Context, params *model.Params)
 		ItemsPerPage: params.Limit,
 		PageIndex:    params.PageIndex,
 		TotalItems:   int(totalItems),
+		NextCursor:   next,
+		PrevCursor:   prev,
 	}, nil
 }
+
+// List returns every user with the role, or all users for an empty role,
+// including soft deleted ones.
+func (r *UserRepository) List(ctx context.Context, role string) ([]model.User, error) {
+	ctx, span := tracing.Start(ctx, "UserRepository.List")
+	defer span.End()
+
+	query := r.DB.WithContext(ctx).Unscoped().Order("id")
+	if role != "" {
+		query = query.Where("role = ?", role)
+	}
+
+	var users []model.User
+	if err := query.Find(&users).Error; err != nil {
+		return nil, wrapError(err, errs.ErrUserNotFound)
+	}
+
+	return users, nil
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"github.com/alibekabdrakhman1/orynal/internal/model"
+	"github.com/alibekabdrakhman1/orynal/pkg/errs"
+	"github.com/alibekabdrakhman1/orynal/pkg/tracing"
 	"gorm.io/gorm"
 )
 
@@ -18,6 +20,9 @@ func NewUserTokenRepository(db *gorm.DB) *UserTokenRepository {
 }
 
 func (r *UserTokenRepository) CreateUserToken(ctx context.Context, userToken model.UserToken) error {
+	ctx, span := tracing.Start(ctx, "UserTokenRepository.CreateUserToken")
+	defer span.End()
+
 	var existingToken model.UserToken
 	result := r.DB.WithContext(ctx).Where("user_id = ?", userToken.UserID).First(&existingToken)
 
@@ -27,15 +32,39 @@ func (r *UserTokenRepository) CreateUserToken(ctx context.Context, userToken mod
 		result = r.DB.WithContext(ctx).Save(&existingToken)
 	} else if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 		if err := r.DB.WithContext(ctx).Create(&userToken).Error; err != nil {
-			return err
+			return wrapError(err, errs.ErrSessionNotFound)
 		}
 	}
 	return nil
 }
 
 func (r *UserTokenRepository) UpdateUserToken(ctx context.Context, userToken model.UserToken) error {
+	ctx, span := tracing.Start(ctx, "UserTokenRepository.UpdateUserToken")
+	defer span.End()
+
 	if err := r.DB.WithContext(ctx).Save(&userToken).Error; err != nil {
-		return err
+		return wrapError(err, errs.ErrSessionNotFound)
+	}
+	return nil
+}
+
+func (r *UserTokenRepository) GetByUserID(ctx context.Context, userID uint) ([]model.UserToken, error) {
+	ctx, span := tracing.Start(ctx, "UserTokenRepository.GetByUserID")
+	defer span.End()
+
+	var tokens []model.UserToken
+	if err := r.DB.WithContext(ctx).Where("user_id = ?", userID).Find(&tokens).Error; err != nil {
+		return nil, wrapError(err, errs.ErrSessionNotFound)
+	}
+	return tokens, nil
+}
+
+func (r *UserTokenRepository) DeleteByUserID(ctx context.Context, userID uint) error {
+	ctx, span := tracing.Start(ctx, "UserTokenRepository.DeleteByUserID")
+	defer span.End()
+
+	if err := r.DB.WithContext(ctx).Where("user_id = ?", userID).Delete(&model.UserToken{}).Error; err != nil {
+		return wrapError(err, errs.ErrSessionNotFound)
 	}
 	return nil
 }
@@ -0,0 +1,91 @@
+package seed
+
+var cities = []string{
+	"Almaty", "Astana", "Shymkent", "Karaganda", "Aktobe", "Taraz", "Pavlodar",
+	"Oskemen", "Semey", "Atyrau", "Kostanay", "Kyzylorda", "Oral", "Petropavl",
+	"Aktau", "Turkistan", "Taldykorgan", "Ekibastuz", "Kokshetau", "Zhezkazgan",
+}
+
+var streets = []string{
+	"Abay Ave", "Dostyk Ave", "Tole Bi St", "Kabanbay Batyr Ave", "Satpayev St",
+	"Zheltoksan St", "Nazarbayev Ave", "Gogol St", "Furmanov St", "Baitursynov St",
+	"Al-Farabi Ave", "Seifullin Ave", "Kunayev St", "Auezov St", "Respublika Ave",
+}
+
+var firstNames = []string{
+	"Aigerim", "Arman", "Aruzhan", "Daniyar", "Dana", "Yerlan", "Kamila", "Nursultan",
+	"Madina", "Timur", "Aliya", "Askar", "Saule", "Bauyrzhan", "Zarina", "Azamat",
+	"Inkar", "Ruslan", "Dinara", "Olzhas", "Anna", "Dmitry", "Elena", "Sergey",
+}
+
+var surnames = []string{
+	"Abenov", "Akhmetova", "Bekov", "Dzhaksybekova", "Iskakov", "Kassymova", "Mukanov",
+	"Nurlanova", "Omarov", "Sadykova", "Serikbayev", "Tokayeva", "Utepov", "Zhumabayeva",
+	"Ivanov", "Petrova", "Kim", "Li", "Sokolov", "Volkova",
+}
+
+var restaurantPrefixes = []string{
+	"Dastarkhan", "Saksaul", "Zhibek Zholy", "Alasha", "Tary", "Baursak", "Navat",
+	"Kishlak", "Line", "Rumi", "Selfie", "Del Papa", "Gakku", "Tiflis", "Khan Tengri",
+}
+
+var restaurantSuffixes = []string{
+	"", "Grill", "House", "Lounge", "Bistro", "Kitchen", "Garden", "Bar", "Cafe", "Terrace",
+}
+
+var descriptions = []string{
+	"Traditional Kazakh cuisine in a cozy family atmosphere.",
+	"Modern European menu with seasonal ingredients.",
+	"Steaks and grill cooked over an open fire.",
+	"Central Asian dishes, live music on weekends.",
+	"Coffee, breakfasts all day and homemade desserts.",
+	"Panoramic city views and a signature cocktail list.",
+	"Georgian recipes, fresh khachapuri from the oven.",
+	"Pan-Asian street food and wok specials.",
+}
+
+// The services table is shared by every restaurant and names are unique.
+var services = []string{
+	"Wi-Fi", "Parking", "Live music", "Kids room", "Summer terrace", "Hookah",
+	"Delivery", "Banquet hall", "Vegetarian menu", "Karaoke", "Sports broadcasts",
+	"Card payment",
+}
+
+type tableType struct {
+	name         string
+	minCapacity  int
+	maxCapacity  int
+	descriptions []string
+}
+
+var tableTypes = []tableType{
+	{"standard", 2, 4, []string{"Table in the main hall.", "Table by the window."}},
+	{"family", 4, 8, []string{"Large table for families and groups."}},
+	{"vip", 4, 12, []string{"Private room with a dedicated waiter."}},
+	{"terrace", 2, 6, []string{"Open-air table on the terrace, seasonal."}},
+	{"bar", 1, 2, []string{"Seat at the bar counter."}},
+}
+
+type menuCategory struct {
+	name     string
+	minPrice float64
+	maxPrice float64
+	dishes   []string
+}
+
+var menuCategories = []menuCategory{
+	{"Salads", 1800, 4500, []string{"Caesar", "Greek salad", "Olivier", "Achichuk", "Warm beef salad", "Burrata with tomatoes"}},
+	{"Soups", 1500, 3500, []string{"Sorpa", "Borscht", "Lagman soup", "Mushroom cream soup", "Tom yum", "Chicken noodle soup"}},
+	{"Hot dishes", 2800, 9000, []string{"Beshbarmak", "Kuyrdak", "Manty", "Plov", "Lagman", "Chicken Kiev", "Duck breast"}},
+	{"Grill", 3500, 15000, []string{"Lamb shashlik", "Beef ribeye", "Chicken kebab", "Lula kebab", "Grilled salmon", "Grilled vegetables"}},
+	{"Desserts", 1200, 3500, []string{"Chak-chak", "Napoleon", "Cheesecake", "Baursaks with honey", "Medovik", "Ice cream"}},
+	{"Drinks", 600, 3000, []string{"Kumys", "Shubat", "Black tea with milk", "Lemonade", "Americano", "Cappuccino", "Fresh juice"}},
+}
+
+var reviewTexts = map[int][]string{
+	1: {"Waited an hour for the food, never again.", "Cold dishes and rude staff."},
+	2: {"Food was fine but the service was slow.", "Too noisy and overpriced."},
+	3: {"Decent place, nothing special.", "Good food, average service."},
+	4: {"Tasty food and friendly waiters.", "Nice atmosphere, will come back.", "Great beshbarmak, a bit crowded."},
+	5: {"Best dinner in town!", "Perfect evening, thank you!", "Excellent food and service, highly recommend."},
+}
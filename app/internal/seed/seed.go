@@ -0,0 +1,460 @@
+// Package seed fills an empty database with realistic demo data. The same
+// seed, size and reference date always produce the same rows.
+package seed
+
+import (
+	"context"
+	"fmt"
+	"github.com/alibekabdrakhman1/orynal/internal/model"
+	"github.com/alibekabdrakhman1/orynal/pkg/enums"
+	"github.com/alibekabdrakhman1/orynal/pkg/geocode"
+	"github.com/alibekabdrakhman1/orynal/pkg/utils"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"math/rand/v2"
+	"time"
+)
+
+// EmailDomain marks seeded accounts, so they can be found and reset.
+const EmailDomain = "seed.orynal.test"
+
+const batchSize = 1000
+
+type Size struct {
+	Cities              int
+	Owners              int
+	Restaurants         int
+	TablesPerRestaurant int
+	FoodsPerRestaurant  int
+	Users               int
+	Orders              int
+	Reviews             int
+}
+
+// Presets are the named sizes accepted by "orynal seed -size".
+var Presets = map[string]Size{
+	"small":  {Cities: 3, Owners: 5, Restaurants: 12, TablesPerRestaurant: 6, FoodsPerRestaurant: 18, Users: 50, Orders: 600, Reviews: 200},
+	"medium": {Cities: 8, Owners: 40, Restaurants: 200, TablesPerRestaurant: 10, FoodsPerRestaurant: 30, Users: 2000, Orders: 25000, Reviews: 8000},
+	"large":  {Cities: 20, Owners: 300, Restaurants: 3000, TablesPerRestaurant: 12, FoodsPerRestaurant: 40, Users: 30000, Orders: 500000, Reviews: 120000},
+}
+
+type Options struct {
+	Seed     uint64
+	Size     Size
+	Password string
+	// Now anchors order and review dates: history lies before it and a few
+	// reservations after it.
+	Now time.Time
+}
+
+type Summary struct {
+	Users       int
+	Restaurants int
+	Tables      int
+	Foods       int
+	Orders      int
+	OrderFoods  int
+	Reviews     int
+}
+
+type Seeder struct {
+	db     *gorm.DB
+	opts   Options
+	rng    *rand.Rand
+	logger *zap.SugaredLogger
+}
+
+type restaurantMenu struct {
+	restaurant model.Restaurant
+	tables     []model.Table
+	foods      []model.Food
+}
+
+func New(db *gorm.DB, opts Options, logger *zap.SugaredLogger) *Seeder {
+	return &Seeder{
+		db:     db,
+		opts:   opts,
+		rng:    rand.New(rand.NewPCG(opts.Seed, opts.Seed^0x9e3779b97f4a7c15)),
+		logger: logger,
+	}
+}
+
+// Exists reports whether seeded accounts are already present.
+func Exists(ctx context.Context, db *gorm.DB) (bool, error) {
+	var count int64
+	err := db.WithContext(ctx).Unscoped().Model(&model.User{}).Where("email LIKE ?", "%@"+EmailDomain).Count(&count).Error
+	return count > 0, err
+}
+
+// Reset deletes seeded accounts together with everything they own or booked.
+// Orders and reviews are removed first because their user keys are RESTRICT.
+func Reset(ctx context.Context, db *gorm.DB) error {
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		seeded := tx.Unscoped().Model(&model.User{}).Select("id").Where("email LIKE ?", "%@"+EmailDomain)
+
+		if err := tx.Exec("DELETE FROM restaurant_reviews WHERE user_id IN (?)", seeded).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM orders WHERE user_id IN (?)", seeded).Error; err != nil {
+			return err
+		}
+		return tx.Exec("DELETE FROM users WHERE email LIKE ?", "%@"+EmailDomain).Error
+	})
+}
+
+// Run inserts everything in one transaction, so a failed run leaves nothing
+// behind.
+func (s *Seeder) Run(ctx context.Context) (*Summary, error) {
+	summary := &Summary{}
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		password, err := utils.HashPassword(s.opts.Password)
+		if err != nil {
+			return err
+		}
+
+		serviceIDs, err := s.seedServices(tx)
+		if err != nil {
+			return err
+		}
+
+		owners, err := s.seedUsers(tx, enums.Owner, s.opts.Size.Owners, password)
+		if err != nil {
+			return err
+		}
+		clients, err := s.seedUsers(tx, enums.User, s.opts.Size.Users, password)
+		if err != nil {
+			return err
+		}
+		admins, err := s.seedUsers(tx, enums.Admin, 1, password)
+		if err != nil {
+			return err
+		}
+		summary.Users = len(owners) + len(clients) + len(admins)
+		s.logger.Infof("seeded %d users", summary.Users)
+
+		menus, err := s.seedRestaurants(tx, owners, serviceIDs)
+		if err != nil {
+			return err
+		}
+		summary.Restaurants = len(menus)
+		for _, menu := range menus {
+			summary.Tables += len(menu.tables)
+			summary.Foods += len(menu.foods)
+		}
+		s.logger.Infof("seeded %d restaurants, %d tables and %d foods", summary.Restaurants, summary.Tables, summary.Foods)
+
+		if summary.Orders, summary.OrderFoods, err = s.seedOrders(tx, menus, clients); err != nil {
+			return err
+		}
+		s.logger.Infof("seeded %d orders", summary.Orders)
+
+		if summary.Reviews, err = s.seedReviews(tx, menus, clients); err != nil {
+			return err
+		}
+		s.logger.Infof("seeded %d reviews", summary.Reviews)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return summary, nil
+}
+
+func (s *Seeder) seedServices(tx *gorm.DB) ([]uint, error) {
+	rows := make([]model.Service, 0, len(services))
+	for _, name := range services {
+		rows = append(rows, model.Service{Name: name})
+	}
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	// Ids of services that already existed are not returned on conflict.
+	var ids []uint
+	if err := tx.Model(&model.Service{}).Where("name IN ?", services).Order("id").Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+func (s *Seeder) seedUsers(tx *gorm.DB, role string, n int, password string) ([]model.User, error) {
+	users := make([]model.User, 0, n)
+	for i := 1; i <= n; i++ {
+		users = append(users, model.User{
+			Name:     pick(s.rng, firstNames),
+			Surname:  pick(s.rng, surnames),
+			Email:    fmt.Sprintf("%s%d@%s", role, i, EmailDomain),
+			Phone:    fmt.Sprintf("+7%d%09d", roleDigit(role), i),
+			Role:     role,
+			Password: password,
+		})
+	}
+	if err := createAll(tx, users); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+func (s *Seeder) seedRestaurants(tx *gorm.DB, owners []model.User, serviceIDs []uint) ([]restaurantMenu, error) {
+	size := s.opts.Size
+	menus := make([]restaurantMenu, 0, size.Restaurants)
+	if len(owners) == 0 || size.Restaurants == 0 {
+		return menus, nil
+	}
+
+	icons := make([]model.Photo, size.Restaurants)
+	for i := range icons {
+		icons[i] = photo("restaurant-icon", i+1)
+	}
+	if err := createAll(tx, icons); err != nil {
+		return nil, err
+	}
+
+	// Every seeded city is known to the offline geocoder, which places the
+	// restaurants without drawing from rng.
+	geocoder := geocode.NewOffline()
+	restaurants := make([]model.Restaurant, 0, size.Restaurants)
+	for i := 0; i < size.Restaurants; i++ {
+		city := cities[s.rng.IntN(max(1, min(size.Cities, len(cities))))]
+		opens := 8 + s.rng.IntN(5)
+		name := restaurantName(s.rng)
+		address := fmt.Sprintf("%s, %d", pick(s.rng, streets), 1+s.rng.IntN(250))
+		location, err := geocoder.Geocode(tx.Statement.Context, address, city)
+		if err != nil {
+			return nil, err
+		}
+		restaurants = append(restaurants, model.Restaurant{
+			Name:        name,
+			Address:     address,
+			Description: pick(s.rng, descriptions),
+			City:        city,
+			Latitude:    &location.Lat,
+			Longitude:   &location.Lng,
+			Status:      s.rng.IntN(10) > 0,
+			Phone:       fmt.Sprintf("+77272%06d", i+1),
+			OwnerID:     owners[i%len(owners)].ID,
+			ModeFrom:    time.Date(2000, 1, 1, opens, 0, 0, 0, time.UTC),
+			ModeTo:      time.Date(2000, 1, 1, opens+12+s.rng.IntN(4), 0, 0, 0, time.UTC),
+			IconID:      icons[i].ID,
+		})
+	}
+	if err := createAll(tx.Omit(clause.Associations), restaurants); err != nil {
+		return nil, err
+	}
+
+	var (
+		links  []model.RestaurantService
+		photos []model.Photo
+	)
+	for _, restaurant := range restaurants {
+		for _, i := range s.rng.Perm(len(serviceIDs))[:min(len(serviceIDs), 2+s.rng.IntN(5))] {
+			links = append(links, model.RestaurantService{ServiceID: serviceIDs[i], RestaurantID: restaurant.ID})
+		}
+		for j := 0; j < 3; j++ {
+			photos = append(photos, photo(fmt.Sprintf("restaurant-%d", restaurant.ID), j+1))
+		}
+	}
+	if err := createAll(tx.Table("restaurant_service"), links); err != nil {
+		return nil, err
+	}
+	if err := createAll(tx, photos); err != nil {
+		return nil, err
+	}
+	gallery := make([]model.RestaurantPhoto, 0, len(photos))
+	for i, p := range photos {
+		gallery = append(gallery, model.RestaurantPhoto{PhotoID: p.ID, RestaurantID: restaurants[i/3].ID})
+	}
+	if err := createAll(tx.Table("restaurant_photos"), gallery); err != nil {
+		return nil, err
+	}
+
+	for _, restaurant := range restaurants {
+		menu, err := s.seedMenu(tx, restaurant)
+		if err != nil {
+			return nil, err
+		}
+		menus = append(menus, menu)
+	}
+
+	return menus, nil
+}
+
+func (s *Seeder) seedMenu(tx *gorm.DB, restaurant model.Restaurant) (restaurantMenu, error) {
+	size := s.opts.Size
+	menu := restaurantMenu{restaurant: restaurant}
+
+	photos := make([]model.Photo, 0, size.TablesPerRestaurant+size.FoodsPerRestaurant)
+	for i := 0; i < size.TablesPerRestaurant+size.FoodsPerRestaurant; i++ {
+		photos = append(photos, photo(fmt.Sprintf("restaurant-%d-item", restaurant.ID), i+1))
+	}
+	if err := createAll(tx, photos); err != nil {
+		return menu, err
+	}
+
+	for i := 0; i < size.TablesPerRestaurant; i++ {
+		kind := tableTypes[i%len(tableTypes)]
+		menu.tables = append(menu.tables, model.Table{
+			Name:         fmt.Sprintf("%s %d", kind.name, i/len(tableTypes)+1),
+			Type:         kind.name,
+			Description:  pick(s.rng, kind.descriptions),
+			Capacity:     kind.minCapacity + s.rng.IntN(kind.maxCapacity-kind.minCapacity+1),
+			PhotoID:      photos[i].ID,
+			RestaurantID: restaurant.ID,
+		})
+	}
+	if err := createAll(tx.Omit(clause.Associations), menu.tables); err != nil {
+		return menu, err
+	}
+
+	for i := 0; i < size.FoodsPerRestaurant; i++ {
+		category := menuCategories[i%len(menuCategories)]
+		price := category.minPrice + float64(s.rng.IntN(int(category.maxPrice-category.minPrice)/100+1))*100
+		menu.foods = append(menu.foods, model.Food{
+			Name:         category.dishes[(i/len(menuCategories))%len(category.dishes)],
+			Type:         category.name,
+			Description:  fmt.Sprintf("%s from the %s menu.", category.dishes[(i/len(menuCategories))%len(category.dishes)], restaurant.Name),
+			Price:        price,
+			Available:    s.rng.IntN(20) > 0,
+			PhotoID:      photos[size.TablesPerRestaurant+i].ID,
+			RestaurantID: restaurant.ID,
+		})
+	}
+	if err := createAll(tx.Omit(clause.Associations), menu.foods); err != nil {
+		return menu, err
+	}
+
+	return menu, nil
+}
+
+// seedOrders spreads bookings over the 180 days before Now, plus reservations
+// for the next two weeks. Orders are inserted chunk by chunk to bound memory
+// on the large preset.
+func (s *Seeder) seedOrders(tx *gorm.DB, menus []restaurantMenu, clients []model.User) (int, int, error) {
+	if len(menus) == 0 || len(clients) == 0 || s.opts.Size.TablesPerRestaurant == 0 {
+		return 0, 0, nil
+	}
+
+	total, totalFoods := 0, 0
+	for total < s.opts.Size.Orders {
+		n := min(batchSize, s.opts.Size.Orders-total)
+		orders := make([]model.Order, 0, n)
+		for i := 0; i < n; i++ {
+			orders = append(orders, s.order(menus[s.rng.IntN(len(menus))], pick(s.rng, clients)))
+		}
+		if err := createAll(tx.Omit(clause.Associations), orders); err != nil {
+			return total, totalFoods, err
+		}
+
+		var foods []model.OrderFood
+		for _, order := range orders {
+			for _, foodID := range order.OrderFoods {
+				foods = append(foods, model.OrderFood{OrderID: order.ID, FoodID: foodID})
+			}
+		}
+		if err := createAll(tx.Table("order_foods"), foods); err != nil {
+			return total, totalFoods, err
+		}
+
+		total += n
+		totalFoods += len(foods)
+	}
+
+	return total, totalFoods, nil
+}
+
+func (s *Seeder) order(menu restaurantMenu, client model.User) model.Order {
+	day := s.rng.IntN(194) - 180
+	date := s.opts.Now.AddDate(0, 0, day).Add(time.Duration(menu.restaurant.ModeFrom.Hour()+1+s.rng.IntN(10)) * time.Hour)
+
+	status := enums.Completed
+	switch {
+	case date.After(s.opts.Now) && s.rng.IntN(100) < 85:
+		status = enums.Reserved
+	case s.rng.IntN(100) < 18:
+		status = enums.Canceled
+	}
+
+	order := model.Order{
+		RestaurantID: menu.restaurant.ID,
+		UserID:       client.ID,
+		TableID:      pick(s.rng, menu.tables).ID,
+		Date:         date,
+		Status:       status,
+	}
+	if len(menu.foods) > 0 {
+		for i := s.rng.IntN(5); i > 0; i-- {
+			food := pick(s.rng, menu.foods)
+			order.OrderFoods = append(order.OrderFoods, food.ID)
+			order.TotalSum += food.Price
+		}
+	}
+
+	return order
+}
+
+func (s *Seeder) seedReviews(tx *gorm.DB, menus []restaurantMenu, clients []model.User) (int, error) {
+	if len(menus) == 0 || len(clients) == 0 {
+		return 0, nil
+	}
+
+	reviews := make([]model.RestaurantReview, 0, s.opts.Size.Reviews)
+	for i := 0; i < s.opts.Size.Reviews; i++ {
+		// Skewed towards good ratings, like real review sites.
+		stars := []int{1, 2, 3, 3, 4, 4, 4, 5, 5, 5}[s.rng.IntN(10)]
+		reviews = append(reviews, model.RestaurantReview{
+			Stars:        stars,
+			Description:  pick(s.rng, reviewTexts[stars]),
+			UserID:       pick(s.rng, clients).ID,
+			RestaurantID: menus[s.rng.IntN(len(menus))].restaurant.ID,
+			Date:         s.opts.Now.Add(-time.Duration(s.rng.IntN(180*24)) * time.Hour),
+		})
+	}
+	if err := createAll(tx.Table("restaurant_reviews").Omit(clause.Associations), reviews); err != nil {
+		return 0, err
+	}
+
+	return len(reviews), nil
+}
+
+// createAll inserts rows in batches and writes the generated ids back into
+// them. Empty slices are skipped, gorm rejects them.
+func createAll[T any](tx *gorm.DB, rows []T) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	return tx.CreateInBatches(&rows, batchSize).Error
+}
+
+func pick[T any](rng *rand.Rand, items []T) T {
+	return items[rng.IntN(len(items))]
+}
+
+func photo(kind string, n int) model.Photo {
+	return model.Photo{Route: fmt.Sprintf("https://picsum.photos/seed/orynal-%s-%d/800/600", kind, n)}
+}
+
+func restaurantName(rng *rand.Rand) string {
+	prefix, suffix := pick(rng, restaurantPrefixes), pick(rng, restaurantSuffixes)
+	if suffix == "" {
+		return prefix
+	}
+	return prefix + " " + suffix
+}
+
+// roleDigit keeps seeded phone numbers unique across roles.
+func roleDigit(role string) int {
+	switch role {
+	case enums.Admin:
+		return 1
+	case enums.Owner:
+		return 2
+	default:
+		return 3
+	}
+}
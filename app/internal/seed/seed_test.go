@@ -0,0 +1,106 @@
+package seed
+
+import (
+	"context"
+	"fmt"
+	pkggorm "github.com/alibekabdrakhman1/orynal/pkg/gorm"
+	"github.com/alibekabdrakhman1/orynal/pkg/migrate"
+	"github.com/alibekabdrakhman1/orynal/schema"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// samples are the columns compared between seeded databases, the first rows
+// of each table in id order. Passwords are left out: hashes are salted.
+var samples = map[string]string{
+	"users":              "id, name, surname, email, phone, role",
+	"restaurants":        "id, name, address, city, latitude, longitude, status, phone, owner_id, mode_from, mode_to",
+	"restaurant_service": "restaurant_id, service_id",
+	"tables":             "id, name, type, capacity, restaurant_id",
+	"foods":              "id, name, type, price, restaurant_id, available, vegetarian, vegan, halal, gluten_free",
+	"orders":             "id, restaurant_id, user_id, table_id, date, status, total_sum",
+	"order_foods":        "order_id, food_id",
+	"restaurant_reviews": "id, restaurant_id, user_id, stars, description, date",
+}
+
+func TestDeterministic(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2030, 5, 17, 0, 0, 0, 0, time.UTC)
+
+	run := func(name string, seed uint64) (*Summary, map[string]string) {
+		t.Helper()
+		db := open(t, ctx, name)
+		summary, err := New(db, Options{Seed: seed, Size: Presets["small"], Password: "password", Now: now}, zap.NewNop().Sugar()).Run(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return summary, dump(t, db)
+	}
+
+	firstSummary, first := run("first.db", 7)
+	secondSummary, second := run("second.db", 7)
+	_, other := run("other.db", 8)
+
+	if *firstSummary != *secondSummary {
+		t.Errorf("summaries %+v and %+v, want the same", *firstSummary, *secondSummary)
+	}
+	for table := range samples {
+		if first[table] != second[table] {
+			t.Errorf("%s differ with the same seed:\n%s\n%s", table, first[table], second[table])
+		}
+	}
+	if first["orders"] == other["orders"] {
+		t.Error("orders are the same with another seed")
+	}
+}
+
+func open(t *testing.T, ctx context.Context, name string) *gorm.DB {
+	t.Helper()
+
+	db, err := pkggorm.Dial(ctx, "sqlite://"+filepath.Join(t.TempDir(), name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+
+	source, err := schema.For(migrate.SQLite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrator, err := migrate.New(sqlDB, migrate.SQLite, source, zap.NewNop().Sugar())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrator.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	return db
+}
+
+// dump renders the row count and the first rows of each sampled table.
+func dump(t *testing.T, db *gorm.DB) map[string]string {
+	t.Helper()
+
+	tables := map[string]string{}
+	for table, columns := range samples {
+		var count int64
+		if err := db.Table(table).Count(&count).Error; err != nil {
+			t.Fatal(err)
+		}
+		var rows []map[string]interface{}
+		if err := db.Raw(fmt.Sprintf("SELECT %s FROM %s ORDER BY %s LIMIT 50", columns, table, columns)).Scan(&rows).Error; err != nil {
+			t.Fatal(err)
+		}
+		tables[table] = fmt.Sprint(count, rows)
+	}
+
+	return tables
+}
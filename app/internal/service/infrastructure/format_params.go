@@ -1,12 +1,15 @@
 package infrastructure
 
 import (
+	"cmp"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"github.com/alibekabdrakhman1/orynal/internal/model"
+	"github.com/alibekabdrakhman1/orynal/pkg/errs"
 	"github.com/labstack/echo/v4"
 	"github.com/samber/lo"
+	"math"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -22,6 +25,21 @@ func (obj *FormatParams) RestaurantsSearchFormatting(params *model.Params, ctx e
 		return nil, err
 	}
 
+	err = obj.FilterFormat(params, ctx, model.RestaurantsListSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	err = obj.OrderFormat(params, ctx, model.RestaurantsListSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	err = obj.OrderVectorFormat(params, ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	err = obj.LimitFormat(params, ctx)
 	if err != nil {
 		return nil, err
@@ -32,11 +50,36 @@ func (obj *FormatParams) RestaurantsSearchFormatting(params *model.Params, ctx e
 		return nil, err
 	}
 
+	err = obj.NearFormat(params, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = obj.CursorFormat(params, ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	return params, nil
 }
 
 func (obj *FormatParams) ReviewsSearchFormatting(params *model.Params, ctx echo.Context) (*model.Params, error) {
-	err := obj.LimitFormat(params, ctx)
+	err := obj.FilterFormat(params, ctx, model.ReviewsListSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	err = obj.OrderFormat(params, ctx, model.ReviewsListSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	err = obj.OrderVectorFormat(params, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = obj.LimitFormat(params, ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -46,11 +89,21 @@ func (obj *FormatParams) ReviewsSearchFormatting(params *model.Params, ctx echo.
 		return nil, err
 	}
 
+	err = obj.CursorFormat(params, ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	return params, nil
 }
 
 func (obj *FormatParams) TablesSearchFormatting(params *model.Params, ctx echo.Context) (*model.Params, error) {
-	err := obj.OrderFormat(params, ctx, model.TablesOrderKeyList)
+	err := obj.FilterFormat(params, ctx, model.TablesListSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	err = obj.OrderFormat(params, ctx, model.TablesListSpec)
 	if err != nil {
 		return nil, err
 	}
@@ -89,7 +142,12 @@ func (obj *FormatParams) UserSearchFormatting(params *model.Params, ctx echo.Con
 		return nil, err
 	}
 
-	err = obj.OrderFormat(params, ctx, model.UsersOrderKeyList)
+	err = obj.FilterFormat(params, ctx, model.UsersListSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	err = obj.OrderFormat(params, ctx, model.UsersListSpec)
 	if err != nil {
 		return nil, err
 	}
@@ -109,11 +167,21 @@ func (obj *FormatParams) UserSearchFormatting(params *model.Params, ctx echo.Con
 		return nil, err
 	}
 
+	err = obj.CursorFormat(params, ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	return params, nil
 }
 
 func (obj *FormatParams) OrderSearchFormatting(params *model.Params, ctx echo.Context) (*model.Params, error) {
-	err := obj.OrderFormat(params, ctx, model.UsersOrderKeyList)
+	err := obj.FilterFormat(params, ctx, model.OrdersListSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	err = obj.OrderFormat(params, ctx, model.OrdersListSpec)
 	if err != nil {
 		return nil, err
 	}
@@ -133,6 +201,11 @@ func (obj *FormatParams) OrderSearchFormatting(params *model.Params, ctx echo.Co
 		return nil, err
 	}
 
+	err = obj.CursorFormat(params, ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	return params, nil
 }
 
@@ -142,6 +215,21 @@ func (obj *FormatParams) MenuSearchFormatting(params *model.Params, ctx echo.Con
 		return nil, err
 	}
 
+	err = obj.FilterFormat(params, ctx, model.MenuListSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	err = obj.OrderFormat(params, ctx, model.MenuListSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	err = obj.OrderVectorFormat(params, ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	err = obj.LimitFormat(params, ctx)
 	if err != nil {
 		return nil, err
@@ -155,65 +243,254 @@ func (obj *FormatParams) MenuSearchFormatting(params *model.Params, ctx echo.Con
 	return params, nil
 }
 
-func (obj *FormatParams) OrderVectorFormat(paramsModel *model.Params, ctx echo.Context) error {
-	orderVector := ctx.QueryParam("order_vector")
+// TextSearchFormatting reads the query of a full-text search, which is
+// required, and its offset page; results are ordered by rank, so there are
+// no sort or cursor params.
+func (obj *FormatParams) TextSearchFormatting(params *model.Params, ctx echo.Context) (*model.Params, error) {
+	err := obj.SearchFormat(params, ctx)
+	if err != nil {
+		return nil, err
+	}
 
-	if orderVector == "" {
-		paramsModel.SortVector = nil
-	} else {
-		if lo.Contains([]string{"asc", "desc"}, orderVector) {
-			paramsModel.SortVector = orderVector
-		} else {
-			return errors.New("your order_vector param is not accepted")
+	if strings.TrimSpace(params.Query) == "" {
+		return nil, errs.ErrInvalidParams.WithMessage("q param is required")
+	}
+
+	err = obj.LimitFormat(params, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = obj.PageIndexFormat(params, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return params, nil
+}
+
+// DishSearchFormatting reads the criteria of a dish search and the offset
+// page of the restaurants it groups dishes by. All criteria are optional;
+// lat and lng come together.
+func (obj *FormatParams) DishSearchFormatting(search *model.DishSearch, ctx echo.Context) (*model.DishSearch, error) {
+	err := obj.SearchFormat(&search.Params, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if search.MinPrice, err = floatParam(ctx, "min_price"); err != nil {
+		return nil, err
+	}
+	if search.MaxPrice, err = floatParam(ctx, "max_price"); err != nil {
+		return nil, err
+	}
+	if search.MinPrice != nil && search.MaxPrice != nil && *search.MinPrice > *search.MaxPrice {
+		return nil, errs.ErrInvalidParams.WithMessage("min_price cannot exceed max_price")
+	}
+
+	search.Category = strings.TrimSpace(ctx.QueryParam("category"))
+	search.City = strings.TrimSpace(ctx.QueryParam("city"))
+
+	if dietary := ctx.QueryParam("dietary"); dietary != "" {
+		for _, diet := range strings.Split(dietary, ",") {
+			column, ok := model.DietColumns[strings.TrimSpace(diet)]
+			if !ok {
+				return nil, errs.ErrInvalidParams.WithMessage(fmt.Sprintf("dietary value %s is not accepted", diet))
+			}
+			search.Diets = append(search.Diets, column)
 		}
 	}
 
+	lat, err := floatParam(ctx, "lat")
+	if err != nil {
+		return nil, err
+	}
+	lng, err := floatParam(ctx, "lng")
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case lat == nil && lng == nil:
+	case lat == nil || lng == nil:
+		return nil, errs.ErrInvalidParams.WithMessage("lat and lng must be given together")
+	case math.Abs(*lat) > 90 || math.Abs(*lng) > 180:
+		return nil, errs.ErrInvalidParams.WithMessage("lat or lng is out of range")
+	default:
+		search.Near = &model.Point{Lat: *lat, Lng: *lng}
+	}
+
+	err = obj.LimitFormat(&search.Params, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = obj.PageIndexFormat(&search.Params, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return search, nil
+}
+
+func floatParam(ctx echo.Context, name string) (*float64, error) {
+	raw := ctx.QueryParam(name)
+	if raw == "" {
+		return nil, nil
+	}
+
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+		return nil, errs.ErrInvalidParams.WithMessage(fmt.Sprintf("%s param must be a number", name))
+	}
+
+	return &value, nil
+}
+
+// OrderVectorFormat applies order_vector to the last column of order, which
+// keeps the order=["name"]&order_vector=desc form working.
+func (obj *FormatParams) OrderVectorFormat(paramsModel *model.Params, ctx echo.Context) error {
+	switch orderVector := ctx.QueryParam("order_vector"); orderVector {
+	case "", "asc":
+	case "desc":
+		if len(paramsModel.Sort) > 0 {
+			paramsModel.Sort[len(paramsModel.Sort)-1].Desc = true
+		}
+	default:
+		return errs.ErrInvalidParams.WithMessage("your order_vector param is not accepted")
+	}
+
 	return nil
 }
 
-func (obj *FormatParams) FilterFormat(paramsModel *model.Params, ctx echo.Context, keyFilterList map[string]string) error {
+// FilterFormat parses filter, a JSON object keyed by the fields of spec. A
+// member is either a value to compare with eq or an object of operators,
+// e.g. {"status":"reserved","totalSum":{"gte":1000},"tableId":{"in":[1,2]}}.
+func (obj *FormatParams) FilterFormat(paramsModel *model.Params, ctx echo.Context, spec model.ListSpec) error {
 	filter := ctx.QueryParam("filter")
+	if filter == "" {
+		return nil
+	}
 
-	if filter != "" {
-		var unmarshalledFilter interface{}
-		err := json.Unmarshal([]byte(filter), &unmarshalledFilter)
-		if err != nil {
-			return err
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(filter), &members); err != nil {
+		return errs.ErrInvalidParams.WithMessage("filter must be a JSON object").Wrap(err)
+	}
+
+	for name, member := range members {
+		field, ok := spec[name]
+		if !ok {
+			return errs.ErrInvalidParams.WithMessage(fmt.Sprintf("filter field %s is not accepted", name))
 		}
 
-		paramsModel.Filter = unmarshalledFilter.(map[string]interface{})
+		ops := map[model.FilterOp]json.RawMessage{}
+		if err := json.Unmarshal(member, &ops); err != nil {
+			ops = map[model.FilterOp]json.RawMessage{model.OpEq: member}
+		}
+		if len(ops) == 0 {
+			return errs.ErrInvalidParams.WithMessage(fmt.Sprintf("filter for %s has no operator", name))
+		}
+
+		for op, raw := range ops {
+			if !lo.Contains(field.Ops, op) {
+				return errs.ErrInvalidParams.WithMessage(fmt.Sprintf("filter operator %s is not accepted for %s", op, name))
+			}
 
-		for name, _ := range paramsModel.Filter {
-			if !lo.Contains(lo.Values(keyFilterList), name) {
-				return errors.New(fmt.Sprintf("filter value not found %s", name))
+			value, err := filterValue(field, op, raw)
+			if err != nil {
+				return errs.ErrInvalidParams.WithMessage(fmt.Sprintf("invalid filter value for %s", name)).Wrap(err)
 			}
+
+			paramsModel.Filters = append(paramsModel.Filters, model.Filter{Column: field.Column, Op: op, Value: value})
 		}
-	} else {
-		return errors.New("filter param not found")
 	}
 
+	slices.SortFunc(paramsModel.Filters, func(a, b model.Filter) int {
+		return cmp.Or(cmp.Compare(a.Column, b.Column), cmp.Compare(a.Op, b.Op))
+	})
+
 	return nil
 }
 
-func (obj *FormatParams) OrderFormat(paramsModel *model.Params, ctx echo.Context, keyFilterList []string) error {
-	order := ctx.QueryParam("order")
-	if order != "" {
-		var unmarshalledOrder interface{}
-		err := json.Unmarshal([]byte(order), &unmarshalledOrder)
+const maxFilterValues = 100
+
+// filterValue parses the value of a filter as the type of field, or as a
+// list of them for in.
+func filterValue(field model.Field, op model.FilterOp, raw json.RawMessage) (any, error) {
+	if op != model.OpIn {
+		return scalarValue(field.Type, raw)
+	}
+
+	var items []json.RawMessage
+	if err := json.Unmarshal(raw, &items); err != nil {
+		return nil, err
+	}
+	if len(items) == 0 || len(items) > maxFilterValues {
+		return nil, fmt.Errorf("in takes 1 to %d values", maxFilterValues)
+	}
+
+	values := make([]any, 0, len(items))
+	for _, item := range items {
+		value, err := scalarValue(field.Type, item)
 		if err != nil {
-			return err
+			return nil, err
 		}
+		values = append(values, value)
+	}
 
-		var newList []string
+	return values, nil
+}
 
-		for _, item := range unmarshalledOrder.([]interface{}) {
-			if !lo.Contains(keyFilterList, item.(string)) {
-				return errors.New(fmt.Sprintf("%v param is not accepted", item))
-			}
+func scalarValue(typ model.FieldType, raw json.RawMessage) (any, error) {
+	switch typ {
+	case model.IntField:
+		var value int64
+		err := json.Unmarshal(raw, &value)
+		return value, err
+	case model.NumberField:
+		var value float64
+		err := json.Unmarshal(raw, &value)
+		return value, err
+	case model.BoolField:
+		var value bool
+		err := json.Unmarshal(raw, &value)
+		return value, err
+	case model.TimeField:
+		var value string
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return nil, err
+		}
+		if t, err := time.Parse("2006-01-02T15:04:05", value); err == nil {
+			return t, nil
+		}
+		return time.Parse(time.RFC3339, value)
+	default:
+		var value string
+		err := json.Unmarshal(raw, &value)
+		return value, err
+	}
+}
+
+// OrderFormat parses order, a JSON array of fields of spec to sort by, each
+// descending when prefixed with "-", e.g. ["-date","id"].
+func (obj *FormatParams) OrderFormat(paramsModel *model.Params, ctx echo.Context, spec model.ListSpec) error {
+	order := ctx.QueryParam("order")
+	if order == "" {
+		return nil
+	}
+
+	var names []string
+	if err := json.Unmarshal([]byte(order), &names); err != nil {
+		return errs.ErrInvalidParams.WithMessage("order must be a JSON array of fields").Wrap(err)
+	}
 
-			newList = append(newList, item.(string))
+	for _, name := range names {
+		trimmed, desc := strings.CutPrefix(name, "-")
+		field, ok := spec[trimmed]
+		if !ok || !field.Sortable {
+			return errs.ErrInvalidParams.WithMessage(fmt.Sprintf("%v param is not accepted", name))
 		}
-		paramsModel.Order = strings.Join(newList, ",")
+
+		paramsModel.Sort = append(paramsModel.Sort, model.Sort{Column: field.Column, Desc: desc})
 	}
 
 	return nil
@@ -223,18 +500,20 @@ func (obj *FormatParams) LimitFormat(paramsModel *model.Params, ctx echo.Context
 	limit := ctx.QueryParam("limit")
 
 	if limit == "" {
-		paramsModel.Limit = 20
+		paramsModel.Limit = model.DefaultLimit
 		return nil
 	}
 
 	convertedInt, err := strconv.Atoi(limit)
 	if err != nil {
-		return err
+		return errs.ErrInvalidParams.Wrap(err)
 	}
 
 	switch {
-	case convertedInt > 20 && convertedInt < 0:
-		paramsModel.Limit = 20
+	case convertedInt < 1:
+		paramsModel.Limit = model.DefaultLimit
+	case convertedInt > model.MaxLimit:
+		paramsModel.Limit = model.MaxLimit
 	default:
 		paramsModel.Limit = convertedInt
 	}
@@ -248,7 +527,7 @@ func (obj *FormatParams) PageIndexFormat(paramsModel *model.Params, ctx echo.Con
 	if page != "" {
 		intQueryLimit, err := strconv.Atoi(page)
 		if err != nil {
-			return err
+			return errs.ErrInvalidParams.Wrap(err)
 		}
 
 		if intQueryLimit <= 1 {
@@ -266,6 +545,70 @@ func (obj *FormatParams) PageIndexFormat(paramsModel *model.Params, ctx echo.Con
 	return nil
 }
 
+// CursorFormat switches to keyset pagination when an after or before cursor
+// is given. Cursors page in id order, so they exclude page and order.
+func (obj *FormatParams) CursorFormat(paramsModel *model.Params, ctx echo.Context) error {
+	after, before := ctx.QueryParam("after"), ctx.QueryParam("before")
+
+	switch {
+	case after == "" && before == "":
+		return nil
+	case after != "" && before != "":
+		return errs.ErrInvalidParams.WithMessage("after and before cannot be combined")
+	case ctx.QueryParam("page") != "":
+		return errs.ErrInvalidParams.WithMessage("page cannot be combined with a cursor")
+	case paramsModel.Near != nil:
+		return errs.ErrInvalidParams.WithMessage("near cannot be combined with a cursor")
+	case paramsModel.Sorted():
+		return errs.ErrInvalidParams.WithMessage("order cannot be combined with a cursor")
+	}
+
+	id, err := model.DecodeCursor(after + before)
+	if err != nil {
+		return errs.ErrInvalidParams.WithMessage("invalid cursor").Wrap(err)
+	}
+
+	paramsModel.Cursor = &model.Cursor{ID: id, Before: before != ""}
+	paramsModel.Offset = 0
+	paramsModel.PageIndex = 0
+
+	return nil
+}
+
+// NearFormat reads near, a lat,lng pair, and radius in meters. Rows are
+// then ordered by distance, so near excludes order.
+func (obj *FormatParams) NearFormat(paramsModel *model.Params, ctx echo.Context) error {
+	nearParam, radius := ctx.QueryParam("near"), ctx.QueryParam("radius")
+	if nearParam == "" {
+		if radius != "" {
+			return errs.ErrInvalidParams.WithMessage("radius requires near")
+		}
+		return nil
+	}
+	if len(paramsModel.Sort) > 0 {
+		return errs.ErrInvalidParams.WithMessage("order cannot be combined with near")
+	}
+
+	rawLat, rawLng, ok := strings.Cut(nearParam, ",")
+	lat, latErr := strconv.ParseFloat(strings.TrimSpace(rawLat), 64)
+	lng, lngErr := strconv.ParseFloat(strings.TrimSpace(rawLng), 64)
+	if !ok || latErr != nil || lngErr != nil || !(math.Abs(lat) <= 90) || !(math.Abs(lng) <= 180) {
+		return errs.ErrInvalidParams.WithMessage("near must be lat,lng")
+	}
+
+	paramsModel.Near = &model.Point{Lat: lat, Lng: lng}
+	paramsModel.Radius = model.DefaultRadius
+	if radius != "" {
+		meters, err := strconv.ParseFloat(radius, 64)
+		if err != nil || !(meters > 0) {
+			return errs.ErrInvalidParams.WithMessage("radius must be a positive number of meters")
+		}
+		paramsModel.Radius = min(meters, model.MaxRadius)
+	}
+
+	return nil
+}
+
 func (obj *FormatParams) SearchFormat(paramsModel *model.Params, ctx echo.Context) error {
 	q := ctx.QueryParam("q")
 
@@ -284,7 +627,7 @@ func (obj *FormatParams) DateFormat(paramsModel *model.Params, ctx echo.Context)
 		t, err := time.Parse(layout, date)
 
 		if err != nil {
-			return fmt.Errorf("error parsing date string: %w", err)
+			return errs.ErrInvalidParams.WithMessage("invalid date param").Wrap(err)
 		}
 
 		paramsModel.Date = &t
@@ -4,6 +4,7 @@ import (
 	"github.com/alibekabdrakhman1/orynal/config"
 	"github.com/alibekabdrakhman1/orynal/internal/repository"
 	"github.com/alibekabdrakhman1/orynal/internal/service/services"
+	"github.com/alibekabdrakhman1/orynal/pkg/geocode"
 	"go.uber.org/zap"
 )
 
@@ -15,16 +16,23 @@ type Manager struct {
 	Menu       services.IMenuService
 	Order      services.IOrderService
 	Reviews    services.IReviewsService
+	Search     services.ISearchService
+	Retention  services.IRetentionService
+	Export     services.IExportService
 }
 
-func NewManager(repository *repository.Manager, config *config.Config, logger *zap.SugaredLogger) *Manager {
+func NewManager(repository *repository.Manager, geocoder geocode.Geocoder, config *config.Config, logger *zap.SugaredLogger) *Manager {
+	export := services.NewExportService(repository, config, logger)
 	return &Manager{
 		Auth:       services.NewAuthService(repository, config, logger),
 		User:       services.NewUserService(repository, config, logger),
-		Restaurant: services.NewRestaurantService(repository, config, logger),
+		Restaurant: services.NewRestaurantService(repository, geocoder, config, logger),
 		Table:      services.NewTableService(repository, config, logger),
 		Menu:       services.NewMenuService(repository, config, logger),
 		Order:      services.NewOrderService(repository, config, logger),
 		Reviews:    services.NewReviewsService(repository, config, logger),
+		Search:     services.NewSearchService(repository, config, logger),
+		Retention:  services.NewRetentionService(repository, export, config, logger),
+		Export:     export,
 	}
 }
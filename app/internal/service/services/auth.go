@@ -7,6 +7,10 @@ import (
 	"github.com/alibekabdrakhman1/orynal/config"
 	"github.com/alibekabdrakhman1/orynal/internal/model"
 	"github.com/alibekabdrakhman1/orynal/internal/repository"
+	"github.com/alibekabdrakhman1/orynal/pkg/errs"
+	"github.com/alibekabdrakhman1/orynal/pkg/logging"
+	"github.com/alibekabdrakhman1/orynal/pkg/metrics"
+	"github.com/alibekabdrakhman1/orynal/pkg/tracing"
 	"github.com/alibekabdrakhman1/orynal/pkg/utils"
 	"github.com/golang-jwt/jwt"
 	"go.uber.org/zap"
@@ -14,8 +18,6 @@ import (
 	"time"
 )
 
-var ErrExpiredToken = errors.New("expiration date validation error")
-
 func NewAuthService(repository *repository.Manager, config *config.Config, logger *zap.SugaredLogger) *AuthService {
 	return &AuthService{repository: repository, config: config, logger: logger}
 }
@@ -27,15 +29,23 @@ type AuthService struct {
 }
 
 func (s *AuthService) Login(ctx context.Context, login model.Login) (*model.JwtTokens, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.Login")
+	defer span.End()
+
 	user, err := s.repository.User.GetByEmail(ctx, login.Email)
 	if err != nil {
-		s.logger.Errorf("GetUser request err: %v", err)
+		logging.FromContext(ctx, s.logger).Errorf("GetUser request err: %v", err)
+		if errors.Is(err, errs.ErrUserNotFound) {
+			metrics.LoginFailures.Inc()
+			return nil, errs.ErrInvalidCredentials
+		}
 		return nil, fmt.Errorf("GetUser request err: %w", err)
 	}
 	err = utils.CheckPassword(login.Password, user.Password)
 	if err != nil {
-		s.logger.Error("incorrect password")
-		return nil, errors.New("incorrect password")
+		logging.FromContext(ctx, s.logger).Error("incorrect password")
+		metrics.LoginFailures.Inc()
+		return nil, errs.ErrInvalidCredentials
 	}
 
 	userClaim := model.UserClaim{
@@ -44,25 +54,49 @@ func (s *AuthService) Login(ctx context.Context, login model.Login) (*model.JwtT
 		Role:   user.Role,
 	}
 
-	tokens, err := s.generateToken(ctx, userClaim)
+	// Canceling a pending deletion and storing the tokens succeed or fail
+	// together.
+	var tokens *model.JwtTokens
+	err = s.repository.WithTx(ctx, func(txRepos *repository.Manager) error {
+		if user.DeletionRequestedAt != nil {
+			if err := txRepos.User.CancelDeletion(ctx, user.ID); err != nil {
+				logging.FromContext(ctx, s.logger).Errorf("CancelDeletion err: %v", err)
+				return fmt.Errorf("CancelDeletion err: %w", err)
+			}
+		}
+
+		tokens, err = s.generateToken(ctx, txRepos, userClaim)
+		if err != nil {
+			logging.FromContext(ctx, s.logger).Errorf("generating token err: %v", err)
+			return fmt.Errorf("generating token err: %w", err)
+		}
+
+		return nil
+	})
 	if err != nil {
-		s.logger.Errorf("generating token err: %v", err)
-		return nil, fmt.Errorf("generating token err: %w", err)
+		return nil, err
+	}
+
+	if user.DeletionRequestedAt != nil {
+		logging.FromContext(ctx, s.logger).Infof("account deletion for user %d canceled by login", user.ID)
 	}
 
 	res := &model.JwtTokens{
 		AccessToken:  tokens.AccessToken,
 		RefreshToken: tokens.RefreshToken,
 	}
+
+	metrics.Logins.Inc()
 	return res, nil
 }
 
 func (s *AuthService) Register(ctx context.Context, user model.Register) (uint, error) {
-	s.logger.Info(user)
+	ctx, span := tracing.Start(ctx, "AuthService.Register")
+	defer span.End()
 
 	pass, err := utils.HashPassword(user.Password)
 	if err != nil {
-		s.logger.Error(err)
+		logging.FromContext(ctx, s.logger).Error(err)
 		return 0, err
 	}
 
@@ -77,14 +111,18 @@ func (s *AuthService) Register(ctx context.Context, user model.Register) (uint,
 
 	res, err := s.repository.User.Create(ctx, req)
 	if err != nil {
-		s.logger.Error(err)
+		logging.FromContext(ctx, s.logger).Error(err)
 		return 0, err
 	}
 
+	logging.FromContext(ctx, s.logger).Infof("registered user %d", res.ID)
 	return res.ID, nil
 }
 
 func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string) (*model.JwtTokens, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.RefreshToken")
+	defer span.End()
+
 	token, err := jwt.Parse(
 		refreshToken,
 		func(token *jwt.Token) (interface{}, error) {
@@ -99,41 +137,53 @@ func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string) (*m
 		var validationErr *jwt.ValidationError
 		if errors.As(err, &validationErr) {
 			if validationErr.Errors&jwt.ValidationErrorExpired > 0 {
-				return nil, errors.New("expiration date validation error")
+				return nil, errs.ErrTokenExpired
 			}
 		}
-		s.logger.Error(err)
-		return nil, fmt.Errorf("failed to parse jwt err: %w", err)
+		logging.FromContext(ctx, s.logger).Error(err)
+		return nil, errs.ErrInvalidToken.Wrap(err)
 	}
 
 	claims, ok := token.Claims.(jwt.MapClaims)
 	if !ok {
-		s.logger.Error(err)
-		return nil, fmt.Errorf("unexpected type %T", claims)
+		logging.FromContext(ctx, s.logger).Error(err)
+		return nil, errs.ErrInvalidToken
 	}
-	fmt.Println(claims)
+
 	user, err := s.repository.User.GetByEmail(ctx, claims["email"].(string))
+	if errors.Is(err, errs.ErrUserNotFound) {
+		return nil, errs.ErrInvalidToken.Wrap(err)
+	}
 	if err != nil {
-		s.logger.Error(err)
+		logging.FromContext(ctx, s.logger).Error(err)
 		return nil, fmt.Errorf("GetUser request err: %w", err)
 	}
 
-	userClaim := model.UserClaim{
+	// Disabling a user drops their sessions.
+	sessions, err := s.repository.UserToken.GetByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(sessions) == 0 {
+		return nil, errs.ErrInvalidToken
+	}
 
+	userClaim := model.UserClaim{
+		Email:  user.Email,
 		UserID: user.ID,
 		Role:   user.Role,
 	}
 
-	tokens, err := s.generateToken(ctx, userClaim)
+	tokens, err := s.generateToken(ctx, s.repository, userClaim)
 	if err != nil {
-		s.logger.Error(err)
+		logging.FromContext(ctx, s.logger).Error(err)
 		return nil, fmt.Errorf("generating token err: %w", err)
 	}
 
 	return tokens, nil
 }
 
-func (s *AuthService) generateToken(ctx context.Context, user model.UserClaim) (*model.JwtTokens, error) {
+func (s *AuthService) generateToken(ctx context.Context, repos *repository.Manager, user model.UserClaim) (*model.JwtTokens, error) {
 	accessTokenExpirationTime := time.Now().Add(time.Hour)
 	refreshTokenExpirationTime := time.Now().Add(24 * time.Hour)
 
@@ -151,7 +201,7 @@ func (s *AuthService) generateToken(ctx context.Context, user model.UserClaim) (
 
 	accessTokenString, err := accessClaimToken.SignedString(secretKey)
 	if err != nil {
-		s.logger.Errorf("AccessToken: SignedStrign err: %v", err)
+		logging.FromContext(ctx, s.logger).Errorf("AccessToken: SignedStrign err: %v", err)
 		return nil, fmt.Errorf("AccessToken: SignedString err: %w", err)
 	}
 
@@ -167,7 +217,7 @@ func (s *AuthService) generateToken(ctx context.Context, user model.UserClaim) (
 
 	refreshTokenString, err := refreshClaimToken.SignedString(secretKey)
 	if err != nil {
-		s.logger.Errorf("RefreshToken: SignedString err: %v", err)
+		logging.FromContext(ctx, s.logger).Errorf("RefreshToken: SignedString err: %v", err)
 		return nil, fmt.Errorf("RefreshToken: SignedString err: %w", err)
 	}
 
@@ -179,12 +229,10 @@ func (s *AuthService) generateToken(ctx context.Context, user model.UserClaim) (
 		RefreshToken: refreshTokenString,
 	}
 
-	s.logger.Info(userToken)
-
-	err = s.repository.UserToken.CreateUserToken(ctx, userToken)
+	err = repos.UserToken.CreateUserToken(ctx, userToken)
 	if err != nil {
-		s.logger.Errorf("CreateUserToken err: %v", err)
-		return nil, errors.New(fmt.Sprintf("CreateUserToken err: %v", err))
+		logging.FromContext(ctx, s.logger).Errorf("CreateUserToken err: %v", err)
+		return nil, fmt.Errorf("CreateUserToken err: %w", err)
 	}
 
 	jwtToken := &model.JwtTokens{
@@ -195,6 +243,18 @@ func (s *AuthService) generateToken(ctx context.Context, user model.UserClaim) (
 	return jwtToken, nil
 }
 
+// CheckUser rejects tokens of users who were disabled or anonymized after
+// the token was issued.
+func (s *AuthService) CheckUser(ctx context.Context, id *model.ContextUserID) error {
+	if _, err := s.repository.User.GetByID(ctx, id.ID); err != nil {
+		if errors.Is(err, errs.ErrUserNotFound) {
+			return errs.ErrInvalidToken.Wrap(err)
+		}
+		return err
+	}
+	return nil
+}
+
 func (s *AuthService) GetJwtUserID(jwtToken string) (*model.ContextUserID, error) {
 	token, err := jwt.Parse(
 		jwtToken,
@@ -210,21 +270,21 @@ func (s *AuthService) GetJwtUserID(jwtToken string) (*model.ContextUserID, error
 		var validationErr *jwt.ValidationError
 		if errors.As(err, &validationErr) {
 			if validationErr.Errors&jwt.ValidationErrorExpired > 0 {
-				return nil, ErrExpiredToken
+				return nil, errs.ErrTokenExpired
 			}
 		}
 
-		return nil, fmt.Errorf("failed to parse jwt err: %w", err)
+		return nil, errs.ErrInvalidToken.Wrap(err)
 	}
 
 	claims, ok := token.Claims.(jwt.MapClaims)
 	if !ok {
-		return nil, fmt.Errorf("unexpected type %T", claims)
+		return nil, errs.ErrInvalidToken
 	}
 
 	user, err := s.getUserIDFromJwt(claims)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user from jwt err: %w", err)
+		return nil, errs.ErrInvalidToken.Wrap(err)
 	}
 	return user, nil
 }
@@ -244,21 +304,21 @@ func (s *AuthService) GetJwtUserRole(jwtToken string) (*model.ContextUserRole, e
 		var validationErr *jwt.ValidationError
 		if errors.As(err, &validationErr) {
 			if validationErr.Errors&jwt.ValidationErrorExpired > 0 {
-				return nil, ErrExpiredToken
+				return nil, errs.ErrTokenExpired
 			}
 		}
 
-		return nil, fmt.Errorf("failed to parse jwt err: %w", err)
+		return nil, errs.ErrInvalidToken.Wrap(err)
 	}
 
 	claims, ok := token.Claims.(jwt.MapClaims)
 	if !ok {
-		return nil, fmt.Errorf("unexpected type %T", claims)
+		return nil, errs.ErrInvalidToken
 	}
 
 	user, err := s.getUserRoleFromJwt(claims)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user from jwt err: %w", err)
+		return nil, errs.ErrInvalidToken.Wrap(err)
 	}
 	return user, nil
 }
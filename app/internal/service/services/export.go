@@ -0,0 +1,352 @@
+package services
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/alibekabdrakhman1/orynal/config"
+	"github.com/alibekabdrakhman1/orynal/internal/model"
+	"github.com/alibekabdrakhman1/orynal/internal/repository"
+	"github.com/alibekabdrakhman1/orynal/pkg/enums"
+	"github.com/alibekabdrakhman1/orynal/pkg/errs"
+	"github.com/alibekabdrakhman1/orynal/pkg/logging"
+	"github.com/alibekabdrakhman1/orynal/pkg/tracing"
+	"github.com/alibekabdrakhman1/orynal/pkg/utils"
+	"go.uber.org/zap"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	exportPageSize = 100
+	// exportStaleAfter is how long a pending export blocks new requests of its
+	// user; one interrupted by a crash never completes.
+	exportStaleAfter = time.Hour
+)
+
+func NewExportService(repository *repository.Manager, config *config.Config, logger *zap.SugaredLogger) *ExportService {
+	return &ExportService{
+		repository: repository,
+		config:     config,
+		logger:     logger,
+		queue:      make(chan exportJob, max(config.Export.Queue, 1)),
+	}
+}
+
+// ExportService assembles a ZIP archive with everything we store about a user
+// and hands it out through a signed, time-limited download link. Archives are
+// built by the workers of Run, one pending export per user at a time.
+type ExportService struct {
+	repository *repository.Manager
+	config     *config.Config
+	logger     *zap.SugaredLogger
+
+	mu      sync.Mutex
+	queue   chan exportJob
+	stopped bool
+}
+
+type exportJob struct {
+	ctx    context.Context
+	export model.DataExport
+}
+
+// Run builds queued exports on Export.Workers goroutines until ctx is done,
+// then stops taking requests and returns once the queue is drained.
+func (s *ExportService) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < max(s.config.Export.Workers, 1); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range s.queue {
+				s.build(job.ctx, job.export)
+			}
+		}()
+	}
+
+	<-ctx.Done()
+	s.mu.Lock()
+	s.stopped = true
+	close(s.queue)
+	s.mu.Unlock()
+
+	wg.Wait()
+}
+
+func (s *ExportService) RequestExport(ctx context.Context) (*model.DataExport, error) {
+	ctx, span := tracing.Start(ctx, "ExportService.RequestExport")
+	defer span.End()
+
+	userID, err := utils.GetIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.repository.DataExport.GetPending(ctx, userID, time.Now().Add(-exportStaleAfter)); err == nil {
+		return nil, errs.ErrExportPending
+	} else if !errors.Is(err, errs.ErrExportNotFound) {
+		return nil, err
+	}
+
+	if s.stopped || len(s.queue) == cap(s.queue) {
+		return nil, errs.ErrExportBusy
+	}
+
+	export, err := s.repository.DataExport.Create(ctx, &model.DataExport{
+		UserID: userID,
+		Status: enums.ExportPending,
+	})
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Error(err)
+		return nil, err
+	}
+
+	// Keep the request logger and trace, but outlive the request. The queue
+	// has room: only RequestExport sends, under s.mu.
+	s.queue <- exportJob{ctx: context.WithoutCancel(ctx), export: *export}
+
+	return export, nil
+}
+
+func (s *ExportService) GetExport(ctx context.Context, id uint) (*model.DataExport, error) {
+	ctx, span := tracing.Start(ctx, "ExportService.GetExport")
+	defer span.End()
+
+	userID, err := utils.GetIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	export, err := s.repository.DataExport.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if export.UserID != userID {
+		return nil, errs.ErrPermissionDenied
+	}
+
+	if export.Status == enums.ExportReady && export.ExpiresAt != nil {
+		if time.Now().After(*export.ExpiresAt) {
+			return nil, errs.ErrExportLinkExpired
+		}
+		export.DownloadURL = s.downloadURL(export)
+	}
+
+	return export, nil
+}
+
+func (s *ExportService) GetExportFile(ctx context.Context, id uint, expires int64, signature string) (string, error) {
+	ctx, span := tracing.Start(ctx, "ExportService.GetExportFile")
+	defer span.End()
+
+	export, err := s.repository.DataExport.GetByID(ctx, id)
+	if err != nil {
+		return "", err
+	}
+
+	if export.Status != enums.ExportReady || export.ExpiresAt == nil {
+		return "", errs.ErrExportNotReady
+	}
+
+	if time.Now().Unix() > expires || export.ExpiresAt.Unix() != expires {
+		return "", errs.ErrExportLinkExpired
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(s.sign(export.ID, export.UserID, expires))) {
+		return "", errs.ErrExportLinkExpired
+	}
+
+	return export.FilePath, nil
+}
+
+func (s *ExportService) PurgeExpired(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "ExportService.PurgeExpired")
+	defer span.End()
+
+	exports, err := s.repository.DataExport.GetExpired(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+
+	for _, export := range exports {
+		if export.FilePath != "" {
+			if err := os.Remove(export.FilePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+		}
+
+		if err := s.repository.DataExport.Delete(ctx, export.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *ExportService) build(ctx context.Context, export model.DataExport) {
+	path, err := s.writeArchive(ctx, export)
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Errorf("data export %d err: %v", export.ID, err)
+		export.Status = enums.ExportFailed
+		export.Error = "failed to assemble export"
+	} else {
+		completedAt := time.Now()
+		expiresAt := completedAt.Add(s.config.Export.LinkTTL)
+		export.Status = enums.ExportReady
+		export.FilePath = path
+		export.CompletedAt = &completedAt
+		export.ExpiresAt = &expiresAt
+	}
+
+	if err := s.repository.DataExport.Update(ctx, &export); err != nil {
+		logging.FromContext(ctx, s.logger).Errorf("update data export %d err: %v", export.ID, err)
+	}
+}
+
+func (s *ExportService) collect(ctx context.Context, userID uint) (*model.UserDataExport, error) {
+	profile, err := s.repository.User.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get profile err: %w", err)
+	}
+
+	var orders []model.OrderResponse
+	for page := 1; ; page++ {
+		params := &model.Params{Limit: exportPageSize, Offset: (page - 1) * exportPageSize, PageIndex: page}
+		list, err := s.repository.Order.GetAllOrders(ctx, userID, params)
+		if err != nil {
+			return nil, fmt.Errorf("get orders err: %w", err)
+		}
+
+		items, _ := list.Items.([]model.OrderResponse)
+		for _, item := range items {
+			order, err := s.repository.Order.GetOrder(ctx, item.ID)
+			if err != nil {
+				return nil, fmt.Errorf("get order %d err: %w", item.ID, err)
+			}
+			orders = append(orders, *order)
+		}
+
+		if len(items) < exportPageSize {
+			break
+		}
+	}
+
+	reviews, err := s.repository.Reviews.GetUserReviews(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get reviews err: %w", err)
+	}
+
+	var favorites []model.Restaurant
+	for page := 1; ; page++ {
+		params := &model.Params{Limit: exportPageSize, Offset: (page - 1) * exportPageSize, PageIndex: page}
+		list, err := s.repository.Restaurant.GetFavoriteRestaurants(ctx, userID, params)
+		if err != nil {
+			return nil, fmt.Errorf("get favorites err: %w", err)
+		}
+
+		items, _ := list.Items.([]model.Restaurant)
+		favorites = append(favorites, items...)
+
+		if len(items) < exportPageSize {
+			break
+		}
+	}
+
+	tokens, err := s.repository.UserToken.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get sessions err: %w", err)
+	}
+
+	sessions := make([]model.Session, 0, len(tokens))
+	for _, token := range tokens {
+		sessions = append(sessions, model.Session{
+			Role:      token.Role,
+			Email:     token.Email,
+			CreatedAt: token.CreatedAt,
+			UpdatedAt: token.UpdatedAt,
+		})
+	}
+
+	return &model.UserDataExport{
+		GeneratedAt: time.Now(),
+		Profile:     profile,
+		Orders:      orders,
+		Reviews:     reviews,
+		Favorites:   favorites,
+		Sessions:    sessions,
+	}, nil
+}
+
+func (s *ExportService) writeArchive(ctx context.Context, export model.DataExport) (string, error) {
+	data, err := s.collect(ctx, export.UserID)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(s.config.Export.Dir, 0o700); err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(s.config.Export.Dir, fmt.Sprintf("export-%d-%d.zip", export.UserID, export.ID))
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	archive := zip.NewWriter(file)
+	entries := []struct {
+		name  string
+		value interface{}
+	}{
+		{"profile.json", data.Profile},
+		{"orders.json", data.Orders},
+		{"reviews.json", data.Reviews},
+		{"favorites.json", data.Favorites},
+		{"sessions.json", data.Sessions},
+	}
+
+	for _, entry := range entries {
+		w, err := archive.Create(entry.name)
+		if err != nil {
+			return "", err
+		}
+
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(entry.value); err != nil {
+			return "", err
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return "", err
+	}
+
+	return path, nil
+}
+
+func (s *ExportService) downloadURL(export *model.DataExport) string {
+	expires := export.ExpiresAt.Unix()
+	return fmt.Sprintf("/api/profile/export/%d/download?expires=%d&signature=%s",
+		export.ID, expires, s.sign(export.ID, export.UserID, expires))
+}
+
+func (s *ExportService) sign(id, userID uint, expires int64) string {
+	mac := hmac.New(sha256.New, []byte(s.config.Export.SigningKey))
+	mac.Write([]byte(strconv.FormatUint(uint64(id), 10) + ":" + strconv.FormatUint(uint64(userID), 10) + ":" + strconv.FormatInt(expires, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 	"github.com/alibekabdrakhman1/orynal/internal/model"
+	"github.com/alibekabdrakhman1/orynal/pkg/patch"
 	"github.com/labstack/echo/v4"
 	"time"
 )
@@ -11,6 +12,7 @@ type IAuthService interface {
 	Login(ctx context.Context, login model.Login) (*model.JwtTokens, error)
 	Register(ctx context.Context, user model.Register) (uint, error)
 	RefreshToken(ctx context.Context, refreshToken string) (*model.JwtTokens, error)
+	CheckUser(ctx context.Context, id *model.ContextUserID) error
 	GetJwtUserID(jwtToken string) (*model.ContextUserID, error)
 	GetJwtUserRole(jwtToken string) (*model.ContextUserRole, error)
 }
@@ -19,8 +21,11 @@ type IUserService interface {
 	Create(ctx context.Context, user *model.User) (*model.UserResponse, error)
 	CreateOwner(ctx context.Context, user *model.User) (*model.UserResponse, error)
 	Update(ctx context.Context, user *model.User) (*model.UserResponse, error)
+	Patch(ctx context.Context, p patch.Patch) (*model.UserResponse, error)
 	ChangePassword(ctx context.Context, user *model.ChangePasswordRequest) error
 	Delete(ctx context.Context, id uint) error
+	RequestDeletion(ctx context.Context) (*model.DeletionResponse, error)
+	Restore(ctx context.Context, id uint, role string) error
 	Profile(ctx context.Context) (*model.UserResponse, error)
 	GetByID(ctx context.Context, id uint) (*model.UserResponse, error)
 	GetAllClients(ctx context.Context, params *model.Params) (*model.ListResponse, error)
@@ -34,7 +39,9 @@ type IRestaurantService interface {
 	GetRestaurantByID(ctx context.Context, id uint) (*model.Restaurant, error)
 	CreateRestaurant(ctx context.Context, restaurant *model.Restaurant) (*model.Restaurant, error)
 	UpdateRestaurant(ctx context.Context, restaurant *model.Restaurant, id uint) (*model.Restaurant, error)
+	PatchRestaurant(ctx context.Context, id, version uint, p patch.Patch) (*model.Restaurant, error)
 	DeleteRestaurant(ctx context.Context, id uint) error
+	RestoreRestaurant(ctx context.Context, id uint) error
 	FavoriteRestaurants(ctx context.Context, id uint, params *model.Params) (*model.ListResponse, error)
 	PopularRestaurants(ctx context.Context) (*model.ListResponse, error)
 	GetRestaurantOrders(ctx context.Context, id uint, params *model.Params) (*model.ListResponse, error)
@@ -52,11 +59,14 @@ type FormatParams interface {
 	OrderSearchFormatting(params *model.Params, ctx echo.Context) (*model.Params, error)
 	MenuSearchFormatting(params *model.Params, ctx echo.Context) (*model.Params, error)
 	ReviewsSearchFormatting(params *model.Params, ctx echo.Context) (*model.Params, error)
+	TextSearchFormatting(params *model.Params, ctx echo.Context) (*model.Params, error)
+	DishSearchFormatting(search *model.DishSearch, ctx echo.Context) (*model.DishSearch, error)
 }
 
 type IOrderService interface {
 	Create(ctx context.Context, order *model.OrderRequest) (*model.OrderResponse, error)
 	Update(ctx context.Context, id uint, order *model.Order) (*model.OrderResponse, error)
+	Patch(ctx context.Context, id, version uint, p patch.Patch) (*model.OrderResponse, error)
 	Delete(ctx context.Context, id uint) error
 	GetByID(ctx context.Context, id uint) (*model.OrderResponse, error)
 	GetAllOrders(ctx context.Context, params *model.Params) (*model.ListResponse, error)
@@ -68,7 +78,9 @@ type IMenuService interface {
 	GetRestaurantFood(ctx context.Context, restaurantID, foodID uint) (*model.Food, error)
 	CreateRestaurantFood(ctx context.Context, restaurantID uint, food *model.Food) (*model.Food, error)
 	UpdateRestaurantFood(ctx context.Context, restaurantID uint, food *model.Food) (*model.Food, error)
+	PatchRestaurantFood(ctx context.Context, restaurantID, foodID, version uint, p patch.Patch) (*model.Food, error)
 	DeleteRestaurantFood(ctx context.Context, restaurantID, foodID uint) error
+	RestoreRestaurantFood(ctx context.Context, restaurantID, foodID uint) error
 	GetMenuCategories(ctx context.Context, restaurantID uint) ([]string, error)
 	FormatParams
 }
@@ -78,6 +90,7 @@ type ITableService interface {
 	GetRestaurantTable(ctx context.Context, restaurantID uint, tableID uint) (*model.Table, error)
 	CreateRestaurantTable(ctx context.Context, restaurantID uint, table *model.Table) (*model.Table, error)
 	UpdateRestaurantTable(ctx context.Context, restaurantID uint, table *model.Table) (*model.Table, error)
+	PatchRestaurantTable(ctx context.Context, restaurantID, tableID, version uint, p patch.Patch) (*model.Table, error)
 	DeleteRestaurantTable(ctx context.Context, restaurantID uint, tableID uint) error
 	GetAvailableTime(ctx context.Context, restaurantID uint, tableID uint, date time.Time) ([]time.Time, error)
 	GetTableCategories(ctx context.Context, restaurantID uint) ([]string, error)
@@ -90,3 +103,22 @@ type IReviewsService interface {
 	DeleteReview(ctx context.Context, id uint) error
 	FormatParams
 }
+
+type ISearchService interface {
+	SearchRestaurants(ctx context.Context, params *model.Params) (*model.ListResponse, error)
+	SearchDishes(ctx context.Context, search *model.DishSearch) (*model.ListResponse, error)
+	FormatParams
+}
+
+type IRetentionService interface {
+	Run(ctx context.Context)
+	PurgeDeleted(ctx context.Context) error
+}
+
+type IExportService interface {
+	Run(ctx context.Context)
+	RequestExport(ctx context.Context) (*model.DataExport, error)
+	GetExport(ctx context.Context, id uint) (*model.DataExport, error)
+	GetExportFile(ctx context.Context, id uint, expires int64, signature string) (string, error)
+	PurgeExpired(ctx context.Context) error
+}
@@ -7,6 +7,11 @@ import (
 	"github.com/alibekabdrakhman1/orynal/internal/model"
 	"github.com/alibekabdrakhman1/orynal/internal/repository"
 	"github.com/alibekabdrakhman1/orynal/internal/service/infrastructure"
+	"github.com/alibekabdrakhman1/orynal/pkg/enums"
+	"github.com/alibekabdrakhman1/orynal/pkg/errs"
+	"github.com/alibekabdrakhman1/orynal/pkg/logging"
+	"github.com/alibekabdrakhman1/orynal/pkg/patch"
+	"github.com/alibekabdrakhman1/orynal/pkg/tracing"
 	"github.com/alibekabdrakhman1/orynal/pkg/utils"
 	"go.uber.org/zap"
 )
@@ -23,10 +28,16 @@ type MenuService struct {
 }
 
 func (s *MenuService) GetMenuCategories(ctx context.Context, restaurantID uint) ([]string, error) {
+	ctx, span := tracing.Start(ctx, "MenuService.GetMenuCategories")
+	defer span.End()
+
 	return s.repository.Food.GetMenuCategories(ctx, restaurantID)
 }
 
 func (s *MenuService) GetRestaurantMenu(ctx context.Context, restaurantID uint, params *model.Params) (*model.ListResponse, error) {
+	ctx, span := tracing.Start(ctx, "MenuService.GetRestaurantMenu")
+	defer span.End()
+
 	foods, err := s.repository.Food.GetRestaurantMenu(ctx, restaurantID, params)
 	if err != nil {
 		return nil, err
@@ -36,6 +47,9 @@ func (s *MenuService) GetRestaurantMenu(ctx context.Context, restaurantID uint,
 }
 
 func (s *MenuService) GetRestaurantFood(ctx context.Context, restaurantID, foodID uint) (*model.Food, error) {
+	ctx, span := tracing.Start(ctx, "MenuService.GetRestaurantFood")
+	defer span.End()
+
 	food, err := s.repository.Food.GetRestaurantFood(ctx, restaurantID, foodID)
 	if err != nil {
 		return nil, err
@@ -45,6 +59,9 @@ func (s *MenuService) GetRestaurantFood(ctx context.Context, restaurantID, foodI
 }
 
 func (s *MenuService) CreateRestaurantFood(ctx context.Context, restaurantID uint, food *model.Food) (*model.Food, error) {
+	ctx, span := tracing.Start(ctx, "MenuService.CreateRestaurantFood")
+	defer span.End()
+
 	if err := s.checkOwner(ctx, restaurantID); err != nil {
 		return nil, err
 	}
@@ -58,13 +75,26 @@ func (s *MenuService) CreateRestaurantFood(ctx context.Context, restaurantID uin
 }
 
 func (s *MenuService) UpdateRestaurantFood(ctx context.Context, restaurantID uint, food *model.Food) (*model.Food, error) {
+	ctx, span := tracing.Start(ctx, "MenuService.UpdateRestaurantFood")
+	defer span.End()
+
 	if err := s.checkOwner(ctx, restaurantID); err != nil {
 		return nil, err
 	}
 
-	food.RestaurantID = restaurantID
+	// The dish and its photo are written separately.
+	var updatedFood *model.Food
+	err := s.repository.WithTx(ctx, func(txRepos *repository.Manager) error {
+		if _, err := txRepos.Food.GetRestaurantFood(ctx, restaurantID, food.ID); err != nil {
+			return err
+		}
+
+		food.RestaurantID = restaurantID
 
-	updatedFood, err := s.repository.Food.UpdateRestaurantFood(ctx, food)
+		var err error
+		updatedFood, err = txRepos.Food.UpdateRestaurantFood(ctx, food)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -72,11 +102,54 @@ func (s *MenuService) UpdateRestaurantFood(ctx context.Context, restaurantID uin
 	return updatedFood, nil
 }
 
+// PatchRestaurantFood applies a merge patch to a dish. version is taken from
+// If-Match; 0 patches any version.
+func (s *MenuService) PatchRestaurantFood(ctx context.Context, restaurantID, foodID, version uint, p patch.Patch) (*model.Food, error) {
+	ctx, span := tracing.Start(ctx, "MenuService.PatchRestaurantFood")
+	defer span.End()
+
+	if err := s.checkOwner(ctx, restaurantID); err != nil {
+		return nil, err
+	}
+
+	var patched *model.Food
+	err := s.repository.WithTx(ctx, func(txRepos *repository.Manager) error {
+		food, err := txRepos.Food.GetRestaurantFood(ctx, restaurantID, foodID)
+		if err != nil {
+			return err
+		}
+		if err := checkVersion(version, food.Version); err != nil {
+			return err
+		}
+
+		fields, err := applyPatch(ctx, foodPatchFields, p, food)
+		if err != nil || len(fields) == 0 {
+			patched = food
+			return err
+		}
+
+		patched, err = txRepos.Food.PatchRestaurantFood(ctx, food, fields)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return patched, nil
+}
+
 func (s *MenuService) DeleteRestaurantFood(ctx context.Context, restaurantID, foodID uint) error {
+	ctx, span := tracing.Start(ctx, "MenuService.DeleteRestaurantFood")
+	defer span.End()
+
 	if err := s.checkOwner(ctx, restaurantID); err != nil {
 		return err
 	}
 
+	if _, err := s.repository.Food.GetRestaurantFood(ctx, restaurantID, foodID); err != nil {
+		return err
+	}
+
 	err := s.repository.Food.DeleteRestaurantFood(ctx, foodID)
 	if err != nil {
 		return err
@@ -85,22 +158,39 @@ func (s *MenuService) DeleteRestaurantFood(ctx context.Context, restaurantID, fo
 	return nil
 }
 
+func (s *MenuService) RestoreRestaurantFood(ctx context.Context, restaurantID, foodID uint) error {
+	ctx, span := tracing.Start(ctx, "MenuService.RestoreRestaurantFood")
+	defer span.End()
+
+	role, err := utils.GetRoleFromContext(ctx)
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Error(err)
+		return err
+	}
+
+	if role != enums.Admin {
+		return errs.ErrPermissionDenied
+	}
+
+	return s.repository.Food.RestoreRestaurantFood(ctx, restaurantID, foodID)
+}
+
 func (s *MenuService) checkOwner(ctx context.Context, restaurantID uint) error {
 	restaurant, err := s.repository.Restaurant.GetRestaurantByID(ctx, restaurantID)
 	if err != nil {
-		s.logger.Error(fmt.Errorf("there is not restaurant by id: %v\n%w", restaurantID, err))
-		return fmt.Errorf("there is not restaurant by id: %v", restaurantID)
+		logging.FromContext(ctx, s.logger).Error(fmt.Errorf("there is not restaurant by id: %v\n%w", restaurantID, err))
+		return err
 	}
 
 	userID, err := utils.GetIDFromContext(ctx)
 	if err != nil {
-		s.logger.Error(err)
-		return fmt.Errorf("there is not user id from ctx")
+		logging.FromContext(ctx, s.logger).Error(err)
+		return errs.ErrUnauthorized
 	}
 
 	if restaurant.Owner.ID != userID {
-		s.logger.Error(fmt.Errorf("the user id is not owner of restaurant"))
-		return fmt.Errorf("permission denied")
+		logging.FromContext(ctx, s.logger).Error(fmt.Errorf("the user id is not owner of restaurant"))
+		return errs.ErrNotRestaurantOwner
 	}
 
 	return nil
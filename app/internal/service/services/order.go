@@ -2,14 +2,18 @@ package services
 
 import (
 	"context"
-	"errors"
 	"github.com/alibekabdrakhman1/orynal/config"
 	"github.com/alibekabdrakhman1/orynal/internal/model"
 	"github.com/alibekabdrakhman1/orynal/internal/repository"
 	"github.com/alibekabdrakhman1/orynal/internal/service/infrastructure"
 	"github.com/alibekabdrakhman1/orynal/pkg/enums"
+	"github.com/alibekabdrakhman1/orynal/pkg/errs"
+	"github.com/alibekabdrakhman1/orynal/pkg/metrics"
+	"github.com/alibekabdrakhman1/orynal/pkg/patch"
+	"github.com/alibekabdrakhman1/orynal/pkg/tracing"
 	"github.com/alibekabdrakhman1/orynal/pkg/utils"
 	"go.uber.org/zap"
+	"slices"
 )
 
 func NewOrderService(repository *repository.Manager, config *config.Config, logger *zap.SugaredLogger) *OrderService {
@@ -24,6 +28,9 @@ type OrderService struct {
 }
 
 func (s *OrderService) Create(ctx context.Context, order *model.OrderRequest) (*model.OrderResponse, error) {
+	ctx, span := tracing.Start(ctx, "OrderService.Create")
+	defer span.End()
+
 	userID, err := utils.GetIDFromContext(ctx)
 	if err != nil {
 		return nil, err
@@ -41,47 +48,189 @@ func (s *OrderService) Create(ctx context.Context, order *model.OrderRequest) (*
 	if err != nil {
 		return nil, err
 	}
+
+	metrics.OrdersCreated.Inc()
 	return createdOrder, nil
 }
 
 func (s *OrderService) Update(ctx context.Context, id uint, order *model.Order) (*model.OrderResponse, error) {
-	userID, err := utils.GetIDFromContext(ctx)
+	ctx, span := tracing.Start(ctx, "OrderService.Update")
+	defer span.End()
+
+	role, err := utils.GetRoleFromContext(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	role, err := utils.GetRoleFromContext(ctx)
+	// Only the fields the role may patch are taken from the body, the rest
+	// stay as stored.
+	var updated *model.OrderResponse
+	var canceled bool
+	err = s.repository.WithTx(ctx, func(txRepos *repository.Manager) error {
+		current, err := txRepos.Order.GetOrder(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := s.checkUpdate(ctx, txRepos, current); err != nil {
+			return err
+		}
+
+		next := storedOrder(current)
+		next.Version = order.Version
+		allowed := orderPatchFields[role]
+		if slices.Contains(allowed, "tableId") && order.TableID != 0 {
+			next.TableID = order.TableID
+		}
+		if slices.Contains(allowed, "date") && !order.Date.IsZero() {
+			next.Date = order.Date
+		}
+		if slices.Contains(allowed, "status") && order.Status != "" {
+			next.Status = order.Status
+		}
+		if slices.Contains(allowed, "totalSum") && order.TotalSum != 0 {
+			next.TotalSum = order.TotalSum
+		}
+		if err := checkChange(ctx, txRepos, role, current, next); err != nil {
+			return err
+		}
+
+		canceled = current.Status != enums.Canceled && next.Status == enums.Canceled
+
+		updated, err = txRepos.Order.UpdateOrder(ctx, next)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
-	if role == enums.Owner {
-		return s.repository.Order.UpdateOrder(ctx, order)
+
+	if canceled {
+		metrics.OrdersCanceled.Inc()
 	}
+	return updated, nil
+}
 
-	oldOrder, err := s.repository.Order.GetOrder(ctx, id)
+// Patch applies a merge patch to an order. version is taken from If-Match;
+// 0 patches any version.
+func (s *OrderService) Patch(ctx context.Context, id, version uint, p patch.Patch) (*model.OrderResponse, error) {
+	ctx, span := tracing.Start(ctx, "OrderService.Patch")
+	defer span.End()
+
+	role, err := utils.GetRoleFromContext(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	if role == enums.User && oldOrder.UserID != userID {
-		return nil, errors.New("permission denied")
+	var patched *model.OrderResponse
+	var canceled bool
+	err = s.repository.WithTx(ctx, func(txRepos *repository.Manager) error {
+		current, err := txRepos.Order.GetOrder(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := s.checkUpdate(ctx, txRepos, current); err != nil {
+			return err
+		}
+		if err := checkVersion(version, current.Version); err != nil {
+			return err
+		}
+
+		order := storedOrder(current)
+		fields, err := applyPatch(ctx, orderPatchFields, p, order)
+		if err != nil || len(fields) == 0 {
+			patched = current
+			return err
+		}
+		if err := checkChange(ctx, txRepos, role, current, order); err != nil {
+			return err
+		}
+
+		canceled = current.Status != enums.Canceled && order.Status == enums.Canceled
+
+		patched, err = txRepos.Order.PatchOrder(ctx, order, fields)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if canceled {
+		metrics.OrdersCanceled.Inc()
+	}
+	return patched, nil
+}
+
+// checkUpdate reports whether the caller may change the order: owners the
+// orders of their restaurants, users their own orders until they are
+// canceled or completed.
+func (s *OrderService) checkUpdate(ctx context.Context, repos *repository.Manager, order *model.OrderResponse) error {
+	userID, err := utils.GetIDFromContext(ctx)
+	if err != nil {
+		return err
 	}
 
-	switch oldOrder.Status {
+	role, err := utils.GetRoleFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	if role == enums.Owner {
+		restaurant, err := repos.Restaurant.GetRestaurantByID(ctx, order.RestaurantID)
+		if err != nil {
+			return err
+		}
+		if restaurant.Owner.ID != userID {
+			return errs.ErrNotRestaurantOwner
+		}
+		return nil
+	}
+
+	if role == enums.User && order.UserID != userID {
+		return errs.ErrPermissionDenied
+	}
+
+	switch order.Status {
 	case enums.Canceled:
-		return nil, errors.New("order status is canceled")
+		return errs.ErrOrderCanceled
 	case enums.Completed:
-		return nil, errors.New("order status is completed")
+		return errs.ErrOrderCompleted
 	}
 
-	updatedOrder, err := s.repository.Order.UpdateOrder(ctx, order)
-	if err != nil {
-		return nil, err
+	return nil
+}
+
+// checkChange reports whether current may become next: the order stays in
+// its restaurant, so must the table, and users may only cancel it.
+func checkChange(ctx context.Context, repos *repository.Manager, role string, current *model.OrderResponse, next *model.Order) error {
+	if next.TableID != current.TableID {
+		if _, err := repos.Table.GetRestaurantTable(ctx, current.RestaurantID, next.TableID); err != nil {
+			return err
+		}
+	}
+	if role == enums.User && next.Status != current.Status && next.Status != enums.Canceled {
+		return errs.ErrPermissionDenied
+	}
+
+	return nil
+}
+
+// storedOrder is the row of order, for updates to start from.
+func storedOrder(order *model.OrderResponse) *model.Order {
+	return &model.Order{
+		ID:           order.ID,
+		RestaurantID: order.RestaurantID,
+		TotalSum:     order.TotalSum,
+		UserID:       order.UserID,
+		TableID:      order.TableID,
+		Date:         order.Date,
+		Status:       order.Status,
+		Version:      order.Version,
 	}
-	return updatedOrder, nil
 }
 
 func (s *OrderService) Delete(ctx context.Context, id uint) error {
+	ctx, span := tracing.Start(ctx, "OrderService.Delete")
+	defer span.End()
+
 	userID, err := utils.GetIDFromContext(ctx)
 	if err != nil {
 		return err
@@ -98,7 +247,7 @@ func (s *OrderService) Delete(ctx context.Context, id uint) error {
 	}
 
 	if role == enums.User && order.UserID != userID {
-		return errors.New("permission denied")
+		return errs.ErrPermissionDenied
 	}
 
 	err = s.repository.Order.DeleteOrder(ctx, id)
@@ -110,6 +259,9 @@ func (s *OrderService) Delete(ctx context.Context, id uint) error {
 }
 
 func (s *OrderService) GetByID(ctx context.Context, id uint) (*model.OrderResponse, error) {
+	ctx, span := tracing.Start(ctx, "OrderService.GetByID")
+	defer span.End()
+
 	userID, err := utils.GetIDFromContext(ctx)
 	if err != nil {
 		return nil, err
@@ -126,13 +278,16 @@ func (s *OrderService) GetByID(ctx context.Context, id uint) (*model.OrderRespon
 	}
 
 	if role == enums.User && order.UserID != userID {
-		return nil, errors.New("permission denied")
+		return nil, errs.ErrPermissionDenied
 	}
 
 	return order, nil
 }
 
 func (s *OrderService) GetAllOrders(ctx context.Context, params *model.Params) (*model.ListResponse, error) {
+	ctx, span := tracing.Start(ctx, "OrderService.GetAllOrders")
+	defer span.End()
+
 	id, err := utils.GetIDFromContext(ctx)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,68 @@
+package services
+
+import (
+	"context"
+	"github.com/alibekabdrakhman1/orynal/pkg/enums"
+	"github.com/alibekabdrakhman1/orynal/pkg/errs"
+	"github.com/alibekabdrakhman1/orynal/pkg/patch"
+	"github.com/alibekabdrakhman1/orynal/pkg/utils"
+	"github.com/alibekabdrakhman1/orynal/pkg/validator"
+)
+
+// The json fields each role may set with a merge patch. Relations such as
+// photos, services and order foods are only replaced through PUT.
+var (
+	restaurantPatchFields = map[string][]string{
+		enums.Admin: {"name", "address", "description", "city", "latitude", "longitude", "status", "phone", "ownerId", "modeFrom", "modeTo"},
+		enums.Owner: {"name", "address", "description", "city", "latitude", "longitude", "status", "phone", "modeFrom", "modeTo"},
+	}
+	tablePatchFields = map[string][]string{
+		enums.Owner: {"name", "type", "description", "capacity"},
+	}
+	foodPatchFields = map[string][]string{
+		enums.Owner: {"name", "type", "description", "price", "available", "vegetarian", "vegan", "halal", "glutenFree"},
+	}
+	orderPatchFields = map[string][]string{
+		enums.Admin: {"tableId", "date", "status", "totalSum"},
+		enums.Owner: {"status"},
+		enums.User:  {"tableId", "date", "status"},
+	}
+	profilePatchFields = map[string][]string{
+		enums.Admin: {"name", "surname", "email", "phone"},
+		enums.Owner: {"name", "surname", "email", "phone"},
+		enums.User:  {"name", "surname", "email", "phone"},
+	}
+)
+
+var patchValidator = validator.New()
+
+// applyPatch merges p into dst, allowing the fields the role of the caller
+// may set, and validates the fields it set. It returns their Go names.
+func applyPatch(ctx context.Context, allowed map[string][]string, p patch.Patch, dst any) ([]string, error) {
+	role, err := utils.GetRoleFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	fields, err := p.Apply(dst, allowed[role]...)
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	if err := patchValidator.ValidateFields(dst, fields...); err != nil {
+		return nil, err
+	}
+
+	return fields, nil
+}
+
+// checkVersion compares the version from If-Match, 0 for any, with the
+// version of the row the patch is applied to.
+func checkVersion(version, current uint) error {
+	if version != 0 && version != current {
+		return errs.ErrVersionMismatch
+	}
+	return nil
+}
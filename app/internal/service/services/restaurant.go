@@ -1,6 +1,7 @@
 package services
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
@@ -9,47 +10,76 @@ import (
 	"github.com/alibekabdrakhman1/orynal/internal/repository"
 	"github.com/alibekabdrakhman1/orynal/internal/service/infrastructure"
 	"github.com/alibekabdrakhman1/orynal/pkg/enums"
+	"github.com/alibekabdrakhman1/orynal/pkg/errs"
+	"github.com/alibekabdrakhman1/orynal/pkg/geocode"
+	"github.com/alibekabdrakhman1/orynal/pkg/logging"
+	"github.com/alibekabdrakhman1/orynal/pkg/patch"
+	"github.com/alibekabdrakhman1/orynal/pkg/tracing"
 	"github.com/alibekabdrakhman1/orynal/pkg/utils"
 	"go.uber.org/zap"
-	"gorm.io/gorm"
+	"slices"
 )
 
-func NewRestaurantService(repository *repository.Manager, config *config.Config, logger *zap.SugaredLogger) *RestaurantService {
-	return &RestaurantService{repository: repository, config: config, logger: logger, FormatParams: infrastructure.NewFormatParams()}
+func NewRestaurantService(repository *repository.Manager, geocoder geocode.Geocoder, config *config.Config, logger *zap.SugaredLogger) *RestaurantService {
+	return &RestaurantService{repository: repository, geocoder: geocoder, config: config, logger: logger, FormatParams: infrastructure.NewFormatParams()}
 }
 
 type RestaurantService struct {
 	repository *repository.Manager
-	config     *config.Config
-	logger     *zap.SugaredLogger
+	// geocoder places restaurants created or moved without coordinates;
+	// nil leaves them unplaced.
+	geocoder geocode.Geocoder
+	config   *config.Config
+	logger   *zap.SugaredLogger
 	FormatParams
 }
 
 func (s *RestaurantService) GetStatistics(ctx context.Context) (*model.Statistics, error) {
+	ctx, span := tracing.Start(ctx, "RestaurantService.GetStatistics")
+	defer span.End()
+
 	return s.repository.Restaurant.GetStatistics(ctx)
 }
 
 func (s *RestaurantService) PopularRestaurants(ctx context.Context) (*model.ListResponse, error) {
+	ctx, span := tracing.Start(ctx, "RestaurantService.PopularRestaurants")
+	defer span.End()
+
 	return s.repository.Restaurant.GetPopularRestaurants(ctx)
 }
 
 func (s *RestaurantService) CreateService(ctx context.Context, service *model.Service) ([]model.Service, error) {
+	ctx, span := tracing.Start(ctx, "RestaurantService.CreateService")
+	defer span.End()
+
 	return s.repository.Services.CreateService(ctx, service)
 }
 
 func (s *RestaurantService) DeleteService(ctx context.Context, id uint) error {
+	ctx, span := tracing.Start(ctx, "RestaurantService.DeleteService")
+	defer span.End()
+
 	return s.repository.Services.DeleteService(ctx, id)
 }
 
 func (s *RestaurantService) GetServices(ctx context.Context) ([]model.Service, error) {
+	ctx, span := tracing.Start(ctx, "RestaurantService.GetServices")
+	defer span.End()
+
 	return s.repository.Services.GetServices(ctx)
 }
 
 func (s *RestaurantService) UpdateService(ctx context.Context, service *model.Service) ([]model.Service, error) {
+	ctx, span := tracing.Start(ctx, "RestaurantService.UpdateService")
+	defer span.End()
+
 	return s.repository.Services.UpdateService(ctx, service)
 }
 
 func (s *RestaurantService) GetRestaurants(ctx context.Context, params *model.Params) (*model.ListResponse, error) {
+	ctx, span := tracing.Start(ctx, "RestaurantService.GetRestaurants")
+	defer span.End()
+
 	role, err := utils.GetRoleFromContext(ctx)
 	if err != nil {
 		return s.repository.Restaurant.GetRestaurants(ctx, params)
@@ -58,7 +88,7 @@ func (s *RestaurantService) GetRestaurants(ctx context.Context, params *model.Pa
 	if role == enums.Owner {
 		id, err := utils.GetIDFromContext(ctx)
 		if err != nil {
-			s.logger.Error(err)
+			logging.FromContext(ctx, s.logger).Error(err)
 			return nil, err
 		}
 		return s.repository.Restaurant.GetRestaurantsByOwner(ctx, id, params)
@@ -68,60 +98,240 @@ func (s *RestaurantService) GetRestaurants(ctx context.Context, params *model.Pa
 }
 
 func (s *RestaurantService) GetRestaurantByID(ctx context.Context, id uint) (*model.Restaurant, error) {
+	ctx, span := tracing.Start(ctx, "RestaurantService.GetRestaurantByID")
+	defer span.End()
+
 	return s.repository.Restaurant.GetRestaurantByID(ctx, id)
 }
 
 func (s *RestaurantService) CreateRestaurant(ctx context.Context, restaurant *model.Restaurant) (*model.Restaurant, error) {
+	ctx, span := tracing.Start(ctx, "RestaurantService.CreateRestaurant")
+	defer span.End()
+
 	role, err := utils.GetRoleFromContext(ctx)
 	if err != nil {
-		s.logger.Error(err)
+		logging.FromContext(ctx, s.logger).Error(err)
 		return nil, err
 	}
 
 	if role != enums.Admin {
-		return nil, errors.New("permission denied")
+		return nil, errs.ErrPermissionDenied
+	}
+	s.locate(ctx, restaurant)
+
+	// The owner check and the insert share a transaction so the owner
+	// cannot be deleted or demoted in between.
+	var created *model.Restaurant
+	err = s.repository.WithTx(ctx, func(txRepos *repository.Manager) error {
+		if err := s.checkOwnerAccount(ctx, txRepos, restaurant.OwnerID); err != nil {
+			return err
+		}
+
+		created, err = txRepos.Restaurant.CreateRestaurant(ctx, restaurant)
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	owner, err := s.repository.User.GetByID(ctx, restaurant.OwnerID)
+	return created, nil
+}
+
+func (s *RestaurantService) UpdateRestaurant(ctx context.Context, restaurant *model.Restaurant, id uint) (*model.Restaurant, error) {
+	ctx, span := tracing.Start(ctx, "RestaurantService.UpdateRestaurant")
+	defer span.End()
+
+	role, err := utils.GetRoleFromContext(ctx)
 	if err != nil {
-		s.logger.Error(err)
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("owner not found")
+		logging.FromContext(ctx, s.logger).Error(err)
+		return nil, err
+	}
+
+	switch role {
+	case enums.Admin:
+	case enums.Owner:
+		if err := s.checkOwner(ctx, id); err != nil {
+			return nil, err
 		}
-		return nil, errors.New(fmt.Sprintf("error get owner from db: %s", err.Error()))
+	default:
+		return nil, errs.ErrPermissionDenied
 	}
 
-	if owner.Role != enums.Owner {
-		return nil, errors.New("by this id is not owner")
+	// Geocoding waits on an HTTP call, so it runs before the transaction.
+	current, err := s.repository.Restaurant.GetRestaurantByID(ctx, id)
+	if err != nil {
+		return nil, err
 	}
+	s.place(ctx, current, restaurant)
 
-	return s.repository.Restaurant.CreateRestaurant(ctx, restaurant)
+	// The row, its photos and its services are written separately. Only
+	// admins hand a restaurant to another owner, as with PATCH.
+	restaurant.Owner = model.UserResponse{}
+	var updated *model.Restaurant
+	err = s.repository.WithTx(ctx, func(txRepos *repository.Manager) error {
+		current, err := txRepos.Restaurant.GetRestaurantByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if role != enums.Admin || restaurant.OwnerID == 0 {
+			restaurant.OwnerID = current.OwnerID
+		} else if restaurant.OwnerID != current.OwnerID {
+			if err := s.checkOwnerAccount(ctx, txRepos, restaurant.OwnerID); err != nil {
+				return err
+			}
+		}
+
+		updated, err = txRepos.Restaurant.UpdateRestaurant(ctx, id, restaurant)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return updated, nil
 }
 
-func (s *RestaurantService) UpdateRestaurant(ctx context.Context, restaurant *model.Restaurant, id uint) (*model.Restaurant, error) {
+// PatchRestaurant applies a merge patch to the restaurant row; its photos and
+// services are left alone. version is taken from If-Match; 0 patches any
+// version.
+func (s *RestaurantService) PatchRestaurant(ctx context.Context, id, version uint, p patch.Patch) (*model.Restaurant, error) {
+	ctx, span := tracing.Start(ctx, "RestaurantService.PatchRestaurant")
+	defer span.End()
+
 	role, err := utils.GetRoleFromContext(ctx)
 	if err != nil {
-		s.logger.Error(err)
+		logging.FromContext(ctx, s.logger).Error(err)
 		return nil, err
 	}
 
 	switch role {
 	case enums.Admin:
-		return s.repository.Restaurant.UpdateRestaurant(ctx, id, restaurant)
 	case enums.Owner:
 		if err := s.checkOwner(ctx, id); err != nil {
 			return nil, err
 		}
-		return s.repository.Restaurant.UpdateRestaurant(ctx, id, restaurant)
 	default:
-		return nil, errors.New("permission denied")
+		return nil, errs.ErrPermissionDenied
 	}
+
+	// Geocoding waits on an HTTP call, so it runs before the transaction.
+	located, err := s.locatePatch(ctx, id, p)
+	if err != nil {
+		return nil, err
+	}
+
+	var patched *model.Restaurant
+	err = s.repository.WithTx(ctx, func(txRepos *repository.Manager) error {
+		restaurant, err := txRepos.Restaurant.GetRestaurantByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := checkVersion(version, restaurant.Version); err != nil {
+			return err
+		}
+
+		fields, err := applyPatch(ctx, restaurantPatchFields, p, restaurant)
+		if err != nil || len(fields) == 0 {
+			patched = restaurant
+			return err
+		}
+		if moved(fields) && s.geocoder != nil {
+			restaurant.Latitude, restaurant.Longitude = nil, nil
+			// The address may have changed since it was geocoded.
+			if located != nil && located.Address == restaurant.Address && located.City == restaurant.City {
+				restaurant.Latitude, restaurant.Longitude = located.Latitude, located.Longitude
+			}
+			fields = append(fields, "Latitude", "Longitude")
+		}
+		if slices.Contains(fields, "OwnerID") {
+			if err := s.checkOwnerAccount(ctx, txRepos, restaurant.OwnerID); err != nil {
+				return err
+			}
+		}
+
+		patched, err = txRepos.Restaurant.PatchRestaurant(ctx, restaurant, fields)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return patched, nil
+}
+
+// locate fills in the coordinates of a restaurant given without them from
+// its address. A failed lookup only leaves the restaurant out of nearby
+// searches, so it is logged rather than returned.
+func (s *RestaurantService) locate(ctx context.Context, restaurant *model.Restaurant) {
+	if s.geocoder == nil || restaurant.Location() != nil || restaurant.City == "" {
+		return
+	}
+
+	location, err := s.geocoder.Geocode(ctx, restaurant.Address, restaurant.City)
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Warnw("cannot geocode restaurant", "address", restaurant.Address, "city", restaurant.City, "error", err)
+		return
+	}
+	restaurant.Latitude, restaurant.Longitude = &location.Lat, &location.Lng
+}
+
+// place sets the coordinates a PUT writes, by the rule PATCH follows:
+// coordinates that differ from the stored ones are kept, otherwise they
+// follow the address, staying if it did and placed again if it moved.
+func (s *RestaurantService) place(ctx context.Context, current, restaurant *model.Restaurant) {
+	if location := restaurant.Location(); location != nil && (current.Location() == nil || *location != *current.Location()) {
+		return
+	}
+
+	// Fields left out of the body keep their stored values.
+	target := *restaurant
+	target.Address = cmp.Or(target.Address, current.Address)
+	target.City = cmp.Or(target.City, current.City)
+	if target.Address == current.Address && target.City == current.City {
+		restaurant.Latitude, restaurant.Longitude = current.Latitude, current.Longitude
+		return
+	}
+
+	target.Latitude, target.Longitude = nil, nil
+	s.locate(ctx, &target)
+	restaurant.Latitude, restaurant.Longitude = target.Latitude, target.Longitude
+}
+
+// locatePatch geocodes the address a patch moves the restaurant to. It
+// returns nil when the patch does not move it.
+func (s *RestaurantService) locatePatch(ctx context.Context, id uint, p patch.Patch) (*model.Restaurant, error) {
+	if s.geocoder == nil {
+		return nil, nil
+	}
+
+	restaurant, err := s.repository.Restaurant.GetRestaurantByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := applyPatch(ctx, restaurantPatchFields, p, restaurant)
+	if err != nil || !moved(fields) {
+		return nil, err
+	}
+
+	restaurant.Latitude, restaurant.Longitude = nil, nil
+	s.locate(ctx, restaurant)
+	return restaurant, nil
+}
+
+// moved reports whether a patch changed the address of a restaurant without
+// setting its coordinates, which then have to follow it.
+func moved(fields []string) bool {
+	return (slices.Contains(fields, "Address") || slices.Contains(fields, "City")) &&
+		!slices.Contains(fields, "Latitude") && !slices.Contains(fields, "Longitude")
 }
 
 func (s *RestaurantService) DeleteRestaurant(ctx context.Context, id uint) error {
+	ctx, span := tracing.Start(ctx, "RestaurantService.DeleteRestaurant")
+	defer span.End()
+
 	role, err := utils.GetRoleFromContext(ctx)
 	if err != nil {
-		s.logger.Error(err)
+		logging.FromContext(ctx, s.logger).Error(err)
 		return err
 	}
 
@@ -134,19 +344,41 @@ func (s *RestaurantService) DeleteRestaurant(ctx context.Context, id uint) error
 		}
 		return s.repository.Restaurant.DeleteRestaurant(ctx, id)
 	default:
-		return errors.New("permission denied")
+		return errs.ErrPermissionDenied
 	}
 }
 
+func (s *RestaurantService) RestoreRestaurant(ctx context.Context, id uint) error {
+	ctx, span := tracing.Start(ctx, "RestaurantService.RestoreRestaurant")
+	defer span.End()
+
+	role, err := utils.GetRoleFromContext(ctx)
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Error(err)
+		return err
+	}
+
+	if role != enums.Admin {
+		return errs.ErrPermissionDenied
+	}
+
+	return s.repository.Restaurant.RestoreRestaurant(ctx, id)
+}
+
 func (s *RestaurantService) FavoriteRestaurants(ctx context.Context, id uint, params *model.Params) (*model.ListResponse, error) {
-	//TODO implement me
-	panic("implement me")
+	ctx, span := tracing.Start(ctx, "RestaurantService.FavoriteRestaurants")
+	defer span.End()
+
+	return s.repository.Restaurant.GetFavoriteRestaurants(ctx, id, params)
 }
 
 func (s *RestaurantService) GetRestaurantOrders(ctx context.Context, id uint, params *model.Params) (*model.ListResponse, error) {
+	ctx, span := tracing.Start(ctx, "RestaurantService.GetRestaurantOrders")
+	defer span.End()
+
 	role, err := utils.GetRoleFromContext(ctx)
 	if err != nil {
-		s.logger.Error(err)
+		logging.FromContext(ctx, s.logger).Error(err)
 		return nil, err
 	}
 
@@ -159,26 +391,45 @@ func (s *RestaurantService) GetRestaurantOrders(ctx context.Context, id uint, pa
 		}
 		return s.repository.Order.GetRestaurantOrders(ctx, id, params)
 	default:
-		return nil, errors.New("permission denied")
+		return nil, errs.ErrPermissionDenied
+	}
+}
+
+// checkOwnerAccount reports ErrInvalidOwner unless ownerID is a restaurant
+// owner.
+func (s *RestaurantService) checkOwnerAccount(ctx context.Context, repos *repository.Manager, ownerID uint) error {
+	owner, err := repos.User.GetByID(ctx, ownerID)
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Error(err)
+		if errors.Is(err, errs.ErrUserNotFound) {
+			return errs.ErrInvalidOwner
+		}
+		return err
+	}
+
+	if owner.Role != enums.Owner {
+		return errs.ErrInvalidOwner
 	}
+
+	return nil
 }
 
 func (s *RestaurantService) checkOwner(ctx context.Context, restaurantID uint) error {
 	restaurant, err := s.repository.Restaurant.GetRestaurantByID(ctx, restaurantID)
 	if err != nil {
-		s.logger.Error(fmt.Errorf("there is not restaurant by id: %v\n%w", restaurantID, err))
-		return fmt.Errorf("there is not restaurant by id: %v", restaurantID)
+		logging.FromContext(ctx, s.logger).Error(fmt.Errorf("there is not restaurant by id: %v\n%w", restaurantID, err))
+		return err
 	}
 
 	userID, err := utils.GetIDFromContext(ctx)
 	if err != nil {
-		s.logger.Error(err)
-		return fmt.Errorf("there is not user id from ctx")
+		logging.FromContext(ctx, s.logger).Error(err)
+		return errs.ErrUnauthorized
 	}
 
 	if restaurant.Owner.ID != userID {
-		s.logger.Error(fmt.Errorf("the user id is not owner of restaurant"))
-		return fmt.Errorf("permission denied")
+		logging.FromContext(ctx, s.logger).Error(fmt.Errorf("the user id is not owner of restaurant"))
+		return errs.ErrNotRestaurantOwner
 	}
 
 	return nil
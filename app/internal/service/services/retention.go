@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"github.com/alibekabdrakhman1/orynal/config"
+	"github.com/alibekabdrakhman1/orynal/internal/repository"
+	"github.com/alibekabdrakhman1/orynal/pkg/logging"
+	"github.com/alibekabdrakhman1/orynal/pkg/tracing"
+	"go.uber.org/zap"
+	"time"
+)
+
+func NewRetentionService(repository *repository.Manager, export IExportService, config *config.Config, logger *zap.SugaredLogger) *RetentionService {
+	return &RetentionService{repository: repository, export: export, config: config, logger: logger}
+}
+
+// RetentionService permanently removes soft-deleted restaurants and menu
+// items once they are older than the configured retention period, along with
+// expired personal data exports. Restaurants and dishes that orders reference
+// stay soft-deleted so the order history survives. Users are never
+// hard-deleted: accounts whose cooling-off or retention period has passed are
+// anonymized instead, so their orders and reviews survive. Each step has its
+// own setting: a zero Period keeps soft-deleted rows, but deletion requests
+// are still honored after CoolingOff and expired exports still removed.
+type RetentionService struct {
+	repository *repository.Manager
+	export     IExportService
+	config     *config.Config
+	logger     *zap.SugaredLogger
+}
+
+func (s *RetentionService) Run(ctx context.Context) {
+	if s.config.Retention.Interval <= 0 {
+		logging.FromContext(ctx, s.logger).Info("retention job is disabled")
+		return
+	}
+
+	ticker := time.NewTicker(s.config.Retention.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.PurgeDeleted(ctx); err != nil {
+			logging.FromContext(ctx, s.logger).Errorf("retention purge err: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *RetentionService) PurgeDeleted(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "RetentionService.PurgeDeleted")
+	defer span.End()
+
+	// The zero time keeps every soft-deleted row.
+	var before time.Time
+	if s.config.Retention.Period > 0 {
+		before = time.Now().Add(-s.config.Retention.Period)
+	}
+
+	var foods, restaurants int64
+	if !before.IsZero() {
+		var err error
+		if foods, err = s.repository.Food.PurgeDeletedFoods(ctx, before); err != nil {
+			return fmt.Errorf("purge foods err: %w", err)
+		}
+		if restaurants, err = s.repository.Restaurant.PurgeDeletedRestaurants(ctx, before); err != nil {
+			return fmt.Errorf("purge restaurants err: %w", err)
+		}
+	}
+
+	users, err := s.anonymizeUsers(ctx, before)
+	if err != nil {
+		return fmt.Errorf("anonymize users err: %w", err)
+	}
+
+	if err := s.export.PurgeExpired(ctx); err != nil {
+		return fmt.Errorf("purge data exports err: %w", err)
+	}
+
+	if foods+restaurants+users > 0 {
+		logging.FromContext(ctx, s.logger).Infof("retention purge: foods=%d restaurants=%d anonymized users=%d", foods, restaurants, users)
+	}
+
+	return nil
+}
+
+func (s *RetentionService) anonymizeUsers(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	requestedBefore := time.Now().Add(-s.config.Retention.CoolingOff)
+
+	ids, err := s.repository.User.GetDueForAnonymization(ctx, requestedBefore, deletedBefore)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, id := range ids {
+		if err := s.repository.User.Anonymize(ctx, id); err != nil {
+			return 0, fmt.Errorf("anonymize user %d err: %w", id, err)
+		}
+	}
+
+	return int64(len(ids)), nil
+}
@@ -2,11 +2,12 @@ package services
 
 import (
 	"context"
-	"errors"
 	"github.com/alibekabdrakhman1/orynal/config"
 	"github.com/alibekabdrakhman1/orynal/internal/model"
 	"github.com/alibekabdrakhman1/orynal/internal/repository"
 	"github.com/alibekabdrakhman1/orynal/internal/service/infrastructure"
+	"github.com/alibekabdrakhman1/orynal/pkg/errs"
+	"github.com/alibekabdrakhman1/orynal/pkg/tracing"
 	"github.com/alibekabdrakhman1/orynal/pkg/utils"
 	"go.uber.org/zap"
 	"time"
@@ -24,6 +25,9 @@ type ReviewsService struct {
 }
 
 func (s *ReviewsService) GetReviews(ctx context.Context, restaurantID uint, params *model.Params) (*model.ListResponse, error) {
+	ctx, span := tracing.Start(ctx, "ReviewsService.GetReviews")
+	defer span.End()
+
 	resp, err := s.repository.Reviews.GetReviews(ctx, restaurantID, params)
 	if err != nil {
 		return nil, err
@@ -38,6 +42,9 @@ func (s *ReviewsService) GetReviews(ctx context.Context, restaurantID uint, para
 }
 
 func (s *ReviewsService) CreateReview(ctx context.Context, review *model.RestaurantReview) (*model.RestaurantReview, error) {
+	ctx, span := tracing.Start(ctx, "ReviewsService.CreateReview")
+	defer span.End()
+
 	id, err := utils.GetIDFromContext(ctx)
 	if err != nil {
 		return nil, err
@@ -50,7 +57,10 @@ func (s *ReviewsService) CreateReview(ctx context.Context, review *model.Restaur
 }
 
 func (s *ReviewsService) DeleteReview(ctx context.Context, id uint) error {
-	id, err := utils.GetIDFromContext(ctx)
+	ctx, span := tracing.Start(ctx, "ReviewsService.DeleteReview")
+	defer span.End()
+
+	userID, err := utils.GetIDFromContext(ctx)
 	if err != nil {
 		return err
 	}
@@ -60,8 +70,8 @@ func (s *ReviewsService) DeleteReview(ctx context.Context, id uint) error {
 		return err
 	}
 
-	if review.UserID != id {
-		return errors.New("not author")
+	if review.UserID != userID {
+		return errs.ErrNotReviewAuthor
 	}
 
 	return s.repository.Reviews.DeleteReview(ctx, id)
@@ -0,0 +1,68 @@
+package services
+
+import (
+	"context"
+	"github.com/alibekabdrakhman1/orynal/config"
+	"github.com/alibekabdrakhman1/orynal/internal/model"
+	"github.com/alibekabdrakhman1/orynal/internal/repository"
+	"github.com/alibekabdrakhman1/orynal/internal/service/infrastructure"
+	"github.com/alibekabdrakhman1/orynal/pkg/logging"
+	"github.com/alibekabdrakhman1/orynal/pkg/tracing"
+	"go.uber.org/zap"
+	"time"
+)
+
+func NewSearchService(repository *repository.Manager, config *config.Config, logger *zap.SugaredLogger) *SearchService {
+	// The app refuses to start with an unknown zone, so this falls back to
+	// UTC only in tests.
+	location, err := config.Location()
+	if err != nil {
+		location = time.UTC
+	}
+	return &SearchService{repository: repository, config: config, logger: logger, location: location, FormatParams: infrastructure.NewFormatParams()}
+}
+
+type SearchService struct {
+	repository *repository.Manager
+	config     *config.Config
+	logger     *zap.SugaredLogger
+	location   *time.Location
+	FormatParams
+}
+
+func (s *SearchService) SearchRestaurants(ctx context.Context, params *model.Params) (*model.ListResponse, error) {
+	ctx, span := tracing.Start(ctx, "SearchService.SearchRestaurants")
+	defer span.End()
+
+	list, err := s.repository.Search.SearchRestaurants(ctx, params)
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Error(err)
+		return nil, err
+	}
+
+	list.TotalPages = (list.TotalItems + list.ItemsPerPage - 1) / list.ItemsPerPage
+
+	return list, nil
+}
+
+// SearchDishes groups the dishes matching search by restaurant and tells
+// whether each restaurant is open now in the business time zone.
+func (s *SearchService) SearchDishes(ctx context.Context, search *model.DishSearch) (*model.ListResponse, error) {
+	ctx, span := tracing.Start(ctx, "SearchService.SearchDishes")
+	defer span.End()
+
+	list, err := s.repository.Search.SearchDishes(ctx, search)
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Error(err)
+		return nil, err
+	}
+
+	now := time.Now().In(s.location)
+	groups := list.Items.([]model.DishGroup)
+	for i := range groups {
+		groups[i].Restaurant.OpenNow = groups[i].Restaurant.OpenAt(now)
+	}
+	list.TotalPages = (list.TotalItems + list.ItemsPerPage - 1) / list.ItemsPerPage
+
+	return list, nil
+}
@@ -0,0 +1,1262 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/alibekabdrakhman1/orynal/config"
+	"github.com/alibekabdrakhman1/orynal/internal/model"
+	"github.com/alibekabdrakhman1/orynal/internal/repository"
+	"github.com/alibekabdrakhman1/orynal/internal/repository/memory"
+	"github.com/alibekabdrakhman1/orynal/pkg/enums"
+	"github.com/alibekabdrakhman1/orynal/pkg/errs"
+	"github.com/alibekabdrakhman1/orynal/pkg/geocode"
+	"github.com/alibekabdrakhman1/orynal/pkg/patch"
+	"github.com/alibekabdrakhman1/orynal/pkg/validator"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fixture seeds two owners with a restaurant, a table and a dish each, a
+// client with an order and a review, and an admin.
+type fixture struct {
+	repo     *repository.Manager
+	geocoder geocode.Geocoder
+	config   *config.Config
+	logger   *zap.SugaredLogger
+
+	admin, owner, rival, client, other uint
+
+	restaurant, rivalRestaurant uint
+	table, rivalTable           uint
+	food, rivalFood             uint
+	order, review               uint
+}
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+
+	f := &fixture{
+		repo:     repository.NewMemoryManager(memory.NewStore()),
+		geocoder: geocode.NewOffline(),
+		config:   &config.Config{Auth: config.Auth{JwtSecretKey: "secret"}},
+		logger:   zap.NewNop().Sugar(),
+	}
+	ctx := context.Background()
+
+	user := func(email, role string) uint {
+		created, err := f.repo.User.Create(ctx, &model.User{Name: role, Email: email, Phone: email, Role: role})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return created.ID
+	}
+	f.admin = user("admin@orynal.kz", enums.Admin)
+	f.owner = user("owner@orynal.kz", enums.Owner)
+	f.rival = user("rival@orynal.kz", enums.Owner)
+	f.client = user("client@orynal.kz", enums.User)
+	f.other = user("other@orynal.kz", enums.User)
+
+	restaurant := func(ownerID uint) (uint, uint, uint) {
+		created, err := f.repo.Restaurant.CreateRestaurant(ctx, &model.Restaurant{Name: "Restaurant", OwnerID: ownerID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		table, err := f.repo.Table.CreateTable(ctx, &model.Table{Name: "Table", Type: "hall", Capacity: 4, RestaurantID: created.ID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		food, err := f.repo.Food.CreateRestaurantFood(ctx, &model.Food{Name: "Plov", Type: "main", Price: 3500, RestaurantID: created.ID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return created.ID, table.ID, food.ID
+	}
+	f.restaurant, f.table, f.food = restaurant(f.owner)
+	f.rivalRestaurant, f.rivalTable, f.rivalFood = restaurant(f.rival)
+
+	order, err := f.repo.Order.CreateOrder(ctx, &model.Order{
+		RestaurantID: f.restaurant,
+		UserID:       f.client,
+		TableID:      f.table,
+		Date:         time.Now().Add(24 * time.Hour),
+		Status:       enums.Reserved,
+		OrderFoods:   []uint{f.food},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.order = order.ID
+
+	review, err := f.repo.Reviews.CreateReview(ctx, &model.RestaurantReview{Stars: 5, UserID: f.client, RestaurantID: f.restaurant})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.review = review.ID
+
+	return f
+}
+
+// as returns a context authenticated the way the JWT middleware does it.
+func as(userID uint, role string) context.Context {
+	ctx := context.WithValue(context.Background(), model.ContextUserIDKey, &model.ContextUserID{ID: userID})
+	return context.WithValue(ctx, model.ContextUserRoleKey, &model.ContextUserRole{Role: role})
+}
+
+func (f *fixture) setOrderStatus(t *testing.T, status string) {
+	t.Helper()
+
+	if _, err := f.repo.Order.UpdateOrder(context.Background(), &model.Order{ID: f.order, Status: status}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func checkError(t *testing.T, err, want error) {
+	t.Helper()
+
+	if want == nil && err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want != nil && !errors.Is(err, want) {
+		t.Fatalf("got error %v, want %v", err, want)
+	}
+}
+
+func TestOwnershipChecks(t *testing.T) {
+	tests := []struct {
+		name string
+		call func(f *fixture) error
+		want error
+	}{
+		{
+			name: "owner updates own restaurant",
+			call: func(f *fixture) error {
+				_, err := NewRestaurantService(f.repo, f.geocoder, f.config, f.logger).UpdateRestaurant(as(f.owner, enums.Owner), &model.Restaurant{Name: "Renamed"}, f.restaurant)
+				return err
+			},
+		},
+		{
+			name: "owner updates another owner's restaurant",
+			call: func(f *fixture) error {
+				_, err := NewRestaurantService(f.repo, f.geocoder, f.config, f.logger).UpdateRestaurant(as(f.rival, enums.Owner), &model.Restaurant{Name: "Renamed"}, f.restaurant)
+				return err
+			},
+			want: errs.ErrNotRestaurantOwner,
+		},
+		{
+			name: "client updates restaurant",
+			call: func(f *fixture) error {
+				_, err := NewRestaurantService(f.repo, f.geocoder, f.config, f.logger).UpdateRestaurant(as(f.client, enums.User), &model.Restaurant{Name: "Renamed"}, f.restaurant)
+				return err
+			},
+			want: errs.ErrPermissionDenied,
+		},
+		{
+			name: "admin updates any restaurant",
+			call: func(f *fixture) error {
+				_, err := NewRestaurantService(f.repo, f.geocoder, f.config, f.logger).UpdateRestaurant(as(f.admin, enums.Admin), &model.Restaurant{Name: "Renamed"}, f.restaurant)
+				return err
+			},
+		},
+		{
+			name: "owner hands own restaurant to a client",
+			call: func(f *fixture) error {
+				updated, err := NewRestaurantService(f.repo, f.geocoder, f.config, f.logger).UpdateRestaurant(as(f.owner, enums.Owner), &model.Restaurant{Name: "Renamed", OwnerID: f.client}, f.restaurant)
+				if err != nil {
+					return err
+				}
+				if updated.OwnerID != f.owner {
+					return fmt.Errorf("owner = %d, want %d", updated.OwnerID, f.owner)
+				}
+				return nil
+			},
+		},
+		{
+			name: "admin hands restaurant to a client",
+			call: func(f *fixture) error {
+				_, err := NewRestaurantService(f.repo, f.geocoder, f.config, f.logger).UpdateRestaurant(as(f.admin, enums.Admin), &model.Restaurant{OwnerID: f.client}, f.restaurant)
+				return err
+			},
+			want: errs.ErrInvalidOwner,
+		},
+		{
+			name: "admin hands restaurant to another owner",
+			call: func(f *fixture) error {
+				_, err := NewRestaurantService(f.repo, f.geocoder, f.config, f.logger).UpdateRestaurant(as(f.admin, enums.Admin), &model.Restaurant{OwnerID: f.rival}, f.restaurant)
+				return err
+			},
+		},
+		{
+			name: "owner deletes another owner's restaurant",
+			call: func(f *fixture) error {
+				return NewRestaurantService(f.repo, f.geocoder, f.config, f.logger).DeleteRestaurant(as(f.rival, enums.Owner), f.restaurant)
+			},
+			want: errs.ErrNotRestaurantOwner,
+		},
+		{
+			name: "owner creates restaurant",
+			call: func(f *fixture) error {
+				_, err := NewRestaurantService(f.repo, f.geocoder, f.config, f.logger).CreateRestaurant(as(f.owner, enums.Owner), &model.Restaurant{Name: "New", OwnerID: f.owner})
+				return err
+			},
+			want: errs.ErrPermissionDenied,
+		},
+		{
+			name: "admin creates restaurant for a client",
+			call: func(f *fixture) error {
+				_, err := NewRestaurantService(f.repo, f.geocoder, f.config, f.logger).CreateRestaurant(as(f.admin, enums.Admin), &model.Restaurant{Name: "New", OwnerID: f.client})
+				return err
+			},
+			want: errs.ErrInvalidOwner,
+		},
+		{
+			name: "owner lists another owner's orders",
+			call: func(f *fixture) error {
+				_, err := NewRestaurantService(f.repo, f.geocoder, f.config, f.logger).GetRestaurantOrders(as(f.rival, enums.Owner), f.restaurant, &model.Params{Limit: 10})
+				return err
+			},
+			want: errs.ErrNotRestaurantOwner,
+		},
+		{
+			name: "owner adds a dish",
+			call: func(f *fixture) error {
+				_, err := NewMenuService(f.repo, f.config, f.logger).CreateRestaurantFood(as(f.owner, enums.Owner), f.restaurant, &model.Food{Name: "Manty", Type: "main", Price: 2500})
+				return err
+			},
+		},
+		{
+			name: "owner adds a dish to another owner's menu",
+			call: func(f *fixture) error {
+				_, err := NewMenuService(f.repo, f.config, f.logger).CreateRestaurantFood(as(f.rival, enums.Owner), f.restaurant, &model.Food{Name: "Manty", Type: "main", Price: 2500})
+				return err
+			},
+			want: errs.ErrNotRestaurantOwner,
+		},
+		{
+			name: "owner updates another restaurant's dish through own restaurant",
+			call: func(f *fixture) error {
+				_, err := NewMenuService(f.repo, f.config, f.logger).UpdateRestaurantFood(as(f.owner, enums.Owner), f.restaurant, &model.Food{ID: f.rivalFood, Name: "Stolen"})
+				return err
+			},
+			want: errs.ErrFoodNotFound,
+		},
+		{
+			name: "owner deletes another restaurant's dish through own restaurant",
+			call: func(f *fixture) error {
+				return NewMenuService(f.repo, f.config, f.logger).DeleteRestaurantFood(as(f.owner, enums.Owner), f.restaurant, f.rivalFood)
+			},
+			want: errs.ErrFoodNotFound,
+		},
+		{
+			name: "owner deletes own dish",
+			call: func(f *fixture) error {
+				return NewMenuService(f.repo, f.config, f.logger).DeleteRestaurantFood(as(f.owner, enums.Owner), f.restaurant, f.food)
+			},
+		},
+		{
+			name: "owner restores a dish",
+			call: func(f *fixture) error {
+				return NewMenuService(f.repo, f.config, f.logger).RestoreRestaurantFood(as(f.owner, enums.Owner), f.restaurant, f.food)
+			},
+			want: errs.ErrPermissionDenied,
+		},
+		{
+			name: "owner adds a table to another owner's restaurant",
+			call: func(f *fixture) error {
+				_, err := NewTableService(f.repo, f.config, f.logger).CreateRestaurantTable(as(f.rival, enums.Owner), f.restaurant, &model.Table{Name: "VIP", Type: "vip", Capacity: 2})
+				return err
+			},
+			want: errs.ErrNotRestaurantOwner,
+		},
+		{
+			name: "owner updates own table",
+			call: func(f *fixture) error {
+				_, err := NewTableService(f.repo, f.config, f.logger).UpdateRestaurantTable(as(f.owner, enums.Owner), f.restaurant, &model.Table{ID: f.table, Capacity: 6})
+				return err
+			},
+		},
+		{
+			name: "owner updates another restaurant's table through own restaurant",
+			call: func(f *fixture) error {
+				_, err := NewTableService(f.repo, f.config, f.logger).UpdateRestaurantTable(as(f.owner, enums.Owner), f.restaurant, &model.Table{ID: f.rivalTable, Capacity: 6})
+				return err
+			},
+			want: errs.ErrTableNotFound,
+		},
+		{
+			name: "owner deletes another restaurant's table through own restaurant",
+			call: func(f *fixture) error {
+				return NewTableService(f.repo, f.config, f.logger).DeleteRestaurantTable(as(f.owner, enums.Owner), f.restaurant, f.rivalTable)
+			},
+			want: errs.ErrTableNotFound,
+		},
+		{
+			name: "author deletes review",
+			call: func(f *fixture) error {
+				return NewReviewsService(f.repo, f.config, f.logger).DeleteReview(as(f.client, enums.User), f.review)
+			},
+		},
+		{
+			name: "client deletes someone else's review",
+			call: func(f *fixture) error {
+				return NewReviewsService(f.repo, f.config, f.logger).DeleteReview(as(f.other, enums.User), f.review)
+			},
+			want: errs.ErrNotReviewAuthor,
+		},
+		{
+			name: "client updates own profile",
+			call: func(f *fixture) error {
+				_, err := NewUserService(f.repo, f.config, f.logger).Update(as(f.client, enums.User), &model.User{Name: "Aruzhan", Role: enums.User})
+				return err
+			},
+		},
+		{
+			name: "client updates another profile",
+			call: func(f *fixture) error {
+				_, err := NewUserService(f.repo, f.config, f.logger).Update(as(f.client, enums.User), &model.User{ID: f.other, Name: "Aruzhan", Role: enums.User})
+				return err
+			},
+			want: errs.ErrPermissionDenied,
+		},
+		{
+			name: "admin updates another profile",
+			call: func(f *fixture) error {
+				_, err := NewUserService(f.repo, f.config, f.logger).Update(as(f.admin, enums.Admin), &model.User{ID: f.other, Name: "Aruzhan", Role: enums.User})
+				return err
+			},
+		},
+		{
+			name: "admin restores a deleted client",
+			call: func(f *fixture) error {
+				if err := f.repo.User.Delete(context.Background(), f.client); err != nil {
+					return err
+				}
+				return NewUserService(f.repo, f.config, f.logger).Restore(as(f.admin, enums.Admin), f.client, enums.User)
+			},
+		},
+		{
+			name: "admin restores a deleted owner as a client",
+			call: func(f *fixture) error {
+				if err := f.repo.User.Delete(context.Background(), f.owner); err != nil {
+					return err
+				}
+				return NewUserService(f.repo, f.config, f.logger).Restore(as(f.admin, enums.Admin), f.owner, enums.User)
+			},
+			want: errs.ErrUserNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkError(t, tt.call(newFixture(t)), tt.want)
+		})
+	}
+}
+
+func TestOrderStatusRules(t *testing.T) {
+	tests := []struct {
+		name   string
+		status string
+		ctx    func(f *fixture) context.Context
+		call   func(ctx context.Context, s *OrderService, f *fixture) error
+		want   error
+	}{
+		{
+			name:   "client cancels own reservation",
+			status: enums.Reserved,
+			ctx:    func(f *fixture) context.Context { return as(f.client, enums.User) },
+			call:   update(enums.Canceled),
+		},
+		{
+			name:   "client updates canceled order",
+			status: enums.Canceled,
+			ctx:    func(f *fixture) context.Context { return as(f.client, enums.User) },
+			call:   update(enums.Reserved),
+			want:   errs.ErrOrderCanceled,
+		},
+		{
+			name:   "client updates completed order",
+			status: enums.Completed,
+			ctx:    func(f *fixture) context.Context { return as(f.client, enums.User) },
+			call:   update(enums.Canceled),
+			want:   errs.ErrOrderCompleted,
+		},
+		{
+			name:   "client updates someone else's order",
+			status: enums.Reserved,
+			ctx:    func(f *fixture) context.Context { return as(f.other, enums.User) },
+			call:   update(enums.Canceled),
+			want:   errs.ErrPermissionDenied,
+		},
+		{
+			name:   "admin updates completed order",
+			status: enums.Completed,
+			ctx:    func(f *fixture) context.Context { return as(f.admin, enums.Admin) },
+			call:   update(enums.Canceled),
+			want:   errs.ErrOrderCompleted,
+		},
+		{
+			name:   "restaurant owner reopens completed order",
+			status: enums.Completed,
+			ctx:    func(f *fixture) context.Context { return as(f.owner, enums.Owner) },
+			call:   update(enums.Reserved),
+		},
+		{
+			name:   "owner updates another restaurant's order",
+			status: enums.Reserved,
+			ctx:    func(f *fixture) context.Context { return as(f.rival, enums.Owner) },
+			call:   update(enums.Canceled),
+			want:   errs.ErrNotRestaurantOwner,
+		},
+		{
+			name:   "client completes own order",
+			status: enums.Reserved,
+			ctx:    func(f *fixture) context.Context { return as(f.client, enums.User) },
+			call:   update(enums.Completed),
+			want:   errs.ErrPermissionDenied,
+		},
+		{
+			name:   "client patches own order to completed",
+			status: enums.Reserved,
+			ctx:    func(f *fixture) context.Context { return as(f.client, enums.User) },
+			call: func(ctx context.Context, s *OrderService, f *fixture) error {
+				_, err := s.Patch(ctx, f.order, 0, patch.Patch{"status": []byte(`"completed"`)})
+				return err
+			},
+			want: errs.ErrPermissionDenied,
+		},
+		{
+			name:   "client rewrites fields of own order",
+			status: enums.Reserved,
+			ctx:    func(f *fixture) context.Context { return as(f.client, enums.User) },
+			call: func(ctx context.Context, s *OrderService, f *fixture) error {
+				before, err := s.GetByID(ctx, f.order)
+				if err != nil {
+					return err
+				}
+				updated, err := s.Update(ctx, f.order, &model.Order{ID: f.order, UserID: f.other, RestaurantID: f.rivalRestaurant, TotalSum: 1, Status: enums.Reserved})
+				if err != nil {
+					return err
+				}
+				if updated.UserID != f.client || updated.RestaurantID != f.restaurant || updated.TotalSum != before.TotalSum {
+					return fmt.Errorf("order = %+v, want user, restaurant and total unchanged", updated)
+				}
+				return nil
+			},
+		},
+		{
+			name:   "client moves own order to another restaurant's table",
+			status: enums.Reserved,
+			ctx:    func(f *fixture) context.Context { return as(f.client, enums.User) },
+			call: func(ctx context.Context, s *OrderService, f *fixture) error {
+				_, err := s.Update(ctx, f.order, &model.Order{ID: f.order, TableID: f.rivalTable})
+				return err
+			},
+			want: errs.ErrTableNotFound,
+		},
+		{
+			name:   "client reads someone else's order",
+			status: enums.Reserved,
+			ctx:    func(f *fixture) context.Context { return as(f.other, enums.User) },
+			call: func(ctx context.Context, s *OrderService, f *fixture) error {
+				_, err := s.GetByID(ctx, f.order)
+				return err
+			},
+			want: errs.ErrPermissionDenied,
+		},
+		{
+			name:   "client deletes someone else's order",
+			status: enums.Reserved,
+			ctx:    func(f *fixture) context.Context { return as(f.other, enums.User) },
+			call: func(ctx context.Context, s *OrderService, f *fixture) error {
+				return s.Delete(ctx, f.order)
+			},
+			want: errs.ErrPermissionDenied,
+		},
+		{
+			name:   "client deletes own order",
+			status: enums.Reserved,
+			ctx:    func(f *fixture) context.Context { return as(f.client, enums.User) },
+			call: func(ctx context.Context, s *OrderService, f *fixture) error {
+				if err := s.Delete(ctx, f.order); err != nil {
+					return err
+				}
+				_, err := s.GetByID(ctx, f.order)
+				return err
+			},
+			want: errs.ErrOrderNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture(t)
+			f.setOrderStatus(t, tt.status)
+
+			err := tt.call(tt.ctx(f), NewOrderService(f.repo, f.config, f.logger), f)
+			checkError(t, err, tt.want)
+		})
+	}
+}
+
+func update(status string) func(ctx context.Context, s *OrderService, f *fixture) error {
+	return func(ctx context.Context, s *OrderService, f *fixture) error {
+		updated, err := s.Update(ctx, f.order, &model.Order{ID: f.order, Status: status})
+		if err != nil {
+			return err
+		}
+		if updated.Status != status {
+			return errors.New("status was not updated")
+		}
+		return nil
+	}
+}
+
+func TestAuthFlows(t *testing.T) {
+	register := model.Register{Name: "Dana", Surname: "Sarsen", Email: "dana@orynal.kz", Phone: "+77011234567", Password: "password123"}
+
+	tests := []struct {
+		name string
+		run  func(ctx context.Context, s *AuthService, f *fixture) error
+		want error
+	}{
+		{
+			name: "register and login",
+			run: func(ctx context.Context, s *AuthService, f *fixture) error {
+				tokens, err := s.Login(ctx, model.Login{Email: register.Email, Password: register.Password})
+				if err != nil {
+					return err
+				}
+				if tokens.AccessToken == "" || tokens.RefreshToken == "" {
+					return errors.New("empty tokens")
+				}
+				return nil
+			},
+		},
+		{
+			name: "register with a taken email",
+			run: func(ctx context.Context, s *AuthService, f *fixture) error {
+				_, err := s.Register(ctx, register)
+				return err
+			},
+			want: errs.ErrAlreadyExists,
+		},
+		{
+			name: "login with a wrong password",
+			run: func(ctx context.Context, s *AuthService, f *fixture) error {
+				_, err := s.Login(ctx, model.Login{Email: register.Email, Password: "wrong-password"})
+				return err
+			},
+			want: errs.ErrInvalidCredentials,
+		},
+		{
+			name: "login with an unknown email",
+			run: func(ctx context.Context, s *AuthService, f *fixture) error {
+				_, err := s.Login(ctx, model.Login{Email: "nobody@orynal.kz", Password: register.Password})
+				return err
+			},
+			want: errs.ErrInvalidCredentials,
+		},
+		{
+			name: "refresh twice",
+			run: func(ctx context.Context, s *AuthService, f *fixture) error {
+				tokens, err := s.Login(ctx, model.Login{Email: register.Email, Password: register.Password})
+				if err != nil {
+					return err
+				}
+				for i := 0; i < 2; i++ {
+					if tokens, err = s.RefreshToken(ctx, tokens.RefreshToken); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+		{
+			name: "refresh with a malformed token",
+			run: func(ctx context.Context, s *AuthService, f *fixture) error {
+				_, err := s.RefreshToken(ctx, "not-a-jwt")
+				return err
+			},
+			want: errs.ErrInvalidToken,
+		},
+		{
+			name: "login cancels a pending deletion",
+			run: func(ctx context.Context, s *AuthService, f *fixture) error {
+				user, err := f.repo.User.GetByEmail(ctx, register.Email)
+				if err != nil {
+					return err
+				}
+				if err := f.repo.User.RequestDeletion(ctx, user.ID, time.Now()); err != nil {
+					return err
+				}
+				if _, err := s.Login(ctx, model.Login{Email: register.Email, Password: register.Password}); err != nil {
+					return err
+				}
+				if user, err = f.repo.User.GetByEmail(ctx, register.Email); err != nil {
+					return err
+				}
+				if user.DeletionRequestedAt != nil {
+					return errors.New("deletion is still pending")
+				}
+				return nil
+			},
+		},
+		{
+			name: "secrets and contacts stay out of the logs",
+			run: func(ctx context.Context, s *AuthService, f *fixture) error {
+				core, logs := observer.New(zap.DebugLevel)
+				s = NewAuthService(f.repo, f.config, zap.New(core).Sugar())
+
+				secret := model.Register{Name: "Aigerim", Surname: "Sarsen", Email: "aigerim@orynal.kz", Phone: "+77017654321", Password: "secret-password"}
+				if _, err := s.Register(ctx, secret); err != nil {
+					return err
+				}
+				login, err := s.Login(ctx, model.Login{Email: secret.Email, Password: secret.Password})
+				if err != nil {
+					return err
+				}
+				refreshed, err := s.RefreshToken(ctx, login.RefreshToken)
+				if err != nil {
+					return err
+				}
+
+				for _, entry := range logs.All() {
+					line := fmt.Sprint(entry.Message, entry.ContextMap())
+					for _, value := range []string{secret.Password, secret.Email, secret.Phone, login.AccessToken, login.RefreshToken, refreshed.AccessToken, refreshed.RefreshToken} {
+						if strings.Contains(line, value) {
+							return fmt.Errorf("log %q contains a secret", line)
+						}
+					}
+				}
+				return nil
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture(t)
+			s := NewAuthService(f.repo, f.config, f.logger)
+			if _, err := s.Register(context.Background(), register); err != nil {
+				t.Fatal(err)
+			}
+
+			checkError(t, tt.run(context.Background(), s, f), tt.want)
+		})
+	}
+}
+
+func TestMergePatch(t *testing.T) {
+	merge := func(doc string) patch.Patch {
+		p, err := patch.Decode(strings.NewReader(doc))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+
+	tests := []struct {
+		name string
+		ctx  func(f *fixture) context.Context
+		call func(ctx context.Context, f *fixture) error
+		want error
+	}{
+		{
+			name: "owner closes restaurant and clears description",
+			ctx:  func(f *fixture) context.Context { return as(f.owner, enums.Owner) },
+			call: func(ctx context.Context, f *fixture) error {
+				s := NewRestaurantService(f.repo, f.geocoder, f.config, f.logger)
+				if _, err := s.PatchRestaurant(ctx, f.restaurant, 0, merge(`{"status": true, "description": "Open"}`)); err != nil {
+					return err
+				}
+				restaurant, err := s.PatchRestaurant(ctx, f.restaurant, 2, merge(`{"status": false, "description": null}`))
+				if err != nil {
+					return err
+				}
+				if restaurant.Status || restaurant.Description != "" || restaurant.Version != 3 {
+					return fmt.Errorf("restaurant = %+v, want closed, no description, version 3", restaurant)
+				}
+				return nil
+			},
+		},
+		{
+			name: "owner hands restaurant over",
+			ctx:  func(f *fixture) context.Context { return as(f.owner, enums.Owner) },
+			call: func(ctx context.Context, f *fixture) error {
+				_, err := NewRestaurantService(f.repo, f.geocoder, f.config, f.logger).PatchRestaurant(ctx, f.restaurant, 0, merge(fmt.Sprintf(`{"ownerId": %d}`, f.rival)))
+				return err
+			},
+			want: errs.ErrFieldNotPatchable,
+		},
+		{
+			name: "admin hands restaurant to a client",
+			ctx:  func(f *fixture) context.Context { return as(f.admin, enums.Admin) },
+			call: func(ctx context.Context, f *fixture) error {
+				_, err := NewRestaurantService(f.repo, f.geocoder, f.config, f.logger).PatchRestaurant(ctx, f.restaurant, 0, merge(fmt.Sprintf(`{"ownerId": %d}`, f.client)))
+				return err
+			},
+			want: errs.ErrInvalidOwner,
+		},
+		{
+			name: "owner marks dish unavailable",
+			ctx:  func(f *fixture) context.Context { return as(f.owner, enums.Owner) },
+			call: func(ctx context.Context, f *fixture) error {
+				s := NewMenuService(f.repo, f.config, f.logger)
+				if _, err := s.PatchRestaurantFood(ctx, f.restaurant, f.food, 1, merge(`{"available": true}`)); err != nil {
+					return err
+				}
+				if _, err := s.PatchRestaurantFood(ctx, f.restaurant, f.food, 2, merge(`{"available": false}`)); err != nil {
+					return err
+				}
+				food, err := f.repo.Food.GetRestaurantFood(ctx, f.restaurant, f.food)
+				if err != nil {
+					return err
+				}
+				if food.Available || food.Price != 3500 {
+					return fmt.Errorf("food = %+v, want unavailable at the same price", food)
+				}
+				return nil
+			},
+		},
+		{
+			name: "owner sets dish price to zero",
+			ctx:  func(f *fixture) context.Context { return as(f.owner, enums.Owner) },
+			call: func(ctx context.Context, f *fixture) error {
+				_, err := NewMenuService(f.repo, f.config, f.logger).PatchRestaurantFood(ctx, f.restaurant, f.food, 0, merge(`{"price": 0}`))
+				var validationErr *validator.ValidationError
+				if errors.As(err, &validationErr) {
+					return errs.ErrValidation
+				}
+				return err
+			},
+			want: errs.ErrValidation,
+		},
+		{
+			name: "owner patches a stale table",
+			ctx:  func(f *fixture) context.Context { return as(f.owner, enums.Owner) },
+			call: func(ctx context.Context, f *fixture) error {
+				_, err := NewTableService(f.repo, f.config, f.logger).PatchRestaurantTable(ctx, f.restaurant, f.table, 5, merge(`{"capacity": 6}`))
+				return err
+			},
+			want: errs.ErrVersionMismatch,
+		},
+		{
+			name: "owner patches another restaurant's table",
+			ctx:  func(f *fixture) context.Context { return as(f.owner, enums.Owner) },
+			call: func(ctx context.Context, f *fixture) error {
+				_, err := NewTableService(f.repo, f.config, f.logger).PatchRestaurantTable(ctx, f.rivalRestaurant, f.rivalTable, 0, merge(`{"capacity": 6}`))
+				return err
+			},
+			want: errs.ErrNotRestaurantOwner,
+		},
+		{
+			name: "client moves order to another restaurant's table",
+			ctx:  func(f *fixture) context.Context { return as(f.client, enums.User) },
+			call: func(ctx context.Context, f *fixture) error {
+				_, err := NewOrderService(f.repo, f.config, f.logger).Patch(ctx, f.order, 0, merge(fmt.Sprintf(`{"tableId": %d}`, f.rivalTable)))
+				return err
+			},
+			want: errs.ErrTableNotFound,
+		},
+		{
+			name: "client changes order total",
+			ctx:  func(f *fixture) context.Context { return as(f.client, enums.User) },
+			call: func(ctx context.Context, f *fixture) error {
+				_, err := NewOrderService(f.repo, f.config, f.logger).Patch(ctx, f.order, 0, merge(`{"totalSum": 0}`))
+				return err
+			},
+			want: errs.ErrFieldNotPatchable,
+		},
+		{
+			name: "owner completes order",
+			ctx:  func(f *fixture) context.Context { return as(f.owner, enums.Owner) },
+			call: func(ctx context.Context, f *fixture) error {
+				order, err := NewOrderService(f.repo, f.config, f.logger).Patch(ctx, f.order, 1, merge(`{"status": "completed"}`))
+				if err != nil {
+					return err
+				}
+				if order.Status != enums.Completed || order.TableID != f.table {
+					return fmt.Errorf("order = %+v, want completed at the same table", order)
+				}
+				return nil
+			},
+		},
+		{
+			name: "client renames self",
+			ctx:  func(f *fixture) context.Context { return as(f.client, enums.User) },
+			call: func(ctx context.Context, f *fixture) error {
+				user, err := NewUserService(f.repo, f.config, f.logger).Patch(ctx, merge(`{"name": "Dana"}`))
+				if err != nil {
+					return err
+				}
+				if user.Name != "Dana" || user.Email != "client@orynal.kz" {
+					return fmt.Errorf("user = %+v, want renamed", user)
+				}
+				return nil
+			},
+		},
+		{
+			name: "client promotes self",
+			ctx:  func(f *fixture) context.Context { return as(f.client, enums.User) },
+			call: func(ctx context.Context, f *fixture) error {
+				_, err := NewUserService(f.repo, f.config, f.logger).Patch(ctx, merge(`{"role": "admin"}`))
+				return err
+			},
+			want: errs.ErrFieldNotPatchable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture(t)
+
+			err := tt.call(tt.ctx(f), f)
+			checkError(t, err, tt.want)
+		})
+	}
+}
+
+func TestCursorPagination(t *testing.T) {
+	f := newFixture(t)
+
+	tests := []struct {
+		name       string
+		params     *model.Params
+		want       []uint
+		next, prev bool
+	}{
+		{name: "first page", params: &model.Params{Limit: 1}, want: []uint{f.restaurant}, next: true},
+		{name: "after", params: &model.Params{Limit: 1, Cursor: &model.Cursor{ID: f.restaurant}}, want: []uint{f.rivalRestaurant}, prev: true},
+		{name: "before", params: &model.Params{Limit: 1, Cursor: &model.Cursor{ID: f.rivalRestaurant, Before: true}}, want: []uint{f.restaurant}, next: true},
+		{name: "after the last", params: &model.Params{Limit: 1, Cursor: &model.Cursor{ID: f.rivalRestaurant}}},
+		{name: "offset past the end", params: &model.Params{Limit: 1, Offset: 5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list, err := f.repo.Restaurant.GetRestaurants(context.Background(), tt.params)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []uint
+			for _, restaurant := range list.Items.([]model.Restaurant) {
+				got = append(got, restaurant.ID)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("got restaurants %v, want %v", got, tt.want)
+			}
+			if (list.NextCursor != "") != tt.next || (list.PrevCursor != "") != tt.prev {
+				t.Errorf("got cursors next %q prev %q, want next %t prev %t", list.NextCursor, list.PrevCursor, tt.next, tt.prev)
+			}
+			if tt.next {
+				if id, err := model.DecodeCursor(list.NextCursor); err != nil || id != got[len(got)-1] {
+					t.Errorf("next cursor decodes to %d, %v, want %d", id, err, got[len(got)-1])
+				}
+			}
+		})
+	}
+}
+
+func TestListFilters(t *testing.T) {
+	f := newFixture(t)
+
+	tests := []struct {
+		name   string
+		params *model.Params
+		want   []uint
+	}{
+		{name: "eq", params: &model.Params{Filters: []model.Filter{{Column: "owner_id", Op: model.OpEq, Value: int64(f.rival)}}}, want: []uint{f.rivalRestaurant}},
+		{name: "in", params: &model.Params{Filters: []model.Filter{{Column: "id", Op: model.OpIn, Value: []any{int64(f.restaurant), int64(99)}}}}, want: []uint{f.restaurant}},
+		{name: "gte", params: &model.Params{Filters: []model.Filter{{Column: "id", Op: model.OpGte, Value: int64(f.rivalRestaurant)}}}, want: []uint{f.rivalRestaurant}},
+		{name: "like", params: &model.Params{Filters: []model.Filter{{Column: "name", Op: model.OpLike, Value: "STAUR"}}}, want: []uint{f.restaurant, f.rivalRestaurant}},
+		{name: "sort", params: &model.Params{Sort: []model.Sort{{Column: "name"}, {Column: "owner_id", Desc: true}}}, want: []uint{f.rivalRestaurant, f.restaurant}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.params.Limit = 10
+			list, err := f.repo.Restaurant.GetRestaurants(context.Background(), tt.params)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []uint
+			for _, restaurant := range list.Items.([]model.Restaurant) {
+				got = append(got, restaurant.ID)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) || list.TotalItems != len(tt.want) {
+				t.Errorf("got restaurants %v of %d, want %v", got, list.TotalItems, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchDishes(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	s := NewSearchService(f.repo, f.config, f.logger)
+
+	locate := func(id uint, lat, lng float64) {
+		if _, err := f.repo.Restaurant.UpdateRestaurant(ctx, id, &model.Restaurant{Latitude: &lat, Longitude: &lng}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	locate(f.restaurant, 43.2380, 76.9450)
+	locate(f.rivalRestaurant, 51.1605, 71.4704)
+
+	dish := func(restaurantID uint, food model.Food) uint {
+		food.Type, food.Available, food.RestaurantID = "main", true, restaurantID
+		created, err := f.repo.Food.CreateRestaurantFood(ctx, &food)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return created.ID
+	}
+	beshbarmak := dish(f.restaurant, model.Food{Name: "Beshbarmak", Price: 4500, Halal: true})
+	lagman := dish(f.restaurant, model.Food{Name: "Lagman", Description: "Hand-pulled noodles", Price: 3000, Halal: true})
+	salad := dish(f.rivalRestaurant, model.Food{Name: "Salad", Description: "Noodles and greens", Price: 2000, Vegan: true})
+
+	price := func(p float64) *float64 { return &p }
+	tests := []struct {
+		name   string
+		search model.DishSearch
+		want   string
+	}{
+		{name: "all", search: model.DishSearch{}, want: fmt.Sprintf("%d:[%d %d] %d:[%d]", f.restaurant, beshbarmak, lagman, f.rivalRestaurant, salad)},
+		{name: "query", search: model.DishSearch{Params: model.Params{Query: "NOODLES"}}, want: fmt.Sprintf("%d:[%d] %d:[%d]", f.restaurant, lagman, f.rivalRestaurant, salad)},
+		{name: "near", search: model.DishSearch{Params: model.Params{Query: "noodles", Near: &model.Point{Lat: 51.128, Lng: 71.430}}}, want: fmt.Sprintf("%d:[%d] %d:[%d]", f.rivalRestaurant, salad, f.restaurant, lagman)},
+		{name: "price", search: model.DishSearch{MinPrice: price(2500), MaxPrice: price(4000)}, want: fmt.Sprintf("%d:[%d]", f.restaurant, lagman)},
+		{name: "diets", search: model.DishSearch{Diets: []string{"vegan"}}, want: fmt.Sprintf("%d:[%d]", f.rivalRestaurant, salad)},
+		{name: "none", search: model.DishSearch{Diets: []string{"vegan", "halal"}}, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.search.Limit = 10
+			list, err := s.SearchDishes(ctx, &tt.search)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			for _, group := range list.Items.([]model.DishGroup) {
+				var ids []uint
+				for _, food := range group.Dishes {
+					ids = append(ids, food.ID)
+				}
+				got = append(got, fmt.Sprintf("%d:%v", group.Restaurant.ID, ids))
+				if (tt.search.Near != nil) != (group.Restaurant.DistanceM != nil) {
+					t.Errorf("restaurant %d: distance %v with near %v", group.Restaurant.ID, group.Restaurant.DistanceM, tt.search.Near)
+				}
+			}
+			if strings.Join(got, " ") != tt.want {
+				t.Errorf("got groups %v, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpenAt(t *testing.T) {
+	clock := func(hour, minute int) time.Time { return time.Date(2000, 1, 1, hour, minute, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name     string
+		from, to time.Time
+		status   bool
+		at       time.Time
+		want     bool
+	}{
+		{name: "open", from: clock(10, 0), to: clock(22, 0), status: true, at: clock(21, 59), want: true},
+		{name: "closing time", from: clock(10, 0), to: clock(22, 0), status: true, at: clock(22, 0), want: false},
+		{name: "past midnight", from: clock(18, 0), to: clock(2, 0), status: true, at: clock(1, 30), want: true},
+		{name: "before opening past midnight", from: clock(18, 0), to: clock(2, 0), status: true, at: clock(12, 0), want: false},
+		{name: "around the clock", from: clock(0, 0), to: clock(0, 0), status: true, at: clock(4, 0), want: true},
+		{name: "closed", from: clock(10, 0), to: clock(22, 0), status: false, at: clock(12, 0), want: false},
+		{name: "local clock", from: clock(10, 0), to: clock(22, 0), status: true, at: clock(6, 0).In(time.FixedZone("+05", 5*60*60)), want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			summary := model.RestaurantSummary{Status: tt.status, ModeFrom: tt.from, ModeTo: tt.to}
+			if got := summary.OpenAt(tt.at); got != tt.want {
+				t.Errorf("OpenAt(%s) = %t, want %t", tt.at.Format("15:04"), got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchDishesOpenNow(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+
+	// Opening hours are wall clock times in the business time zone, an hour
+	// either side of now there; five hours behind, in UTC, they are closed.
+	almaty, err := time.LoadLocation("Asia/Almaty")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().In(almaty)
+	wall := func(t time.Time) time.Time { return time.Date(0, 1, 1, t.Hour(), t.Minute(), 0, 0, time.UTC) }
+	if _, err := f.repo.Restaurant.UpdateRestaurant(ctx, f.restaurant, &model.Restaurant{
+		Status: true, ModeFrom: wall(now.Add(-time.Hour)), ModeTo: wall(now.Add(time.Hour)),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.repo.Food.CreateRestaurantFood(ctx, &model.Food{Name: "Beshbarmak", Type: "main", Available: true, RestaurantID: f.restaurant}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		zone string
+		want bool
+	}{
+		{zone: "Asia/Almaty", want: true},
+		{zone: "", want: false},
+	} {
+		f.config.Business.TimeZone = tt.zone
+		list, err := NewSearchService(f.repo, f.config, f.logger).SearchDishes(ctx, &model.DishSearch{Params: model.Params{Limit: 10}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		groups := list.Items.([]model.DishGroup)
+		if len(groups) != 1 || groups[0].Restaurant.OpenNow != tt.want {
+			t.Errorf("zone %q: groups %+v, want open now %t", tt.zone, groups, tt.want)
+		}
+	}
+}
+
+// outsideTx is a geocoder that fails the test when it is called inside a
+// transaction, which it notices by waiting to start one of its own.
+type outsideTx struct {
+	geocode.Geocoder
+	t    *testing.T
+	repo *repository.Manager
+}
+
+func (g outsideTx) Geocode(ctx context.Context, address, city string) (geocode.Location, error) {
+	done := make(chan error, 1)
+	go func() {
+		done <- g.repo.WithTx(ctx, func(*repository.Manager) error { return nil })
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		g.t.Errorf("geocoded %s, %s inside a transaction", address, city)
+	}
+	return g.Geocoder.Geocode(ctx, address, city)
+}
+
+func TestGeocodeRestaurants(t *testing.T) {
+	f := newFixture(t)
+	s := NewRestaurantService(f.repo, outsideTx{Geocoder: f.geocoder, t: t, repo: f.repo}, f.config, f.logger)
+	admin := as(f.admin, enums.Admin)
+
+	near := func(restaurant *model.Restaurant, city string) bool {
+		location, err := f.geocoder.Geocode(context.Background(), "", city)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return restaurant.Location() != nil && model.Distance(*restaurant.Location(), model.Point(location)) < 5000
+	}
+
+	created, err := s.CreateRestaurant(admin, &model.Restaurant{Name: "Steppe", Address: "Abay Ave, 1", City: "Astana", OwnerID: f.owner})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !near(created, "Astana") {
+		t.Errorf("created restaurant at %v, want Astana", created.Location())
+	}
+
+	moved, err := s.PatchRestaurant(admin, created.ID, 0, patch.Patch{"city": []byte(`"Алматы"`)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !near(moved, "Almaty") {
+		t.Errorf("moved restaurant at %v, want Almaty", moved.Location())
+	}
+
+	pinned, err := s.PatchRestaurant(admin, created.ID, 0, patch.Patch{"city": []byte(`"Astana"`), "latitude": []byte("10"), "longitude": []byte("20")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if location := pinned.Location(); location == nil || *location != (model.Point{Lat: 10, Lng: 20}) {
+		t.Errorf("pinned restaurant at %v, want the given coordinates", location)
+	}
+
+	unknown, err := s.CreateRestaurant(admin, &model.Restaurant{Name: "Nowhere", City: "Atlantis", OwnerID: f.owner})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if unknown.Location() != nil {
+		t.Errorf("restaurant in an unknown city at %v, want no coordinates", unknown.Location())
+	}
+
+	list, err := s.GetRestaurants(context.Background(), &model.Params{Limit: 10, Near: &model.Point{Lat: 10.001, Lng: 20}, Radius: 1000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	restaurants := list.Items.([]model.Restaurant)
+	if len(restaurants) != 1 || restaurants[0].ID != created.ID || restaurants[0].DistanceM == nil || math.Abs(*restaurants[0].DistanceM-111) > 1 {
+		t.Errorf("restaurants within 1 km = %+v, want the pinned one 111 m away", restaurants)
+	}
+
+	// A PUT of a fetched restaurant follows the same rule as PATCH.
+	put := func(s *RestaurantService, edit func(*model.Restaurant)) *model.Restaurant {
+		t.Helper()
+		restaurant, err := s.GetRestaurantByID(context.Background(), created.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		edit(restaurant)
+		updated, err := s.UpdateRestaurant(admin, restaurant, created.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return updated
+	}
+	if kept := put(s, func(r *model.Restaurant) { r.Latitude, r.Longitude = nil, nil }); kept.Location() == nil || *kept.Location() != (model.Point{Lat: 10, Lng: 20}) {
+		t.Errorf("restaurant put without coordinates at %v, want the stored ones", kept.Location())
+	}
+	if moved := put(s, func(r *model.Restaurant) { r.City = "Алматы" }); !near(moved, "Almaty") {
+		t.Errorf("restaurant put in Almaty at %v, want Almaty", moved.Location())
+	}
+	unplaced := NewRestaurantService(f.repo, nil, f.config, f.logger)
+	if moved := put(unplaced, func(r *model.Restaurant) { r.City = "Astana" }); moved.Location() != nil {
+		t.Errorf("restaurant put in Astana without a geocoder at %v, want no coordinates", moved.Location())
+	}
+}
+
+func TestRetentionKeepsOrders(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+
+	for _, id := range []uint{f.restaurant, f.rivalRestaurant} {
+		if err := f.repo.Restaurant.DeleteRestaurant(ctx, id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, id := range []uint{f.food, f.rivalFood} {
+		if err := f.repo.Food.DeleteRestaurantFood(ctx, id); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	f.config.Retention.Period = time.Millisecond
+	time.Sleep(2 * time.Millisecond)
+	s := NewRetentionService(f.repo, NewExportService(f.repo, f.config, f.logger), f.config, f.logger)
+	if err := s.PurgeDeleted(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	order, err := f.repo.Order.GetOrder(ctx, f.order)
+	if err != nil {
+		t.Fatalf("order after purge: %v", err)
+	}
+	if order.Restaurant.ID != f.restaurant || len(order.Foods) != 1 || order.Foods[0].ID != f.food {
+		t.Errorf("order = %+v, want its restaurant and dish", order)
+	}
+	if err := f.repo.Restaurant.RestoreRestaurant(ctx, f.restaurant); err != nil {
+		t.Errorf("restore ordered restaurant: %v", err)
+	}
+	if err := f.repo.Restaurant.RestoreRestaurant(ctx, f.rivalRestaurant); !errors.Is(err, errs.ErrRestaurantNotFound) {
+		t.Errorf("restore purged restaurant: %v, want %v", err, errs.ErrRestaurantNotFound)
+	}
+}
+
+func TestExportQueue(t *testing.T) {
+	f := newFixture(t)
+	f.config.Export = config.Export{Dir: t.TempDir(), LinkTTL: time.Hour, SigningKey: "export-secret", Workers: 1, Queue: 1}
+	s := NewExportService(f.repo, f.config, f.logger)
+
+	// No worker runs yet, so the export stays pending and fills the queue.
+	export, err := s.RequestExport(as(f.client, enums.User))
+	checkError(t, err, nil)
+	_, err = s.RequestExport(as(f.client, enums.User))
+	checkError(t, err, errs.ErrExportPending)
+	_, err = s.RequestExport(as(f.other, enums.User))
+	checkError(t, err, errs.ErrExportBusy)
+
+	// Shutdown drains the queue, then refuses new exports.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s.Run(ctx)
+	got, err := s.GetExport(as(f.client, enums.User), export.ID)
+	checkError(t, err, nil)
+	if got.Status != enums.ExportReady || got.DownloadURL == "" {
+		t.Fatalf("export after shutdown = %+v, want ready with a link", got)
+	}
+	_, err = s.RequestExport(as(f.other, enums.User))
+	checkError(t, err, errs.ErrExportBusy)
+
+	// Links are signed with the export key, not the JWT secret.
+	expires := got.ExpiresAt.Unix()
+	if _, err := s.GetExportFile(context.Background(), got.ID, expires, s.sign(got.ID, f.client, expires)); err != nil {
+		t.Errorf("download with the export signature: %v", err)
+	}
+	f.config.Export.SigningKey = f.config.Auth.JwtSecretKey
+	forged := s.sign(got.ID, f.client, expires)
+	f.config.Export.SigningKey = "export-secret"
+	_, err = s.GetExportFile(context.Background(), got.ID, expires, forged)
+	checkError(t, err, errs.ErrExportLinkExpired)
+}
+
+func TestRetentionKeepsDisabledUsers(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+
+	if err := f.repo.User.Disable(ctx, f.other); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.repo.User.Delete(ctx, f.client); err != nil {
+		t.Fatal(err)
+	}
+
+	f.config.Retention = config.Retention{Period: time.Millisecond, CoolingOff: time.Millisecond}
+	time.Sleep(2 * time.Millisecond)
+	s := NewRetentionService(f.repo, NewExportService(f.repo, f.config, f.logger), f.config, f.logger)
+	if err := s.PurgeDeleted(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := f.repo.User.Restore(ctx, f.other, ""); err != nil {
+		t.Fatalf("enable disabled user: %v", err)
+	}
+	if user, err := f.repo.User.GetByID(ctx, f.other); err != nil || user.Email != "other@orynal.kz" {
+		t.Errorf("enabled user = %+v (%v), want the account as it was", user, err)
+	}
+	if err := f.repo.User.Restore(ctx, f.client, ""); !errors.Is(err, errs.ErrUserNotFound) {
+		t.Errorf("restore anonymized user: %v, want %v", err, errs.ErrUserNotFound)
+	}
+}
+
+func TestRetentionSteps(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+
+	if err := f.repo.Restaurant.DeleteRestaurant(ctx, f.rivalRestaurant); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.repo.User.RequestDeletion(ctx, f.other, time.Now().Add(-time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Purging is off, deletion requests are still honored.
+	f.config.Retention = config.Retention{Period: 0, CoolingOff: time.Second}
+	s := NewRetentionService(f.repo, NewExportService(f.repo, f.config, f.logger), f.config, f.logger)
+	if err := s.PurgeDeleted(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := f.repo.Restaurant.RestoreRestaurant(ctx, f.rivalRestaurant); err != nil {
+		t.Errorf("restore restaurant with purging off: %v", err)
+	}
+	if _, err := f.repo.User.GetByEmail(ctx, "other@orynal.kz"); !errors.Is(err, errs.ErrUserNotFound) {
+		t.Errorf("user who requested deletion: %v, want anonymized", err)
+	}
+}
@@ -7,6 +7,10 @@ import (
 	"github.com/alibekabdrakhman1/orynal/internal/model"
 	"github.com/alibekabdrakhman1/orynal/internal/repository"
 	"github.com/alibekabdrakhman1/orynal/internal/service/infrastructure"
+	"github.com/alibekabdrakhman1/orynal/pkg/errs"
+	"github.com/alibekabdrakhman1/orynal/pkg/logging"
+	"github.com/alibekabdrakhman1/orynal/pkg/patch"
+	"github.com/alibekabdrakhman1/orynal/pkg/tracing"
 	"github.com/alibekabdrakhman1/orynal/pkg/utils"
 	"go.uber.org/zap"
 	"time"
@@ -24,18 +28,30 @@ type TableService struct {
 }
 
 func (s *TableService) GetTableCategories(ctx context.Context, restaurantID uint) ([]string, error) {
+	ctx, span := tracing.Start(ctx, "TableService.GetTableCategories")
+	defer span.End()
+
 	return s.repository.Table.GetTableCategories(ctx, restaurantID)
 }
 
 func (s *TableService) GetRestaurantTables(ctx context.Context, restaurantID uint, params *model.Params) (*model.ListResponse, error) {
+	ctx, span := tracing.Start(ctx, "TableService.GetRestaurantTables")
+	defer span.End()
+
 	return s.repository.Table.GetRestaurantTables(ctx, restaurantID, params)
 }
 
 func (s *TableService) GetRestaurantTable(ctx context.Context, restaurantID uint, tableID uint) (*model.Table, error) {
+	ctx, span := tracing.Start(ctx, "TableService.GetRestaurantTable")
+	defer span.End()
+
 	return s.repository.Table.GetRestaurantTable(ctx, restaurantID, tableID)
 }
 
 func (s *TableService) CreateRestaurantTable(ctx context.Context, restaurantID uint, table *model.Table) (*model.Table, error) {
+	ctx, span := tracing.Start(ctx, "TableService.CreateRestaurantTable")
+	defer span.End()
+
 	err := s.checkOwner(ctx, restaurantID)
 	if err != nil {
 		return nil, err
@@ -47,26 +63,79 @@ func (s *TableService) CreateRestaurantTable(ctx context.Context, restaurantID u
 }
 
 func (s *TableService) UpdateRestaurantTable(ctx context.Context, restaurantID uint, table *model.Table) (*model.Table, error) {
+	ctx, span := tracing.Start(ctx, "TableService.UpdateRestaurantTable")
+	defer span.End()
+
 	err := s.checkOwner(ctx, restaurantID)
 	if err != nil {
 		return nil, err
 	}
 
+	if _, err := s.repository.Table.GetRestaurantTable(ctx, restaurantID, table.ID); err != nil {
+		return nil, err
+	}
+
 	table.RestaurantID = restaurantID
 
 	return s.repository.Table.UpdateTable(ctx, table)
 }
 
+// PatchRestaurantTable applies a merge patch to a table. version is taken
+// from If-Match; 0 patches any version.
+func (s *TableService) PatchRestaurantTable(ctx context.Context, restaurantID, tableID, version uint, p patch.Patch) (*model.Table, error) {
+	ctx, span := tracing.Start(ctx, "TableService.PatchRestaurantTable")
+	defer span.End()
+
+	if err := s.checkOwner(ctx, restaurantID); err != nil {
+		return nil, err
+	}
+
+	var patched *model.Table
+	err := s.repository.WithTx(ctx, func(txRepos *repository.Manager) error {
+		table, err := txRepos.Table.GetRestaurantTable(ctx, restaurantID, tableID)
+		if err != nil {
+			return err
+		}
+		if err := checkVersion(version, table.Version); err != nil {
+			return err
+		}
+
+		fields, err := applyPatch(ctx, tablePatchFields, p, table)
+		if err != nil || len(fields) == 0 {
+			patched = table
+			return err
+		}
+
+		patched, err = txRepos.Table.PatchTable(ctx, table, fields)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return patched, nil
+}
+
 func (s *TableService) DeleteRestaurantTable(ctx context.Context, restaurantID uint, tableID uint) error {
+	ctx, span := tracing.Start(ctx, "TableService.DeleteRestaurantTable")
+	defer span.End()
+
 	err := s.checkOwner(ctx, restaurantID)
 	if err != nil {
 		return err
 	}
 
+	if _, err := s.repository.Table.GetRestaurantTable(ctx, restaurantID, tableID); err != nil {
+		return err
+	}
+
 	return s.repository.Table.DeleteTable(ctx, tableID)
 }
 
 func (s *TableService) GetAvailableTime(ctx context.Context, restaurantID uint, tableID uint, date time.Time) ([]time.Time, error) {
+	ctx, span := tracing.Start(ctx, "TableService.GetAvailableTime")
+	defer span.End()
+
 	//TODO implement me
 	panic("implement me")
 }
@@ -74,19 +143,19 @@ func (s *TableService) GetAvailableTime(ctx context.Context, restaurantID uint,
 func (s *TableService) checkOwner(ctx context.Context, restaurantID uint) error {
 	restaurant, err := s.repository.Restaurant.GetRestaurantByID(ctx, restaurantID)
 	if err != nil {
-		s.logger.Error(fmt.Errorf("there is not restaurant by id: %v\n%w", restaurantID, err))
-		return fmt.Errorf("there is not restaurant by id: %v", restaurantID)
+		logging.FromContext(ctx, s.logger).Error(fmt.Errorf("there is not restaurant by id: %v\n%w", restaurantID, err))
+		return err
 	}
 
 	userID, err := utils.GetIDFromContext(ctx)
 	if err != nil {
-		s.logger.Error(err)
-		return fmt.Errorf("there is not user id from ctx")
+		logging.FromContext(ctx, s.logger).Error(err)
+		return errs.ErrUnauthorized
 	}
 
 	if restaurant.Owner.ID != userID {
-		s.logger.Error(fmt.Errorf("the user id is not owner of restaurant"))
-		return fmt.Errorf("permission denied")
+		logging.FromContext(ctx, s.logger).Error(fmt.Errorf("the user id is not owner of restaurant"))
+		return errs.ErrNotRestaurantOwner
 	}
 
 	return nil
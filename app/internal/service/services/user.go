@@ -2,15 +2,19 @@ package services
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"github.com/alibekabdrakhman1/orynal/config"
 	"github.com/alibekabdrakhman1/orynal/internal/model"
 	"github.com/alibekabdrakhman1/orynal/internal/repository"
 	"github.com/alibekabdrakhman1/orynal/internal/service/infrastructure"
 	"github.com/alibekabdrakhman1/orynal/pkg/enums"
+	"github.com/alibekabdrakhman1/orynal/pkg/errs"
+	"github.com/alibekabdrakhman1/orynal/pkg/logging"
+	"github.com/alibekabdrakhman1/orynal/pkg/patch"
+	"github.com/alibekabdrakhman1/orynal/pkg/tracing"
 	"github.com/alibekabdrakhman1/orynal/pkg/utils"
 	"go.uber.org/zap"
+	"time"
 )
 
 func NewUserService(repository *repository.Manager, config *config.Config, logger *zap.SugaredLogger) *UserService {
@@ -25,17 +29,20 @@ type UserService struct {
 }
 
 func (s *UserService) Create(ctx context.Context, user *model.User) (*model.UserResponse, error) {
+	ctx, span := tracing.Start(ctx, "UserService.Create")
+	defer span.End()
+
 	if user.Role == enums.Admin {
-		return nil, errors.New("permission denied")
+		return nil, errs.ErrPermissionDenied
 	}
 
 	role, err := utils.GetRoleFromContext(ctx)
 	if err == nil {
 		if role != enums.Admin {
-			return nil, errors.New("permission denied")
+			return nil, errs.ErrPermissionDenied
 		} else {
 			if user.Role != enums.Owner {
-				return nil, errors.New("admin can create only restaurant owners")
+				return nil, errs.ErrInvalidRole
 			}
 		}
 	}
@@ -44,9 +51,12 @@ func (s *UserService) Create(ctx context.Context, user *model.User) (*model.User
 }
 
 func (s *UserService) CreateOwner(ctx context.Context, user *model.User) (*model.UserResponse, error) {
+	ctx, span := tracing.Start(ctx, "UserService.CreateOwner")
+	defer span.End()
+
 	pass, err := utils.HashPassword(user.Password)
 	if err != nil {
-		s.logger.Error(err)
+		logging.FromContext(ctx, s.logger).Error(err)
 		return nil, err
 	}
 
@@ -55,10 +65,10 @@ func (s *UserService) CreateOwner(ctx context.Context, user *model.User) (*model
 	role, err := utils.GetRoleFromContext(ctx)
 	if err == nil {
 		if role != enums.Admin {
-			return nil, errors.New("permission denied")
+			return nil, errs.ErrPermissionDenied
 		} else {
 			if user.Role != enums.Owner {
-				return nil, errors.New("admin can create only restaurant owners")
+				return nil, errs.ErrInvalidRole
 			}
 		}
 	}
@@ -67,38 +77,93 @@ func (s *UserService) CreateOwner(ctx context.Context, user *model.User) (*model
 }
 
 func (s *UserService) Update(ctx context.Context, user *model.User) (*model.UserResponse, error) {
+	ctx, span := tracing.Start(ctx, "UserService.Update")
+	defer span.End()
+
 	if user.Role == enums.Admin {
-		return nil, errors.New("permission denied")
+		return nil, errs.ErrPermissionDenied
 	}
 
 	role, err := utils.GetRoleFromContext(ctx)
 	if err != nil {
-		s.logger.Error(err)
+		logging.FromContext(ctx, s.logger).Error(err)
 		return nil, err
 	}
 
 	id, err := utils.GetIDFromContext(ctx)
 	if err != nil {
-		s.logger.Error(err)
+		logging.FromContext(ctx, s.logger).Error(err)
 		return nil, err
 	}
 
-	if role != enums.Admin || user.ID != id {
-		return nil, errors.New("permission denied")
+	if user.ID == 0 {
+		user.ID = id
+	}
+
+	if role != enums.Admin && user.ID != id {
+		return nil, errs.ErrPermissionDenied
 	}
 
+	// Passwords change through ChangePassword, which hashes them.
+	user.Password = ""
+
 	return s.repository.User.Update(ctx, user)
 }
 
+// Patch applies a merge patch to the profile of the current user.
+func (s *UserService) Patch(ctx context.Context, p patch.Patch) (*model.UserResponse, error) {
+	ctx, span := tracing.Start(ctx, "UserService.Patch")
+	defer span.End()
+
+	id, err := utils.GetIDFromContext(ctx)
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Error(err)
+		return nil, err
+	}
+
+	var patched *model.UserResponse
+	err = s.repository.WithTx(ctx, func(txRepos *repository.Manager) error {
+		profile, err := txRepos.User.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		user := &model.User{
+			ID:      profile.ID,
+			Name:    profile.Name,
+			Surname: profile.Surname,
+			Email:   profile.Email,
+			Phone:   profile.Phone,
+			Role:    profile.Role,
+		}
+		fields, err := applyPatch(ctx, profilePatchFields, p, user)
+		if err != nil || len(fields) == 0 {
+			patched = profile
+			return err
+		}
+
+		patched, err = txRepos.User.Patch(ctx, user, fields)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return patched, nil
+}
+
 func (s *UserService) ChangePassword(ctx context.Context, pass *model.ChangePasswordRequest) error {
+	ctx, span := tracing.Start(ctx, "UserService.ChangePassword")
+	defer span.End()
+
 	id, err := utils.GetIDFromContext(ctx)
 	if err != nil {
-		s.logger.Error(err)
+		logging.FromContext(ctx, s.logger).Error(err)
 		return err
 	}
 
 	if pass.OldPassword == pass.NewPassword {
-		return errors.New("old password is equal new password")
+		return errs.ErrSamePassword
 	}
 
 	pass.NewPassword, err = utils.HashPassword(pass.NewPassword)
@@ -111,31 +176,89 @@ func (s *UserService) ChangePassword(ctx context.Context, pass *model.ChangePass
 }
 
 func (s *UserService) Delete(ctx context.Context, id uint) error {
+	ctx, span := tracing.Start(ctx, "UserService.Delete")
+	defer span.End()
+
 	user, err := s.repository.User.GetByID(ctx, id)
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Error(err)
+		return err
+	}
+
 	if user.Role == enums.Admin {
-		return errors.New("permission denied")
+		return errs.ErrPermissionDenied
 	}
 
 	role, err := utils.GetRoleFromContext(ctx)
 	if err != nil {
-		s.logger.Error(err)
+		logging.FromContext(ctx, s.logger).Error(err)
 		return err
 	}
 
-	ctxID, err := utils.GetIDFromContext(ctx)
+	if role != enums.Admin {
+		return errs.ErrPermissionDenied
+	}
+
+	return s.repository.User.Delete(ctx, id)
+}
+
+func (s *UserService) RequestDeletion(ctx context.Context) (*model.DeletionResponse, error) {
+	ctx, span := tracing.Start(ctx, "UserService.RequestDeletion")
+	defer span.End()
+
+	id, err := utils.GetIDFromContext(ctx)
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Error(err)
+		return nil, err
+	}
+
+	user, err := s.repository.User.GetByID(ctx, id)
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Error(err)
+		return nil, err
+	}
+
+	if user.Role == enums.Admin {
+		return nil, errs.ErrPermissionDenied
+	}
+
+	requestedAt := time.Now()
+	if user.DeletionRequestedAt != nil {
+		requestedAt = *user.DeletionRequestedAt
+	} else if err := s.repository.User.RequestDeletion(ctx, id, requestedAt); err != nil {
+		logging.FromContext(ctx, s.logger).Error(err)
+		return nil, err
+	}
+
+	return &model.DeletionResponse{
+		DeletionRequestedAt: requestedAt,
+		AnonymizeAt:         requestedAt.Add(s.config.Retention.CoolingOff),
+	}, nil
+}
+
+// Restore brings back a deleted user with role; users with another role are
+// not found.
+func (s *UserService) Restore(ctx context.Context, id uint, role string) error {
+	ctx, span := tracing.Start(ctx, "UserService.Restore")
+	defer span.End()
+
+	callerRole, err := utils.GetRoleFromContext(ctx)
 	if err != nil {
-		s.logger.Error(err)
+		logging.FromContext(ctx, s.logger).Error(err)
 		return err
 	}
 
-	if role != enums.Admin && user.ID != ctxID {
-		return errors.New("permission denied")
+	if callerRole != enums.Admin {
+		return errs.ErrPermissionDenied
 	}
 
-	return s.repository.User.Delete(ctx, id)
+	return s.repository.User.Restore(ctx, id, role)
 }
 
 func (s *UserService) Profile(ctx context.Context) (*model.UserResponse, error) {
+	ctx, span := tracing.Start(ctx, "UserService.Profile")
+	defer span.End()
+
 	id, err := utils.GetIDFromContext(ctx)
 	if err != nil {
 		return nil, err
@@ -145,23 +268,29 @@ func (s *UserService) Profile(ctx context.Context) (*model.UserResponse, error)
 }
 
 func (s *UserService) GetByID(ctx context.Context, id uint) (*model.UserResponse, error) {
+	ctx, span := tracing.Start(ctx, "UserService.GetByID")
+	defer span.End()
+
 	return s.repository.User.GetByID(ctx, id)
 }
 
 func (s *UserService) GetAllClients(ctx context.Context, params *model.Params) (*model.ListResponse, error) {
+	ctx, span := tracing.Start(ctx, "UserService.GetAllClients")
+	defer span.End()
+
 	role, err := utils.GetRoleFromContext(ctx)
 	if err != nil {
-		s.logger.Error(err)
+		logging.FromContext(ctx, s.logger).Error(err)
 		return nil, err
 	}
 
 	if role != enums.Admin {
-		return nil, errors.New("permission denied")
+		return nil, errs.ErrPermissionDenied
 	}
 
 	list, err := s.repository.User.GetAllClients(ctx, params)
 	if err != nil {
-		s.logger.Error(err)
+		logging.FromContext(ctx, s.logger).Error(err)
 		return nil, err
 	}
 
@@ -171,14 +300,17 @@ func (s *UserService) GetAllClients(ctx context.Context, params *model.Params) (
 }
 
 func (s *UserService) GetAllOwners(ctx context.Context, params *model.Params) (*model.ListResponse, error) {
+	ctx, span := tracing.Start(ctx, "UserService.GetAllOwners")
+	defer span.End()
+
 	role, err := utils.GetRoleFromContext(ctx)
 	if err != nil {
-		s.logger.Error(err)
+		logging.FromContext(ctx, s.logger).Error(err)
 		return nil, err
 	}
 
 	if role != enums.Admin {
-		return nil, errors.New("permission denied")
+		return nil, errs.ErrPermissionDenied
 	}
 	return s.repository.User.GetAllOwners(ctx, params)
 }
@@ -0,0 +1,89 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemory(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	newMemory := func(size int) *Memory {
+		m := NewMemory(size)
+		m.SetClock(func() time.Time { return now })
+		return m
+	}
+	set := func(m *Memory, key string, ttl time.Duration) {
+		t.Helper()
+		if err := m.Set(ctx, key, []byte(key), ttl); err != nil {
+			t.Fatal(err)
+		}
+	}
+	has := func(m *Memory, key string) bool {
+		t.Helper()
+		value, ok, err := m.Get(ctx, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok && string(value) != key {
+			t.Errorf("%s = %s", key, value)
+		}
+		return ok
+	}
+
+	t.Run("evicts the least recently used", func(t *testing.T) {
+		m := newMemory(2)
+		set(m, "a", 0)
+		set(m, "b", 0)
+		has(m, "a")
+		set(m, "c", 0)
+		if !has(m, "a") || has(m, "b") || !has(m, "c") || m.Len() != 2 {
+			t.Errorf("after evicting, a %t, b %t, c %t, len %d, want a and c", has(m, "a"), has(m, "b"), has(m, "c"), m.Len())
+		}
+	})
+
+	t.Run("expires after the ttl", func(t *testing.T) {
+		m := newMemory(0)
+		set(m, "short", time.Minute)
+		set(m, "forever", 0)
+		now = now.Add(time.Minute)
+		if !has(m, "short") {
+			t.Error("entry expired at its ttl, want it kept until after")
+		}
+		now = now.Add(time.Second)
+		if has(m, "short") || !has(m, "forever") || m.Len() != 1 {
+			t.Errorf("after the ttl, short %t, forever %t, len %d, want only forever", has(m, "short"), has(m, "forever"), m.Len())
+		}
+	})
+
+	t.Run("set renews the ttl", func(t *testing.T) {
+		m := newMemory(0)
+		set(m, "key", time.Minute)
+		now = now.Add(50 * time.Second)
+		set(m, "key", time.Minute)
+		now = now.Add(50 * time.Second)
+		if !has(m, "key") || m.Len() != 1 {
+			t.Errorf("renewed entry kept %t, len %d, want one entry", has(m, "key"), m.Len())
+		}
+	})
+
+	t.Run("deletes by key and prefix", func(t *testing.T) {
+		m := newMemory(0)
+		for _, key := range []string{"menu:1", "menu:2", "menus", "food:1", "food:2"} {
+			set(m, key, 0)
+		}
+		if err := m.Delete(ctx, "food:1", "missing"); err != nil {
+			t.Fatal(err)
+		}
+		if err := m.DeletePrefix(ctx, "menu:"); err != nil {
+			t.Fatal(err)
+		}
+		for key, want := range map[string]bool{"menu:1": false, "menu:2": false, "menus": true, "food:1": false, "food:2": true} {
+			if got := has(m, key); got != want {
+				t.Errorf("%s cached %t, want %t", key, got, want)
+			}
+		}
+	})
+}
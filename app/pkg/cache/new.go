@@ -0,0 +1,34 @@
+package cache
+
+import (
+	"fmt"
+	"github.com/alibekabdrakhman1/orynal/config"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	BackendNone   = "none"
+	BackendMemory = "memory"
+	BackendRedis  = "redis"
+)
+
+const redisPrefix = "orynal:"
+
+// New builds the configured backend. It returns nil for BackendNone, which
+// leaves the repositories uncached.
+func New(cfg config.Cache) (Cache, error) {
+	switch cfg.Backend {
+	case "", BackendNone:
+		return nil, nil
+	case BackendMemory:
+		return NewMemory(cfg.Size), nil
+	case BackendRedis:
+		return NewRedis(redis.NewClient(&redis.Options{
+			Addr:     cfg.RedisAddr,
+			Password: cfg.RedisPassword,
+			DB:       cfg.RedisDB,
+		}), redisPrefix), nil
+	default:
+		return nil, fmt.Errorf("unknown cache backend %q", cfg.Backend)
+	}
+}
@@ -0,0 +1,7 @@
+package enums
+
+const (
+	ExportPending string = "pending"
+	ExportReady          = "ready"
+	ExportFailed         = "failed"
+)
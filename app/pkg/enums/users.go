@@ -0,0 +1,6 @@
+package enums
+
+const (
+	DeletedUserName   string = "Deleted user"
+	DeletedUserDomain        = "deleted.orynal.invalid"
+)
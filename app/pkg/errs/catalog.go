@@ -0,0 +1,48 @@
+package errs
+
+var (
+	ErrInvalidID     = BadRequest("invalid_id", "invalid id")
+	ErrInvalidParams = BadRequest("invalid_params", "invalid query params")
+	ErrInvalidBody   = BadRequest("invalid_body", "invalid request body")
+	ErrInvalidETag   = BadRequest("invalid_etag", "If-Match must be a single ETag such as \"3\"")
+	ErrValidation    = Validation("validation_failed", "validation failed")
+
+	ErrUnauthorized       = Unauthorized("unauthorized", "unauthorized")
+	ErrInvalidCredentials = Unauthorized("invalid_credentials", "incorrect email or password")
+	ErrTokenExpired       = Unauthorized("token_expired", "token is expired")
+	ErrInvalidToken       = Unauthorized("invalid_token", "token is invalid")
+
+	ErrPermissionDenied    = Forbidden("permission_denied", "permission denied")
+	ErrNotRestaurantOwner  = Forbidden("not_restaurant_owner", "user is not the owner of the restaurant")
+	ErrNotReviewAuthor     = Forbidden("not_review_author", "user is not the author of the review")
+	ErrRoleChangeForbidden = Forbidden("role_change_forbidden", "user role cannot be changed")
+	ErrExportLinkExpired   = Forbidden("export_link_expired", "export link is expired or invalid")
+	ErrFieldNotPatchable   = Forbidden("field_not_patchable", "field cannot be patched")
+
+	ErrUserNotFound       = NotFound("user_not_found", "user not found")
+	ErrRestaurantNotFound = NotFound("restaurant_not_found", "restaurant not found")
+	ErrFoodNotFound       = NotFound("food_not_found", "food not found")
+	ErrTableNotFound      = NotFound("table_not_found", "table not found")
+	ErrOrderNotFound      = NotFound("order_not_found", "order not found")
+	ErrReviewNotFound     = NotFound("review_not_found", "review not found")
+	ErrServiceNotFound    = NotFound("service_not_found", "service not found")
+	ErrSessionNotFound    = NotFound("session_not_found", "session not found")
+	ErrExportNotFound     = NotFound("export_not_found", "export not found")
+
+	ErrAlreadyExists      = Conflict("already_exists", "resource already exists")
+	ErrReferenceViolation = Conflict("reference_violation", "referenced resource does not exist or is still in use")
+	ErrOrderCanceled      = Conflict("order_canceled", "order status is canceled")
+	ErrOrderCompleted     = Conflict("order_completed", "order status is completed")
+	ErrExportNotReady     = Conflict("export_not_ready", "export is not ready")
+	ErrExportPending      = Conflict("export_pending", "an export is already being prepared")
+
+	ErrVersionMismatch = PreconditionFailed("version_mismatch", "resource was modified since it was read, fetch it again")
+	ErrIfMatchRequired = PreconditionRequired("if_match_required", "If-Match header with the resource ETag is required")
+
+	ErrExportBusy = Unavailable("export_busy", "too many exports are being prepared, try again later")
+
+	ErrSamePassword  = Validation("same_password", "new password must differ from the old one")
+	ErrWrongPassword = Validation("wrong_password", "wrong old password")
+	ErrInvalidOwner  = Validation("invalid_owner", "owner does not exist or is not a restaurant owner")
+	ErrInvalidRole   = Validation("invalid_role", "admin can create only restaurant owners")
+)
@@ -0,0 +1,20 @@
+package geocode
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNotFound is returned when an address cannot be placed.
+var ErrNotFound = errors.New("address not found")
+
+type Location struct {
+	Lat float64
+	Lng float64
+}
+
+// Geocoder places a street address in a city. Implementations must be safe
+// for concurrent use.
+type Geocoder interface {
+	Geocode(ctx context.Context, address, city string) (Location, error)
+}
@@ -0,0 +1,34 @@
+package geocode
+
+import (
+	"fmt"
+	"github.com/alibekabdrakhman1/orynal/config"
+	"net/http"
+)
+
+const (
+	BackendNone      = "none"
+	BackendOffline   = "offline"
+	BackendNominatim = "nominatim"
+)
+
+const nominatimURL = "https://nominatim.openstreetmap.org"
+
+// New builds the configured backend. It returns nil for BackendNone, which
+// leaves restaurants without coordinates unless they are given.
+func New(cfg config.Geocoder) (Geocoder, error) {
+	switch cfg.Backend {
+	case "", BackendNone:
+		return nil, nil
+	case BackendOffline:
+		return NewOffline(), nil
+	case BackendNominatim:
+		baseURL := cfg.URL
+		if baseURL == "" {
+			baseURL = nominatimURL
+		}
+		return NewNominatim(&http.Client{Timeout: cfg.Timeout}, baseURL, cfg.UserAgent, cfg.CountryCodes), nil
+	default:
+		return nil, fmt.Errorf("unknown geocoder backend %q", cfg.Backend)
+	}
+}
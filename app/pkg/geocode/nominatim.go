@@ -0,0 +1,79 @@
+package geocode
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Nominatim geocodes with the search API of an OpenStreetMap Nominatim
+// server. The public server asks for a descriptive User-Agent and at most
+// one request per second.
+type Nominatim struct {
+	client       *http.Client
+	url          string
+	userAgent    string
+	countryCodes string
+}
+
+func NewNominatim(client *http.Client, baseURL, userAgent, countryCodes string) *Nominatim {
+	return &Nominatim{
+		client:       client,
+		url:          strings.TrimSuffix(baseURL, "/"),
+		userAgent:    userAgent,
+		countryCodes: countryCodes,
+	}
+}
+
+func (n *Nominatim) Geocode(ctx context.Context, address, city string) (Location, error) {
+	query := url.Values{"format": {"jsonv2"}, "limit": {"1"}, "city": {city}}
+	if address != "" {
+		query.Set("street", address)
+	}
+	if n.countryCodes != "" {
+		query.Set("countrycodes", n.countryCodes)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, n.url+"/search?"+query.Encode(), nil)
+	if err != nil {
+		return Location{}, err
+	}
+	req.Header.Set("User-Agent", n.userAgent)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return Location{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Location{}, fmt.Errorf("nominatim: %s", resp.Status)
+	}
+
+	var places []struct {
+		Lat string `json:"lat"`
+		Lon string `json:"lon"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&places); err != nil {
+		return Location{}, fmt.Errorf("nominatim: %w", err)
+	}
+	if len(places) == 0 {
+		return Location{}, ErrNotFound
+	}
+
+	lat, err := strconv.ParseFloat(places[0].Lat, 64)
+	if err != nil {
+		return Location{}, fmt.Errorf("nominatim: %w", err)
+	}
+	lng, err := strconv.ParseFloat(places[0].Lon, 64)
+	if err != nil {
+		return Location{}, fmt.Errorf("nominatim: %w", err)
+	}
+
+	return Location{Lat: lat, Lng: lng}, nil
+}
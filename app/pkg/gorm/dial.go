@@ -2,16 +2,46 @@ package gorm
 
 import (
 	"context"
+	"github.com/alibekabdrakhman1/orynal/pkg/metrics"
+	"github.com/alibekabdrakhman1/orynal/pkg/tracing"
+	"github.com/glebarez/sqlite"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"strings"
 )
 
+// sqlitePragmas are applied to every pooled connection: SQLite leaves
+// foreign keys off by default and fails immediately on a locked database.
+const sqlitePragmas = "_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)"
+
+// Dial opens the database at url. sqlite://path opens a SQLite file, anything
+// else is handed to the Postgres driver as a DSN or postgres:// URL.
 func Dial(ctx context.Context, url string) (*gorm.DB, error) {
 	_ = ctx
-	db, err := gorm.Open(postgres.Open(url), &gorm.Config{})
+	db, err := gorm.Open(dialector(url), &gorm.Config{TranslateError: true})
 	if err != nil {
 		return nil, err
 	}
 
+	if err := db.Use(metrics.NewGormPlugin()); err != nil {
+		return nil, err
+	}
+
+	if err := db.Use(tracing.NewGormPlugin()); err != nil {
+		return nil, err
+	}
+
 	return db, nil
 }
+
+func dialector(url string) gorm.Dialector {
+	path, ok := strings.CutPrefix(url, "sqlite://")
+	if !ok {
+		return postgres.Open(url)
+	}
+
+	if strings.Contains(path, "?") {
+		return sqlite.Open(path + "&" + sqlitePragmas)
+	}
+	return sqlite.Open(path + "?" + sqlitePragmas)
+}
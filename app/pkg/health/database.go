@@ -0,0 +1,44 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"gorm.io/gorm"
+)
+
+// Database pings the database through the gorm connection pool.
+func Database(db *gorm.DB) Check {
+	return func(ctx context.Context) error {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+
+		return sqlDB.PingContext(ctx)
+	}
+}
+
+// Migrations checks that the schema_migrations table is clean and at least at
+// the expected version. Newer schemas pass so that old instances stay ready
+// while a rolling deploy migrates the database ahead of them.
+func Migrations(db *gorm.DB, expected uint) Check {
+	return func(ctx context.Context) error {
+		var state struct {
+			Version uint
+			Dirty   bool
+		}
+		err := db.WithContext(ctx).Raw("SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&state).Error
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case state.Dirty:
+			return fmt.Errorf("migration %d is dirty", state.Version)
+		case state.Version < expected:
+			return fmt.Errorf("schema version %d, expected %d", state.Version, expected)
+		}
+
+		return nil
+	}
+}
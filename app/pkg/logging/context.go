@@ -0,0 +1,24 @@
+package logging
+
+import (
+	"context"
+	"go.uber.org/zap"
+)
+
+type contextKey struct{}
+
+// WithContext stores a request-scoped logger in ctx.
+func WithContext(ctx context.Context, logger *zap.SugaredLogger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// FromContext returns the logger stored in ctx, or fallback when ctx carries
+// none, e.g. in background jobs.
+func FromContext(ctx context.Context, fallback *zap.SugaredLogger) *zap.SugaredLogger {
+	if ctx != nil {
+		if logger, ok := ctx.Value(contextKey{}).(*zap.SugaredLogger); ok {
+			return logger
+		}
+	}
+	return fallback
+}
@@ -0,0 +1,37 @@
+package metrics
+
+import (
+	"github.com/labstack/echo/v4"
+	"strconv"
+	"time"
+)
+
+// Middleware records request count and latency per route template, so
+// /restaurants/1 and /restaurants/2 share a series.
+func Middleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+
+			// Render the error here so the final status code is known.
+			if err := next(c); err != nil {
+				c.Error(err)
+			}
+
+			route := c.Path()
+			if route == "" {
+				route = "unmatched"
+			}
+			method := c.Request().Method
+
+			HTTPDuration.WithLabelValues(method, route).Observe(time.Since(start).Seconds())
+			HTTPRequests.WithLabelValues(method, route, statusClass(c.Response().Status)).Inc()
+
+			return nil
+		}
+	}
+}
+
+func statusClass(status int) string {
+	return strconv.Itoa(status/100) + "xx"
+}
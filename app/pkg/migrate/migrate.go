@@ -0,0 +1,353 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// lockID keys the session advisory lock that serializes concurrent runners,
+// e.g. several replicas migrating on startup during one deploy.
+const lockID int64 = 7318390215547641601
+
+// Dialects as reported by gorm's Dialector.Name.
+const (
+	Postgres = "postgres"
+	SQLite   = "sqlite"
+)
+
+// The table layout matches the golang-migrate CLI so databases migrated with
+// it before keep their version.
+const createTable = `CREATE TABLE IF NOT EXISTS schema_migrations (
+	version BIGINT NOT NULL PRIMARY KEY,
+	dirty BOOLEAN NOT NULL
+)`
+
+var (
+	fileName    = regexp.MustCompile(`^([0-9]+)_([A-Za-z0-9_]+)\.(up|down)\.sql$`)
+	nonWordChar = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+var ErrDirty = errors.New("database is dirty, fix the failed migration and force its version")
+
+type Migration struct {
+	Version uint
+	Name    string
+	Up      string
+	Down    string
+}
+
+type Status struct {
+	Migration
+	Applied bool
+}
+
+// Load reads NNNNNN_name.up.sql and NNNNNN_name.down.sql pairs from source,
+// sorted by version. Files without the .sql extension are ignored.
+func Load(source fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(source, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[uint]*Migration{}
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".sql" {
+			continue
+		}
+
+		match := fileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("migration %s: want NNNNNN_name.up.sql or NNNNNN_name.down.sql", entry.Name())
+		}
+		version, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil || version == 0 {
+			return nil, fmt.Errorf("migration %s: invalid version", entry.Name())
+		}
+
+		m, ok := byVersion[uint(version)]
+		if !ok {
+			m = &Migration{Version: uint(version), Name: match[2]}
+			byVersion[uint(version)] = m
+		}
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has two names: %s and %s", version, m.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			m.Up = entry.Name()
+		} else {
+			m.Down = entry.Name()
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s: both up and down files are required", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+// Create writes an empty up/down pair for the next version into dir and
+// returns the file paths.
+func Create(dir, name string) ([]string, error) {
+	name = strings.Trim(nonWordChar.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if name == "" {
+		return nil, errors.New("migration name is empty")
+	}
+
+	migrations, err := Load(os.DirFS(dir))
+	if err != nil {
+		return nil, err
+	}
+
+	var next uint = 1
+	if len(migrations) > 0 {
+		next = migrations[len(migrations)-1].Version + 1
+	}
+
+	var paths []string
+	for _, direction := range []string{"up", "down"} {
+		path := filepath.Join(dir, fmt.Sprintf("%06d_%s.%s.sql", next, name, direction))
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if err != nil {
+			return paths, err
+		}
+		if err := file.Close(); err != nil {
+			return paths, err
+		}
+		paths = append(paths, path)
+	}
+
+	return paths, nil
+}
+
+type Migrator struct {
+	db         *sql.DB
+	dialect    string
+	source     fs.FS
+	migrations []Migration
+	logger     *zap.SugaredLogger
+}
+
+func New(db *sql.DB, dialect string, source fs.FS, logger *zap.SugaredLogger) (*Migrator, error) {
+	if dialect != Postgres && dialect != SQLite {
+		return nil, fmt.Errorf("unsupported dialect %q", dialect)
+	}
+
+	migrations, err := Load(source)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{
+		db:         db,
+		dialect:    dialect,
+		source:     source,
+		migrations: migrations,
+		logger:     logger,
+	}, nil
+}
+
+// Latest returns the highest known version, or 0 without migrations.
+func (m *Migrator) Latest() uint {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Up applies every pending migration and returns how many ran.
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	applied := 0
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		current, err := m.checkedVersion(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			if migration.Version <= current {
+				continue
+			}
+			if err := m.apply(ctx, conn, migration.Up, migration.Version); err != nil {
+				return fmt.Errorf("migration %d_%s up: %w", migration.Version, migration.Name, err)
+			}
+			m.logger.Infof("applied migration %d_%s", migration.Version, migration.Name)
+			applied++
+		}
+
+		return nil
+	})
+
+	return applied, err
+}
+
+// Down reverts up to steps applied migrations, newest first, and returns how
+// many ran.
+func (m *Migrator) Down(ctx context.Context, steps int) (int, error) {
+	reverted := 0
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		current, err := m.checkedVersion(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		index := m.indexOf(current)
+		if current != 0 && index < 0 {
+			return fmt.Errorf("database version %d is not a known migration", current)
+		}
+
+		for ; index >= 0 && reverted < steps; index-- {
+			migration := m.migrations[index]
+
+			var previous uint
+			if index > 0 {
+				previous = m.migrations[index-1].Version
+			}
+			if err := m.apply(ctx, conn, migration.Down, previous); err != nil {
+				return fmt.Errorf("migration %d_%s down: %w", migration.Version, migration.Name, err)
+			}
+			m.logger.Infof("reverted migration %d_%s", migration.Version, migration.Name)
+			reverted++
+		}
+
+		return nil
+	})
+
+	return reverted, err
+}
+
+// Status returns the database version and whether each known migration is
+// applied.
+func (m *Migrator) Status(ctx context.Context) (uint, bool, []Status, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return 0, false, nil, err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, createTable); err != nil {
+		return 0, false, nil, err
+	}
+	current, dirty, err := version(ctx, conn)
+	if err != nil {
+		return 0, false, nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		statuses = append(statuses, Status{Migration: migration, Applied: migration.Version <= current})
+	}
+
+	return current, dirty, statuses, nil
+}
+
+// withLock runs fn on a single connection holding the advisory lock, so the
+// lock is released even if the runner dies mid-migration. SQLite has no
+// advisory locks; a database file is expected to have a single runner.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if m.dialect == Postgres {
+		if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", lockID); err != nil {
+			return fmt.Errorf("acquire migration lock: %w", err)
+		}
+		defer func() {
+			if _, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", lockID); err != nil {
+				m.logger.Errorf("release migration lock: %v", err)
+			}
+		}()
+	}
+
+	if _, err := conn.ExecContext(ctx, createTable); err != nil {
+		return err
+	}
+
+	return fn(conn)
+}
+
+func (m *Migrator) checkedVersion(ctx context.Context, conn *sql.Conn) (uint, error) {
+	current, dirty, err := version(ctx, conn)
+	if err != nil {
+		return 0, err
+	}
+	if dirty {
+		return 0, fmt.Errorf("version %d: %w", current, ErrDirty)
+	}
+
+	return current, nil
+}
+
+// apply runs a migration file and records the resulting version in one
+// transaction; Postgres and SQLite DDL are transactional, so a failure leaves
+// no trace.
+func (m *Migrator) apply(ctx context.Context, conn *sql.Conn, file string, to uint) error {
+	query, err := fs.ReadFile(m.source, file)
+	if err != nil {
+		return err
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if strings.TrimSpace(string(query)) != "" {
+		if _, err := tx.ExecContext(ctx, string(query)); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM schema_migrations"); err != nil {
+		return err
+	}
+	if to > 0 {
+		if _, err := tx.ExecContext(ctx, "INSERT INTO schema_migrations (version, dirty) VALUES ($1, false)", to); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (m *Migrator) indexOf(v uint) int {
+	for i, migration := range m.migrations {
+		if migration.Version == v {
+			return i
+		}
+	}
+	return -1
+}
+
+func version(ctx context.Context, conn *sql.Conn) (uint, bool, error) {
+	var (
+		current uint
+		dirty   bool
+	)
+	err := conn.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&current, &dirty)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+
+	return current, dirty, err
+}
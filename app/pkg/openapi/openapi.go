@@ -0,0 +1,309 @@
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const Version = "3.0.3"
+
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Servers    []Server             `json:"servers,omitempty"`
+	Tags       []Tag                `json:"tags,omitempty"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+type Server struct {
+	URL string `json:"url"`
+}
+
+type Tag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+type PathItem map[string]*Operation
+
+type Operation struct {
+	Tags        []string              `json:"tags,omitempty"`
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	OperationID string                `json:"operationId"`
+	Parameters  []*Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+type Parameter struct {
+	Ref         string  `json:"$ref,omitempty"`
+	Name        string  `json:"name,omitempty"`
+	In          string  `json:"in,omitempty"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema,omitempty"`
+}
+
+type RequestBody struct {
+	Required bool                  `json:"required"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+type Response struct {
+	Ref         string                `json:"$ref,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Headers     map[string]*Header    `json:"headers,omitempty"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+type Header struct {
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas"`
+	Parameters      map[string]*Parameter      `json:"parameters,omitempty"`
+	Responses       map[string]*Response       `json:"responses,omitempty"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+}
+
+// Route documents a single endpoint. Path uses Echo syntax (":id"), Body and
+// Response are zero values of the models the handler binds and returns.
+// Routes with a ContentType are documented as raw, unwrapped responses, and
+// BodyType overrides the application/json media type of the request body.
+// Query and Header name component parameters; ETag routes send the version of
+// the returned entity in an ETag header.
+type Route struct {
+	Method      string
+	Path        string
+	Tag         string
+	Summary     string
+	Description string
+	Auth        bool
+	Query       []string
+	Header      []string
+	Body        interface{}
+	BodyType    string
+	Status      int
+	Response    interface{}
+	List        bool
+	ContentType string
+	ETag        bool
+}
+
+var pathParam = regexp.MustCompile(`:([A-Za-z0-9_]+)`)
+
+// PathOf converts an Echo route path to an OpenAPI path template.
+func PathOf(echoPath string) string {
+	return pathParam.ReplaceAllString(echoPath, "{$1}")
+}
+
+type Builder struct {
+	doc      *Document
+	schemas  *schemaRegistry
+	envelope interface{}
+	list     interface{}
+}
+
+// NewBuilder starts a document whose responses are wrapped in envelope, with
+// the payload under its "data" field. List routes use list as the page model
+// and put their items under its "items" field.
+func NewBuilder(info Info, envelope, list interface{}) *Builder {
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Paths:   map[string]*PathItem{},
+		Components: Components{
+			Schemas:    map[string]*Schema{},
+			Parameters: map[string]*Parameter{},
+			Responses:  map[string]*Response{},
+			SecuritySchemes: map[string]*SecurityScheme{
+				"bearerAuth": {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+			},
+		},
+	}
+
+	return &Builder{
+		doc:      doc,
+		schemas:  newSchemaRegistry(doc.Components.Schemas),
+		envelope: envelope,
+		list:     list,
+	}
+}
+
+// Schema returns a schema for the type of v, registering named structs as
+// reusable components.
+func (b *Builder) Schema(v interface{}) *Schema {
+	return b.schemas.schemaOf(reflect.TypeOf(v))
+}
+
+func (b *Builder) AddServer(url string) {
+	b.doc.Servers = append(b.doc.Servers, Server{URL: url})
+}
+
+func (b *Builder) AddTag(name, description string) {
+	b.doc.Tags = append(b.doc.Tags, Tag{Name: name, Description: description})
+}
+
+func (b *Builder) AddParameter(name string, parameter *Parameter) {
+	b.doc.Components.Parameters[name] = parameter
+}
+
+func (b *Builder) AddResponse(name string, response *Response) {
+	b.doc.Components.Responses[name] = response
+}
+
+func (b *Builder) AddRoute(r Route) {
+	path := PathOf(r.Path)
+	item, ok := b.doc.Paths[path]
+	if !ok {
+		item = &PathItem{}
+		b.doc.Paths[path] = item
+	}
+
+	op := &Operation{
+		Summary:     r.Summary,
+		Description: r.Description,
+		OperationID: operationID(r.Method, r.Path),
+		Responses:   map[string]*Response{},
+	}
+	if r.Tag != "" {
+		op.Tags = []string{r.Tag}
+	}
+	if r.Auth {
+		op.Security = []map[string][]string{{"bearerAuth": {}}}
+	}
+
+	for _, match := range pathParam.FindAllStringSubmatch(r.Path, -1) {
+		op.Parameters = append(op.Parameters, &Parameter{
+			Name:     match[1],
+			In:       "path",
+			Required: true,
+			Schema:   &Schema{Type: "integer", Minimum: floatPtr(1)},
+		})
+	}
+	for _, name := range r.Query {
+		op.Parameters = append(op.Parameters, &Parameter{Ref: "#/components/parameters/" + name})
+	}
+	for _, name := range r.Header {
+		op.Parameters = append(op.Parameters, &Parameter{Ref: "#/components/parameters/" + name})
+	}
+
+	if r.Body != nil {
+		bodyType := r.BodyType
+		if bodyType == "" {
+			bodyType = "application/json"
+		}
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]*MediaType{bodyType: {Schema: b.Schema(r.Body)}},
+		}
+	}
+
+	status := r.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	success := &Response{Description: http.StatusText(status)}
+	if r.ETag {
+		success.Headers = map[string]*Header{"ETag": {
+			Description: "Version of the returned entity, send it back in If-Match to update it",
+			Schema:      &Schema{Type: "string"},
+		}}
+	}
+	switch {
+	case r.ContentType != "":
+		schema := &Schema{Type: "string", Format: "binary"}
+		if r.Response != nil {
+			schema = b.Schema(r.Response)
+		}
+		success.Content = map[string]*MediaType{r.ContentType: {Schema: schema}}
+	default:
+		var data *Schema
+		if r.Response != nil {
+			data = b.Schema(r.Response)
+		}
+		if r.List {
+			data = &Schema{AllOf: []*Schema{
+				b.Schema(b.list),
+				{Type: "object", Properties: map[string]*Schema{
+					"items": {Type: "array", Items: data},
+				}},
+			}}
+		}
+		success.Content = map[string]*MediaType{"application/json": {Schema: b.wrap(data)}}
+	}
+	op.Responses[strconv.Itoa(status)] = success
+	op.Responses["default"] = &Response{Ref: "#/components/responses/Error"}
+
+	(*item)[strings.ToLower(r.Method)] = op
+}
+
+func (b *Builder) wrap(data *Schema) *Schema {
+	base := b.Schema(b.envelope)
+	if data == nil {
+		return base
+	}
+
+	return &Schema{AllOf: []*Schema{
+		base,
+		{Type: "object", Properties: map[string]*Schema{"data": data}},
+	}}
+}
+
+func (b *Builder) Document() *Document {
+	return b.doc
+}
+
+// HasOperation reports whether the document describes method on the Echo
+// route path.
+func (d *Document) HasOperation(method, echoPath string) bool {
+	item, ok := d.Paths[PathOf(echoPath)]
+	if !ok {
+		return false
+	}
+	_, ok = (*item)[strings.ToLower(method)]
+	return ok
+}
+
+func operationID(method, path string) string {
+	parts := []string{strings.ToLower(method)}
+	for _, segment := range strings.Split(path, "/") {
+		if segment == "" || segment == "api" {
+			continue
+		}
+		segment = strings.TrimPrefix(segment, ":")
+		for _, word := range strings.FieldsFunc(segment, func(r rune) bool { return r == '-' || r == '_' }) {
+			parts = append(parts, strings.ToUpper(word[:1])+word[1:])
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+func floatPtr(f float64) *float64 {
+	return &f
+}
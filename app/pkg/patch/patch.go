@@ -0,0 +1,85 @@
+// Package patch applies RFC 7396 JSON merge patches to models.
+package patch
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/alibekabdrakhman1/orynal/pkg/errs"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Patch holds the members of a merge patch document by name.
+type Patch map[string]json.RawMessage
+
+// Decode reads a merge patch, which must be a JSON object.
+func Decode(r io.Reader) (Patch, error) {
+	var p Patch
+	if err := json.NewDecoder(r).Decode(&p); err != nil {
+		return nil, errs.ErrInvalidBody.Wrap(err)
+	}
+	if p == nil {
+		return nil, errs.ErrInvalidBody.WithMessage("merge patch must be a JSON object")
+	}
+
+	return p, nil
+}
+
+// Apply merges p into the struct dst points to and returns the Go names of
+// the fields it set, for a repository to write even when they are zero.
+// Members match fields by their json names and must be listed in allowed;
+// null resets a field to its zero value.
+func (p Patch) Apply(dst any, allowed ...string) ([]string, error) {
+	v := reflect.ValueOf(dst).Elem()
+	fields := jsonFields(v.Type())
+
+	names := make([]string, 0, len(p))
+	for _, member := range sortedKeys(p) {
+		index, ok := fields[member]
+		if !ok || !slices.Contains(allowed, member) {
+			return nil, errs.ErrFieldNotPatchable.WithDetails(map[string]string{"field": member})
+		}
+
+		field := v.FieldByIndex(index)
+		if bytes.Equal(bytes.TrimSpace(p[member]), []byte("null")) {
+			field.SetZero()
+		} else if err := json.Unmarshal(p[member], field.Addr().Interface()); err != nil {
+			return nil, errs.ErrInvalidBody.Wrap(err).WithDetails(map[string]string{"field": member})
+		}
+		names = append(names, v.Type().FieldByIndex(index).Name)
+	}
+
+	return names, nil
+}
+
+// jsonFields maps the json names of the exported fields of t to their index.
+func jsonFields(t reflect.Type) map[string][]int {
+	fields := map[string][]int{}
+	for _, field := range reflect.VisibleFields(t) {
+		if !field.IsExported() || field.Anonymous {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		switch name {
+		case "-":
+			continue
+		case "":
+			name = field.Name
+		}
+		fields[name] = field.Index
+	}
+
+	return fields
+}
+
+func sortedKeys(p Patch) []string {
+	keys := make([]string, 0, len(p))
+	for key := range p {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	return keys
+}
@@ -2,16 +2,15 @@ package utils
 
 import (
 	"context"
-	"errors"
-	"fmt"
 	"github.com/alibekabdrakhman1/orynal/internal/model"
+	"github.com/alibekabdrakhman1/orynal/pkg/errs"
 	"strconv"
 )
 
 func ConvertIdToUint(in string) (uint, error) {
 	id, err := strconv.ParseUint(in, 10, 32)
 	if err != nil {
-		return 0, errors.New(fmt.Sprintf("converting id to uint error: %v", err))
+		return 0, errs.ErrInvalidID.Wrap(err)
 	}
 
 	return uint(id), err
@@ -20,7 +19,7 @@ func ConvertIdToUint(in string) (uint, error) {
 func GetIDFromContext(ctx context.Context) (uint, error) {
 	userID, ok := ctx.Value(model.ContextUserIDKey).(*model.ContextUserID)
 	if !ok {
-		return 0, errors.New("not valid context userID")
+		return 0, errs.ErrUnauthorized
 	}
 
 	return userID.ID, nil
@@ -29,7 +28,7 @@ func GetIDFromContext(ctx context.Context) (uint, error) {
 func GetRoleFromContext(ctx context.Context) (string, error) {
 	userRole, ok := ctx.Value(model.ContextUserRoleKey).(*model.ContextUserRole)
 	if !ok {
-		return "", errors.New("not valid context userRole")
+		return "", errs.ErrUnauthorized
 	}
 
 	return userRole.Role, nil
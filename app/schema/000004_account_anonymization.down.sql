@@ -0,0 +1,10 @@
+ALTER TABLE restaurant_reviews DROP CONSTRAINT IF EXISTS restaurant_reviews_user_id_fkey;
+ALTER TABLE restaurant_reviews ADD CONSTRAINT restaurant_reviews_user_id_fkey
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_user_id_fkey;
+ALTER TABLE orders ADD CONSTRAINT orders_user_id_fkey
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
+ALTER TABLE users DROP COLUMN IF EXISTS anonymized_at;
+ALTER TABLE users DROP COLUMN IF EXISTS deletion_requested_at;
@@ -0,0 +1,4 @@
+ALTER TABLE orders DROP COLUMN IF EXISTS version;
+ALTER TABLE foods DROP COLUMN IF EXISTS version;
+ALTER TABLE tables DROP COLUMN IF EXISTS version;
+ALTER TABLE restaurants DROP COLUMN IF EXISTS version;
@@ -0,0 +1,10 @@
+DROP TRIGGER IF EXISTS foods_search ON foods;
+DROP TRIGGER IF EXISTS restaurants_search ON restaurants;
+DROP FUNCTION IF EXISTS foods_search_trigger();
+DROP FUNCTION IF EXISTS restaurants_search_trigger();
+DROP FUNCTION IF EXISTS refresh_restaurant_search(INTEGER);
+DROP INDEX IF EXISTS idx_foods_name_trgm;
+DROP INDEX IF EXISTS idx_restaurants_name_trgm;
+DROP TABLE IF EXISTS restaurant_search;
+DROP FUNCTION IF EXISTS search_tsquery(text);
+DROP FUNCTION IF EXISTS search_tsvector(text);
@@ -0,0 +1,73 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Kazakh has no Snowball stemmer; the simple config indexes its words as
+-- they are, next to the Russian and English stems.
+CREATE OR REPLACE FUNCTION search_tsvector(body text) RETURNS tsvector AS $$
+    SELECT to_tsvector('russian', coalesce(body, ''))
+        || to_tsvector('english', coalesce(body, ''))
+        || to_tsvector('simple', coalesce(body, ''))
+$$ LANGUAGE sql IMMUTABLE;
+
+CREATE OR REPLACE FUNCTION search_tsquery(query text) RETURNS tsquery AS $$
+    SELECT websearch_to_tsquery('russian', query)
+        || websearch_to_tsquery('english', query)
+        || websearch_to_tsquery('simple', query)
+$$ LANGUAGE sql IMMUTABLE;
+
+CREATE TABLE IF NOT EXISTS restaurant_search (
+    restaurant_id INTEGER PRIMARY KEY,
+    document TSVECTOR NOT NULL,
+    FOREIGN KEY (restaurant_id) REFERENCES restaurants(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_restaurant_search_document ON restaurant_search USING gin (document);
+CREATE INDEX IF NOT EXISTS idx_restaurants_name_trgm ON restaurants USING gin (name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_foods_name_trgm ON foods USING gin (name gin_trgm_ops);
+
+-- The document of a restaurant weighs its name above its city and dishes,
+-- and those above the descriptions.
+CREATE OR REPLACE FUNCTION refresh_restaurant_search(rid INTEGER) RETURNS void AS $$
+    INSERT INTO restaurant_search (restaurant_id, document)
+    SELECT r.id,
+           setweight(search_tsvector(r.name), 'A')
+               || setweight(search_tsvector(r.city), 'B')
+               || setweight(search_tsvector(string_agg(f.name, ' ')), 'B')
+               || setweight(search_tsvector(r.description), 'C')
+               || setweight(search_tsvector(string_agg(f.description, ' ')), 'D')
+    FROM restaurants r
+    LEFT JOIN foods f ON f.restaurant_id = r.id AND f.deleted_at IS NULL
+    WHERE r.id = rid
+    GROUP BY r.id
+    ON CONFLICT (restaurant_id) DO UPDATE SET document = EXCLUDED.document;
+$$ LANGUAGE sql;
+
+CREATE OR REPLACE FUNCTION restaurants_search_trigger() RETURNS trigger AS $$
+BEGIN
+    PERFORM refresh_restaurant_search(NEW.id);
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION foods_search_trigger() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP <> 'INSERT' THEN
+        PERFORM refresh_restaurant_search(OLD.restaurant_id);
+    END IF;
+    IF TG_OP = 'INSERT' OR (TG_OP = 'UPDATE' AND NEW.restaurant_id <> OLD.restaurant_id) THEN
+        PERFORM refresh_restaurant_search(NEW.restaurant_id);
+    END IF;
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS restaurants_search ON restaurants;
+CREATE TRIGGER restaurants_search
+    AFTER INSERT OR UPDATE OF name, city, description ON restaurants
+    FOR EACH ROW EXECUTE FUNCTION restaurants_search_trigger();
+
+DROP TRIGGER IF EXISTS foods_search ON foods;
+CREATE TRIGGER foods_search
+    AFTER INSERT OR UPDATE OF name, description, restaurant_id, deleted_at OR DELETE ON foods
+    FOR EACH ROW EXECUTE FUNCTION foods_search_trigger();
+
+SELECT refresh_restaurant_search(id) FROM restaurants;
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_foods_price;
+DROP INDEX IF EXISTS idx_foods_search;
+
+ALTER TABLE restaurants DROP COLUMN IF EXISTS longitude;
+ALTER TABLE restaurants DROP COLUMN IF EXISTS latitude;
+ALTER TABLE foods DROP COLUMN IF EXISTS gluten_free;
+ALTER TABLE foods DROP COLUMN IF EXISTS halal;
+ALTER TABLE foods DROP COLUMN IF EXISTS vegan;
+ALTER TABLE foods DROP COLUMN IF EXISTS vegetarian;
@@ -0,0 +1,9 @@
+ALTER TABLE foods ADD COLUMN IF NOT EXISTS vegetarian boolean NOT NULL DEFAULT false;
+ALTER TABLE foods ADD COLUMN IF NOT EXISTS vegan boolean NOT NULL DEFAULT false;
+ALTER TABLE foods ADD COLUMN IF NOT EXISTS halal boolean NOT NULL DEFAULT false;
+ALTER TABLE foods ADD COLUMN IF NOT EXISTS gluten_free boolean NOT NULL DEFAULT false;
+ALTER TABLE restaurants ADD COLUMN IF NOT EXISTS latitude double precision;
+ALTER TABLE restaurants ADD COLUMN IF NOT EXISTS longitude double precision;
+
+CREATE INDEX IF NOT EXISTS idx_foods_search ON foods USING gin (search_tsvector(name || ' ' || coalesce(description, '')));
+CREATE INDEX IF NOT EXISTS idx_foods_price ON foods (price);
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_restaurants_location;
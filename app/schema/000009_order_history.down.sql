@@ -0,0 +1,7 @@
+ALTER TABLE order_foods DROP CONSTRAINT IF EXISTS order_foods_food_id_fkey;
+ALTER TABLE order_foods ADD CONSTRAINT order_foods_food_id_fkey
+    FOREIGN KEY (food_id) REFERENCES foods(id) ON DELETE CASCADE;
+
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_restaurant_id_fkey;
+ALTER TABLE orders ADD CONSTRAINT orders_restaurant_id_fkey
+    FOREIGN KEY (restaurant_id) REFERENCES restaurants(id) ON DELETE CASCADE;
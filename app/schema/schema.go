@@ -0,0 +1,28 @@
+// Package schema embeds the versioned SQL migrations applied by pkg/migrate.
+// The Postgres files live at the top level; sqlite/ holds their SQLite
+// counterparts under the same versions, so a change needs both.
+package schema
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+)
+
+//go:embed *.sql
+var FS embed.FS
+
+//go:embed sqlite/*.sql
+var sqliteFS embed.FS
+
+// For returns the migrations written for a gorm dialect.
+func For(dialect string) (fs.FS, error) {
+	switch dialect {
+	case "postgres":
+		return FS, nil
+	case "sqlite":
+		return fs.Sub(sqliteFS, "sqlite")
+	default:
+		return nil, fmt.Errorf("no migrations for dialect %q", dialect)
+	}
+}
@@ -0,0 +1,31 @@
+package schema
+
+import (
+	"github.com/alibekabdrakhman1/orynal/pkg/migrate"
+	"testing"
+)
+
+func TestDialectsMatch(t *testing.T) {
+	load := func(dialect string) []migrate.Migration {
+		source, err := For(dialect)
+		if err != nil {
+			t.Fatal(err)
+		}
+		migrations, err := migrate.Load(source)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return migrations
+	}
+
+	postgres, sqlite := load(migrate.Postgres), load(migrate.SQLite)
+	if len(postgres) != len(sqlite) {
+		t.Fatalf("%d postgres migrations, %d sqlite migrations", len(postgres), len(sqlite))
+	}
+	for i := range postgres {
+		if postgres[i].Version != sqlite[i].Version || postgres[i].Name != sqlite[i].Name {
+			t.Errorf("postgres migration %d_%s, sqlite migration %d_%s",
+				postgres[i].Version, postgres[i].Name, sqlite[i].Version, sqlite[i].Name)
+		}
+	}
+}
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_foods_deleted_at;
+DROP INDEX IF EXISTS idx_restaurants_deleted_at;
+DROP INDEX IF EXISTS idx_users_deleted_at;
+
+ALTER TABLE foods DROP COLUMN deleted_at;
+ALTER TABLE restaurants DROP COLUMN deleted_at;
+ALTER TABLE users DROP COLUMN deleted_at;
@@ -0,0 +1,7 @@
+ALTER TABLE users ADD COLUMN deleted_at TIMESTAMP;
+ALTER TABLE restaurants ADD COLUMN deleted_at TIMESTAMP;
+ALTER TABLE foods ADD COLUMN deleted_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_restaurants_deleted_at ON restaurants (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_foods_deleted_at ON foods (deleted_at);
@@ -0,0 +1,5 @@
+DROP TRIGGER IF EXISTS restaurant_reviews_user_id_restrict;
+DROP TRIGGER IF EXISTS orders_user_id_restrict;
+
+ALTER TABLE users DROP COLUMN anonymized_at;
+ALTER TABLE users DROP COLUMN deletion_requested_at;
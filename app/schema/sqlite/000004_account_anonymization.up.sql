@@ -0,0 +1,21 @@
+ALTER TABLE users ADD COLUMN deletion_requested_at TIMESTAMP;
+ALTER TABLE users ADD COLUMN anonymized_at TIMESTAMP;
+
+-- SQLite cannot alter a foreign key, so ON DELETE RESTRICT on orders.user_id
+-- and restaurant_reviews.user_id is enforced by triggers, which run before
+-- the declared cascade.
+CREATE TRIGGER IF NOT EXISTS orders_user_id_restrict
+    BEFORE DELETE ON users
+    FOR EACH ROW
+    WHEN EXISTS (SELECT 1 FROM orders WHERE user_id = OLD.id)
+BEGIN
+    SELECT RAISE(ABORT, 'FOREIGN KEY constraint failed');
+END;
+
+CREATE TRIGGER IF NOT EXISTS restaurant_reviews_user_id_restrict
+    BEFORE DELETE ON users
+    FOR EACH ROW
+    WHEN EXISTS (SELECT 1 FROM restaurant_reviews WHERE user_id = OLD.id)
+BEGIN
+    SELECT RAISE(ABORT, 'FOREIGN KEY constraint failed');
+END;
@@ -0,0 +1,4 @@
+ALTER TABLE orders DROP COLUMN version;
+ALTER TABLE foods DROP COLUMN version;
+ALTER TABLE tables DROP COLUMN version;
+ALTER TABLE restaurants DROP COLUMN version;
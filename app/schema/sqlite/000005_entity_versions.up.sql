@@ -0,0 +1,4 @@
+ALTER TABLE restaurants ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE tables ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE foods ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE orders ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,2 @@
+-- SQLite has no tsvector or pg_trgm: search matches substrings instead, see
+-- SearchRepository.
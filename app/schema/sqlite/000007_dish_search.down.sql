@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_foods_price;
+
+ALTER TABLE restaurants DROP COLUMN longitude;
+ALTER TABLE restaurants DROP COLUMN latitude;
+ALTER TABLE foods DROP COLUMN gluten_free;
+ALTER TABLE foods DROP COLUMN halal;
+ALTER TABLE foods DROP COLUMN vegan;
+ALTER TABLE foods DROP COLUMN vegetarian;
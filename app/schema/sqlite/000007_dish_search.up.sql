@@ -0,0 +1,8 @@
+ALTER TABLE foods ADD COLUMN vegetarian BOOLEAN NOT NULL DEFAULT 0;
+ALTER TABLE foods ADD COLUMN vegan BOOLEAN NOT NULL DEFAULT 0;
+ALTER TABLE foods ADD COLUMN halal BOOLEAN NOT NULL DEFAULT 0;
+ALTER TABLE foods ADD COLUMN gluten_free BOOLEAN NOT NULL DEFAULT 0;
+ALTER TABLE restaurants ADD COLUMN latitude REAL;
+ALTER TABLE restaurants ADD COLUMN longitude REAL;
+
+CREATE INDEX IF NOT EXISTS idx_foods_price ON foods (price);
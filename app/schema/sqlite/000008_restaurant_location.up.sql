@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS idx_restaurants_location ON restaurants (latitude, longitude);
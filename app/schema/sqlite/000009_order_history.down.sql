@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS order_foods_food_id_restrict;
+DROP TRIGGER IF EXISTS orders_restaurant_id_restrict;
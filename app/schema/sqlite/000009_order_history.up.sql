@@ -0,0 +1,18 @@
+-- SQLite cannot alter a foreign key, so ON DELETE RESTRICT on
+-- orders.restaurant_id and order_foods.food_id is enforced by triggers, which
+-- run before the declared cascade.
+CREATE TRIGGER IF NOT EXISTS orders_restaurant_id_restrict
+    BEFORE DELETE ON restaurants
+    FOR EACH ROW
+    WHEN EXISTS (SELECT 1 FROM orders WHERE restaurant_id = OLD.id)
+BEGIN
+    SELECT RAISE(ABORT, 'FOREIGN KEY constraint failed');
+END;
+
+CREATE TRIGGER IF NOT EXISTS order_foods_food_id_restrict
+    BEFORE DELETE ON foods
+    FOR EACH ROW
+    WHEN EXISTS (SELECT 1 FROM order_foods WHERE food_id = OLD.id)
+BEGIN
+    SELECT RAISE(ABORT, 'FOREIGN KEY constraint failed');
+END;
@@ -0,0 +1,3 @@
+-- Disabled accounts are soft-deleted like deleted ones, but retention keeps
+-- them until they are enabled again.
+ALTER TABLE users ADD COLUMN disabled_at TIMESTAMP;